@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/tanqiangyes/go-word/pkg/utils"
 	"github.com/tanqiangyes/go-word/pkg/word"
@@ -65,7 +66,7 @@ func (cli *CLI) registerCommands() {
 	cli.registerCommand(&Command{
 		Name:        "create",
 		Description: "创建新文档",
-		Usage:       "go-word create <输出路径> [内容文件]",
+		Usage:       "go-word create <输出路径> [内容文件(.txt/.md/.html)]",
 		Run:         cli.cmdCreate,
 	})
 
@@ -76,6 +77,13 @@ func (cli *CLI) registerCommands() {
 		Run:         cli.cmdConvert,
 	})
 
+	cli.registerCommand(&Command{
+		Name:        "translate",
+		Description: "导入XLIFF译文",
+		Usage:       "go-word translate <输入路径> <XLIFF文件> <输出路径>",
+		Run:         cli.cmdTranslate,
+	})
+
 	cli.registerCommand(&Command{
 		Name:        "protect",
 		Description: "保护文档",
@@ -245,7 +253,17 @@ func (cli *CLI) cmdCreate(args []string) error {
 	}
 
 	// Add content if provided
-	if contentPath != "" {
+	if isMarkdownFile(contentPath) {
+		// Markdown content keeps headings, lists, tables, links and images
+		if err := docWriter.ImportMarkdownFile(contentPath); err != nil {
+			return fmt.Errorf("无法导入Markdown文件: %w", err)
+		}
+	} else if isHTMLFile(contentPath) {
+		// HTML content is sanitized; scripts and styles are dropped
+		if err := docWriter.ImportHTMLFile(contentPath); err != nil {
+			return fmt.Errorf("无法导入HTML文件: %w", err)
+		}
+	} else if contentPath != "" {
 		content, err := os.ReadFile(contentPath)
 		if err != nil {
 			return fmt.Errorf("无法读取内容文件: %w", err)
@@ -274,6 +292,26 @@ func (cli *CLI) cmdCreate(args []string) error {
 	return nil
 }
 
+// isMarkdownFile reports whether the content file is Markdown
+func isMarkdownFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	default:
+		return false
+	}
+}
+
+// isHTMLFile reports whether the content file is HTML
+func isHTMLFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return true
+	default:
+		return false
+	}
+}
+
 // cmdConvert converts document format
 func (cli *CLI) cmdConvert(args []string) error {
 	if len(args) < 2 {
@@ -311,6 +349,26 @@ func (cli *CLI) cmdConvert(args []string) error {
 		if err := formatSupport.ConvertFormat(word.RtfFormat); err != nil {
 			return fmt.Errorf("无法转换为 .rtf 格式: %w", err)
 		}
+	case ".html", ".htm":
+		if err := word.NewHTMLExporter(doc, nil).ExportToHTML(outputPath); err != nil {
+			return fmt.Errorf("无法转换为 HTML 格式: %w", err)
+		}
+	case ".md", ".markdown":
+		if err := word.NewMarkdownExporter(doc, nil).ExportToMarkdown(outputPath); err != nil {
+			return fmt.Errorf("无法转换为 Markdown 格式: %w", err)
+		}
+	case ".epub":
+		if err := word.NewEPUBExporter(doc, nil).ExportToEPUB(outputPath); err != nil {
+			return fmt.Errorf("无法转换为 EPUB 格式: %w", err)
+		}
+	case ".tex":
+		if err := word.NewLaTeXExporter(doc, nil).ExportToLaTeX(outputPath); err != nil {
+			return fmt.Errorf("无法转换为 LaTeX 格式: %w", err)
+		}
+	case ".xlf", ".xliff":
+		if err := word.NewXLIFFExporter(doc, nil).ExportToXLIFF(outputPath); err != nil {
+			return fmt.Errorf("无法转换为 XLIFF 格式: %w", err)
+		}
 	default:
 		return fmt.Errorf("不支持的输出格式: %s", outputExt)
 	}
@@ -319,6 +377,32 @@ func (cli *CLI) cmdConvert(args []string) error {
 	return nil
 }
 
+// cmdTranslate writes the translations of an XLIFF file into a copy of a document
+func (cli *CLI) cmdTranslate(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("用法: %s", cli.commands["translate"].Usage)
+	}
+
+	inputPath := args[0]
+	xliffPath := args[1]
+	outputPath := args[2]
+
+	cli.logger.Info("正在导入译文: %s -> %s", xliffPath, outputPath)
+
+	doc, err := word.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("无法打开输入文档: %w", err)
+	}
+	defer doc.Close()
+
+	if err := word.NewXLIFFImporter(doc).ImportXLIFF(xliffPath, outputPath); err != nil {
+		return fmt.Errorf("无法导入译文: %w", err)
+	}
+
+	cli.logger.Info("译文导入完成: %s", outputPath)
+	return nil
+}
+
 // cmdProtect protects a document
 func (cli *CLI) cmdProtect(args []string) error {
 	if len(args) < 2 {
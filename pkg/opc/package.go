@@ -10,6 +10,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"sort"
 	"strings"
 )
 
@@ -254,4 +255,66 @@ func (c *Container) SaveToFile(filename string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// WriteModified writes the opened package to w, replacing the content of
+// the parts in replaced. Entries that are not replaced are copied without
+// recompression, so the rest of the package stays byte-for-byte identical.
+// Replaced parts that do not exist in the package are added at the end.
+func (c *Container) WriteModified(w io.Writer, replaced map[string][]byte) error {
+	if c.Reader == nil {
+		return fmt.Errorf("container not opened for reading")
+	}
+
+	writer := zip.NewWriter(w)
+	written := make(map[string]bool, len(replaced))
+
+	for _, file := range c.Reader.File {
+		content, ok := replaced[file.Name]
+		if !ok {
+			raw, err := file.OpenRaw()
+			if err != nil {
+				return fmt.Errorf("failed to read zip entry %s: %w", file.Name, err)
+			}
+			header := file.FileHeader
+			entry, err := writer.CreateRaw(&header)
+			if err != nil {
+				return fmt.Errorf("failed to create zip entry %s: %w", file.Name, err)
+			}
+			if _, err := io.Copy(entry, raw); err != nil {
+				return fmt.Errorf("failed to copy zip entry %s: %w", file.Name, err)
+			}
+			continue
+		}
+
+		header := &zip.FileHeader{Name: file.Name, Method: zip.Deflate, Modified: file.Modified}
+		entry, err := writer.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to create zip entry %s: %w", file.Name, err)
+		}
+		if _, err := entry.Write(content); err != nil {
+			return fmt.Errorf("failed to write part %s: %w", file.Name, err)
+		}
+		written[file.Name] = true
+	}
+
+	var added []string
+	for name := range replaced {
+		if !written[name] {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	for _, name := range added {
+		content := replaced[name]
+		entry, err := writer.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to create zip entry %s: %w", name, err)
+		}
+		if _, err := entry.Write(content); err != nil {
+			return fmt.Errorf("failed to write part %s: %w", name, err)
+		}
+	}
+
+	return writer.Close()
+}
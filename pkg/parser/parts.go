@@ -0,0 +1,394 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// WordNumbering represents the numbering definitions (word/numbering.xml)
+type WordNumbering struct {
+	XMLName      xml.Name          `xml:"numbering"`
+	AbstractNums []WordAbstractNum `xml:"abstractNum"`
+	Nums         []WordNum         `xml:"num"`
+}
+
+// WordAbstractNum represents an abstract list definition
+type WordAbstractNum struct {
+	ID     string          `xml:"abstractNumId,attr"`
+	Levels []WordListLevel `xml:"lvl"`
+}
+
+// WordListLevel represents a single level of a list definition
+type WordListLevel struct {
+	Level  string     `xml:"ilvl,attr"`
+	Start  *ValueProp `xml:"start,omitempty"`
+	Format *ValueProp `xml:"numFmt,omitempty"`
+	Text   *ValueProp `xml:"lvlText,omitempty"`
+}
+
+// WordNum represents a list instance referencing an abstract definition
+type WordNum struct {
+	ID            string              `xml:"numId,attr"`
+	AbstractNumID ValueProp           `xml:"abstractNumId"`
+	Overrides     []WordLevelOverride `xml:"lvlOverride"`
+}
+
+// WordLevelOverride overrides the start value of a list level
+type WordLevelOverride struct {
+	Level         string     `xml:"ilvl,attr"`
+	StartOverride *ValueProp `xml:"startOverride,omitempty"`
+}
+
+// ParseNumbering parses the numbering definitions part
+func ParseNumbering(data []byte) (*WordNumbering, error) {
+	var numbering WordNumbering
+	if err := xml.Unmarshal(data, &numbering); err != nil {
+		return nil, fmt.Errorf("failed to parse numbering: %w", err)
+	}
+	return &numbering, nil
+}
+
+// Level returns the level definition used by a list instance, or nil
+func (n *WordNumbering) Level(numID, level int) *WordListLevel {
+	if n == nil {
+		return nil
+	}
+
+	id := strconv.Itoa(numID)
+	for _, num := range n.Nums {
+		if num.ID != id {
+			continue
+		}
+		for i := range n.AbstractNums {
+			abstract := &n.AbstractNums[i]
+			if abstract.ID != num.AbstractNumID.Val {
+				continue
+			}
+			for j := range abstract.Levels {
+				if abstract.Levels[j].Level == strconv.Itoa(level) {
+					return &abstract.Levels[j]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Format returns the number format (bullet, decimal, lowerLetter, ...) of a
+// list level. An empty string is returned for unknown lists.
+func (n *WordNumbering) Format(numID, level int) string {
+	lvl := n.Level(numID, level)
+	if lvl == nil || lvl.Format == nil {
+		return ""
+	}
+	return lvl.Format.Val
+}
+
+// AbstractNumID returns the abstract definition of a list instance, or an
+// empty string for unknown lists. Instances sharing an abstract definition
+// continue each other's numbering.
+func (n *WordNumbering) AbstractNumID(numID int) string {
+	if n == nil {
+		return ""
+	}
+
+	id := strconv.Itoa(numID)
+	for _, num := range n.Nums {
+		if num.ID == id {
+			return num.AbstractNumID.Val
+		}
+	}
+	return ""
+}
+
+// StartOverride returns the start override of a list level and whether the
+// list instance has one
+func (n *WordNumbering) StartOverride(numID, level int) (int, bool) {
+	if n == nil {
+		return 0, false
+	}
+
+	id := strconv.Itoa(numID)
+	for _, num := range n.Nums {
+		if num.ID != id {
+			continue
+		}
+		for _, override := range num.Overrides {
+			if override.Level == strconv.Itoa(level) && override.StartOverride != nil {
+				if start, err := strconv.Atoi(override.StartOverride.Val); err == nil {
+					return start, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// Start returns the first number of a list level, honouring level overrides
+func (n *WordNumbering) Start(numID, level int) int {
+	if start, ok := n.StartOverride(numID, level); ok {
+		return start
+	}
+
+	if lvl := n.Level(numID, level); lvl != nil && lvl.Start != nil {
+		if start, err := strconv.Atoi(lvl.Start.Val); err == nil {
+			return start
+		}
+	}
+	return 1
+}
+
+// wordNotes represents the footnotes part (word/footnotes.xml)
+type wordNotes struct {
+	Notes []wordNote `xml:"footnote"`
+}
+
+// wordNote represents a single footnote
+type wordNote struct {
+	ID         string          `xml:"id,attr"`
+	Type       string          `xml:"type,attr"`
+	Paragraphs []WordParagraph `xml:"p"`
+}
+
+// ParseFootnotes parses the footnotes part and returns the paragraphs of
+// every normal footnote keyed by footnote ID. Separator footnotes are skipped.
+func ParseFootnotes(data []byte, relationships map[string]string) (map[string][]types.Paragraph, error) {
+	var notes wordNotes
+	if err := xml.Unmarshal(data, &notes); err != nil {
+		return nil, fmt.Errorf("failed to parse footnotes: %w", err)
+	}
+
+	parser := &WordMLParser{Relationships: relationships}
+	footnotes := make(map[string][]types.Paragraph)
+	for _, note := range notes.Notes {
+		if note.Type != "" && note.Type != "normal" {
+			continue
+		}
+		paragraphs := make([]types.Paragraph, 0, len(note.Paragraphs))
+		for _, wp := range note.Paragraphs {
+			paragraphs = append(paragraphs, parser.convertParagraph(wp))
+		}
+		footnotes[note.ID] = paragraphs
+	}
+
+	return footnotes, nil
+}
+
+// ParseHeaderFooter parses a header or footer part (word/header1.xml,
+// word/footer1.xml) with the relationships of that part and returns its
+// paragraphs and tables
+func ParseHeaderFooter(data []byte, relationships map[string]string) (*types.DocumentContent, error) {
+	var doc WordDocument
+	if err := xml.Unmarshal(data, &doc.Body); err != nil {
+		return nil, fmt.Errorf("failed to parse header or footer: %w", err)
+	}
+
+	parser := &WordMLParser{Relationships: relationships}
+	return &types.DocumentContent{
+		Text:       parser.ExtractText(&doc),
+		Paragraphs: parser.ExtractParagraphs(&doc),
+		Tables:     parser.ExtractTables(&doc),
+	}, nil
+}
+
+// WordStyles represents the style definitions part (word/styles.xml)
+type WordStyles struct {
+	XMLName  xml.Name `xml:"styles"`
+	Defaults struct {
+		RunProperties struct {
+			Properties *RunProps `xml:"rPr,omitempty"`
+		} `xml:"rPrDefault"`
+		ParagraphProperties struct {
+			Properties *ParagraphProps `xml:"pPr,omitempty"`
+		} `xml:"pPrDefault"`
+	} `xml:"docDefaults"`
+	Styles []WordStyle `xml:"style"`
+}
+
+// WordStyle represents a single style definition
+type WordStyle struct {
+	Type                string          `xml:"type,attr"`
+	ID                  string          `xml:"styleId,attr"`
+	Default             string          `xml:"default,attr,omitempty"`
+	Name                ValueProp       `xml:"name"`
+	BasedOn             *ValueProp      `xml:"basedOn,omitempty"`
+	ParagraphProperties *ParagraphProps `xml:"pPr,omitempty"`
+	RunProperties       *RunProps       `xml:"rPr,omitempty"`
+}
+
+// ParseStyles parses the style definitions part
+func ParseStyles(data []byte) (*WordStyles, error) {
+	var styles WordStyles
+	if err := xml.Unmarshal(data, &styles); err != nil {
+		return nil, fmt.Errorf("failed to parse styles: %w", err)
+	}
+	return &styles, nil
+}
+
+// ParseStyleNames parses the styles part and returns the style names keyed
+// by style ID, e.g. "Heading1" -> "heading 1"
+func ParseStyleNames(data []byte) (map[string]string, error) {
+	styles, err := ParseStyles(data)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(styles.Styles))
+	for _, style := range styles.Styles {
+		names[style.ID] = style.Name.Val
+	}
+	return names, nil
+}
+
+// ParseDefaultLanguage parses the styles part and returns the language of
+// the document defaults, e.g. "en-US". The East Asian language is used
+// when no Latin language is set.
+func ParseDefaultLanguage(data []byte) (string, error) {
+	styles, err := ParseStyles(data)
+	if err != nil {
+		return "", err
+	}
+
+	properties := styles.Defaults.RunProperties.Properties
+	if properties == nil || properties.Lang == nil {
+		return "", nil
+	}
+	if properties.Lang.Val != "" {
+		return properties.Lang.Val, nil
+	}
+	return properties.Lang.EastAsia, nil
+}
+
+// WordSettings represents the document settings part (word/settings.xml)
+type WordSettings struct {
+	XMLName xml.Name `xml:"settings"`
+	// EvenAndOddHeaders uses the even page headers and footers
+	EvenAndOddHeaders *OnOffProp `xml:"evenAndOddHeaders"`
+	// AutoHyphenation hyphenates words at the end of lines
+	AutoHyphenation *OnOffProp `xml:"autoHyphenation"`
+	// HyphenationZone is the space in twips that may be left at the end
+	// of a line before a word is hyphenated
+	HyphenationZone *ValueProp `xml:"hyphenationZone"`
+	// ConsecutiveHyphenLimit is the most lines in a row that may end with
+	// a hyphen; 0 means no limit
+	ConsecutiveHyphenLimit *ValueProp `xml:"consecutiveHyphenLimit"`
+}
+
+// ParseSettings parses the document settings part
+func ParseSettings(data []byte) (*WordSettings, error) {
+	var settings WordSettings
+	if err := xml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// WordComment represents a comment from the comments part
+type WordComment struct {
+	ID         string
+	Author     string
+	Initials   string
+	Date       string
+	Paragraphs []types.Paragraph
+}
+
+// wordComments represents the comments part (word/comments.xml)
+type wordComments struct {
+	Comments []struct {
+		ID         string          `xml:"id,attr"`
+		Author     string          `xml:"author,attr"`
+		Initials   string          `xml:"initials,attr"`
+		Date       string          `xml:"date,attr"`
+		Paragraphs []WordParagraph `xml:"p"`
+	} `xml:"comment"`
+}
+
+// ParseComments parses the comments part and returns the comments keyed by ID
+func ParseComments(data []byte, relationships map[string]string) (map[string]*WordComment, error) {
+	var parsed wordComments
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse comments: %w", err)
+	}
+
+	parser := &WordMLParser{Relationships: relationships}
+	comments := make(map[string]*WordComment, len(parsed.Comments))
+	for _, c := range parsed.Comments {
+		comment := &WordComment{
+			ID:       c.ID,
+			Author:   c.Author,
+			Initials: c.Initials,
+			Date:     c.Date,
+		}
+		for _, wp := range c.Paragraphs {
+			comment.Paragraphs = append(comment.Paragraphs, parser.convertParagraph(wp))
+		}
+		comments[c.ID] = comment
+	}
+
+	return comments, nil
+}
+
+// wordCoreProperties represents the core properties part (docProps/core.xml)
+type wordCoreProperties struct {
+	Title          string `xml:"title"`
+	Subject        string `xml:"subject"`
+	Creator        string `xml:"creator"`
+	Keywords       string `xml:"keywords"`
+	Description    string `xml:"description"`
+	Language       string `xml:"language"`
+	Category       string `xml:"category"`
+	Version        string `xml:"version"`
+	Revision       string `xml:"revision"`
+	LastModifiedBy string `xml:"lastModifiedBy"`
+	Created        string `xml:"created"`
+	Modified       string `xml:"modified"`
+	LastPrinted    string `xml:"lastPrinted"`
+}
+
+// ParseCoreProperties parses the core properties part. Keywords are split
+// at commas and semicolons; dates that are not W3CDTF are ignored.
+func ParseCoreProperties(data []byte) (*types.CoreProperties, error) {
+	var parsed wordCoreProperties
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse core properties: %w", err)
+	}
+
+	properties := &types.CoreProperties{
+		Title:          strings.TrimSpace(parsed.Title),
+		Subject:        strings.TrimSpace(parsed.Subject),
+		Creator:        strings.TrimSpace(parsed.Creator),
+		Description:    strings.TrimSpace(parsed.Description),
+		Language:       strings.TrimSpace(parsed.Language),
+		Category:       strings.TrimSpace(parsed.Category),
+		Version:        strings.TrimSpace(parsed.Version),
+		LastModifiedBy: strings.TrimSpace(parsed.LastModifiedBy),
+		Created:        parseW3CDate(parsed.Created),
+		Modified:       parseW3CDate(parsed.Modified),
+		LastPrinted:    parseW3CDate(parsed.LastPrinted),
+	}
+	properties.Revision, _ = strconv.Atoi(strings.TrimSpace(parsed.Revision))
+
+	for _, keyword := range strings.FieldsFunc(parsed.Keywords, func(r rune) bool { return r == ',' || r == ';' }) {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			properties.Keywords = append(properties.Keywords, keyword)
+		}
+	}
+
+	return properties, nil
+}
+
+// parseW3CDate parses the date formats used in core properties
+func parseW3CDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
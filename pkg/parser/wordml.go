@@ -4,6 +4,7 @@ package parser
 import (
 	"encoding/xml"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
@@ -11,7 +12,11 @@ import (
 )
 
 // WordMLParser provides word specific parsing
-type WordMLParser struct{}
+type WordMLParser struct {
+	// Relationships maps relationship IDs of the main document part to
+	// their resolved targets, used for hyperlinks and images
+	Relationships map[string]string
+}
 
 // WordDocument represents the complete Word document structure
 type WordDocument struct {
@@ -24,6 +29,51 @@ type WordBody struct {
 	XMLName    xml.Name        `xml:"body"`
 	Paragraphs []WordParagraph `xml:"p"`
 	Tables     []WordTable     `xml:"tbl"`
+	// Section holds the properties of the last section
+	Section *SectionProps `xml:"sectPr"`
+}
+
+// UnmarshalXML decodes the body children in document order so that the
+// position of every table relative to the paragraphs is preserved
+func (b *WordBody) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	b.XMLName = start.Name
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				var paragraph WordParagraph
+				if err := d.DecodeElement(&paragraph, &t); err != nil {
+					return err
+				}
+				b.Paragraphs = append(b.Paragraphs, paragraph)
+			case "tbl":
+				var table WordTable
+				if err := d.DecodeElement(&table, &t); err != nil {
+					return err
+				}
+				table.Position = len(b.Paragraphs)
+				b.Tables = append(b.Tables, table)
+			case "sectPr":
+				var section SectionProps
+				if err := d.DecodeElement(&section, &t); err != nil {
+					return err
+				}
+				b.Section = &section
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
 }
 
 // WordParagraph represents a paragraph in Word
@@ -32,12 +82,281 @@ type WordParagraph struct {
 	Properties *ParagraphProps `xml:"pPr,omitempty"`
 	Runs       []WordRun       `xml:"r"`
 	Text       string          `xml:",chardata"`
+	// Bookmarks are the names of the bookmarks that start in the paragraph
+	Bookmarks []string `xml:"-"`
+	// fields are the complex fields that are being decoded, innermost last
+	fields []wordField
+}
+
+// wordField is a complex field (w:fldChar) of a paragraph
+type wordField struct {
+	instruction string
+	// result is the index of the first result run, or -1 before the
+	// field separator
+	result int
+}
+
+// UnmarshalXML decodes the paragraph runs in document order, including
+// runs nested in hyperlinks, insertions, smart tags and content controls
+func (wp *WordParagraph) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	wp.XMLName = start.Name
+	return wp.decodeRuns(d, "")
+}
+
+// decodeRuns decodes runs until the end of the current element. Runs inside
+// a hyperlink are tagged with its relationship ID or "#anchor".
+func (wp *WordParagraph) decodeRuns(d *xml.Decoder, hyperlink string) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "pPr":
+				var props ParagraphProps
+				if err := d.DecodeElement(&props, &t); err != nil {
+					return err
+				}
+				wp.Properties = &props
+			case "r":
+				var run WordRun
+				if err := d.DecodeElement(&run, &t); err != nil {
+					return err
+				}
+				run.Hyperlink = hyperlink
+				wp.trackField(run)
+				wp.Runs = append(wp.Runs, run)
+			case "hyperlink":
+				target := hyperlink
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "id":
+						target = attr.Value
+					case "anchor":
+						if target == hyperlink {
+							target = "#" + attr.Value
+						}
+					}
+				}
+				if err := wp.decodeRuns(d, target); err != nil {
+					return err
+				}
+			case "bookmarkStart":
+				for _, attr := range t.Attr {
+					// _GoBack 是Word记录上次编辑位置的隐藏书签
+					if attr.Name.Local == "name" && attr.Value != "_GoBack" {
+						wp.Bookmarks = append(wp.Bookmarks, attr.Value)
+					}
+				}
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			case "fldSimple":
+				start := len(wp.Runs)
+				if err := wp.decodeRuns(d, hyperlink); err != nil {
+					return err
+				}
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "instr" {
+						wp.fieldResult(start, attr.Value)
+					}
+				}
+			case "ins", "smartTag", "sdt", "sdtContent", "customXml":
+				if err := wp.decodeRuns(d, hyperlink); err != nil {
+					return err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			wp.Text += string(t)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// trackField follows the complex fields of the paragraph before run is
+// added: it collects field instructions and tags the result runs when a
+// field ends
+func (wp *WordParagraph) trackField(run WordRun) {
+	if run.FieldChar == nil {
+		if n := len(wp.fields); n > 0 && run.InstrText != nil && wp.fields[n-1].result < 0 {
+			wp.fields[n-1].instruction += run.InstrText.Content
+		}
+		return
+	}
+
+	n := len(wp.fields)
+	switch run.FieldChar.Type {
+	case "begin":
+		wp.fields = append(wp.fields, wordField{result: -1})
+	case "separate":
+		if n > 0 {
+			// 分隔符所在的运行不属于结果
+			wp.fields[n-1].result = len(wp.Runs) + 1
+		}
+	case "end":
+		if n > 0 {
+			field := wp.fields[n-1]
+			wp.fields = wp.fields[:n-1]
+			if field.result < 0 {
+				field.result = len(wp.Runs)
+			}
+			wp.fieldResult(field.result, field.instruction)
+		}
+	}
+}
+
+// fieldResult tags the result runs of a field, from index start to the
+// end of the runs, with its instruction. The result text is merged into
+// the first run so that the field can be replaced as a whole; a field
+// without a result gets an empty run.
+func (wp *WordParagraph) fieldResult(start int, instruction string) {
+	instruction = strings.TrimSpace(instruction)
+	if instruction == "" {
+		return
+	}
+	if start >= len(wp.Runs) {
+		wp.Runs = append(wp.Runs, WordRun{Field: instruction})
+		return
+	}
+
+	first := &wp.Runs[start]
+	first.Field = instruction
+	runs := wp.Runs[:start+1]
+	for _, run := range wp.Runs[start+1:] {
+		if run.Text != nil && run.Tab == nil && run.Break == nil && run.Drawing == nil {
+			if first.Text == nil {
+				first.Text = &WordText{}
+			}
+			first.Text.Content += run.Text.Content
+			continue
+		}
+		runs = append(runs, run)
+	}
+	wp.Runs = runs
 }
 
 // ParagraphProps represents paragraph properties
 type ParagraphProps struct {
-	XMLName xml.Name `xml:"pPr"`
-	Style   *Style   `xml:"pStyle,omitempty"`
+	XMLName     xml.Name          `xml:"pPr"`
+	Style       *Style            `xml:"pStyle,omitempty"`
+	Numbering   *NumberingProps   `xml:"numPr,omitempty"`
+	Alignment   *ValueProp        `xml:"jc,omitempty"`
+	Spacing     *SpacingProps     `xml:"spacing,omitempty"`
+	Indentation *IndentationProps `xml:"ind,omitempty"`
+	// PageBreakBefore starts the paragraph on a new page
+	PageBreakBefore *OnOffProp `xml:"pageBreakBefore,omitempty"`
+	// Bidi makes the paragraph right-to-left
+	Bidi *OnOffProp `xml:"bidi,omitempty"`
+	// SuppressAutoHyphens excludes the paragraph from automatic hyphenation
+	SuppressAutoHyphens *OnOffProp `xml:"suppressAutoHyphens,omitempty"`
+	// Section ends a section at the paragraph
+	Section *SectionProps `xml:"sectPr,omitempty"`
+}
+
+// SectionProps represents section properties (w:sectPr)
+type SectionProps struct {
+	Type        *ValueProp        `xml:"type,omitempty"`
+	Headers     []HeaderFooterRef `xml:"headerReference"`
+	Footers     []HeaderFooterRef `xml:"footerReference"`
+	PageSize    *PageSizeProps    `xml:"pgSz,omitempty"`
+	Margins     *PageMarginProps  `xml:"pgMar,omitempty"`
+	PageNumbers *PageNumberProps  `xml:"pgNumType,omitempty"`
+	Columns     *ColumnsProps     `xml:"cols,omitempty"`
+	TitlePage   *OnOffProp        `xml:"titlePg,omitempty"`
+	// TextDirection is the direction of the text flow, such as tbRl
+	TextDirection *ValueProp `xml:"textDirection,omitempty"`
+}
+
+// PageSizeProps represents the page size of a section in twips
+type PageSizeProps struct {
+	Width  string `xml:"w,attr,omitempty"`
+	Height string `xml:"h,attr,omitempty"`
+	// Orient is portrait or landscape; the size is already swapped
+	Orient string `xml:"orient,attr,omitempty"`
+}
+
+// ColumnsProps represents the text columns of a section
+type ColumnsProps struct {
+	Num   string `xml:"num,attr,omitempty"`
+	Space string `xml:"space,attr,omitempty"`
+}
+
+// HeaderFooterRef references a header or footer part by relationship ID
+type HeaderFooterRef struct {
+	// Type is default, first or even
+	Type string `xml:"type,attr"`
+	ID   string `xml:"id,attr"`
+}
+
+// PageMarginProps represents the page margins of a section in twips
+type PageMarginProps struct {
+	Top    string `xml:"top,attr,omitempty"`
+	Right  string `xml:"right,attr,omitempty"`
+	Bottom string `xml:"bottom,attr,omitempty"`
+	Left   string `xml:"left,attr,omitempty"`
+	Header string `xml:"header,attr,omitempty"`
+	Footer string `xml:"footer,attr,omitempty"`
+}
+
+// PageNumberProps represents the page numbering of a section
+type PageNumberProps struct {
+	Format string `xml:"fmt,attr,omitempty"`
+	Start  string `xml:"start,attr,omitempty"`
+}
+
+// SpacingProps represents paragraph spacing in twips
+type SpacingProps struct {
+	Before string `xml:"before,attr,omitempty"`
+	After  string `xml:"after,attr,omitempty"`
+	Line   string `xml:"line,attr,omitempty"`
+	// LineRule is auto (Line in 240ths of a line), exact or atLeast (twips)
+	LineRule string `xml:"lineRule,attr,omitempty"`
+}
+
+// IndentationProps represents paragraph indentation in twips
+type IndentationProps struct {
+	Left      string `xml:"left,attr,omitempty"`
+	Right     string `xml:"right,attr,omitempty"`
+	FirstLine string `xml:"firstLine,attr,omitempty"`
+	Hanging   string `xml:"hanging,attr,omitempty"`
+}
+
+// NumberingProps represents the list numbering of a paragraph
+type NumberingProps struct {
+	XMLName xml.Name   `xml:"numPr"`
+	Level   *ValueProp `xml:"ilvl,omitempty"`
+	NumID   *ValueProp `xml:"numId,omitempty"`
+}
+
+// ValueProp represents a property that only has a w:val attribute
+type ValueProp struct {
+	Val string `xml:"val,attr"`
+}
+
+// OnOffProp represents a toggle property such as w:strike
+type OnOffProp struct {
+	Val string `xml:"val,attr,omitempty"`
+}
+
+// IsOn reports whether the toggle property is enabled
+func (o *OnOffProp) IsOn() bool {
+	if o == nil {
+		return false
+	}
+	switch o.Val {
+	case "false", "0", "off":
+		return false
+	default:
+		return true
+	}
 }
 
 // Style represents a style reference
@@ -53,6 +372,99 @@ type WordRun struct {
 	Text       *WordText `xml:"t,omitempty"`
 	Tab        *Tab      `xml:"tab,omitempty"`
 	Break      *Break    `xml:"br,omitempty"`
+	Drawing    *Drawing  `xml:"drawing,omitempty"`
+	// FootnoteReference marks the run as a footnote reference mark
+	FootnoteReference *NoteReference `xml:"footnoteReference,omitempty"`
+	// CommentReference marks the run as the anchor of a comment
+	CommentReference *NoteReference `xml:"commentReference,omitempty"`
+	// FieldChar and InstrText are the parts of a complex field
+	FieldChar *FieldChar `xml:"fldChar,omitempty"`
+	InstrText *FieldCode `xml:"instrText,omitempty"`
+	// Field is the instruction of the field whose result the run holds
+	Field string `xml:"-"`
+	// Hyperlink is the relationship ID or "#anchor" of the enclosing hyperlink
+	Hyperlink string `xml:"-"`
+	// Ruby is a phonetic guide that takes the place of the run text
+	Ruby *Ruby `xml:"ruby,omitempty"`
+}
+
+// Ruby represents a phonetic guide with its annotation and base runs
+type Ruby struct {
+	Properties *RubyProps `xml:"rubyPr,omitempty"`
+	Annotation []WordRun  `xml:"rt>r"`
+	Base       []WordRun  `xml:"rubyBase>r"`
+}
+
+// RubyProps represents the properties of a phonetic guide. Sizes are in
+// half points.
+type RubyProps struct {
+	Align    *ValueProp `xml:"rubyAlign,omitempty"`
+	Size     *ValueProp `xml:"hps,omitempty"`
+	Raise    *ValueProp `xml:"hpsRaise,omitempty"`
+	BaseSize *ValueProp `xml:"hpsBaseText,omitempty"`
+	Language *ValueProp `xml:"lid,omitempty"`
+}
+
+// FieldChar represents a complex field character
+type FieldChar struct {
+	// Type is begin, separate or end
+	Type string `xml:"fldCharType,attr"`
+}
+
+// FieldCode represents the instruction text of a complex field
+type FieldCode struct {
+	Content string `xml:",chardata"`
+}
+
+// NoteReference represents a footnote or endnote reference
+type NoteReference struct {
+	ID string `xml:"id,attr"`
+}
+
+// Drawing represents a DrawingML picture in a run
+type Drawing struct {
+	XMLName xml.Name       `xml:"drawing"`
+	Inline  *DrawingObject `xml:"inline,omitempty"`
+	Anchor  *DrawingObject `xml:"anchor,omitempty"`
+}
+
+// DrawingObject represents an inline or anchored drawing
+type DrawingObject struct {
+	Extent struct {
+		Cx int64 `xml:"cx,attr"`
+		Cy int64 `xml:"cy,attr"`
+	} `xml:"extent"`
+	DocPr struct {
+		Name  string `xml:"name,attr"`
+		Descr string `xml:"descr,attr"`
+		Title string `xml:"title,attr"`
+	} `xml:"docPr"`
+	Blip struct {
+		Embed string `xml:"embed,attr"`
+		// SVG is the SVG picture that the blip shows as a bitmap fallback
+		SVG struct {
+			Embed string `xml:"embed,attr"`
+		} `xml:"extLst>ext>svgBlip"`
+	} `xml:"graphic>graphicData>pic>blipFill>blip"`
+
+	// 以下仅用于浮动图片
+	DistL            int64            `xml:"distL,attr"`
+	BehindDoc        bool             `xml:"behindDoc,attr"`
+	PositionH        *DrawingPosition `xml:"positionH,omitempty"`
+	PositionV        *DrawingPosition `xml:"positionV,omitempty"`
+	WrapSquare       *struct{}        `xml:"wrapSquare,omitempty"`
+	WrapTight        *struct{}        `xml:"wrapTight,omitempty"`
+	WrapThrough      *struct{}        `xml:"wrapThrough,omitempty"`
+	WrapTopAndBottom *struct{}        `xml:"wrapTopAndBottom,omitempty"`
+	WrapNone         *struct{}        `xml:"wrapNone,omitempty"`
+}
+
+// DrawingPosition represents the horizontal or vertical position of an
+// anchored drawing
+type DrawingPosition struct {
+	RelativeFrom string `xml:"relativeFrom,attr"`
+	PosOffset    *int64 `xml:"posOffset,omitempty"`
+	Align        string `xml:"align,omitempty"`
 }
 
 // RunProps represents run properties
@@ -64,6 +476,17 @@ type RunProps struct {
 	Size      *types.Size      `xml:"sz,omitempty"`
 	Font      *types.Font      `xml:"rFonts,omitempty"`
 	Color     *types.Color     `xml:"color,omitempty"`
+	Strike    *OnOffProp       `xml:"strike,omitempty"`
+	DStrike   *OnOffProp       `xml:"dstrike,omitempty"`
+	Lang      *LangProp        `xml:"lang,omitempty"`
+	RTL       *OnOffProp       `xml:"rtl,omitempty"`
+}
+
+// LangProp represents the languages of a run
+type LangProp struct {
+	Val      string `xml:"val,attr,omitempty"`
+	EastAsia string `xml:"eastAsia,attr,omitempty"`
+	Bidi     string `xml:"bidi,attr,omitempty"`
 }
 
 // WordText represents text content
@@ -94,13 +517,56 @@ type Color struct {
 type WordTable struct {
 	XMLName    xml.Name       `xml:"tbl"`
 	Properties *TableProps    `xml:"tblPr,omitempty"`
+	Grid       *TableGrid     `xml:"tblGrid,omitempty"`
 	Rows       []WordTableRow `xml:"tr"`
+	// Position is the number of body paragraphs preceding the table
+	Position int `xml:"-"`
 }
 
 // TableProps represents table properties
 type TableProps struct {
 	XMLName xml.Name `xml:"tblPr"`
-	Style   *Style   `xml:"tblStyle,omitempty"`
+	Style   *ValueProp `xml:"tblStyle,omitempty"`
+	Borders *BordersProps `xml:"tblBorders,omitempty"`
+}
+
+// TableGrid represents the column widths of a table
+type TableGrid struct {
+	Columns []WidthProp `xml:"gridCol"`
+}
+
+// WidthProp represents a width in twips (w:w); Type is only set on
+// preferred widths such as w:tcW
+type WidthProp struct {
+	W    string `xml:"w,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// BordersProps represents table or cell borders. Start and End are the
+// bidi-aware names of Left and Right.
+type BordersProps struct {
+	Top     *BorderProp `xml:"top,omitempty"`
+	Left    *BorderProp `xml:"left,omitempty"`
+	Start   *BorderProp `xml:"start,omitempty"`
+	Bottom  *BorderProp `xml:"bottom,omitempty"`
+	Right   *BorderProp `xml:"right,omitempty"`
+	End     *BorderProp `xml:"end,omitempty"`
+	InsideH *BorderProp `xml:"insideH,omitempty"`
+	InsideV *BorderProp `xml:"insideV,omitempty"`
+}
+
+// BorderProp represents a border line; Size is in eighths of a point
+type BorderProp struct {
+	Val   string `xml:"val,attr"`
+	Size  string `xml:"sz,attr,omitempty"`
+	Color string `xml:"color,attr,omitempty"`
+}
+
+// ShadingProp represents cell shading; only the fill color is used
+type ShadingProp struct {
+	Val   string `xml:"val,attr,omitempty"`
+	Fill  string `xml:"fill,attr,omitempty"`
+	Color string `xml:"color,attr,omitempty"`
 }
 
 // WordTableRow represents a table row
@@ -112,7 +578,8 @@ type WordTableRow struct {
 
 // RowProps represents row properties
 type RowProps struct {
-	XMLName xml.Name `xml:"trPr"`
+	XMLName xml.Name   `xml:"trPr"`
+	Header  *OnOffProp `xml:"tblHeader,omitempty"`
 }
 
 // WordTableCell represents a table cell
@@ -124,7 +591,13 @@ type WordTableCell struct {
 
 // CellProps represents cell properties
 type CellProps struct {
-	XMLName xml.Name `xml:"tcPr"`
+	XMLName  xml.Name   `xml:"tcPr"`
+	GridSpan *ValueProp `xml:"gridSpan,omitempty"`
+	// VMerge without a value continues the merged region above
+	VMerge  *ValueProp    `xml:"vMerge,omitempty"`
+	Width   *WidthProp    `xml:"tcW,omitempty"`
+	Shading *ShadingProp  `xml:"shd,omitempty"`
+	Borders *BordersProps `xml:"tcBorders,omitempty"`
 }
 
 // ParseWordDocument parses a Word document XML
@@ -157,26 +630,67 @@ func (p *WordMLParser) ExtractParagraphs(doc *WordDocument) []types.Paragraph {
 	var paragraphs []types.Paragraph
 
 	for _, wp := range doc.Body.Paragraphs {
-		paragraph := types.Paragraph{
-			Runs: make([]types.Run, 0, len(wp.Runs)),
-		}
+		paragraphs = append(paragraphs, p.convertParagraph(wp))
+	}
 
+	return paragraphs
+}
+
+// convertParagraph converts a WordParagraph to types.Paragraph
+func (p *WordMLParser) convertParagraph(wp WordParagraph) types.Paragraph {
+	paragraph := types.Paragraph{
+		Runs:      make([]types.Run, 0, len(wp.Runs)),
+		Bookmarks: wp.Bookmarks,
+	}
+
+	if wp.Properties != nil {
 		// Extract style information
-		if wp.Properties != nil && wp.Properties.Style != nil {
+		if wp.Properties.Style != nil {
 			paragraph.Style = wp.Properties.Style.Val
 		}
-
-		// Extract runs
-		for _, run := range wp.Runs {
-			wordRun := p.convertRun(run)
-			paragraph.Runs = append(paragraph.Runs, wordRun)
-			paragraph.Text += wordRun.Text
+		if wp.Properties.Alignment != nil {
+			paragraph.Alignment = wp.Properties.Alignment.Val
+		}
+		if numbering := wp.Properties.Numbering; numbering != nil && numbering.NumID != nil {
+			paragraph.NumID, _ = strconv.Atoi(numbering.NumID.Val)
+			if numbering.Level != nil {
+				paragraph.ListLevel, _ = strconv.Atoi(numbering.Level.Val)
+			}
+		}
+		if spacing := wp.Properties.Spacing; spacing != nil {
+			paragraph.SpaceBefore = twipsToPoints(spacing.Before)
+			paragraph.SpaceAfter = twipsToPoints(spacing.After)
+			// 只支持按行数的行距，固定行距按单倍行距处理
+			if line, err := strconv.Atoi(spacing.Line); err == nil && line > 0 && (spacing.LineRule == "" || spacing.LineRule == "auto") {
+				paragraph.LineSpacing = float64(line) / 240
+			}
+		}
+		if indentation := wp.Properties.Indentation; indentation != nil {
+			paragraph.LeftIndent = twipsToPoints(indentation.Left)
+			paragraph.RightIndent = twipsToPoints(indentation.Right)
+			paragraph.FirstLineIndent = twipsToPoints(indentation.FirstLine)
+			if indentation.Hanging != "" {
+				paragraph.FirstLineIndent = -twipsToPoints(indentation.Hanging)
+			}
 		}
+		paragraph.PageBreakBefore = wp.Properties.PageBreakBefore.IsOn()
+		paragraph.Bidi = wp.Properties.Bidi.IsOn()
+		paragraph.SuppressAutoHyphens = wp.Properties.SuppressAutoHyphens.IsOn()
+	}
 
-		paragraphs = append(paragraphs, paragraph)
+	// Extract runs
+	for _, run := range wp.Runs {
+		wordRun := p.convertRun(run)
+		paragraph.Runs = append(paragraph.Runs, wordRun)
+		paragraph.Text += wordRun.Text
+
+		if wordRun.CommentID != "" && !paragraph.HasComment {
+			paragraph.HasComment = true
+			paragraph.CommentID = wordRun.CommentID
+		}
 	}
 
-	return paragraphs
+	return paragraph
 }
 
 // ExtractTables extracts tables from the document
@@ -185,18 +699,53 @@ func (p *WordMLParser) ExtractTables(doc *WordDocument) []types.Table {
 
 	for _, wt := range doc.Body.Tables {
 		table := types.Table{
-			Rows: make([]types.TableRow, 0, len(wt.Rows)),
+			Rows:     make([]types.TableRow, 0, len(wt.Rows)),
+			Position: wt.Position,
+		}
+		if wt.Properties != nil {
+			if wt.Properties.Style != nil {
+				table.Style = wt.Properties.Style.Val
+			}
+			table.Borders = convertBorders(wt.Properties.Borders)
+		}
+		if wt.Grid != nil {
+			for _, column := range wt.Grid.Columns {
+				table.ColumnWidths = append(table.ColumnWidths, twipsToPoints(column.W))
+			}
 		}
 
 		for _, row := range wt.Rows {
 			tableRow := types.TableRow{
-				Cells: make([]types.TableCell, 0, len(row.Cells)),
+				Cells:  make([]types.TableCell, 0, len(row.Cells)),
+				Header: row.Properties != nil && row.Properties.Header.IsOn(),
 			}
 
 			for _, cell := range row.Cells {
 				cellText := p.extractCellText(cell)
 				tableCell := types.TableCell{
-					Text: cellText,
+					Text:       cellText,
+					Paragraphs: make([]types.Paragraph, 0, len(cell.Paragraphs)),
+				}
+				if cell.Properties != nil {
+					if cell.Properties.GridSpan != nil {
+						tableCell.ColSpan, _ = strconv.Atoi(cell.Properties.GridSpan.Val)
+					}
+					if cell.Properties.VMerge != nil {
+						tableCell.VMerge = cell.Properties.VMerge.Val
+						if tableCell.VMerge == "" {
+							tableCell.VMerge = "continue"
+						}
+					}
+					if width := cell.Properties.Width; width != nil && (width.Type == "" || width.Type == "dxa") {
+						tableCell.Width = twipsToPoints(width.W)
+					}
+					if shading := cell.Properties.Shading; shading != nil && shading.Fill != "" && shading.Fill != "auto" {
+						tableCell.Shading = shading.Fill
+					}
+					tableCell.Borders = convertBorders(cell.Properties.Borders)
+				}
+				for _, wp := range cell.Paragraphs {
+					tableCell.Paragraphs = append(tableCell.Paragraphs, p.convertParagraph(wp))
 				}
 				tableRow.Cells = append(tableRow.Cells, tableCell)
 			}
@@ -222,6 +771,13 @@ func (p *WordMLParser) extractParagraphText(paragraph WordParagraph) string {
 		if run.Text != nil {
 			text.WriteString(run.Text.Content)
 		}
+		if run.Ruby != nil {
+			for _, base := range run.Ruby.Base {
+				if base.Text != nil {
+					text.WriteString(base.Text.Content)
+				}
+			}
+		}
 		if run.Tab != nil {
 			text.WriteString("\t")
 		}
@@ -235,11 +791,21 @@ func (p *WordMLParser) extractParagraphText(paragraph WordParagraph) string {
 
 // convertRun converts a WordRun to types.Run
 func (p *WordMLParser) convertRun(run WordRun) types.Run {
+	if run.Ruby != nil {
+		return p.convertRuby(run)
+	}
 	wordRun := types.Run{}
 
 	if run.Text != nil {
 		wordRun.Text = run.Text.Content
 	}
+	wordRun.Tab = run.Tab != nil
+	if run.Break != nil {
+		wordRun.Break = run.Break.Type
+		if wordRun.Break == "" || wordRun.Break == "textWrapping" {
+			wordRun.Break = "line"
+		}
+	}
 
 	if run.Properties != nil {
 		if run.Properties.Bold != nil {
@@ -265,11 +831,200 @@ func (p *WordMLParser) convertRun(run WordRun) types.Run {
 		if run.Properties.Color != nil {
 			wordRun.Color = run.Properties.Color.Val
 		}
+		wordRun.Strike = run.Properties.Strike.IsOn() || run.Properties.DStrike.IsOn()
+		wordRun.RTL = run.Properties.RTL.IsOn()
+		if run.Properties.Lang != nil {
+			wordRun.Language = run.Properties.Lang.Val
+		}
+	}
+
+	if run.Hyperlink != "" {
+		wordRun.Hyperlink = p.resolveHyperlink(run.Hyperlink)
+	}
+
+	if run.FootnoteReference != nil {
+		wordRun.FootnoteID = run.FootnoteReference.ID
+	}
+
+	if run.CommentReference != nil {
+		wordRun.CommentID = run.CommentReference.ID
+	}
+	wordRun.Field = run.Field
+
+	if run.Drawing != nil {
+		wordRun.Image = p.convertDrawing(run.Drawing)
 	}
 
 	return wordRun
 }
 
+// convertRuby converts a run holding a phonetic guide. The run takes the
+// formatting of the first base run and the text of all base runs.
+func (p *WordMLParser) convertRuby(run WordRun) types.Run {
+	var wordRun types.Run
+	var base strings.Builder
+	for i, baseRun := range run.Ruby.Base {
+		converted := p.convertRun(baseRun)
+		if i == 0 {
+			wordRun = converted
+		}
+		base.WriteString(converted.Text)
+	}
+	wordRun.Text = base.String()
+	if run.Hyperlink != "" {
+		wordRun.Hyperlink = p.resolveHyperlink(run.Hyperlink)
+	}
+
+	ruby := &types.Ruby{}
+	for _, annotation := range run.Ruby.Annotation {
+		if annotation.Text != nil {
+			ruby.Text += annotation.Text.Content
+		}
+	}
+	if props := run.Ruby.Properties; props != nil {
+		if props.Align != nil {
+			ruby.Alignment = props.Align.Val
+		}
+		if props.Size != nil {
+			ruby.FontSize, _ = strconv.Atoi(props.Size.Val)
+		}
+		if props.Raise != nil {
+			ruby.Raise, _ = strconv.Atoi(props.Raise.Val)
+		}
+		if props.BaseSize != nil {
+			ruby.BaseFontSize, _ = strconv.Atoi(props.BaseSize.Val)
+		}
+		if props.Language != nil {
+			ruby.Language = props.Language.Val
+		}
+	}
+	wordRun.Ruby = ruby
+	return wordRun
+}
+
+// twipsToPoints converts a twips attribute to points, returning zero for
+// missing or invalid values
+func twipsToPoints(value string) float64 {
+	twips, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return float64(twips) / 20
+}
+
+// convertBorders converts table or cell borders, preferring the bidi-aware
+// start and end names over left and right
+func convertBorders(borders *BordersProps) *types.TableBorders {
+	if borders == nil {
+		return nil
+	}
+
+	convert := func(props ...*BorderProp) *types.TableBorder {
+		for _, prop := range props {
+			if prop == nil {
+				continue
+			}
+			border := &types.TableBorder{Style: prop.Val, Color: prop.Color}
+			if size, err := strconv.Atoi(prop.Size); err == nil {
+				border.Width = float64(size) / 8
+			}
+			if border.Color == "auto" {
+				border.Color = ""
+			}
+			return border
+		}
+		return nil
+	}
+	return &types.TableBorders{
+		Top:     convert(borders.Top),
+		Left:    convert(borders.Start, borders.Left),
+		Bottom:  convert(borders.Bottom),
+		Right:   convert(borders.End, borders.Right),
+		InsideH: convert(borders.InsideH),
+		InsideV: convert(borders.InsideV),
+	}
+}
+
+// resolveHyperlink returns the target of a hyperlink relationship. Anchors
+// starting with "#" refer to bookmarks and are returned unchanged.
+func (p *WordMLParser) resolveHyperlink(ref string) string {
+	if strings.HasPrefix(ref, "#") {
+		return ref
+	}
+	if target, ok := p.Relationships[ref]; ok {
+		return target
+	}
+	return ref
+}
+
+// convertDrawing converts a DrawingML picture to types.Image. The size is
+// converted from EMU to pixels at 96 DPI.
+func (p *WordMLParser) convertDrawing(drawing *Drawing) *types.Image {
+	object := drawing.Inline
+	position := types.ImagePositionInline
+	if object == nil {
+		object = drawing.Anchor
+		position = types.ImagePositionFloating
+	}
+	if object == nil || object.Blip.Embed == "" {
+		return nil
+	}
+
+	const emuPerPixel = 9525
+	image := &types.Image{
+		ID:       object.Blip.Embed,
+		Path:     p.Relationships[object.Blip.Embed],
+		Width:    float64(object.Extent.Cx) / emuPerPixel,
+		Height:   float64(object.Extent.Cy) / emuPerPixel,
+		AltText:  object.DocPr.Descr,
+		Title:    object.DocPr.Title,
+		Position: position,
+	}
+	// SVG图片引用其位图替代图片，路径使用SVG本身
+	if svg := object.Blip.SVG.Embed; svg != "" {
+		image.ID = svg
+		image.Path = p.Relationships[svg]
+	}
+	if position == types.ImagePositionFloating {
+		p.convertAnchor(object, image)
+	}
+	return image
+}
+
+// convertAnchor fills the position and wrapping of a floating picture.
+// Offsets are converted from EMU to points.
+func (p *WordMLParser) convertAnchor(object *DrawingObject, image *types.Image) {
+	const emuPerPoint = 12700
+	anchor := &types.ImageAnchor{Distance: float64(object.DistL) / emuPerPoint}
+	if position := object.PositionH; position != nil {
+		anchor.HorizontalRelative = position.RelativeFrom
+		if position.PosOffset != nil {
+			anchor.X = float64(*position.PosOffset) / emuPerPoint
+		}
+		image.Alignment = position.Align
+	}
+	if position := object.PositionV; position != nil {
+		anchor.VerticalRelative = position.RelativeFrom
+		if position.PosOffset != nil {
+			anchor.Y = float64(*position.PosOffset) / emuPerPoint
+		}
+	}
+	image.Anchor = anchor
+
+	switch {
+	case object.WrapSquare != nil:
+		image.Wrapping = "square"
+	case object.WrapTight != nil, object.WrapThrough != nil:
+		image.Wrapping = "tight"
+	case object.WrapTopAndBottom != nil:
+		image.Wrapping = "topAndBottom"
+	case object.WrapNone != nil && object.BehindDoc:
+		image.Wrapping = "behind"
+	case object.WrapNone != nil:
+		image.Wrapping = "inFront"
+	}
+}
+
 // extractCellText extracts text from a table cell
 func (p *WordMLParser) extractCellText(cell WordTableCell) string {
 	var text strings.Builder
@@ -285,7 +1040,13 @@ func (p *WordMLParser) extractCellText(cell WordTableCell) string {
 // ParseWordML parses word XML data and returns document content
 // This is the main entry point for parsing Word documents
 func ParseWordML(data []byte) (*types.DocumentContent, error) {
-	parser := &WordMLParser{}
+	return ParseWordMLWithRelationships(data, nil)
+}
+
+// ParseWordMLWithRelationships parses word XML data and resolves hyperlink
+// and image relationship IDs using the given ID to target map
+func ParseWordMLWithRelationships(data []byte, relationships map[string]string) (*types.DocumentContent, error) {
+	parser := &WordMLParser{Relationships: relationships}
 
 	// Parse the Word document
 	doc, err := parser.ParseWordDocument(data)
@@ -302,5 +1063,83 @@ func ParseWordML(data []byte) (*types.DocumentContent, error) {
 		Text:       text,
 		Paragraphs: paragraphs,
 		Tables:     tables,
+		Sections:   parser.ExtractSections(doc),
 	}, nil
 }
+
+// ExtractSections extracts the sections of the body. The last section
+// ends with the body; a document without section properties has none.
+func (p *WordMLParser) ExtractSections(doc *WordDocument) []types.Section {
+	var sections []types.Section
+	for i, wp := range doc.Body.Paragraphs {
+		if wp.Properties != nil && wp.Properties.Section != nil {
+			sections = append(sections, p.convertSection(wp.Properties.Section, i))
+		}
+	}
+	if last := doc.Body.Section; last != nil || len(sections) > 0 {
+		if last == nil {
+			last = &SectionProps{}
+		}
+		sections = append(sections, p.convertSection(last, len(doc.Body.Paragraphs)-1))
+	}
+	return sections
+}
+
+// convertSection converts section properties that end at the paragraph
+// with index end
+func (p *WordMLParser) convertSection(props *SectionProps, end int) types.Section {
+	section := types.Section{
+		End:       end,
+		Headers:   make(map[string]string),
+		Footers:   make(map[string]string),
+		TitlePage: props.TitlePage.IsOn(),
+	}
+	if props.Type != nil {
+		section.Break = props.Type.Val
+	}
+	for _, ref := range props.Headers {
+		section.Headers[headerFooterKind(ref.Type)] = p.resolveHyperlink(ref.ID)
+	}
+	for _, ref := range props.Footers {
+		section.Footers[headerFooterKind(ref.Type)] = p.resolveHyperlink(ref.ID)
+	}
+	if numbers := props.PageNumbers; numbers != nil {
+		section.PageNumberFormat = numbers.Format
+		section.PageNumberStart, _ = strconv.Atoi(numbers.Start)
+	}
+	if size := props.PageSize; size != nil {
+		section.PageWidth = twipsToPoints(size.Width)
+		section.PageHeight = twipsToPoints(size.Height)
+	}
+	if margins := props.Margins; margins != nil {
+		section.HeaderDistance = twipsToPoints(margins.Header)
+		section.FooterDistance = twipsToPoints(margins.Footer)
+		// 负的上下边距表示正文不随页眉页脚移动，这里只取距离
+		section.Margins = &types.PDFMargins{
+			Top:    math.Abs(twipsToPoints(margins.Top)),
+			Right:  twipsToPoints(margins.Right),
+			Bottom: math.Abs(twipsToPoints(margins.Bottom)),
+			Left:   twipsToPoints(margins.Left),
+		}
+	}
+	if props.TextDirection != nil {
+		section.TextDirection = props.TextDirection.Val
+	}
+	if columns := props.Columns; columns != nil {
+		section.Columns, _ = strconv.Atoi(columns.Num)
+		section.ColumnSpace = 36
+		if columns.Space != "" {
+			section.ColumnSpace = twipsToPoints(columns.Space)
+		}
+	}
+	return section
+}
+
+// headerFooterKind returns the kind of a header or footer reference,
+// which is "default" when not set
+func headerFooterKind(kind string) string {
+	if kind == "" {
+		return "default"
+	}
+	return kind
+}
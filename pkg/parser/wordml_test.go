@@ -2,6 +2,7 @@ package parser
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/tanqiangyes/go-word/pkg/types"
@@ -423,4 +424,217 @@ func TestWordMLParserExtractCellTextWithNilRuns(t *testing.T) {
 	if text != "" {
 		t.Errorf("Expected empty text, got '%s'", text)
 	}
-} 
\ No newline at end of file
+}
+
+func TestParseWordMLWithRelationships(t *testing.T) {
+	content := []byte(`<w:document xmlns:w="w" xmlns:r="r"><w:body>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="2"/><w:numId w:val="4"/></w:numPr><w:jc w:val="center"/></w:pPr>
+<w:r><w:t xml:space="preserve">See </w:t></w:r>
+<w:hyperlink r:id="rId7"><w:r><w:rPr><w:strike/></w:rPr><w:t>link</w:t></w:r></w:hyperlink>
+<w:hyperlink w:anchor="top"><w:r><w:t>top</w:t></w:r></w:hyperlink>
+</w:p>
+<w:tbl><w:tblPr><w:tblStyle w:val="Grid"/></w:tblPr>
+<w:tr><w:trPr><w:tblHeader/></w:trPr><w:tc><w:p><w:r><w:rPr><w:b/></w:rPr><w:t>H</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+<w:p><w:r><w:t>after</w:t></w:r></w:p>
+</w:body></w:document>`)
+
+	docContent, err := ParseWordMLWithRelationships(content, map[string]string{"rId7": "https://example.com"})
+	if err != nil {
+		t.Fatalf("Failed to parse document: %v", err)
+	}
+
+	paragraph := docContent.Paragraphs[0]
+	if paragraph.NumID != 4 || paragraph.ListLevel != 2 {
+		t.Errorf("Expected numId 4 level 2, got %d level %d", paragraph.NumID, paragraph.ListLevel)
+	}
+	if paragraph.Alignment != "center" {
+		t.Errorf("Expected center alignment, got '%s'", paragraph.Alignment)
+	}
+	if paragraph.Text != "See linktop" || len(paragraph.Runs) != 3 {
+		t.Fatalf("Expected runs in document order, got '%s'", paragraph.Text)
+	}
+	if paragraph.Runs[1].Hyperlink != "https://example.com" || !paragraph.Runs[1].Strike {
+		t.Errorf("Expected struck hyperlink run, got %+v", paragraph.Runs[1])
+	}
+	if paragraph.Runs[2].Hyperlink != "#top" {
+		t.Errorf("Expected anchor hyperlink, got '%s'", paragraph.Runs[2].Hyperlink)
+	}
+
+	if len(docContent.Tables) != 1 {
+		t.Fatalf("Expected 1 table, got %d", len(docContent.Tables))
+	}
+	table := docContent.Tables[0]
+	if table.Position != 1 {
+		t.Errorf("Expected table position 1, got %d", table.Position)
+	}
+	if !table.Rows[0].Header {
+		t.Error("Expected header row")
+	}
+	if cell := table.Rows[0].Cells[0]; len(cell.Paragraphs) != 1 || !cell.Paragraphs[0].Runs[0].Bold {
+		t.Error("Expected formatted cell paragraph")
+	}
+}
+
+func TestParseWordMLTableLayout(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:tbl>
+  <w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblBorders><w:top w:val="single" w:sz="12" w:color="FF0000"/><w:start w:val="double" w:sz="4" w:color="auto"/><w:insideH w:val="none"/></w:tblBorders></w:tblPr>
+  <w:tblGrid><w:gridCol w:w="2880"/><w:gridCol w:w="1440"/></w:tblGrid>
+  <w:tr>
+    <w:tc><w:tcPr><w:tcW w:w="2880" w:type="dxa"/><w:shd w:val="clear" w:color="auto" w:fill="D9E2F3"/><w:tcBorders><w:bottom w:val="dotted" w:sz="8"/></w:tcBorders></w:tcPr><w:p/></w:tc>
+    <w:tc><w:tcPr><w:tcW w:w="50" w:type="pct"/><w:shd w:val="clear" w:fill="auto"/></w:tcPr><w:p/></w:tc>
+  </w:tr>
+</w:tbl>
+</w:body></w:document>`)
+
+	content, err := ParseWordML(data)
+	if err != nil {
+		t.Fatalf("Failed to parse document: %v", err)
+	}
+	table := content.Tables[0]
+	if table.Style != "TableGrid" || len(table.ColumnWidths) != 2 || table.ColumnWidths[0] != 144 || table.ColumnWidths[1] != 72 {
+		t.Errorf("Unexpected table grid: %q %v", table.Style, table.ColumnWidths)
+	}
+	borders := table.Borders
+	if borders == nil || borders.Top == nil || *borders.Top != (types.TableBorder{Style: "single", Width: 1.5, Color: "FF0000"}) {
+		t.Fatalf("Unexpected top border: %+v", borders)
+	}
+	if borders.Left == nil || borders.Left.Style != "double" || borders.Left.Color != "" {
+		t.Errorf("Expected start border as left border, got %+v", borders.Left)
+	}
+	if borders.InsideH == nil || borders.InsideH.Style != "none" || borders.Bottom != nil {
+		t.Errorf("Unexpected inside borders: %+v", borders)
+	}
+
+	first, second := table.Rows[0].Cells[0], table.Rows[0].Cells[1]
+	if first.Width != 144 || first.Shading != "D9E2F3" {
+		t.Errorf("Unexpected cell properties: %+v", first)
+	}
+	if first.Borders == nil || first.Borders.Bottom == nil || first.Borders.Bottom.Style != "dotted" || first.Borders.Bottom.Width != 1 {
+		t.Errorf("Unexpected cell borders: %+v", first.Borders)
+	}
+	if second.Width != 0 || second.Shading != "" || second.Borders != nil {
+		t.Errorf("Percentage widths and automatic shading should be ignored: %+v", second)
+	}
+}
+
+func TestParseWordMLBookmarks(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:bookmarkStart w:id="0" w:name="_Toc1"/><w:bookmarkStart w:id="1" w:name="_GoBack"/><w:r><w:t>Intro</w:t></w:r><w:bookmarkEnd w:id="0"/></w:p>
+</w:body></w:document>`)
+
+	content, err := ParseWordML(data)
+	if err != nil {
+		t.Fatalf("Failed to parse document: %v", err)
+	}
+	paragraph := content.Paragraphs[0]
+	if len(paragraph.Bookmarks) != 1 || paragraph.Bookmarks[0] != "_Toc1" || paragraph.Text != "Intro" {
+		t.Errorf("Unexpected bookmarks: %v", paragraph.Bookmarks)
+	}
+}
+
+func TestParseWordMLFields(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t xml:space="preserve">Page </w:t></w:r><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> PAGE </w:instrText></w:r>
+<w:r><w:instrText>\* roman</w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>i</w:t></w:r><w:r><w:t>v</w:t></w:r>
+<w:r><w:fldChar w:fldCharType="end"/></w:r><w:r><w:t xml:space="preserve"> of </w:t></w:r><w:fldSimple w:instr=" NUMPAGES "><w:r><w:t>9</w:t></w:r></w:fldSimple>
+<w:fldSimple w:instr="SECTIONPAGES"/></w:p>
+</w:body></w:document>`)
+
+	content, err := ParseWordML(data)
+	if err != nil {
+		t.Fatalf("Failed to parse document: %v", err)
+	}
+	paragraph := content.Paragraphs[0]
+	if paragraph.Text != "Page iv of 9" {
+		t.Errorf("Unexpected text: %q", paragraph.Text)
+	}
+	var fields []string
+	for _, run := range paragraph.Runs {
+		if run.Field != "" {
+			fields = append(fields, run.Field+"="+run.Text)
+		}
+	}
+	if strings.Join(fields, ",") != `PAGE \* roman=iv,NUMPAGES=9,SECTIONPAGES=` {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+}
+
+func TestParseWordMLSections(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body>
+<w:p><w:pPr><w:sectPr><w:headerReference w:type="first" r:id="rId1"/><w:footerReference r:id="rId2"/><w:titlePg/>
+<w:pgMar w:header="720" w:footer="360"/></w:sectPr></w:pPr><w:r><w:t>Cover</w:t></w:r></w:p>
+<w:p><w:r><w:t>Body</w:t></w:r></w:p>
+<w:sectPr><w:type w:val="continuous"/><w:pgSz w:w="15840" w:h="12240" w:orient="landscape"/>
+<w:pgMar w:top="-1440" w:right="1080" w:bottom="1440" w:left="1080" w:header="708" w:footer="708" w:gutter="0"/>
+<w:pgNumType w:fmt="upperRoman" w:start="3"/><w:cols w:num="2" w:space="360"/></w:sectPr>
+</w:body></w:document>`)
+
+	content, err := ParseWordMLWithRelationships(data, map[string]string{"rId1": "word/header1.xml", "rId2": "word/footer1.xml"})
+	if err != nil {
+		t.Fatalf("Failed to parse document: %v", err)
+	}
+	if len(content.Sections) != 2 || len(content.Paragraphs) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(content.Sections))
+	}
+	first, last := content.Sections[0], content.Sections[1]
+	if first.End != 0 || !first.TitlePage || first.Headers["first"] != "word/header1.xml" || first.Footers["default"] != "word/footer1.xml" {
+		t.Errorf("Unexpected first section: %+v", first)
+	}
+	if first.HeaderDistance != 36 || first.FooterDistance != 18 {
+		t.Errorf("Unexpected header distances: %v %v", first.HeaderDistance, first.FooterDistance)
+	}
+	if last.End != 1 || last.Break != "continuous" || last.PageNumberFormat != "upperRoman" || last.PageNumberStart != 3 {
+		t.Errorf("Unexpected last section: %+v", last)
+	}
+	if first.PageWidth != 0 || first.Margins == nil || first.Margins.Top != 0 || first.Columns != 0 {
+		t.Errorf("Unexpected first section page setup: %+v", first)
+	}
+	if last.PageWidth != 792 || last.PageHeight != 612 || last.Columns != 2 || last.ColumnSpace != 18 {
+		t.Errorf("Unexpected last section page setup: %+v", last)
+	}
+	if m := last.Margins; m == nil || m.Top != 72 || m.Bottom != 72 || m.Left != 54 || m.Right != 54 {
+		t.Errorf("Unexpected margins: %+v", m)
+	}
+}
+
+func TestParseWordMLRuby(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:ruby><w:rubyPr><w:rubyAlign w:val="distributeSpace"/><w:hps w:val="10"/><w:hpsRaise w:val="18"/>
+<w:hpsBaseText w:val="21"/><w:lid w:val="ja-JP"/></w:rubyPr>
+<w:rt><w:r><w:rPr><w:sz w:val="10"/></w:rPr><w:t>かん</w:t></w:r><w:r><w:t>じ</w:t></w:r></w:rt>
+<w:rubyBase><w:r><w:rPr><w:b/><w:sz w:val="21"/></w:rPr><w:t>漢</w:t></w:r><w:r><w:t>字</w:t></w:r></w:rubyBase></w:ruby></w:r>
+<w:r><w:t>を読む</w:t></w:r></w:p>
+<w:sectPr><w:textDirection w:val="tbRl"/></w:sectPr>
+</w:body></w:document>`)
+
+	content, err := ParseWordML(data)
+	if err != nil {
+		t.Fatalf("Failed to parse document: %v", err)
+	}
+	paragraph := content.Paragraphs[0]
+	if paragraph.Text != "漢字を読む" || content.Text != "漢字を読む\n" {
+		t.Errorf("Unexpected text: %q %q", paragraph.Text, content.Text)
+	}
+	run := paragraph.Runs[0]
+	if run.Text != "漢字" || !run.Bold || run.FontSize != 21 {
+		t.Errorf("Unexpected base run: %+v", run)
+	}
+	want := types.Ruby{Text: "かんじ", Alignment: "distributeSpace", FontSize: 10, Raise: 18, BaseFontSize: 21, Language: "ja-JP"}
+	if run.Ruby == nil || *run.Ruby != want {
+		t.Errorf("Unexpected ruby: %+v", run.Ruby)
+	}
+	if paragraph.Runs[1].Ruby != nil {
+		t.Error("Expected no ruby on a plain run")
+	}
+	if len(content.Sections) != 1 || content.Sections[0].TextDirection != "tbRl" || !content.Sections[0].IsVertical() {
+		t.Errorf("Unexpected sections: %+v", content.Sections)
+	}
+}
@@ -0,0 +1,401 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// WordNamespace is the namespace of WordprocessingML elements
+const WordNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+
+// XMLNodeKind identifies the kind of an XMLNode
+type XMLNodeKind int
+
+const (
+	// XMLDocumentNode is the root of a parsed tree
+	XMLDocumentNode XMLNodeKind = iota
+	// XMLElementNode is an element with attributes and children
+	XMLElementNode
+	// XMLTextNode is character data
+	XMLTextNode
+	// XMLOtherNode is a comment, processing instruction or directive
+	XMLOtherNode
+)
+
+// XMLNode is a node of an XML tree that remembers its original bytes.
+// Nodes that are not modified are written back byte for byte, so parts
+// can be edited without losing markup the model does not know about.
+type XMLNode struct {
+	Kind XMLNodeKind
+	// Name keeps the prefix in Space as written in the source
+	Name xml.Name
+	// URI is the resolved namespace of an element
+	URI      string
+	Attr     []xml.Attr
+	Children []*XMLNode
+	Parent   *XMLNode
+	// Value is the decoded character data of a text node
+	Value string
+
+	raw         []byte
+	endRaw      []byte
+	selfClosing bool
+	modified    bool
+}
+
+// ParseXMLTree parses data into a tree that can be serialized back to the
+// same bytes
+func ParseXMLTree(data []byte) (*XMLNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root := &XMLNode{Kind: XMLDocumentNode}
+	current := root
+	// 命名空间前缀的作用域
+	scopes := []map[string]string{{"xml": "http://www.w3.org/XML/1998/namespace"}}
+
+	lookup := func(prefix string) string {
+		for i := len(scopes) - 1; i >= 0; i-- {
+			if uri, ok := scopes[i][prefix]; ok {
+				return uri
+			}
+		}
+		return ""
+	}
+
+	offset := int64(0)
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse xml: %w", err)
+		}
+		end := decoder.InputOffset()
+		raw := data[offset:end]
+		offset = end
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			scope := make(map[string]string)
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "xmlns":
+					scope[attr.Name.Local] = attr.Value
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					scope[""] = attr.Value
+				}
+			}
+			scopes = append(scopes, scope)
+
+			node := &XMLNode{
+				Kind:        XMLElementNode,
+				Name:        t.Name,
+				Attr:        append([]xml.Attr(nil), t.Attr...),
+				Parent:      current,
+				raw:         raw,
+				selfClosing: bytes.HasSuffix(bytes.TrimRight(raw, " \t\r\n"), []byte("/>")),
+			}
+			node.URI = lookup(t.Name.Space)
+			current.Children = append(current.Children, node)
+			current = node
+
+		case xml.EndElement:
+			if current.Kind != XMLElementNode {
+				return nil, fmt.Errorf("failed to parse xml: unexpected end element %s", t.Name.Local)
+			}
+			current.endRaw = raw
+			current = current.Parent
+			scopes = scopes[:len(scopes)-1]
+
+		case xml.CharData:
+			current.Children = append(current.Children, &XMLNode{
+				Kind:   XMLTextNode,
+				Value:  string(t),
+				Parent: current,
+				raw:    raw,
+			})
+
+		default:
+			current.Children = append(current.Children, &XMLNode{
+				Kind:   XMLOtherNode,
+				Parent: current,
+				raw:    raw,
+			})
+		}
+	}
+
+	if current != root {
+		return nil, fmt.Errorf("failed to parse xml: unclosed element %s", current.Name.Local)
+	}
+	return root, nil
+}
+
+// NewXMLElement creates an element. The name may carry a prefix, as in "w:r".
+func NewXMLElement(name string, attrs ...xml.Attr) *XMLNode {
+	return &XMLNode{Kind: XMLElementNode, Name: splitXMLName(name), Attr: attrs, modified: true}
+}
+
+// NewXMLText creates a text node
+func NewXMLText(value string) *XMLNode {
+	return &XMLNode{Kind: XMLTextNode, Value: value, modified: true}
+}
+
+// XMLAttr creates an attribute. The name may carry a prefix.
+func XMLAttr(name, value string) xml.Attr {
+	return xml.Attr{Name: splitXMLName(name), Value: value}
+}
+
+// splitXMLName splits a prefixed name
+func splitXMLName(name string) xml.Name {
+	for i := 0; i < len(name); i++ {
+		if name[i] == ':' {
+			return xml.Name{Space: name[:i], Local: name[i+1:]}
+		}
+	}
+	return xml.Name{Local: name}
+}
+
+// Is reports whether the node is an element with the namespace and local name
+func (n *XMLNode) Is(uri, local string) bool {
+	return n != nil && n.Kind == XMLElementNode && n.URI == uri && n.Name.Local == local
+}
+
+// Child returns the first child element with the namespace and local name
+func (n *XMLNode) Child(uri, local string) *XMLNode {
+	if n == nil {
+		return nil
+	}
+	for _, child := range n.Children {
+		if child.Is(uri, local) {
+			return child
+		}
+	}
+	return nil
+}
+
+// Walk visits the node and its descendants in document order. Children of
+// a node are skipped when visit returns false.
+func (n *XMLNode) Walk(visit func(*XMLNode) bool) {
+	if !visit(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(visit)
+	}
+}
+
+// GetAttr returns the value of an attribute matched by prefix and local name
+func (n *XMLNode) GetAttr(name string) (string, bool) {
+	key := splitXMLName(name)
+	for _, attr := range n.Attr {
+		if attr.Name == key {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetAttr sets or adds an attribute
+func (n *XMLNode) SetAttr(name, value string) {
+	key := splitXMLName(name)
+	n.modified = true
+	for i := range n.Attr {
+		if n.Attr[i].Name == key {
+			n.Attr[i].Value = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, xml.Attr{Name: key, Value: value})
+}
+
+// SetChildren replaces the children of the node
+func (n *XMLNode) SetChildren(children []*XMLNode) {
+	n.Children = children
+	for _, child := range children {
+		child.Parent = n
+		if child.Kind == XMLElementNode && child.URI == "" && child.Name.Space == n.Name.Space && n.URI != "" {
+			child.resolveURI(n.URI)
+		}
+	}
+}
+
+// resolveURI sets the namespace of a new element and of its descendants
+// that share its prefix
+func (n *XMLNode) resolveURI(uri string) {
+	n.URI = uri
+	for _, child := range n.Children {
+		if child.Kind == XMLElementNode && child.URI == "" && child.Name.Space == n.Name.Space {
+			child.resolveURI(uri)
+		}
+	}
+}
+
+// AppendChild adds a child at the end
+func (n *XMLNode) AppendChild(child *XMLNode) {
+	n.SetChildren(append(n.Children, child))
+}
+
+// InsertChild adds a child before the child at index; an index past the
+// last child appends it
+func (n *XMLNode) InsertChild(index int, child *XMLNode) {
+	index = max(0, min(index, len(n.Children)))
+	children := make([]*XMLNode, 0, len(n.Children)+1)
+	children = append(children, n.Children[:index]...)
+	children = append(children, child)
+	n.SetChildren(append(children, n.Children[index:]...))
+}
+
+// RemoveChild removes a child and reports whether it was a child of the node
+func (n *XMLNode) RemoveChild(child *XMLNode) bool {
+	for i, c := range n.Children {
+		if c == child {
+			n.Children = append(n.Children[:i:i], n.Children[i+1:]...)
+			child.Parent = nil
+			return true
+		}
+	}
+	return false
+}
+
+// RenamePrefix changes the prefix old of the elements and attributes of
+// the subtree to prefix. Renamed elements are written with new tags.
+func (n *XMLNode) RenamePrefix(old, prefix string) {
+	if n.Kind != XMLElementNode && n.Kind != XMLDocumentNode {
+		return
+	}
+	if n.Kind == XMLElementNode && n.Name.Space == old {
+		n.Name.Space = prefix
+		n.modified = true
+	}
+	for i := range n.Attr {
+		if n.Attr[i].Name.Space == old {
+			n.Attr[i].Name.Space = prefix
+			n.modified = true
+		}
+	}
+	for _, child := range n.Children {
+		child.RenamePrefix(old, prefix)
+	}
+}
+
+// SetText replaces the content of the node with a single text node
+func (n *XMLNode) SetText(value string) {
+	n.SetChildren([]*XMLNode{NewXMLText(value)})
+}
+
+// Text returns the concatenated character data of the node
+func (n *XMLNode) Text() string {
+	if n.Kind == XMLTextNode {
+		return n.Value
+	}
+	var buf bytes.Buffer
+	for _, child := range n.Children {
+		if child.Kind == XMLTextNode || child.Kind == XMLElementNode {
+			buf.WriteString(child.Text())
+		}
+	}
+	return buf.String()
+}
+
+// ShallowCopy returns a copy of the element without children
+func (n *XMLNode) ShallowCopy() *XMLNode {
+	return &XMLNode{
+		Kind:        n.Kind,
+		Name:        n.Name,
+		URI:         n.URI,
+		Attr:        append([]xml.Attr(nil), n.Attr...),
+		Value:       n.Value,
+		raw:         n.raw,
+		endRaw:      n.endRaw,
+		selfClosing: n.selfClosing,
+		modified:    n.modified,
+	}
+}
+
+// Clone returns a deep copy of the node
+func (n *XMLNode) Clone() *XMLNode {
+	clone := n.ShallowCopy()
+	children := make([]*XMLNode, len(n.Children))
+	for i, child := range n.Children {
+		children[i] = child.Clone()
+	}
+	clone.SetChildren(children)
+	return clone
+}
+
+// Bytes serializes the node
+func (n *XMLNode) Bytes() []byte {
+	var buf bytes.Buffer
+	n.write(&buf)
+	return buf.Bytes()
+}
+
+// write serializes the node, reusing the original bytes where possible
+func (n *XMLNode) write(buf *bytes.Buffer) {
+	switch n.Kind {
+	case XMLDocumentNode:
+		for _, child := range n.Children {
+			child.write(buf)
+		}
+
+	case XMLTextNode:
+		if n.raw != nil && !n.modified {
+			buf.Write(n.raw)
+			return
+		}
+		xml.EscapeText(buf, []byte(n.Value))
+
+	case XMLOtherNode:
+		buf.Write(n.raw)
+
+	case XMLElementNode:
+		reuse := n.raw != nil && !n.modified
+		switch {
+		case reuse && !n.selfClosing:
+			buf.Write(n.raw)
+			for _, child := range n.Children {
+				child.write(buf)
+			}
+			buf.Write(n.endRaw)
+			return
+		case reuse && len(n.Children) == 0:
+			buf.Write(n.raw)
+			return
+		}
+
+		// 修改过的元素或新增了内容的空元素需要重新生成标签
+		n.writeStartTag(buf, len(n.Children) == 0)
+		if len(n.Children) == 0 {
+			return
+		}
+		for _, child := range n.Children {
+			child.write(buf)
+		}
+		buf.WriteString("</" + qualifiedXMLName(n.Name) + ">")
+	}
+}
+
+// writeStartTag writes a start tag generated from the name and attributes
+func (n *XMLNode) writeStartTag(buf *bytes.Buffer, empty bool) {
+	buf.WriteString("<" + qualifiedXMLName(n.Name))
+	for _, attr := range n.Attr {
+		buf.WriteString(" " + qualifiedXMLName(attr.Name) + `="`)
+		xml.EscapeText(buf, []byte(attr.Value))
+		buf.WriteString(`"`)
+	}
+	if empty {
+		buf.WriteString("/>")
+	} else {
+		buf.WriteString(">")
+	}
+}
+
+// qualifiedXMLName returns a name with its prefix
+func qualifiedXMLName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const xmlTreeTestDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<!-- generated -->
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:x="urn:example">
+  <w:body>
+    <w:p w:rsidR="00A1"><w:r><w:t xml:space='preserve'>Tom &amp; Jerry </w:t></w:r><x:custom  a="1" /></w:p>
+    <w:p/>
+  </w:body>
+</w:document>`
+
+func TestParseXMLTreeRoundTrip(t *testing.T) {
+	tree, err := ParseXMLTree([]byte(xmlTreeTestDocument))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if got := string(tree.Bytes()); got != xmlTreeTestDocument {
+		t.Errorf("未修改的树应该原样输出:\n%s", got)
+	}
+
+	var paragraphs []*XMLNode
+	tree.Walk(func(node *XMLNode) bool {
+		if node.Is(WordNamespace, "p") {
+			paragraphs = append(paragraphs, node)
+		}
+		return true
+	})
+	if len(paragraphs) != 2 {
+		t.Fatalf("应该有2个段落，实际 %d", len(paragraphs))
+	}
+	if text := paragraphs[0].Text(); text != "Tom & Jerry " {
+		t.Errorf("段落文字错误: %q", text)
+	}
+}
+
+func TestXMLNodeModification(t *testing.T) {
+	tree, err := ParseXMLTree([]byte(xmlTreeTestDocument))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	var paragraphs []*XMLNode
+	tree.Walk(func(node *XMLNode) bool {
+		if node.Is(WordNamespace, "p") {
+			paragraphs = append(paragraphs, node)
+		}
+		return true
+	})
+
+	text := paragraphs[0].Child(WordNamespace, "r").Child(WordNamespace, "t")
+	text.SetText("<Tom>")
+
+	run := NewXMLElement("w:r")
+	added := NewXMLElement("w:t")
+	added.SetText("new")
+	run.AppendChild(added)
+	paragraphs[1].AppendChild(run)
+	paragraphs[1].SetAttr("w:rsidR", "00B2")
+
+	if !run.Is(WordNamespace, "r") || !added.Is(WordNamespace, "t") {
+		t.Error("新元素应该继承父元素的命名空间")
+	}
+
+	got := string(tree.Bytes())
+	for _, expected := range []string{
+		`<w:t xml:space='preserve'>&lt;Tom&gt;</w:t>`,
+		`<x:custom  a="1" />`,
+		`<w:p w:rsidR="00B2"><w:r><w:t>new</w:t></w:r></w:p>`,
+		"<!-- generated -->",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("输出应该包含 %q:\n%s", expected, got)
+		}
+	}
+
+	if value, ok := paragraphs[0].GetAttr("w:rsidR"); !ok || value != "00A1" {
+		t.Errorf("属性读取错误: %q", value)
+	}
+}
+
+func TestXMLNodeInsertRemoveAndRename(t *testing.T) {
+	tree, err := ParseXMLTree([]byte(xmlTreeTestDocument))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	body := tree.Child(WordNamespace, "document").Child(WordNamespace, "body")
+	var paragraphs []*XMLNode
+	for _, child := range body.Children {
+		if child.Is(WordNamespace, "p") {
+			paragraphs = append(paragraphs, child)
+		}
+	}
+
+	inserted := NewXMLElement("w:p", XMLAttr("w:rsidR", "00C3"))
+	body.InsertChild(0, inserted)
+	if !inserted.Is(WordNamespace, "p") || inserted.Parent != body {
+		t.Error("插入的元素应该继承父元素的命名空间")
+	}
+	if !body.RemoveChild(paragraphs[1]) || body.RemoveChild(paragraphs[1]) {
+		t.Error("只能删除一次子元素")
+	}
+
+	got := string(tree.Bytes())
+	if !strings.HasPrefix(strings.SplitN(got, "<w:body>", 2)[1], `<w:p w:rsidR="00C3"/>`) {
+		t.Errorf("插入的段落应该位于正文开头:\n%s", got)
+	}
+	if strings.Contains(got, "<w:p/>") || !strings.Contains(got, "Tom &amp; Jerry") {
+		t.Errorf("删除的段落不应该输出:\n%s", got)
+	}
+
+	inserted.RenamePrefix("w", "ns0")
+	if got := string(inserted.Bytes()); got != `<ns0:p ns0:rsidR="00C3"/>` {
+		t.Errorf("前缀重命名错误: %s", got)
+	}
+}
@@ -0,0 +1,699 @@
+// Package text implements the text algorithms used by layout: the
+// bidirectional algorithm of UAX #9, the line breaking algorithm of
+// UAX #14 with the kinsoku rules of Chinese and Japanese text, and
+// hyphenation with TeX patterns.
+package text
+
+import "golang.org/x/text/unicode/bidi"
+
+// maxDepth is the deepest explicit embedding level (BD2)
+const maxDepth = 125
+
+// maxBrackets is the size of the bracket stack of rule BD16
+const maxBrackets = 63
+
+// Level is a resolved embedding level. Odd levels are right-to-left.
+type Level uint8
+
+// RTL reports whether text at the level runs right to left
+func (l Level) RTL() bool {
+	return l&1 == 1
+}
+
+// Direction is the base direction of a paragraph
+type Direction int
+
+const (
+	// DirectionAuto takes the direction of the first strong character
+	// (rules P2 and P3) and is left-to-right when there is none
+	DirectionAuto Direction = iota
+	DirectionLTR
+	DirectionRTL
+)
+
+// BidiParagraph is a paragraph with resolved embedding levels
+type BidiParagraph struct {
+	text []rune
+	// classes are the bidi classes of the characters
+	classes []bidi.Class
+	// levels are the levels of the characters before the line rules
+	levels []Level
+	level  Level
+}
+
+// NewBidiParagraph resolves the embedding levels of a paragraph of text
+// with the rules of UAX #9 up to I2. A paragraph separator inside the text
+// terminates explicit embeddings like the end of a paragraph.
+func NewBidiParagraph(text []rune, direction Direction) *BidiParagraph {
+	p := &BidiParagraph{text: text, classes: make([]bidi.Class, len(text))}
+	for i, r := range text {
+		p.classes[i] = bidiClass(r)
+	}
+
+	switch direction {
+	case DirectionRTL:
+		p.level = 1
+	case DirectionAuto:
+		if c := firstStrong(p.classes, 0, false); c == bidi.R || c == bidi.AL {
+			p.level = 1
+		}
+	}
+	p.resolve()
+	return p
+}
+
+// bidiClass returns the bidi class of r
+func bidiClass(r rune) bidi.Class {
+	properties, _ := bidi.LookupRune(r)
+	return properties.Class()
+}
+
+// StrongDirection returns the direction of the first strong character of
+// text outside isolates, or DirectionAuto when text has none
+func StrongDirection(text string) Direction {
+	classes := make([]bidi.Class, 0, len(text))
+	for _, r := range text {
+		classes = append(classes, bidiClass(r))
+	}
+	switch firstStrong(classes, 0, false) {
+	case bidi.L:
+		return DirectionLTR
+	case bidi.R, bidi.AL:
+		return DirectionRTL
+	default:
+		return DirectionAuto
+	}
+}
+
+// HasRTL reports whether text has right-to-left characters, Arabic
+// numbers or explicit formatting characters. Other text resolves to the
+// paragraph level everywhere and needs no reordering in left-to-right
+// paragraphs.
+func HasRTL(text []rune) bool {
+	for _, r := range text {
+		switch bidiClass(r) {
+		case bidi.R, bidi.AL, bidi.AN, bidi.LRE, bidi.LRO, bidi.RLE, bidi.RLO, bidi.LRI, bidi.RLI, bidi.FSI:
+			return true
+		}
+	}
+	return false
+}
+
+// Level returns the paragraph embedding level
+func (p *BidiParagraph) Level() Level {
+	return p.level
+}
+
+// Levels returns the resolved levels of the characters
+func (p *BidiParagraph) Levels() []Level {
+	return append([]Level(nil), p.levels...)
+}
+
+// LineLevels returns the levels of the characters of the line text[start:end]
+// after rule L1: separators and whitespace at the end of the line and
+// before separators take the paragraph level.
+func (p *BidiParagraph) LineLevels(start, end int) []Level {
+	levels := append([]Level(nil), p.levels[start:end]...)
+	// 从行尾向前查找，trailing 表示当前字符之后直到行尾或分隔符都是空白
+	trailing := true
+	for i := len(levels) - 1; i >= 0; i-- {
+		switch p.classes[start+i] {
+		case bidi.S, bidi.B:
+			levels[i] = p.level
+			trailing = true
+		case bidi.WS, bidi.LRI, bidi.RLI, bidi.FSI, bidi.PDI, bidi.BN, bidi.LRE, bidi.RLE, bidi.LRO, bidi.RLO, bidi.PDF:
+			if trailing {
+				levels[i] = p.level
+			}
+		default:
+			trailing = false
+		}
+	}
+	return levels
+}
+
+// Reorder returns the indexes of the characters of the line text[start:end]
+// in visual order, relative to start
+func (p *BidiParagraph) Reorder(start, end int) []int {
+	return ReorderLevels(p.LineLevels(start, end))
+}
+
+// ReorderLevels returns the visual order of items with the given levels
+// (rule L2): from the highest level down to the lowest odd level, every
+// run of items at that level or higher is reversed.
+func ReorderLevels(levels []Level) []int {
+	order := make([]int, len(levels))
+	var highest Level
+	lowestOdd := Level(maxDepth + 2)
+	for i, level := range levels {
+		order[i] = i
+		if level > highest {
+			highest = level
+		}
+		if level.RTL() && level < lowestOdd {
+			lowestOdd = level
+		}
+	}
+
+	for level := highest; level >= lowestOdd && level > 0; level-- {
+		for i := 0; i < len(levels); {
+			if levels[order[i]] < level {
+				i++
+				continue
+			}
+			end := i
+			for end < len(levels) && levels[order[end]] >= level {
+				end++
+			}
+			for a, b := i, end-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = end
+		}
+	}
+	return order
+}
+
+// Mirror returns the mirrored glyph of a character drawn right to left,
+// such as ")" for "(" (rule L4), or the character itself
+func Mirror(r rune) rune {
+	if m, ok := mirrors[r]; ok {
+		return m
+	}
+	return r
+}
+
+// firstStrong returns the class of the first L, R or AL character from
+// start, skipping isolates (rules P2 and P3). With pdi it stops at the
+// PDI that closes an isolate started before start. It returns ON when
+// there is no strong character.
+func firstStrong(classes []bidi.Class, start int, pdi bool) bidi.Class {
+	isolates := 0
+	for _, c := range classes[start:] {
+		switch c {
+		case bidi.L, bidi.R, bidi.AL:
+			if isolates == 0 {
+				return c
+			}
+		case bidi.LRI, bidi.RLI, bidi.FSI:
+			isolates++
+		case bidi.PDI:
+			if isolates > 0 {
+				isolates--
+			} else if pdi {
+				return bidi.ON
+			}
+		case bidi.B:
+			return bidi.ON
+		}
+	}
+	return bidi.ON
+}
+
+// removed reports whether rule X9 removes characters of class c
+func removed(c bidi.Class) bool {
+	switch c {
+	case bidi.LRE, bidi.RLE, bidi.LRO, bidi.RLO, bidi.PDF, bidi.BN:
+		return true
+	}
+	return false
+}
+
+// bidiStatus is an entry of the directional status stack
+type bidiStatus struct {
+	level Level
+	// override is L or R for overrides and ON otherwise
+	override bidi.Class
+	isolate  bool
+}
+
+// resolve computes the embedding levels with the explicit rules X1 to X10,
+// the weak and neutral rules and the implicit rules
+func (p *BidiParagraph) resolve() {
+	n := len(p.text)
+	p.levels = make([]Level, n)
+	types := append([]bidi.Class(nil), p.classes...)
+
+	// 匹配隔离起始符和PDI (BD9)
+	matchingPDI := make([]int, n)
+	var open []int
+	for i, c := range p.classes {
+		matchingPDI[i] = -1
+		switch c {
+		case bidi.LRI, bidi.RLI, bidi.FSI:
+			open = append(open, i)
+		case bidi.PDI:
+			if len(open) > 0 {
+				initiator := open[len(open)-1]
+				open = open[:len(open)-1]
+				matchingPDI[initiator] = i
+			}
+		case bidi.B:
+			open = open[:0]
+		}
+	}
+
+	// X1-X8
+	stack := []bidiStatus{{level: p.level, override: bidi.ON}}
+	overflowIsolates, overflowEmbeddings, validIsolates := 0, 0, 0
+	next := func(rtl bool) Level {
+		level := stack[len(stack)-1].level
+		if rtl {
+			return (level + 1) | 1
+		}
+		return (level + 2) &^ 1
+	}
+	for i, c := range p.classes {
+		top := stack[len(stack)-1]
+		switch c {
+		case bidi.RLE, bidi.LRE, bidi.RLO, bidi.LRO:
+			p.levels[i] = top.level
+			level := next(c == bidi.RLE || c == bidi.RLO)
+			if level <= maxDepth && overflowIsolates == 0 && overflowEmbeddings == 0 {
+				override := bidi.ON
+				switch c {
+				case bidi.RLO:
+					override = bidi.R
+				case bidi.LRO:
+					override = bidi.L
+				}
+				stack = append(stack, bidiStatus{level: level, override: override})
+			} else if overflowIsolates == 0 {
+				overflowEmbeddings++
+			}
+
+		case bidi.RLI, bidi.LRI, bidi.FSI:
+			p.levels[i] = top.level
+			if top.override != bidi.ON {
+				types[i] = top.override
+			}
+			rtl := c == bidi.RLI
+			if c == bidi.FSI {
+				strong := firstStrong(p.classes, i+1, true)
+				rtl = strong == bidi.R || strong == bidi.AL
+			}
+			level := next(rtl)
+			if level <= maxDepth && overflowIsolates == 0 && overflowEmbeddings == 0 {
+				validIsolates++
+				stack = append(stack, bidiStatus{level: level, override: bidi.ON, isolate: true})
+			} else {
+				overflowIsolates++
+			}
+
+		case bidi.PDI:
+			switch {
+			case overflowIsolates > 0:
+				overflowIsolates--
+			case validIsolates > 0:
+				overflowEmbeddings = 0
+				for !stack[len(stack)-1].isolate {
+					stack = stack[:len(stack)-1]
+				}
+				stack = stack[:len(stack)-1]
+				validIsolates--
+			}
+			top = stack[len(stack)-1]
+			p.levels[i] = top.level
+			if top.override != bidi.ON {
+				types[i] = top.override
+			}
+
+		case bidi.PDF:
+			p.levels[i] = top.level
+			switch {
+			case overflowIsolates > 0:
+			case overflowEmbeddings > 0:
+				overflowEmbeddings--
+			case !top.isolate && len(stack) >= 2:
+				stack = stack[:len(stack)-1]
+			}
+
+		case bidi.B:
+			// X8: 段落分隔符结束所有嵌入
+			p.levels[i] = p.level
+			stack = stack[:1]
+			overflowIsolates, overflowEmbeddings, validIsolates = 0, 0, 0
+
+		default:
+			p.levels[i] = top.level
+			if top.override != bidi.ON && c != bidi.BN {
+				types[i] = top.override
+			}
+		}
+	}
+
+	// X10: 级别相同的连续字符组成级别段，隔离起始符和匹配的PDI把级别段连接成隔离段序列
+	var runs [][]int
+	for i := 0; i < n; i++ {
+		if removed(p.classes[i]) {
+			continue
+		}
+		if len(runs) > 0 {
+			last := runs[len(runs)-1]
+			if p.levels[last[len(last)-1]] == p.levels[i] {
+				runs[len(runs)-1] = append(last, i)
+				continue
+			}
+		}
+		runs = append(runs, []int{i})
+	}
+	runStarting := make(map[int]int, len(runs))
+	for r, run := range runs {
+		runStarting[run[0]] = r
+	}
+	claimed := make([]bool, len(runs))
+	for r := range runs {
+		if claimed[r] {
+			continue
+		}
+		sequence := append([]int(nil), runs[r]...)
+		for {
+			last := sequence[len(sequence)-1]
+			pdi := matchingPDI[last]
+			if pdi < 0 {
+				break
+			}
+			following, ok := runStarting[pdi]
+			if !ok || claimed[following] {
+				break
+			}
+			claimed[following] = true
+			sequence = append(sequence, runs[following]...)
+		}
+		p.resolveSequence(sequence, types, matchingPDI)
+	}
+
+	// 被X9移除的字符取前一个字符的级别，便于重排
+	for i := range p.levels {
+		if removed(p.classes[i]) {
+			if i > 0 {
+				p.levels[i] = p.levels[i-1]
+			} else {
+				p.levels[i] = p.level
+			}
+		}
+	}
+}
+
+// resolveSequence applies the weak, neutral and implicit rules to an
+// isolating run sequence
+func (p *BidiParagraph) resolveSequence(sequence []int, types []bidi.Class, matchingPDI []int) {
+	level := p.levels[sequence[0]]
+	first, last := sequence[0], sequence[len(sequence)-1]
+
+	// sos 和 eos 取两侧级别中较高的一个的方向
+	before, after := p.level, p.level
+	for i := first - 1; i >= 0; i-- {
+		if !removed(p.classes[i]) {
+			before = p.levels[i]
+			break
+		}
+	}
+	// 以没有匹配PDI的隔离起始符结尾的序列之后按段落级别处理
+	if c := p.classes[last]; c != bidi.LRI && c != bidi.RLI && c != bidi.FSI {
+		for i := last + 1; i < len(p.text); i++ {
+			if !removed(p.classes[i]) {
+				after = p.levels[i]
+				break
+			}
+		}
+	}
+	direction := func(l Level) bidi.Class {
+		if l.RTL() {
+			return bidi.R
+		}
+		return bidi.L
+	}
+	sos := direction(max(level, before))
+	eos := direction(max(level, after))
+
+	t := make([]bidi.Class, len(sequence))
+	for k, i := range sequence {
+		t[k] = types[i]
+	}
+	isolateControl := func(c bidi.Class) bool {
+		return c == bidi.LRI || c == bidi.RLI || c == bidi.FSI || c == bidi.PDI
+	}
+
+	// W1: 非间距标记取前一个字符的类型
+	for k := range t {
+		if t[k] != bidi.NSM {
+			continue
+		}
+		switch {
+		case k == 0:
+			t[k] = sos
+		case isolateControl(t[k-1]):
+			t[k] = bidi.ON
+		default:
+			t[k] = t[k-1]
+		}
+	}
+	// W2, W3: 阿拉伯字母之后的欧洲数字是阿拉伯数字
+	strong := sos
+	for k, c := range t {
+		switch c {
+		case bidi.L, bidi.R, bidi.AL:
+			strong = c
+		case bidi.EN:
+			if strong == bidi.AL {
+				t[k] = bidi.AN
+			}
+		}
+	}
+	for k := range t {
+		if t[k] == bidi.AL {
+			t[k] = bidi.R
+		}
+	}
+	// W4: 数字之间的单个分隔符
+	for k := 1; k+1 < len(t); k++ {
+		switch {
+		case t[k] == bidi.ES && t[k-1] == bidi.EN && t[k+1] == bidi.EN:
+			t[k] = bidi.EN
+		case t[k] == bidi.CS && t[k-1] == bidi.EN && t[k+1] == bidi.EN:
+			t[k] = bidi.EN
+		case t[k] == bidi.CS && t[k-1] == bidi.AN && t[k+1] == bidi.AN:
+			t[k] = bidi.AN
+		}
+	}
+	// W5: 与欧洲数字相邻的欧洲终止符
+	for k := 0; k < len(t); k++ {
+		if t[k] != bidi.ET {
+			continue
+		}
+		end := k
+		for end < len(t) && t[end] == bidi.ET {
+			end++
+		}
+		if (k > 0 && t[k-1] == bidi.EN) || (end < len(t) && t[end] == bidi.EN) {
+			for j := k; j < end; j++ {
+				t[j] = bidi.EN
+			}
+		}
+		k = end - 1
+	}
+	// W6, W7
+	strong = sos
+	for k, c := range t {
+		switch c {
+		case bidi.ES, bidi.ET, bidi.CS:
+			t[k] = bidi.ON
+		case bidi.L, bidi.R:
+			strong = c
+		case bidi.EN:
+			if strong == bidi.L {
+				t[k] = bidi.L
+			}
+		}
+	}
+
+	p.resolveBrackets(sequence, t, level, sos)
+
+	// N1, N2: 中性字符两侧方向相同时取该方向，否则取嵌入方向
+	neutral := func(c bidi.Class) bool {
+		return c == bidi.B || c == bidi.S || c == bidi.WS || c == bidi.ON || isolateControl(c)
+	}
+	strongOf := func(c bidi.Class) bidi.Class {
+		if c == bidi.EN || c == bidi.AN {
+			return bidi.R
+		}
+		return c
+	}
+	for k := 0; k < len(t); k++ {
+		if !neutral(t[k]) {
+			continue
+		}
+		end := k
+		for end < len(t) && neutral(t[end]) {
+			end++
+		}
+		leading, trailing := sos, eos
+		if k > 0 {
+			leading = strongOf(t[k-1])
+		}
+		if end < len(t) {
+			trailing = strongOf(t[end])
+		}
+		resolved := direction(level)
+		if leading == trailing {
+			resolved = leading
+		}
+		for j := k; j < end; j++ {
+			t[j] = resolved
+		}
+		k = end - 1
+	}
+
+	// I1, I2
+	for k, i := range sequence {
+		switch {
+		case !level.RTL() && t[k] == bidi.R:
+			p.levels[i] = level + 1
+		case !level.RTL() && (t[k] == bidi.AN || t[k] == bidi.EN):
+			p.levels[i] = level + 2
+		case level.RTL() && (t[k] == bidi.L || t[k] == bidi.EN || t[k] == bidi.AN):
+			p.levels[i] = level + 1
+		default:
+			p.levels[i] = level
+		}
+	}
+}
+
+// resolveBrackets applies rule N0: paired brackets take the embedding
+// direction when the text between them has it, and the direction of the
+// context otherwise
+func (p *BidiParagraph) resolveBrackets(sequence []int, t []bidi.Class, level Level, sos bidi.Class) {
+	type pair struct{ open, close int }
+	var pairs []pair
+	type entry struct {
+		closing  rune
+		position int
+	}
+	var stack []entry
+scan:
+	for k, i := range sequence {
+		if t[k] != bidi.ON {
+			continue
+		}
+		r := canonicalBracket(p.text[i])
+		properties, _ := bidi.LookupRune(r)
+		if !properties.IsBracket() {
+			continue
+		}
+		if properties.IsOpeningBracket() {
+			if len(stack) == maxBrackets {
+				break scan
+			}
+			stack = append(stack, entry{closing: canonicalBracket(Mirror(r)), position: k})
+			continue
+		}
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j].closing == r {
+				pairs = append(pairs, pair{stack[j].position, k})
+				stack = stack[:j]
+				break
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		return
+	}
+	// 按开括号的位置处理
+	for i := 1; i < len(pairs); i++ {
+		for j := i; j > 0 && pairs[j].open < pairs[j-1].open; j-- {
+			pairs[j], pairs[j-1] = pairs[j-1], pairs[j]
+		}
+	}
+
+	embedding := bidi.L
+	if level.RTL() {
+		embedding = bidi.R
+	}
+	strongOf := func(c bidi.Class) bidi.Class {
+		switch c {
+		case bidi.EN, bidi.AN, bidi.R:
+			return bidi.R
+		case bidi.L:
+			return bidi.L
+		}
+		return bidi.ON
+	}
+	for _, pr := range pairs {
+		resolved := bidi.ON
+		opposite := false
+		for k := pr.open + 1; k < pr.close; k++ {
+			switch strongOf(t[k]) {
+			case embedding:
+				resolved = embedding
+			case bidi.ON:
+			default:
+				opposite = true
+			}
+			if resolved != bidi.ON {
+				break
+			}
+		}
+		if resolved == bidi.ON && opposite {
+			context := sos
+			for k := pr.open - 1; k >= 0; k-- {
+				if c := strongOf(t[k]); c != bidi.ON {
+					context = c
+					break
+				}
+			}
+			resolved = embedding
+			if context != embedding {
+				resolved = context
+			}
+		}
+		if resolved == bidi.ON {
+			continue
+		}
+		for _, k := range []int{pr.open, pr.close} {
+			t[k] = resolved
+			// 括号后的非间距标记随括号改变
+			for j := k + 1; j < len(t) && p.classes[sequence[j]] == bidi.NSM; j++ {
+				t[j] = resolved
+			}
+		}
+	}
+}
+
+// canonicalBracket maps the angle brackets U+2329 and U+232A to their
+// canonical equivalents U+3008 and U+3009
+func canonicalBracket(r rune) rune {
+	switch r {
+	case 0x2329:
+		return 0x3008
+	case 0x232A:
+		return 0x3009
+	}
+	return r
+}
+
+// mirrors are the pairs of Bidi_Mirroring_Glyph that documents commonly use
+var mirrors = func() map[rune]rune {
+	pairs := []rune{
+		'(', ')', '<', '>', '[', ']', '{', '}', '«', '»',
+		0x0F3A, 0x0F3B, 0x0F3C, 0x0F3D, 0x169B, 0x169C,
+		0x2039, 0x203A, 0x2045, 0x2046, 0x207D, 0x207E, 0x208D, 0x208E,
+		0x2208, 0x220B, 0x2209, 0x220C, 0x220A, 0x220D, 0x2264, 0x2265,
+		0x2266, 0x2267, 0x226A, 0x226B, 0x2282, 0x2283, 0x2286, 0x2287,
+		0x2308, 0x2309, 0x230A, 0x230B, 0x2329, 0x232A,
+		0x2768, 0x2769, 0x276A, 0x276B, 0x276C, 0x276D, 0x276E, 0x276F,
+		0x2770, 0x2771, 0x2772, 0x2773, 0x2774, 0x2775,
+		0x27E6, 0x27E7, 0x27E8, 0x27E9, 0x27EA, 0x27EB, 0x27EC, 0x27ED, 0x27EE, 0x27EF,
+		0x2983, 0x2984, 0x2985, 0x2986, 0x2987, 0x2988, 0x2989, 0x298A,
+		0x3008, 0x3009, 0x300A, 0x300B, 0x300C, 0x300D, 0x300E, 0x300F,
+		0x3010, 0x3011, 0x3014, 0x3015, 0x3016, 0x3017, 0x3018, 0x3019, 0x301A, 0x301B,
+		0xFE59, 0xFE5A, 0xFE5B, 0xFE5C, 0xFE5D, 0xFE5E, 0xFE64, 0xFE65,
+		0xFF08, 0xFF09, 0xFF1C, 0xFF1E, 0xFF3B, 0xFF3D, 0xFF5B, 0xFF5D,
+		0xFF5F, 0xFF60, 0xFF62, 0xFF63,
+	}
+	m := make(map[rune]rune, len(pairs))
+	for i := 0; i < len(pairs); i += 2 {
+		m[pairs[i]], m[pairs[i+1]] = pairs[i+1], pairs[i]
+	}
+	return m
+}()
@@ -0,0 +1,81 @@
+package text
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// visual returns a line in visual order with mirrored right-to-left
+// characters and without explicit formatting characters
+func visual(line string, direction Direction) string {
+	runes := []rune(line)
+	p := NewBidiParagraph(runes, direction)
+	levels := p.LineLevels(0, len(runes))
+	var out strings.Builder
+	for _, i := range p.Reorder(0, len(runes)) {
+		r := runes[i]
+		if r >= 0x2066 && r <= 0x2069 || r >= 0x202A && r <= 0x202E {
+			continue
+		}
+		if levels[i].RTL() {
+			r = Mirror(r)
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+func TestBidiParagraph(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		direction Direction
+		want      string
+	}{
+		{"latin", "hello world", DirectionAuto, "hello world"},
+		{"embedded hebrew", "abc אבג def", DirectionAuto, "abc גבא def"},
+		{"numbers in rtl", "אבג 123 abc.", DirectionAuto, ".abc 123 גבא"},
+		{"brackets", "אבג (abc) ד", DirectionRTL, "ד (abc) גבא"},
+		{"isolate", "abc ⁧אבג def⁩ ghi", DirectionLTR, "abc def גבא ghi"},
+		{"override", "‮abc‬", DirectionLTR, "cba"},
+	}
+	for _, tt := range tests {
+		if got := visual(tt.text, tt.direction); got != tt.want {
+			t.Errorf("%s: visual order %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBidiLevels(t *testing.T) {
+	// 阿拉伯字母之后的数字是阿拉伯数字，级别为2
+	p := NewBidiParagraph([]rune("عدد 12"), DirectionAuto)
+	if p.Level() != 1 {
+		t.Fatalf("expected paragraph level 1, got %d", p.Level())
+	}
+	if got, want := p.Levels(), []Level{1, 1, 1, 1, 2, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("levels %v, want %v", got, want)
+	}
+
+	// 行尾空格取段落级别
+	p = NewBidiParagraph([]rune("abc אב "), DirectionLTR)
+	if got, want := p.LineLevels(0, 7), []Level{0, 0, 0, 0, 1, 1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("line levels %v, want %v", got, want)
+	}
+
+	if StrongDirection("123 שלום") != DirectionRTL || StrongDirection("(hello)") != DirectionLTR || StrongDirection("123") != DirectionAuto {
+		t.Error("wrong strong direction")
+	}
+	if HasRTL([]rune("plain text 123")) || !HasRTL([]rune("text שלום")) {
+		t.Error("wrong right-to-left detection")
+	}
+}
+
+func TestReorderLevels(t *testing.T) {
+	if got, want := ReorderLevels([]Level{0, 1, 1, 2, 2, 1, 0}), []int{0, 5, 3, 4, 2, 1, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order %v, want %v", got, want)
+	}
+	if Mirror('(') != ')' || Mirror('「') != '」' || Mirror('a') != 'a' {
+		t.Error("wrong mirrored characters")
+	}
+}
@@ -0,0 +1,217 @@
+package text
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// hyphenationMinimums are the shortest word starts and ends that the
+// patterns of a language are made for
+var hyphenationMinimums = map[string][2]int{
+	"en-us": {2, 3},
+	"en-gb": {2, 3},
+	"de":    {2, 2},
+	"fr":    {2, 3},
+}
+
+// hyphenationFiles maps languages whose pattern files are named after a
+// spelling or region to those names
+var hyphenationFiles = map[string][]string{
+	"en": {"en-us", "en-gb"},
+	"de": {"de-1996", "de-1901"},
+}
+
+// Hyphenator finds the hyphenation points of words with Liang's algorithm
+// and the pattern files of TeX
+type Hyphenator struct {
+	// patterns holds the values between the letters of every pattern,
+	// one more than the letters
+	patterns  map[string][]byte
+	maxLength int
+	// exceptions are the hyphenation points of words the patterns get wrong
+	exceptions map[string][]int
+	// LeftMin and RightMin are the fewest letters kept before the first
+	// and after the last hyphen
+	LeftMin  int
+	RightMin int
+}
+
+// NewHyphenator reads hyphenation patterns. The input is either a list of
+// patterns such as the hyph-*.pat.txt files of hyph-utf8, one per line, or
+// a TeX file with \patterns{...} and \hyphenation{...} groups. % starts a
+// comment.
+func NewHyphenator(r io.Reader) (*Hyphenator, error) {
+	h := &Hyphenator{
+		patterns:   make(map[string][]byte),
+		exceptions: make(map[string][]int),
+		LeftMin:    2,
+		RightMin:   3,
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	// group is the TeX group being read; plain lists have no groups
+	group, plain := "", true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '%'); i >= 0 {
+			line = line[:i]
+		}
+		for _, word := range strings.Fields(line) {
+			switch {
+			case strings.HasPrefix(word, `\patterns{`):
+				group, plain, word = "patterns", false, strings.TrimPrefix(word, `\patterns{`)
+			case strings.HasPrefix(word, `\hyphenation{`):
+				group, plain, word = "hyphenation", false, strings.TrimPrefix(word, `\hyphenation{`)
+			case strings.HasPrefix(word, `\`):
+				continue
+			}
+			closed := strings.HasSuffix(word, "}")
+			word = strings.TrimSuffix(word, "}")
+			switch {
+			case word == "":
+			case group == "hyphenation":
+				h.AddException(word)
+			case group == "patterns" || plain:
+				h.addPattern(word)
+			}
+			if closed {
+				group = ""
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hyphenation patterns: %w", err)
+	}
+	if len(h.patterns) == 0 {
+		return nil, fmt.Errorf("no hyphenation patterns found")
+	}
+	return h, nil
+}
+
+// LoadHyphenator loads the patterns of a language from the hyph-*.pat,
+// hyph-*.pat.txt or hyph-*.tex file of the directory, together with the
+// exceptions of a hyph-*.hyp.txt file next to it. A language without a
+// file of its own, such as de-DE, uses the file of its primary language.
+func LoadHyphenator(directory, language string) (*Hyphenator, error) {
+	for _, name := range hyphenationNames(language) {
+		for _, file := range []string{"hyph-" + name + ".pat", "hyph-" + name + ".pat.txt", "hyph-" + name + ".tex"} {
+			f, err := os.Open(filepath.Join(directory, file))
+			if err != nil {
+				continue
+			}
+			h, err := NewHyphenator(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			if minimums, ok := hyphenationMinimums[name]; ok {
+				h.LeftMin, h.RightMin = minimums[0], minimums[1]
+			} else if minimums, ok := hyphenationMinimums[strings.SplitN(name, "-", 2)[0]]; ok {
+				h.LeftMin, h.RightMin = minimums[0], minimums[1]
+			}
+			if data, err := os.ReadFile(filepath.Join(directory, "hyph-"+name+".hyp.txt")); err == nil {
+				for _, word := range strings.Fields(string(data)) {
+					h.AddException(word)
+				}
+			}
+			return h, nil
+		}
+	}
+	return nil, fmt.Errorf("no hyphenation patterns for %s in %s", language, directory)
+}
+
+// hyphenationNames returns the pattern file names to try for a language
+func hyphenationNames(language string) []string {
+	language = strings.ToLower(strings.ReplaceAll(language, "_", "-"))
+	primary := strings.SplitN(language, "-", 2)[0]
+	names := []string{language}
+	if primary != language {
+		names = append(names, primary)
+	}
+	return append(names, hyphenationFiles[primary]...)
+}
+
+// addPattern adds a pattern such as "1ba" or ".ach4". Digits are the
+// values between letters; dots match the start and end of a word.
+func (h *Hyphenator) addPattern(pattern string) {
+	var letters []rune
+	values := []byte{0}
+	for _, r := range pattern {
+		if r >= '0' && r <= '9' {
+			values[len(values)-1] = byte(r - '0')
+			continue
+		}
+		letters = append(letters, unicode.ToLower(r))
+		values = append(values, 0)
+	}
+	if len(letters) == 0 {
+		return
+	}
+	h.patterns[string(letters)] = values
+	h.maxLength = max(h.maxLength, len(letters))
+}
+
+// AddException sets the hyphenation points of a word written with hyphens
+// at those points, such as "ta-ble"
+func (h *Hyphenator) AddException(word string) {
+	var letters []rune
+	var points []int
+	for _, r := range word {
+		if r == '-' {
+			points = append(points, len(letters))
+			continue
+		}
+		letters = append(letters, unicode.ToLower(r))
+	}
+	if len(letters) > 0 {
+		h.exceptions[string(letters)] = points
+	}
+}
+
+// Hyphenate returns the positions in the characters of word where it may
+// be hyphenated, in increasing order. Words shorter than LeftMin plus
+// RightMin characters are not hyphenated.
+func (h *Hyphenator) Hyphenate(word string) []int {
+	letters := []rune(strings.ToLower(word))
+	if len(letters) < h.LeftMin+h.RightMin {
+		return nil
+	}
+	if points, ok := h.exceptions[string(letters)]; ok {
+		var allowed []int
+		for _, point := range points {
+			if point >= h.LeftMin && point <= len(letters)-h.RightMin {
+				allowed = append(allowed, point)
+			}
+		}
+		return allowed
+	}
+
+	// 在单词前后加上点号，记录每个字母间隔的最大值
+	dotted := append(append([]rune{'.'}, letters...), '.')
+	values := make([]byte, len(dotted)+1)
+	for start := range dotted {
+		for end := start + 1; end <= len(dotted) && end-start <= h.maxLength; end++ {
+			pattern, ok := h.patterns[string(dotted[start:end])]
+			if !ok {
+				continue
+			}
+			for i, value := range pattern {
+				values[start+i] = max(values[start+i], value)
+			}
+		}
+	}
+
+	// 奇数值允许断字，values[i+1]是第i个字母之前的间隔
+	var points []int
+	for i := h.LeftMin; i <= len(letters)-h.RightMin; i++ {
+		if values[i+1]%2 == 1 {
+			points = append(points, i)
+		}
+	}
+	return points
+}
@@ -0,0 +1,75 @@
+package text
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// liangPatterns are the patterns that hyphenate "hyphenation" in Liang's
+// thesis
+const liangPatterns = `% patterns of the example
+hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n
+`
+
+func TestHyphenate(t *testing.T) {
+	h, err := NewHyphenator(strings.NewReader(liangPatterns))
+	if err != nil {
+		t.Fatalf("failed to read patterns: %v", err)
+	}
+	if got, want := h.Hyphenate("Hyphenation"), []int{2, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hyphenation points %v, want %v", got, want)
+	}
+	// 太短的单词不断字
+	if got := h.Hyphenate("hyph"); got != nil {
+		t.Errorf("short word hyphenated at %v", got)
+	}
+
+	h.AddException("hyphen-ation")
+	if got, want := h.Hyphenate("hyphenation"), []int{6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("exception points %v, want %v", got, want)
+	}
+}
+
+func TestNewHyphenatorTeX(t *testing.T) {
+	tex := `\message{example patterns}
+\patterns{ % the patterns
+hy3ph he2n hena4
+hen5at 1na n2at 1tio 2io o2n}
+\hyphenation{ta-ble}
+`
+	h, err := NewHyphenator(strings.NewReader(tex))
+	if err != nil {
+		t.Fatalf("failed to read patterns: %v", err)
+	}
+	if got, want := h.Hyphenate("hyphenation"), []int{2, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hyphenation points %v, want %v", got, want)
+	}
+	h.LeftMin, h.RightMin = 1, 1
+	if got, want := h.Hyphenate("table"), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("exception points %v, want %v", got, want)
+	}
+
+	if _, err := NewHyphenator(strings.NewReader("% nothing\n")); err == nil {
+		t.Error("expected an error without patterns")
+	}
+}
+
+func TestLoadHyphenator(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hyph-de-1996.pat.txt"), []byte(liangPatterns), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := LoadHyphenator(dir, "de-DE")
+	if err != nil {
+		t.Fatalf("failed to load patterns: %v", err)
+	}
+	if h.LeftMin != 2 || h.RightMin != 2 {
+		t.Errorf("minimums %d and %d, want 2 and 2", h.LeftMin, h.RightMin)
+	}
+	if _, err := LoadHyphenator(dir, "fr-FR"); err == nil {
+		t.Error("expected an error without French patterns")
+	}
+}
@@ -0,0 +1,352 @@
+package text
+
+import "unicode"
+
+// LineClass is a line breaking class of UAX #14
+type LineClass uint8
+
+const (
+	LineBK  LineClass = iota // mandatory break
+	LineCR                   // carriage return
+	LineLF                   // line feed
+	LineCM                   // combining mark
+	LineNL                   // next line
+	LineWJ                   // word joiner
+	LineZW                   // zero width space
+	LineGL                   // non-breaking glue
+	LineSP                   // space
+	LineZWJ                  // zero width joiner
+	LineB2                   // break opportunity before and after
+	LineBA                   // break after
+	LineBB                   // break before
+	LineHY                   // hyphen
+	LineCB                   // contingent break
+	LineCL                   // close punctuation
+	LineCP                   // close parenthesis
+	LineEX                   // exclamation and interrogation
+	LineIN                   // inseparable
+	LineNS                   // nonstarter
+	LineOP                   // open punctuation
+	LineQU                   // quotation
+	LineIS                   // infix numeric separator
+	LineNU                   // numeric
+	LinePO                   // postfix numeric
+	LinePR                   // prefix numeric
+	LineSY                   // symbols allowing break after
+	LineAL                   // alphabetic
+	LineCJ                   // conditional Japanese starter
+	LineEB                   // emoji base
+	LineEM                   // emoji modifier
+	LineH2                   // Hangul LV syllable
+	LineH3                   // Hangul LVT syllable
+	LineHL                   // Hebrew letter
+	LineID                   // ideographic
+	LineJL                   // Hangul L jamo
+	LineJT                   // Hangul T jamo
+	LineJV                   // Hangul V jamo
+	LineRI                   // regional indicator
+	LineSA                   // complex context dependent (South East Asian)
+)
+
+// Break is the line break status before a character
+type Break uint8
+
+const (
+	// BreakProhibited forbids a line break
+	BreakProhibited Break = iota
+	// BreakAllowed is a line break opportunity
+	BreakAllowed
+	// BreakMandatory forces a line break, as after a line separator
+	BreakMandatory
+)
+
+// lineClasses are the classes of characters that the general category
+// does not determine
+var lineClasses = map[rune]LineClass{
+	'\t': LineBA, '\n': LineLF, 0x0B: LineBK, 0x0C: LineBK, '\r': LineCR, ' ': LineSP,
+	'!': LineEX, '"': LineQU, '$': LinePR, '%': LinePO, '\'': LineQU, '(': LineOP, ')': LineCP,
+	'+': LinePR, ',': LineIS, '-': LineHY, '.': LineIS, '/': LineSY, ':': LineIS, ';': LineIS,
+	'?': LineEX, '[': LineOP, '\\': LinePR, ']': LineCP, '{': LineOP, '|': LineBA, '}': LineCL,
+	0x85: LineNL, 0xA0: LineGL, 0xA2: LinePO, 0xAB: LineQU, 0xAD: LineBA, 0xB0: LinePO,
+	0xB1: LinePR, 0xB4: LineBB, 0xBB: LineQU, 0x034F: LineGL, 0x058A: LineBA, 0x05BE: LineBA,
+	0x060C: LineIS, 0x060D: LineIS, 0x061B: LineEX, 0x061F: LineEX, 0x066A: LinePO, 0x06D4: LineEX,
+	0x0E5A: LineBA, 0x0E5B: LineBA, 0x0F0B: LineBA, 0x0F0C: LineGL, 0x1680: LineBA,
+	0x2007: LineGL, 0x200B: LineZW, 0x200D: LineZWJ, 0x2010: LineBA, 0x2011: LineGL,
+	0x2012: LineBA, 0x2013: LineBA, 0x2014: LineB2, 0x2024: LineIN, 0x2025: LineIN, 0x2026: LineIN,
+	0x2027: LineBA, 0x2028: LineBK, 0x2029: LineBK, 0x202F: LineGL, 0x2030: LinePO, 0x2031: LinePO,
+	0x2032: LinePO, 0x2033: LinePO, 0x2034: LinePO, 0x203C: LineNS, 0x203D: LineNS, 0x2044: LineIS,
+	0x2047: LineNS, 0x2048: LineNS, 0x2049: LineNS, 0x205F: LineBA, 0x2060: LineWJ,
+	0x2103: LinePO, 0x2109: LinePO, 0x2116: LinePR, 0x2212: LinePR, 0x2213: LinePR, 0x22EF: LineIN,
+	0x2E3A: LineB2, 0x2E3B: LineB2,
+	// 中日文标点：句读和闭括号不能位于行首，开括号不能位于行尾
+	0x3000: LineBA, 0x3001: LineCL, 0x3002: LineCL, 0x3005: LineNS, 0x301C: LineNS, 0x303B: LineNS,
+	0x309B: LineNS, 0x309C: LineNS, 0x309D: LineNS, 0x309E: LineNS, 0x30A0: LineNS, 0x30FB: LineNS,
+	0x30FC: LineCJ, 0x30FD: LineNS, 0x30FE: LineNS, 0xFE10: LineIS, 0xFE11: LineCL, 0xFE12: LineCL,
+	0xFE13: LineIS, 0xFE14: LineIS, 0xFE15: LineEX, 0xFE16: LineEX, 0xFE19: LineIN, 0xFE50: LineCL,
+	0xFE52: LineCL, 0xFE54: LineNS, 0xFE55: LineNS, 0xFE56: LineEX, 0xFE57: LineEX, 0xFEFF: LineWJ,
+	0xFF01: LineEX, 0xFF04: LinePR, 0xFF05: LinePO, 0xFF0C: LineCL, 0xFF0E: LineCL, 0xFF1A: LineNS,
+	0xFF1B: LineNS, 0xFF1F: LineEX, 0xFF61: LineCL, 0xFF64: LineCL, 0xFF65: LineNS, 0xFF70: LineCJ,
+	0xFF9E: LineNS, 0xFF9F: LineNS, 0xFFE0: LinePO, 0xFFE1: LinePR, 0xFFE5: LinePR, 0xFFE6: LinePR,
+	0xFFFC: LineCB,
+}
+
+// smallKana are the small kana and prolonged sound marks that Japanese
+// kinsoku keeps off the start of a line
+var smallKana = &unicode.RangeTable{R16: []unicode.Range16{
+	{Lo: 0x3041, Hi: 0x3049, Stride: 2}, {Lo: 0x3063, Hi: 0x3063, Stride: 1},
+	{Lo: 0x3083, Hi: 0x3087, Stride: 2}, {Lo: 0x308E, Hi: 0x308E, Stride: 1},
+	{Lo: 0x3095, Hi: 0x3096, Stride: 1}, {Lo: 0x30A1, Hi: 0x30A9, Stride: 2},
+	{Lo: 0x30C3, Hi: 0x30C3, Stride: 1}, {Lo: 0x30E3, Hi: 0x30E7, Stride: 2},
+	{Lo: 0x30EE, Hi: 0x30EE, Stride: 1}, {Lo: 0x30F5, Hi: 0x30F6, Stride: 1},
+	{Lo: 0x31F0, Hi: 0x31FF, Stride: 1}, {Lo: 0xFF67, Hi: 0xFF6F, Stride: 1},
+}}
+
+// LookupLineClass returns the line breaking class of r. The classes of
+// most characters follow from their general category and script.
+func LookupLineClass(r rune) LineClass {
+	if c, ok := lineClasses[r]; ok {
+		return c
+	}
+	switch {
+	case r >= 0x2000 && r <= 0x200A:
+		return LineBA
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return LineH2
+		}
+		return LineH3
+	case r >= 0x1100 && r <= 0x115F || r >= 0xA960 && r <= 0xA97C:
+		return LineJL
+	case r >= 0x1160 && r <= 0x11A7 || r >= 0xD7B0 && r <= 0xD7C6:
+		return LineJV
+	case r >= 0x11A8 && r <= 0x11FF || r >= 0xD7CB && r <= 0xD7FB:
+		return LineJT
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return LineRI
+	case r >= 0x1F3FB && r <= 0x1F3FF:
+		return LineEM
+	case r >= 0x1F466 && r <= 0x1F469 || r >= 0x1F645 && r <= 0x1F647 || r >= 0x1F64B && r <= 0x1F64F || r >= 0x270A && r <= 0x270D || r == 0x261D:
+		return LineEB
+	case unicode.Is(smallKana, r):
+		return LineCJ
+	case unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me, unicode.Cc, unicode.Cf):
+		return LineCM
+	case unicode.In(r, unicode.Thai, unicode.Lao, unicode.Myanmar, unicode.Khmer, unicode.Tai_Tham, unicode.Tai_Viet):
+		return LineSA
+	case unicode.Is(unicode.Hebrew, r) && unicode.IsLetter(r):
+		return LineHL
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Bopomofo, unicode.Yi) && r < 0xFF00,
+		r >= 0x2E80 && r <= 0x2FFF, r >= 0x3000 && r <= 0x303F, r >= 0x3200 && r <= 0x33FF,
+		r >= 0xFF01 && r <= 0xFF60, r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F, r >= 0x1F900 && r <= 0x1F9FF, r >= 0x20000 && r <= 0x3FFFD:
+		// 兼容区和表意字符区的开闭括号已在前面的分支中处理
+		switch {
+		case unicode.Is(unicode.Ps, r):
+			return LineOP
+		case unicode.Is(unicode.Pe, r):
+			return LineCL
+		}
+		return LineID
+	case unicode.Is(unicode.Nd, r):
+		return LineNU
+	case unicode.Is(unicode.Ps, r):
+		return LineOP
+	case unicode.Is(unicode.Pe, r):
+		return LineCL
+	case unicode.In(r, unicode.Pi, unicode.Pf):
+		return LineQU
+	case unicode.Is(unicode.Pd, r), unicode.Is(unicode.Zs, r):
+		return LineBA
+	case unicode.Is(unicode.Sc, r):
+		return LinePR
+	default:
+		return LineAL
+	}
+}
+
+// resolveLineClass applies rule LB1 with strict kinsoku: conditional
+// Japanese starters are nonstarters, and South East Asian letters, which
+// need a dictionary to break, are alphabetic
+func resolveLineClass(r rune) LineClass {
+	switch c := LookupLineClass(r); c {
+	case LineCJ:
+		return LineNS
+	case LineSA:
+		if unicode.In(r, unicode.Mn, unicode.Mc) {
+			return LineCM
+		}
+		return LineAL
+	default:
+		return c
+	}
+}
+
+// LineBreaks returns the line break status before every character of text
+// and, as the last element, at the end of text, which is always a
+// mandatory break (LB3). The pair rules LB4 to LB31 of UAX #14 apply with
+// the strict line breaking of Chinese and Japanese: closing punctuation,
+// iteration marks, small kana and the prolonged sound mark never start a
+// line, and opening punctuation never ends one.
+func LineBreaks(text []rune) []Break {
+	n := len(text)
+	breaks := make([]Break, n+1)
+	breaks[n] = BreakMandatory
+	if n == 0 {
+		return breaks
+	}
+
+	classes := make([]LineClass, n)
+	for i, r := range text {
+		classes[i] = resolveLineClass(r)
+	}
+	// LB9, LB10: 组合字符跟随前一个字符的类别，单独的组合字符按字母处理
+	effective := make([]LineClass, n)
+	attached := make([]bool, n)
+	for i, c := range classes {
+		effective[i] = c
+		if c != LineCM && c != LineZWJ {
+			continue
+		}
+		if i > 0 {
+			switch effective[i-1] {
+			case LineBK, LineCR, LineLF, LineNL, LineSP, LineZW:
+			default:
+				effective[i] = effective[i-1]
+				attached[i] = true
+				continue
+			}
+		}
+		effective[i] = LineAL
+	}
+
+	// beforeSpaces 返回 i 之前跳过空格后的类别
+	beforeSpaces := func(i int) LineClass {
+		for i >= 0 && effective[i] == LineSP {
+			i--
+		}
+		if i < 0 {
+			return LineSP
+		}
+		return effective[i]
+	}
+	// regional 是当前位置之前连续区域指示符的个数
+	regional := 0
+	for i := 1; i < n; i++ {
+		if effective[i-1] == LineRI {
+			regional++
+		} else {
+			regional = 0
+		}
+		breaks[i] = lineBreak(effective, classes, attached, text, i, beforeSpaces, regional)
+	}
+	return breaks
+}
+
+// lineBreak decides the break between text[i-1] and text[i]
+func lineBreak(effective, classes []LineClass, attached []bool, text []rune, i int, beforeSpaces func(int) LineClass, regional int) Break {
+	a, b := effective[i-1], effective[i]
+	in := func(c LineClass, set ...LineClass) bool {
+		for _, s := range set {
+			if c == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	// LB4, LB5: 强制换行
+	case a == LineBK:
+		return BreakMandatory
+	case a == LineCR && b == LineLF:
+		return BreakProhibited
+	case in(a, LineCR, LineLF, LineNL):
+		return BreakMandatory
+	// LB6, LB7
+	case in(b, LineBK, LineCR, LineLF, LineNL, LineSP, LineZW):
+		return BreakProhibited
+	// LB8: 零宽空格之后可以换行
+	case beforeSpaces(i-1) == LineZW:
+		return BreakAllowed
+	// LB8a, LB9
+	case classes[i-1] == LineZWJ, attached[i]:
+		return BreakProhibited
+	// LB11, LB12, LB12a: 连接符
+	case a == LineWJ, b == LineWJ, a == LineGL:
+		return BreakProhibited
+	case b == LineGL && !in(a, LineSP, LineBA, LineHY):
+		return BreakProhibited
+	// LB13: 闭标点、感叹号、分隔符之前不能换行
+	case in(b, LineCL, LineCP, LineEX, LineIS, LineSY):
+		return BreakProhibited
+	// LB14-LB17: 开标点之后以及引号、闭标点、破折号与空格组合时
+	case beforeSpaces(i-1) == LineOP:
+		return BreakProhibited
+	case b == LineOP && beforeSpaces(i-1) == LineQU:
+		return BreakProhibited
+	case b == LineNS && in(beforeSpaces(i-1), LineCL, LineCP):
+		return BreakProhibited
+	case b == LineB2 && beforeSpaces(i-1) == LineB2:
+		return BreakProhibited
+	// LB18: 空格之后可以换行
+	case a == LineSP:
+		return BreakAllowed
+	// LB19, LB20
+	case a == LineQU, b == LineQU:
+		return BreakProhibited
+	case a == LineCB, b == LineCB:
+		return BreakAllowed
+	// LB21, LB21a, LB21b: 连字符和不能位于行首的字符
+	case in(b, LineBA, LineHY, LineNS), a == LineBB:
+		return BreakProhibited
+	case i >= 2 && effective[i-2] == LineHL && in(a, LineHY, LineBA):
+		return BreakProhibited
+	case a == LineSY && b == LineHL:
+		return BreakProhibited
+	// LB22
+	case b == LineIN:
+		return BreakProhibited
+	// LB23, LB23a, LB24: 字母、数字和前后缀
+	case in(a, LineAL, LineHL) && b == LineNU, a == LineNU && in(b, LineAL, LineHL):
+		return BreakProhibited
+	case a == LinePR && in(b, LineID, LineEB, LineEM), in(a, LineID, LineEB, LineEM) && b == LinePO:
+		return BreakProhibited
+	case in(a, LinePR, LinePO) && in(b, LineAL, LineHL), in(a, LineAL, LineHL) && in(b, LinePR, LinePO):
+		return BreakProhibited
+	// LB25: 数字不拆开
+	case b == LineNU && in(a, LinePR, LinePO, LineOP, LineHY, LineNU, LineSY, LineIS):
+		return BreakProhibited
+	case in(b, LinePO, LinePR) && (a == LineNU || in(a, LineCL, LineCP) && i >= 2 && effective[i-2] == LineNU):
+		return BreakProhibited
+	case in(a, LinePR, LinePO) && in(b, LineOP, LineHY) && i+1 < len(effective) && effective[i+1] == LineNU:
+		return BreakProhibited
+	// LB26, LB27: 韩文音节
+	case a == LineJL && in(b, LineJL, LineJV, LineH2, LineH3),
+		in(a, LineJV, LineH2) && in(b, LineJV, LineJT),
+		in(a, LineJT, LineH3) && b == LineJT:
+		return BreakProhibited
+	case in(a, LineJL, LineJV, LineJT, LineH2, LineH3) && b == LinePO, a == LinePR && in(b, LineJL, LineJV, LineJT, LineH2, LineH3):
+		return BreakProhibited
+	// LB28, LB29
+	case in(a, LineAL, LineHL) && in(b, LineAL, LineHL):
+		return BreakProhibited
+	case a == LineIS && in(b, LineAL, LineHL):
+		return BreakProhibited
+	// LB30: 字母数字与半角括号之间
+	case in(a, LineAL, LineHL, LineNU) && b == LineOP && !wide(text[i]),
+		a == LineCP && in(b, LineAL, LineHL, LineNU) && !wide(text[i-1]):
+		return BreakProhibited
+	// LB30a, LB30b: 国旗和表情修饰符
+	case a == LineRI && b == LineRI && regional%2 == 1:
+		return BreakProhibited
+	case a == LineEB && b == LineEM:
+		return BreakProhibited
+	}
+	// LB31
+	return BreakAllowed
+}
+
+// wide reports whether r is a full width character of East Asian text
+func wide(r rune) bool {
+	return r >= 0x1100 && (r >= 0x2E80 && r <= 0xA4CF || r >= 0xAC00 && r <= 0xD7A3 || r >= 0xF900 && r <= 0xFAFF || r >= 0xFE30 && r <= 0xFE4F || r >= 0xFF00 && r <= 0xFF60 || r >= 0xFFE0 && r <= 0xFFE6 || r >= 0x20000)
+}
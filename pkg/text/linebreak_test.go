@@ -0,0 +1,62 @@
+package text
+
+import (
+	"strings"
+	"testing"
+)
+
+// segments splits text at its break opportunities and marks mandatory
+// breaks with "|"
+func segments(text string) string {
+	runes := []rune(text)
+	breaks := LineBreaks(runes)
+	var out strings.Builder
+	for i, r := range runes {
+		switch breaks[i] {
+		case BreakAllowed:
+			out.WriteString("/")
+		case BreakMandatory:
+			out.WriteString("|")
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+func TestLineBreaks(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"words", "Hello world, foo-bar.", "Hello /world, /foo-/bar."},
+		{"numbers", "costs $100.50 (about 20%)", "costs /$100.50 /(about /20%)"},
+		{"chinese", "中文，测试。", "中/文，/测/试。"},
+		{"kinsoku", "「日本」ですっ。", "「日/本」/で/すっ。"},
+		{"prolonged sound mark", "コーヒー", "コー/ヒー"},
+		{"mandatory", "a\nb\r\nc", "a\n|b\r\n|c"},
+		{"glue", "10 kg", "10 kg"},
+		{"hangul", "한국어 문장", "한/국/어 /문/장"},
+	}
+	for _, tt := range tests {
+		if got := segments(tt.text); got != tt.want {
+			t.Errorf("%s: breaks %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	breaks := LineBreaks([]rune("ab"))
+	if len(breaks) != 3 || breaks[0] != BreakProhibited || breaks[2] != BreakMandatory {
+		t.Errorf("wrong breaks at the text boundaries: %v", breaks)
+	}
+}
+
+func TestLookupLineClass(t *testing.T) {
+	for r, want := range map[rune]LineClass{
+		'a': LineAL, '中': LineID, '。': LineCL, '（': LineOP, 'ぁ': LineCJ, 'ー': LineCJ,
+		'々': LineNS, '5': LineNU, 'ש': LineHL, '́': LineCM, 'ก': LineSA,
+	} {
+		if got := LookupLineClass(r); got != want {
+			t.Errorf("class of %q is %d, want %d", r, got, want)
+		}
+	}
+}
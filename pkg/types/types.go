@@ -13,33 +13,158 @@ type Paragraph struct {
 	Runs       []Run
 	HasComment bool
 	CommentID  string
+
+	// NumID and ListLevel reference the list numbering (w:numPr) of the
+	// paragraph. A zero NumID means the paragraph is not part of a list.
+	NumID     int
+	ListLevel int
+	// Alignment is the paragraph justification (w:jc): left, center, right or both
+	Alignment string
+
+	// SpaceBefore and SpaceAfter are the paragraph spacing in points
+	SpaceBefore float64
+	SpaceAfter  float64
+	// LineSpacing is a multiple of single line spacing; zero means single
+	LineSpacing float64
+	// LeftIndent, RightIndent and FirstLineIndent are in points. A negative
+	// FirstLineIndent is a hanging indent.
+	LeftIndent      float64
+	RightIndent     float64
+	FirstLineIndent float64
+	// PageBreakBefore starts the paragraph on a new page
+	PageBreakBefore bool
+	// Bidi makes the paragraph right-to-left (w:bidi). Its indents and
+	// alignment then start at the right edge.
+	Bidi bool
+	// SuppressAutoHyphens excludes the paragraph from automatic
+	// hyphenation (w:suppressAutoHyphens)
+	SuppressAutoHyphens bool
+	// Bookmarks are the names of the bookmarks that start in the paragraph
+	Bookmarks []string
 }
 
 // Run represents a text run with specific formatting
 type Run struct {
-	Text     string
-	Bold     bool
-	Italic   bool
+	Text      string
+	Bold      bool
+	Italic    bool
 	Underline bool
+	Strike    bool
+	FontSize  int
+	FontName  string
+	Color     string
+
+	// Hyperlink is the target of the hyperlink that contains the run.
+	// Targets starting with "#" refer to a bookmark inside the document.
+	Hyperlink string
+	// Image is set when the run holds a picture instead of text
+	Image *Image
+	// FootnoteID is set when the run is a footnote reference mark
+	FootnoteID string
+	// CommentID is set when the run is the reference mark of a comment
+	CommentID string
+	// Break is the type of a break that follows the run text: "line",
+	// "page" or "column". Empty when the run has no break.
+	Break string
+	// Tab is set when the run contains a tab character
+	Tab bool
+	// Field is the instruction of the field whose result the run holds,
+	// such as "PAGE" or "NUMPAGES \\* roman". Text is the last computed
+	// result; a field without a result has an empty run.
+	Field string
+	// RTL marks the run as right-to-left text (w:rtl)
+	RTL bool
+	// Language is the language of the run text (w:lang), such as "de-DE";
+	// empty means the language of the document
+	Language string
+	// Ruby is the phonetic guide over the run text, which is the base
+	// text of the guide; nil for plain runs
+	Ruby *Ruby
+}
+
+// Ruby is a phonetic guide (w:ruby) such as furigana or pinyin
+type Ruby struct {
+	// Text is the annotation shown above the base text
+	Text string
+	// Alignment is the alignment of the annotation over the base text:
+	// "center", "distributeLetter", "distributeSpace", "left", "right" or
+	// "rightVertical"
+	Alignment string
+	// FontSize is the size of the annotation in half points (w:hps)
 	FontSize int
-	FontName string
-	Color    string
+	// Raise is the distance of the annotation above the base text in half
+	// points (w:hpsRaise)
+	Raise int
+	// BaseFontSize is the size of the base text in half points
+	// (w:hpsBaseText)
+	BaseFontSize int
+	// Language is the language of the guide (w:lid), such as "ja-JP"
+	Language string
 }
 
 // Table represents a table in the document
 type Table struct {
 	Rows    []TableRow
 	Columns int
+	// Position is the number of body paragraphs preceding the table,
+	// which keeps tables in place relative to the surrounding text.
+	Position int
+	// Style is the table style ID (w:tblStyle)
+	Style string
+	// ColumnWidths are the widths of the grid columns in points (w:tblGrid)
+	ColumnWidths []float64
+	// Borders are the table borders (w:tblBorders); nil when the table
+	// does not set them directly
+	Borders *TableBorders
+}
+
+// TableBorders holds the borders of a table or a cell. Inside borders
+// only apply to tables. A nil border is not set.
+type TableBorders struct {
+	Top     *TableBorder
+	Left    *TableBorder
+	Bottom  *TableBorder
+	Right   *TableBorder
+	InsideH *TableBorder
+	InsideV *TableBorder
+}
+
+// TableBorder is a single border line
+type TableBorder struct {
+	// Style is the line style such as single or double; none and nil
+	// hide the border
+	Style string
+	// Width is the line width in points
+	Width float64
+	// Color is a hex RGB color or empty for automatic
+	Color string
 }
 
 // TableRow represents a row in a table
 type TableRow struct {
 	Cells []TableCell
+	// Header marks a header row that repeats on every page
+	Header bool
 }
 
 // TableCell represents a cell in a table
 type TableCell struct {
 	Text string
+	// Paragraphs holds the formatted content of the cell. When empty,
+	// Text is used as the only paragraph.
+	Paragraphs []Paragraph
+	// ColSpan is the number of grid columns the cell spans (w:gridSpan);
+	// zero means a single column
+	ColSpan int
+	// VMerge is the vertical merge state (w:vMerge): "restart" starts a
+	// merged region and "continue" extends the region above
+	VMerge string
+	// Width is the preferred cell width in points; zero when not set
+	Width float64
+	// Shading is the hex RGB fill color of the cell (w:shd)
+	Shading string
+	// Borders override the table borders for the cell (w:tcBorders)
+	Borders *TableBorders
 }
 
 // DocumentContent represents the content of the document
@@ -47,6 +172,59 @@ type DocumentContent struct {
 	Paragraphs []Paragraph
 	Tables     []Table
 	Text       string
+	// Sections are the sections of the body in document order
+	Sections []Section
+}
+
+// Section holds the page settings of a document section (w:sectPr)
+type Section struct {
+	// End is the index of the last body paragraph of the section
+	End int
+	// Break is how the section starts (w:type): "nextPage", "continuous",
+	// "evenPage" or "oddPage". Empty means a new page.
+	Break string
+	// Headers and Footers map the kinds "default", "first" and "even" to
+	// part names such as "word/header1.xml". Missing kinds are inherited
+	// from the previous section.
+	Headers map[string]string
+	Footers map[string]string
+	// TitlePage uses the first page header and footer (w:titlePg)
+	TitlePage bool
+	// PageNumberFormat is the number format of page numbers (w:pgNumType),
+	// such as "decimal" or "lowerRoman"
+	PageNumberFormat string
+	// PageNumberStart restarts page numbering at the section; zero
+	// continues the numbering of the previous section
+	PageNumberStart int
+	// HeaderDistance and FooterDistance are the distances of the header
+	// and footer from the page edge in points; zero when not set
+	HeaderDistance float64
+	FooterDistance float64
+	// PageWidth and PageHeight are the page size in points (w:pgSz); zero
+	// when not set
+	PageWidth  float64
+	PageHeight float64
+	// Margins are the page margins in points (w:pgMar); nil when not set
+	Margins *PDFMargins
+	// Columns is the number of text columns (w:cols), zero or one for a
+	// single column, and ColumnSpace the gap between them in points
+	Columns     int
+	ColumnSpace float64
+	// TextDirection is the direction of the text flow (w:textDirection):
+	// "lrTb" for horizontal text, "tbRl" for vertical text with lines
+	// from right to left, or "btLr". Empty means horizontal.
+	TextDirection string
+}
+
+// IsVertical reports whether the text of the section runs top to bottom
+// or bottom to top
+func (s Section) IsVertical() bool {
+	switch s.TextDirection {
+	case "tbRl", "btLr", "tbRlV", "tbLrV":
+		return true
+	default:
+		return false
+	}
 }
 
 // 通用Word格式属性类型
@@ -210,21 +388,41 @@ func (sp *StyleProperties) Clone() *StyleProperties {
 
 // PDFExportConfig represents PDF export configuration
 type PDFExportConfig struct {
-	PageSize       PDFPageSize    `json:"pageSize"`
-	Orientation    PDFOrientation `json:"orientation"`
-	Margins        PDFMargins     `json:"margins"`
-	Quality        PDFQuality     `json:"quality"`
-	Compression    bool           `json:"compression"`
-	ImageQuality   int            `json:"imageQuality"`
-	IncludeImages  bool           `json:"includeImages"`
-	IncludeTables  bool           `json:"includeTables"`
-	IncludeHeaders bool           `json:"includeHeaders"`
-	IncludeFooters bool           `json:"includeFooters"`
-	FontEmbedding  bool           `json:"fontEmbedding"`
-	DefaultFont    string         `json:"defaultFont"`
-	FontSize       int            `json:"fontSize"`
-	Permissions    PDFPermissions `json:"permissions"`
-	Creator        string         `json:"creator"`
+	PageSize        PDFPageSize    `json:"pageSize"`
+	Orientation     PDFOrientation `json:"orientation"`
+	Margins         PDFMargins     `json:"margins"`
+	Quality         PDFQuality     `json:"quality"`
+	Compression     bool           `json:"compression"`
+	ImageQuality    int            `json:"imageQuality"`
+	IncludeImages   bool           `json:"includeImages"`
+	IncludeTables   bool           `json:"includeTables"`
+	IncludeHeaders  bool           `json:"includeHeaders"`
+	IncludeFooters  bool           `json:"includeFooters"`
+	FontEmbedding   bool           `json:"fontEmbedding"`
+	// FontDirectories are searched for TrueType and OpenType fonts. The
+	// system font directories are used when empty.
+	FontDirectories []string       `json:"fontDirectories,omitempty"`
+	// HyphenationDirectory holds the hyph-*.pat pattern files of TeX used
+	// when the document turns on automatic hyphenation. Words are not
+	// hyphenated when it is empty.
+	HyphenationDirectory string    `json:"hyphenationDirectory,omitempty"`
+	DefaultFont     string         `json:"defaultFont"`
+	FontSize        int            `json:"fontSize"`
+	Permissions     PDFPermissions `json:"permissions"`
+	Creator         string         `json:"creator"`
+	// Conformance selects an archival standard the output must follow.
+	// PDF/A output embeds all fonts and is never encrypted.
+	Conformance     PDFConformance `json:"conformance,omitempty"`
+	// Encryption selects the standard security handler cipher. The output
+	// is encrypted when it is set or when Permissions has a password;
+	// AES-256 is used by default.
+	Encryption      PDFEncryption  `json:"encryption,omitempty"`
+	// Tagged adds a structure tree for accessibility (PDF/UA) with the
+	// headings, paragraphs, lists, tables and figures in reading order
+	Tagged          bool           `json:"tagged,omitempty"`
+	// PageSetupFromDocument lays out every section with its own page size,
+	// margins and columns instead of PageSize, Orientation and Margins
+	PageSetupFromDocument bool     `json:"pageSetupFromDocument,omitempty"`
 }
 
 // PDFPageSize represents PDF page size
@@ -255,6 +453,23 @@ const (
 	PDFQualityHigh   PDFQuality = "high"
 )
 
+// PDFConformance represents a PDF conformance level
+type PDFConformance string
+
+const (
+	PDFConformanceNone   PDFConformance = ""
+	PDFConformancePDFA2B PDFConformance = "PDF/A-2b"
+)
+
+// PDFEncryption represents a PDF encryption algorithm
+type PDFEncryption string
+
+const (
+	PDFEncryptionNone   PDFEncryption = ""
+	PDFEncryptionAES128 PDFEncryption = "AES-128"
+	PDFEncryptionAES256 PDFEncryption = "AES-256"
+)
+
 // PDFMargins represents PDF page margins
 type PDFMargins struct {
 	Top    float64 `json:"top"`
@@ -269,6 +484,10 @@ type PDFPermissions struct {
 	AllowCopy     bool `json:"allowCopy"`
 	AllowModify   bool `json:"allowModify"`
 	AllowAnnotate bool `json:"allowAnnotate"`
+	// UserPassword is needed to open the document. OwnerPassword lifts the
+	// restrictions; a random one is used when it is empty.
+	UserPassword  string `json:"userPassword,omitempty"`
+	OwnerPassword string `json:"ownerPassword,omitempty"`
 }
 
 // PDFImageInfo represents PDF image information
@@ -281,6 +500,15 @@ type PDFImageInfo struct {
 	Position string  `json:"position"`
 }
 
+// RenderConfig represents page rasterization configuration
+type RenderConfig struct {
+	// Layout sets the page size, margins and fonts of the pages. The
+	// default PDF export configuration is used when nil.
+	Layout *PDFExportConfig `json:"layout,omitempty"`
+	// DPI is the resolution of the images, 96 when zero
+	DPI    float64          `json:"dpi,omitempty"`
+}
+
 // DocumentProtectionConfig represents document protection configuration
 type DocumentProtectionConfig struct {
 	Type           ProtectionType `json:"type"`
@@ -361,6 +589,46 @@ type Image struct {
 	Wrapping    string            `json:"wrapping,omitempty"`
 	Effects     map[string]interface{} `json:"effects,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	// Data holds the encoded image bytes when the image does not come from Path
+	Data        []byte            `json:"-"`
+	// DPI is the resolution that gives the natural size of the picture
+	// when Width and Height are not set; zero uses the resolution stored
+	// in the file, or 96
+	DPI         float64           `json:"dpi,omitempty"`
+	// Anchor places a floating picture relative to the page, margin or
+	// paragraph. Floating pictures wrap text as Wrapping says: "square",
+	// "tight", "topAndBottom", "behind" or "inFront".
+	Anchor      *ImageAnchor      `json:"anchor,omitempty"`
+	// Fallback is a PNG or JPEG picture shown by applications that cannot
+	// show SVG pictures
+	Fallback    []byte            `json:"-"`
+}
+
+// IsFloating reports whether the picture is anchored (wp:anchor) instead
+// of sitting in the text line (wp:inline)
+func (img *Image) IsFloating() bool {
+	switch img.Position {
+	case ImagePositionFloating, ImagePositionAbsolute, ImagePositionRelative:
+		return true
+	}
+	return img.Anchor != nil
+}
+
+// ImageAnchor positions a floating picture (wp:anchor)
+type ImageAnchor struct {
+	// HorizontalRelative is what X is measured from: "page", "margin",
+	// "column" or "character". Empty means the column of the paragraph.
+	HorizontalRelative string `json:"horizontalRelative,omitempty"`
+	// VerticalRelative is what Y is measured from: "page", "margin",
+	// "paragraph" or "line". Empty means the paragraph.
+	VerticalRelative string `json:"verticalRelative,omitempty"`
+	// X and Y are the offsets in points. The Alignment of the image, such
+	// as "right", replaces X when set.
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	// Distance is the gap between the picture and the text around it in
+	// points
+	Distance float64 `json:"distance,omitempty"`
 }
 
 // ImagePosition represents the position of an image
@@ -469,4 +737,4 @@ type StyleConflict struct {
 	// Conflict resolution fields
 	Status                StyleConflictStatus    `json:"status,omitempty"`
 	ResolvedStyle         *Style                 `json:"resolvedStyle,omitempty"`
-}
\ No newline at end of file
+}
\ No newline at end of file
@@ -235,6 +235,16 @@ func (b *EnhancedDocumentBuilder) AddImageToDocument(doc *Document, image types.
     images = append(images, image)
     doc.metadata["images"] = images
 
+    // 图片作为单独的段落添加到文档内容，保存时写入图片部件
+    if doc.mainPart.Content == nil {
+        doc.mainPart.Content = &types.DocumentContent{}
+    }
+    paragraph := types.Paragraph{Runs: []types.Run{{Image: &image}}}
+    if !image.IsFloating() {
+        paragraph.Alignment = image.Alignment
+    }
+    doc.mainPart.Content.Paragraphs = append(doc.mainPart.Content.Paragraphs, paragraph)
+
     b.logger.Info("图片已添加到文档，路径: %s, 宽度: %f, 高度: %f, 总图片数: %d", image.Path, image.Width, image.Height, len(images))
 
     return nil
@@ -294,6 +304,14 @@ func (b *EnhancedDocumentBuilder) ExportDocument(doc *Document, filepath string,
         return b.exportToHTML(doc, filepath)
     case "txt":
         return b.exportToTXT(doc, filepath)
+    case "md", "markdown":
+        return b.exportToMarkdown(doc, filepath)
+    case "epub":
+        return b.exportToEPUB(doc, filepath)
+    case "tex", "latex":
+        return b.exportToLaTeX(doc, filepath)
+    case "xlf", "xliff":
+        return b.exportToXLIFF(doc, filepath)
     default:
         return fmt.Errorf("不支持的导出格式: %s", format)
     }
@@ -315,6 +333,58 @@ func (b *EnhancedDocumentBuilder) exportToPDF(doc *Document, filepath string) er
     return nil
 }
 
+// exportToMarkdown 导出为Markdown
+func (b *EnhancedDocumentBuilder) exportToMarkdown(doc *Document, filepath string) error {
+    markdownExporter := NewMarkdownExporter(doc, nil)
+
+    if err := markdownExporter.ExportToMarkdown(filepath); err != nil {
+        return fmt.Errorf("Markdown导出失败: %w", err)
+    }
+
+    b.logger.Info("文档已导出为Markdown，文件路径: %s", filepath)
+
+    return nil
+}
+
+// exportToEPUB 导出为EPUB
+func (b *EnhancedDocumentBuilder) exportToEPUB(doc *Document, filepath string) error {
+    epubExporter := NewEPUBExporter(doc, nil)
+
+    if err := epubExporter.ExportToEPUB(filepath); err != nil {
+        return fmt.Errorf("EPUB导出失败: %w", err)
+    }
+
+    b.logger.Info("文档已导出为EPUB，文件路径: %s", filepath)
+
+    return nil
+}
+
+// exportToLaTeX 导出为LaTeX
+func (b *EnhancedDocumentBuilder) exportToLaTeX(doc *Document, filepath string) error {
+    latexExporter := NewLaTeXExporter(doc, nil)
+
+    if err := latexExporter.ExportToLaTeX(filepath); err != nil {
+        return fmt.Errorf("LaTeX导出失败: %w", err)
+    }
+
+    b.logger.Info("文档已导出为LaTeX，文件路径: %s", filepath)
+
+    return nil
+}
+
+// exportToXLIFF 导出为XLIFF
+func (b *EnhancedDocumentBuilder) exportToXLIFF(doc *Document, filepath string) error {
+    xliffExporter := NewXLIFFExporter(doc, nil)
+
+    if err := xliffExporter.ExportToXLIFF(filepath); err != nil {
+        return fmt.Errorf("XLIFF导出失败: %w", err)
+    }
+
+    b.logger.Info("文档已导出为XLIFF，文件路径: %s", filepath)
+
+    return nil
+}
+
 // exportToRTF 导出为RTF
 func (b *EnhancedDocumentBuilder) exportToRTF(doc *Document, filepath string) error {
 	b.logger.Info("开始导出RTF文件，文件路径: %s", filepath)
@@ -335,81 +405,15 @@ func (b *EnhancedDocumentBuilder) exportToRTF(doc *Document, filepath string) er
 func (b *EnhancedDocumentBuilder) exportToHTML(doc *Document, filepath string) error {
 	b.logger.Info("开始导出HTML文件，文件路径: %s", filepath)
 
-	// 获取文档内容
-	paragraphs, err := doc.GetParagraphs()
-	if err != nil {
-		return fmt.Errorf("获取文档段落失败: %w", err)
-	}
-
-	tables, err := doc.GetTables()
-	if err != nil {
-		// 如果获取表格失败，继续处理但不包含表格
-		b.logger.Info("获取文档表格失败，跳过表格: %v", err)
-		tables = []types.Table{}
-	}
-
-	// 构建HTML内容
-	htmlContent := b.buildHTMLContent(paragraphs, tables)
-
-	// 保存HTML文件
-	if err := os.WriteFile(filepath, []byte(htmlContent), 0644); err != nil {
-		return fmt.Errorf("保存HTML文件失败: %w", err)
+	htmlExporter := NewHTMLExporter(doc, nil)
+	if err := htmlExporter.ExportToHTML(filepath); err != nil {
+		return fmt.Errorf("HTML导出失败: %w", err)
 	}
 
-	b.logger.Info("HTML文件导出成功，文件路径: %s, 内容长度: %d", filepath, len(htmlContent))
+	b.logger.Info("HTML文件导出成功，文件路径: %s", filepath)
 	return nil
 }
 
-// buildHTMLContent 构建HTML内容
-func (b *EnhancedDocumentBuilder) buildHTMLContent(paragraphs []types.Paragraph, tables []types.Table) string {
-	html := `<!DOCTYPE html>
-<html>
-<head>
-	<meta charset="UTF-8">
-	<title>Document</title>
-	<style>
-		body { font-family: Arial, sans-serif; margin: 20px; }
-		p { margin: 10px 0; }
-		table { border-collapse: collapse; width: 100%; margin: 10px 0; }
-		th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
-		th { background-color: #f2f2f2; }
-	</style>
-</head>
-<body>
-`
-
-	// 添加段落
-	for _, paragraph := range paragraphs {
-		html += fmt.Sprintf("\t<p>%s</p>\n", paragraph.Text)
-	}
-
-	// 添加表格
-	for _, table := range tables {
-		html += "\t<table>\n"
-		for i, row := range table.Rows {
-			if i == 0 {
-				// 第一行作为表头
-				html += "\t\t<tr>\n"
-				for _, cell := range row.Cells {
-					html += fmt.Sprintf("\t\t\t<th>%s</th>\n", cell.Text)
-				}
-				html += "\t\t</tr>\n"
-			} else {
-				// 其他行作为数据行
-				html += "\t\t<tr>\n"
-				for _, cell := range row.Cells {
-					html += fmt.Sprintf("\t\t\t<td>%s</td>\n", cell.Text)
-				}
-				html += "\t\t</tr>\n"
-			}
-		}
-		html += "\t</table>\n"
-	}
-
-	html += "</body>\n</html>"
-	return html
-}
-
 // exportToTXT 导出为TXT
 func (b *EnhancedDocumentBuilder) exportToTXT(doc *Document, filepath string) error {
     // 获取文档文本内容
@@ -436,6 +440,32 @@ func (b *EnhancedDocumentBuilder) GetDocumentStatistics(doc *Document) (*types.D
     now := time.Now()
     stats.CreationDate = &now
 
+    if doc == nil || doc.mainPart == nil || doc.mainPart.Content == nil {
+        return stats, nil
+    }
+    content := doc.mainPart.Content
+    stats.TotalParagraphs = len(content.Paragraphs)
+    stats.TotalTables = len(content.Tables)
+    stats.TotalWords = b.countWords(content.Paragraphs)
+    stats.TotalCharacters = b.countCharacters(content.Paragraphs)
+    stats.TotalSections = len(content.Sections)
+    for _, paragraph := range content.Paragraphs {
+        for _, run := range paragraph.Runs {
+            if run.Image != nil {
+                stats.TotalImages++
+            }
+        }
+    }
+
+    // 页数来自排版树
+    layout := NewLayoutManager()
+    layout.SetLogger(b.logger)
+    tree, err := layout.LayoutDocument(context.Background(), doc)
+    if err != nil {
+        return nil, fmt.Errorf("排版文档失败: %w", err)
+    }
+    stats.TotalPages = tree.TotalPages()
+
     return stats, nil
 }
 
@@ -4,10 +4,10 @@ package word
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
-
-	"github.com/tanqiangyes/go-word/pkg/types"
 )
 
 // BatchProcessor represents a batch document processor
@@ -259,7 +259,7 @@ func (bp *BatchProcessor) convertDocumentFormat(docID string, doc *Document, ope
 	case "txt":
 		return bp.convertToText(docID, doc)
 	case "html":
-		return bp.convertToHTML(docID, doc)
+		return bp.convertToHTML(docID, doc, operation)
 	case "pdf":
 		return bp.convertToPDF(docID, doc)
 	default:
@@ -280,36 +280,25 @@ func (bp *BatchProcessor) convertToText(docID string, doc *Document) error {
 	return nil
 }
 
-// convertToHTML converts document to HTML format
-func (bp *BatchProcessor) convertToHTML(docID string, doc *Document) error {
-	// 获取文档内容
-	paragraphs, err := doc.GetParagraphs()
-	if err != nil {
-		return fmt.Errorf("获取文档段落失败: %w", err)
+// convertToHTML converts document to HTML format. The HTML is written to
+// <docID>.html in the "output_dir" parameter of the operation.
+func (bp *BatchProcessor) convertToHTML(docID string, doc *Document, operation BatchOperation) error {
+	outputDir, ok := operation.Parameters["output_dir"].(string)
+	if !ok || outputDir == "" {
+		return fmt.Errorf("output directory not specified")
 	}
-
-	tables, err := doc.GetTables()
-	if err != nil {
-		// 如果获取表格失败，继续处理但不包含表格
-		tables = []types.Table{}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
 	}
 
-	// 构建HTML内容
-	htmlContent := bp.buildHTMLContent(paragraphs, tables)
-
-	// 这里可以保存HTML文件或进行其他处理
-	_ = htmlContent
-
+	if err := NewHTMLExporter(doc, nil).ExportToHTML(filepath.Join(outputDir, docID+".html")); err != nil {
+		return fmt.Errorf("转换 %s 为HTML失败: %w", docID, err)
+	}
 	return nil
 }
 
 // convertToPDF converts document to PDF format
 func (bp *BatchProcessor) convertToPDF(docID string, doc *Document) error {
-	// 先转换为HTML，然后基于HTML生成PDF
-	if err := bp.convertToHTML(docID, doc); err != nil {
-		return fmt.Errorf("转换为HTML失败: %w", err)
-	}
-
 	// 获取文档文本内容用于PDF生成
 	text, err := doc.GetText()
 	if err != nil {
@@ -325,56 +314,6 @@ func (bp *BatchProcessor) convertToPDF(docID string, doc *Document) error {
 	return nil
 }
 
-// buildHTMLContent 构建HTML内容
-func (bp *BatchProcessor) buildHTMLContent(paragraphs []types.Paragraph, tables []types.Table) string {
-	html := `<!DOCTYPE html>
-<html>
-<head>
-	<meta charset="UTF-8">
-	<title>Document</title>
-	<style>
-		body { font-family: Arial, sans-serif; margin: 20px; }
-		p { margin: 10px 0; }
-		table { border-collapse: collapse; width: 100%; margin: 10px 0; }
-		th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
-		th { background-color: #f2f2f2; }
-	</style>
-</head>
-<body>
-`
-
-	// 添加段落
-	for _, paragraph := range paragraphs {
-		html += fmt.Sprintf("\t<p>%s</p>\n", paragraph.Text)
-	}
-
-	// 添加表格
-	for _, table := range tables {
-		html += "\t<table>\n"
-		for i, row := range table.Rows {
-			if i == 0 {
-				// 第一行作为表头
-				html += "\t\t<tr>\n"
-				for _, cell := range row.Cells {
-					html += fmt.Sprintf("\t\t\t<th>%s</th>\n", cell.Text)
-				}
-				html += "\t\t</tr>\n"
-			} else {
-				// 其他行作为数据行
-				html += "\t\t<tr>\n"
-				for _, cell := range row.Cells {
-					html += fmt.Sprintf("\t\t\t<td>%s</td>\n", cell.Text)
-				}
-				html += "\t\t</tr>\n"
-			}
-		}
-		html += "\t</table>\n"
-	}
-
-	html += "</body>\n</html>"
-	return html
-}
-
 // generateBasicPDF 生成基本的PDF内容
 func (bp *BatchProcessor) generateBasicPDF(text string) string {
 	// 这是一个非常简化的PDF格式，实际应用中应该使用专业的PDF库
@@ -25,6 +25,7 @@ package word
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/tanqiangyes/go-word/pkg/opc"
@@ -226,6 +227,70 @@ func (d *Document) GetText() (string, error) {
 	return text.String(), nil
 }
 
+// RubyTextMode selects how phonetic guides (ruby) appear in extracted text.
+type RubyTextMode int
+
+const (
+	// RubyBaseOnly keeps only the base text, such as "漢字"
+	RubyBaseOnly RubyTextMode = iota
+	// RubyWithAnnotation follows the base text with the guide in
+	// parentheses, such as "漢字(かんじ)"
+	RubyWithAnnotation
+)
+
+// GetTextWithRuby returns the plain text content of the document like
+// GetText, with the phonetic guides of Japanese and Chinese text in the
+// given mode.
+//
+// Parameters:
+//   - mode: RubyBaseOnly or RubyWithAnnotation
+//
+// Returns:
+//   - string: The plain text content of the document
+//   - error: An error if the text cannot be extracted
+//
+// Example:
+//
+//	text, err := doc.GetTextWithRuby(word.RubyWithAnnotation)
+//	if err != nil {
+//		log.Fatal("Failed to get text:", err)
+//	}
+//	fmt.Println(text) // 漢字(かんじ)を読む
+func (d *Document) GetTextWithRuby(mode RubyTextMode) (string, error) {
+	if d.mainPart == nil || d.mainPart.Content == nil {
+		return "", fmt.Errorf("document content not loaded")
+	}
+
+	var text strings.Builder
+	for _, paragraph := range d.mainPart.Content.Paragraphs {
+		text.WriteString(rubyParagraphText(paragraph, mode))
+		text.WriteString("\n")
+	}
+
+	return text.String(), nil
+}
+
+// rubyParagraphText returns the text of a paragraph with its phonetic
+// guides in the given mode
+func rubyParagraphText(paragraph types.Paragraph, mode RubyTextMode) string {
+	hasRuby := false
+	for _, run := range paragraph.Runs {
+		hasRuby = hasRuby || run.Ruby != nil
+	}
+	if !hasRuby || mode == RubyBaseOnly {
+		return paragraph.Text
+	}
+
+	var text strings.Builder
+	for _, run := range paragraph.Runs {
+		text.WriteString(run.Text)
+		if run.Ruby != nil && run.Ruby.Text != "" {
+			text.WriteString("(" + run.Ruby.Text + ")")
+		}
+	}
+	return text.String()
+}
+
 // GetParagraphs returns all paragraphs in the document.
 // Each paragraph contains text runs with formatting information.
 //
@@ -297,6 +362,26 @@ func (d *Document) GetDocumentParts() *DocumentParts {
 	return d.documentParts
 }
 
+// GetCoreProperties returns the core properties of the document.
+// Properties set through the builders take precedence; otherwise they are
+// read from docProps/core.xml on first use.
+//
+// Returns:
+//   - *types.CoreProperties: The core properties, never nil
+func (d *Document) GetCoreProperties() *types.CoreProperties {
+	if d.coreProperties != nil {
+		return d.coreProperties
+	}
+
+	d.coreProperties = &types.CoreProperties{}
+	if data := d.readPart("docProps/core.xml"); data != nil {
+		if properties, err := parser.ParseCoreProperties(data); err == nil {
+			d.coreProperties = properties
+		}
+	}
+	return d.coreProperties
+}
+
 // GetPartsSummary returns a summary of all document parts.
 // This is useful for debugging and understanding the document structure.
 //
@@ -340,8 +425,8 @@ func (d *Document) loadMainDocumentPart() error {
 		return fmt.Errorf("failed to get main document part: %w", err)
 	}
 
-	// Parse the document content
-	content, err := parseDocumentContent(part.Content)
+	// Parse the document content, resolving hyperlink and image targets
+	content, err := parser.ParseWordMLWithRelationships(part.Content, d.documentRelationships())
 	if err != nil {
 		return fmt.Errorf("failed to parse document content: %w", err)
 	}
@@ -354,6 +439,41 @@ func (d *Document) loadMainDocumentPart() error {
 	return nil
 }
 
+// documentRelationships returns the relationships of the main document part
+// keyed by ID. Internal targets are resolved to part names such as
+// "word/media/image1.png"; external targets are returned unchanged.
+func (d *Document) documentRelationships() map[string]string {
+	return d.partRelationships("word/document.xml")
+}
+
+// partRelationships returns the relationships of a part keyed by ID, with
+// internal targets resolved relative to the folder of the part
+func (d *Document) partRelationships(name string) map[string]string {
+	relationships := make(map[string]string)
+	if d.container == nil || d.container.Reader == nil {
+		return relationships
+	}
+
+	rels, err := d.container.GetRelationships(name)
+	if err != nil {
+		return relationships
+	}
+
+	for _, rel := range rels {
+		target := rel.Target
+		if !strings.HasSuffix(rel.Type, "/hyperlink") && !strings.Contains(target, "://") {
+			if strings.HasPrefix(target, "/") {
+				target = strings.TrimPrefix(target, "/")
+			} else {
+				target = path.Join(path.Dir(name), target)
+			}
+		}
+		relationships[rel.ID] = target
+	}
+
+	return relationships
+}
+
 // parseDocumentContent parses the XML content of the main document part.
 // This function converts the word XML into structured data
 // that can be easily accessed by the Document methods.
@@ -394,3 +514,26 @@ func (d *Document) GetMainPart() *MainDocumentPart {
 func (d *Document) SetMainPart(mainPart *MainDocumentPart) {
 	d.mainPart = mainPart
 }
+
+// ReloadContent parses data as the XML of the main document part and
+// replaces the content of the main part with it. Relationship IDs are
+// resolved with the relationships of the package; the package itself is
+// not changed. Editors that patch the document XML use it to keep the
+// content model in step with their changes.
+//
+// Parameters:
+//   - data: The XML of word/document.xml
+//
+// Returns:
+//   - error: An error if the XML cannot be parsed
+func (d *Document) ReloadContent(data []byte) error {
+	content, err := parser.ParseWordMLWithRelationships(data, d.documentRelationships())
+	if err != nil {
+		return fmt.Errorf("failed to parse document content: %w", err)
+	}
+	if d.mainPart == nil {
+		d.mainPart = &MainDocumentPart{}
+	}
+	d.mainPart.Content = content
+	return nil
+}
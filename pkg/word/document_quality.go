@@ -763,7 +763,9 @@ func (dqm *DocumentQualityManager) GenerateAltText(content *types.DocumentConten
 	}
 }
 
-// AddStructureTags adds structure tags for accessibility
+// AddStructureTags adds structure tags for accessibility. Paragraphs
+// marked as "Heading" become H1 elements when the document is exported as
+// a tagged PDF.
 func (dqm *DocumentQualityManager) AddStructureTags(content *types.DocumentContent) {
 	// 为段落添加结构标签
 	for i := range content.Paragraphs {
@@ -108,26 +108,37 @@ func (ds *DocumentStructure) generateOutline() error {
 		Level:    0,
 	}
 
-	// 构建层次结构
-	sectionMap := make(map[int]*DocumentSection)
-
+	// 构建层次结构：每个段落的父段落是前面最近的级别更高的段落。
+	// 子段落按值保存，所以递归组装完整的子树。
+	parents := make([]int, len(ds.Sections))
 	for i := range ds.Sections {
-		section := &ds.Sections[i]
-		sectionMap[i] = section
-
-		// 查找父段落
+		parents[i] = -1
 		for j := i - 1; j >= 0; j-- {
-			parent := sectionMap[j]
-			if parent.Level < section.Level {
-				parent.SubSections = append(parent.SubSections, *section)
+			if ds.Sections[j].Level < ds.Sections[i].Level {
+				parents[i] = j
 				break
 			}
 		}
 	}
 
+	var build func(i int) DocumentSection
+	build = func(i int) DocumentSection {
+		section := ds.Sections[i]
+		section.SubSections = nil
+		for j := i + 1; j < len(ds.Sections); j++ {
+			if parents[j] == i {
+				section.SubSections = append(section.SubSections, build(j))
+			}
+		}
+		return section
+	}
+	for i := range ds.Sections {
+		ds.Sections[i].SubSections = build(i).SubSections
+	}
+
 	// 添加顶级段落到大纲
-	for _, section := range ds.Sections {
-		if section.Level == 1 {
+	for i, section := range ds.Sections {
+		if parents[i] == -1 {
 			ds.Outline.Sections = append(ds.Outline.Sections, section)
 		}
 	}
@@ -0,0 +1,442 @@
+package word
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+// EPUBExporter exports a document as an EPUB 3 publication. The document
+// is split into chapters at Heading 1 paragraphs; the navigation document
+// is built from the document outline.
+type EPUBExporter struct {
+	Document *Document
+	Config   *EPUBExportConfig
+	Logger   *utils.Logger
+}
+
+// EPUBExportConfig EPUB导出配置
+type EPUBExportConfig struct {
+	// Title, Author and Language override the core properties
+	Title    string `json:"title"`
+	Author   string `json:"author"`
+	Language string `json:"language"`
+	// Identifier is the unique publication identifier; a UUID derived from
+	// the content is used when empty
+	Identifier string `json:"identifier"`
+	// TOCDepth is the deepest heading level listed in the navigation
+	TOCDepth        int      `json:"toc_depth"`
+	IncludeComments bool     `json:"include_comments"`
+	CodeFonts       []string `json:"code_fonts"`
+}
+
+// epubChapter is a content document of the publication
+type epubChapter struct {
+	file   string
+	title  string
+	blocks []bodyBlock
+}
+
+// epubNavPoint is an entry of the navigation document
+type epubNavPoint struct {
+	level int
+	title string
+	href  string
+}
+
+// epubMetadata is the publication metadata written to the package document
+type epubMetadata struct {
+	identifier  string
+	title       string
+	creator     string
+	language    string
+	subject     []string
+	description string
+	created     *time.Time
+	modified    time.Time
+}
+
+// epubBaseStylesheet is emitted before the rules generated from styles
+const epubBaseStylesheet = `body { font-family: serif; line-height: 1.4; margin: 0 0.5em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #bfbfbf; padding: 4px 8px; vertical-align: top; }
+th { background-color: #f2f2f2; }
+td > p, th > p { margin: 0; }
+img { max-width: 100%; height: auto; }
+blockquote { margin: 1em 2em; padding-left: 1em; border-left: 3px solid #ccc; }
+aside.comment { margin: 0.5em 0 0.5em 2em; padding: 0.5em 1em; border-left: 3px solid #f0ad4e; font-size: 0.9em; }
+aside.comment .comment-meta { margin: 0 0 0.25em; font-weight: bold; }
+section.footnotes { margin-top: 2em; font-size: 0.9em; }
+nav#toc ol { list-style-type: none; }
+`
+
+// NewEPUBExporter 创建EPUB导出器
+func NewEPUBExporter(document *Document, config *EPUBExportConfig) *EPUBExporter {
+	if config == nil {
+		config = getDefaultEPUBConfig()
+	}
+
+	return &EPUBExporter{
+		Document: document,
+		Config:   config,
+		Logger:   utils.NewLogger(utils.LogLevelInfo, os.Stdout),
+	}
+}
+
+// getDefaultEPUBConfig 获取默认EPUB配置
+func getDefaultEPUBConfig() *EPUBExportConfig {
+	return &EPUBExportConfig{
+		TOCDepth:  3,
+		CodeFonts: getDefaultHTMLConfig().CodeFonts,
+	}
+}
+
+// ExportToEPUB writes the publication to outputPath
+func (ee *EPUBExporter) ExportToEPUB(outputPath string) error {
+	var buf bytes.Buffer
+	if err := ee.WriteEPUB(&buf); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("保存EPUB文件失败: %w", err)
+	}
+
+	ee.Logger.Info("EPUB导出完成: %s", outputPath)
+	return nil
+}
+
+// WriteEPUB writes the publication as a zip container. The mimetype entry
+// is stored uncompressed as the first entry, as required by OCF.
+func (ee *EPUBExporter) WriteEPUB(w io.Writer) error {
+	if ee.Document == nil || ee.Document.mainPart == nil || ee.Document.mainPart.Content == nil {
+		return fmt.Errorf("document content not loaded")
+	}
+
+	renderer := NewHTMLExporter(ee.Document, &HTMLExportConfig{
+		ImageMode:       HTMLImageExtract,
+		MediaDir:        "images",
+		IncludeComments: ee.Config.IncludeComments,
+		CodeFonts:       ee.Config.CodeFonts,
+	})
+	renderer.Logger = ee.Logger
+	renderer.xhtml = true
+	renderer.prepare("")
+
+	chapters, navPoints, err := ee.splitChapters(renderer)
+	if err != nil {
+		return err
+	}
+	metadata := ee.metadata(chapters)
+
+	contents := make([]string, len(chapters))
+	for i, chapter := range chapters {
+		// 脚注按章节编号
+		renderer.usedNotes = nil
+		contents[i] = ee.contentDocument(chapter.title, metadata.language, renderer.renderBlocks(chapter.blocks))
+	}
+
+	archive := zip.NewWriter(w)
+
+	mimetype := []byte("application/epub+zip")
+	header := &zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(mimetype),
+		CompressedSize64:   uint64(len(mimetype)),
+		UncompressedSize64: uint64(len(mimetype)),
+	}
+	entry, err := archive.CreateRaw(header)
+	if err != nil {
+		return fmt.Errorf("无法写入mimetype: %w", err)
+	}
+	if _, err := entry.Write(mimetype); err != nil {
+		return fmt.Errorf("无法写入mimetype: %w", err)
+	}
+
+	files := []exportedFile{
+		{name: "META-INF/container.xml", data: []byte(epubContainerXML)},
+		{name: "OEBPS/content.opf", data: []byte(ee.packageDocument(metadata, chapters, renderer.media.files))},
+		{name: "OEBPS/nav.xhtml", data: []byte(ee.navigationDocument(metadata, navPoints))},
+		{name: "OEBPS/styles.css", data: []byte(epubBaseStylesheet + renderer.styleRules())},
+	}
+	for i, chapter := range chapters {
+		files = append(files, exportedFile{name: "OEBPS/" + chapter.file, data: []byte(contents[i])})
+	}
+	for _, image := range renderer.media.files {
+		files = append(files, exportedFile{name: "OEBPS/images/" + image.name, data: image.data})
+	}
+
+	for _, file := range files {
+		entry, err := archive.Create(file.name)
+		if err != nil {
+			return fmt.Errorf("无法创建 %s: %w", file.name, err)
+		}
+		if _, err := entry.Write(file.data); err != nil {
+			return fmt.Errorf("无法写入 %s: %w", file.name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("无法完成EPUB文件: %w", err)
+	}
+	return nil
+}
+
+// splitChapters splits the body at Heading 1 sections of the document
+// outline and collects the navigation entries. Headings listed in the
+// navigation get an anchor in their content document.
+func (ee *EPUBExporter) splitChapters(renderer *HTMLExporter) ([]epubChapter, []epubNavPoint, error) {
+	structure, err := ee.Document.ReorganizeDocument()
+	if err != nil {
+		return nil, nil, fmt.Errorf("无法分析文档结构: %w", err)
+	}
+
+	paragraphs := ee.Document.mainPart.Content.Paragraphs
+	index := make(map[*types.Paragraph]int, len(paragraphs))
+	for i := range paragraphs {
+		index[&paragraphs[i]] = i
+	}
+
+	depth := ee.Config.TOCDepth
+	if depth <= 0 {
+		depth = 3
+	}
+
+	titles := make(map[int]string)
+	for _, section := range structure.GetSections() {
+		titles[section.StartIndex] = section.Title
+	}
+
+	// 大纲把粗体段落也当作标题，而本地化的标题样式ID（如"1"）又不在大纲中，
+	// 因此级别按样式名称判断
+	levels := make(map[int]int)
+	for i := range paragraphs {
+		if level := headingLevel(paragraphs[i].Style, renderer.styleNames); level > 0 {
+			levels[i] = level
+			if _, ok := titles[i]; !ok {
+				titles[i] = extractHeadingTitle(paragraphs[i])
+			}
+		}
+	}
+
+	var chapters []epubChapter
+	var navPoints []epubNavPoint
+	renderer.headingIDs = make(map[*types.Paragraph]string)
+
+	for _, block := range collectBody(ee.Document.mainPart.Content) {
+		level := 0
+		i := -1
+		if block.paragraph != nil {
+			i = index[block.paragraph]
+			level = levels[i]
+		}
+
+		if len(chapters) == 0 || (level == 1 && len(chapters[len(chapters)-1].blocks) > 0) {
+			chapters = append(chapters, epubChapter{file: fmt.Sprintf("chapter%03d.xhtml", len(chapters)+1)})
+		}
+		chapter := &chapters[len(chapters)-1]
+		chapter.blocks = append(chapter.blocks, block)
+
+		if level == 0 || level > depth {
+			continue
+		}
+		title := titles[i]
+		if title == "" {
+			title = fmt.Sprintf("Section %d", len(navPoints)+1)
+		}
+		if level == 1 && chapter.title == "" {
+			chapter.title = title
+		}
+
+		id := fmt.Sprintf("h%d", i+1)
+		renderer.headingIDs[block.paragraph] = id
+		navPoints = append(navPoints, epubNavPoint{level: level, title: title, href: chapter.file + "#" + id})
+	}
+
+	if len(chapters) == 0 {
+		chapters = append(chapters, epubChapter{file: "chapter001.xhtml"})
+	}
+
+	// 第一个标题之前的内容作为独立章节
+	if first := chapters[0]; first.title == "" {
+		navPoints = append([]epubNavPoint{{level: 1, href: first.file}}, navPoints...)
+	}
+
+	return chapters, navPoints, nil
+}
+
+// metadata collects the publication metadata from the configuration and
+// the core properties
+func (ee *EPUBExporter) metadata(chapters []epubChapter) epubMetadata {
+	core := ee.Document.GetCoreProperties()
+
+	metadata := epubMetadata{
+		identifier:  ee.Config.Identifier,
+		title:       firstNonEmpty(ee.Config.Title, core.Title),
+		creator:     firstNonEmpty(ee.Config.Author, core.Creator),
+		language:    firstNonEmpty(ee.Config.Language, core.Language, "en"),
+		subject:     core.Keywords,
+		description: firstNonEmpty(core.Description, core.Subject),
+		created:     core.Created,
+		modified:    time.Now().UTC(),
+	}
+	if core.Modified != nil {
+		metadata.modified = core.Modified.UTC()
+	}
+
+	if metadata.title == "" {
+		for _, chapter := range chapters {
+			if chapter.title != "" {
+				metadata.title = chapter.title
+				break
+			}
+		}
+	}
+	if metadata.title == "" {
+		metadata.title = "Document"
+	}
+
+	for i := range chapters {
+		if chapters[i].title == "" {
+			chapters[i].title = metadata.title
+		}
+	}
+
+	if metadata.identifier == "" {
+		// 根据内容生成稳定的UUID，重复导出时保持不变
+		text, _ := ee.Document.GetText()
+		sum := sha1.Sum([]byte(metadata.title + "\x00" + metadata.creator + "\x00" + text))
+		sum[6] = sum[6]&0x0f | 0x50
+		sum[8] = sum[8]&0x3f | 0x80
+		metadata.identifier = fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+	}
+
+	return metadata
+}
+
+// firstNonEmpty returns the first value that is not blank
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// epubContainerXML points reading systems to the package document
+const epubContainerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// packageDocument builds the OPF package document with metadata, manifest
+// and spine
+func (ee *EPUBExporter) packageDocument(metadata epubMetadata, chapters []epubChapter, images []exportedFile) string {
+	var buf strings.Builder
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="pub-id" xml:lang="` + html.EscapeString(metadata.language) + `">` + "\n")
+
+	buf.WriteString(`  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	buf.WriteString(`    <dc:identifier id="pub-id">` + html.EscapeString(metadata.identifier) + "</dc:identifier>\n")
+	buf.WriteString("    <dc:title>" + html.EscapeString(metadata.title) + "</dc:title>\n")
+	buf.WriteString("    <dc:language>" + html.EscapeString(metadata.language) + "</dc:language>\n")
+	if metadata.creator != "" {
+		buf.WriteString("    <dc:creator>" + html.EscapeString(metadata.creator) + "</dc:creator>\n")
+	}
+	for _, subject := range metadata.subject {
+		buf.WriteString("    <dc:subject>" + html.EscapeString(subject) + "</dc:subject>\n")
+	}
+	if metadata.description != "" {
+		buf.WriteString("    <dc:description>" + html.EscapeString(metadata.description) + "</dc:description>\n")
+	}
+	if metadata.created != nil {
+		buf.WriteString("    <dc:date>" + metadata.created.UTC().Format("2006-01-02T15:04:05Z") + "</dc:date>\n")
+	}
+	buf.WriteString(`    <meta property="dcterms:modified">` + metadata.modified.Format("2006-01-02T15:04:05Z") + "</meta>\n")
+	buf.WriteString("  </metadata>\n")
+
+	buf.WriteString("  <manifest>\n")
+	buf.WriteString(`    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>` + "\n")
+	buf.WriteString(`    <item id="css" href="styles.css" media-type="text/css"/>` + "\n")
+	for i, chapter := range chapters {
+		buf.WriteString(fmt.Sprintf(`    <item id="chapter%d" href="%s" media-type="application/xhtml+xml"/>`+"\n", i+1, chapter.file))
+	}
+	for i, image := range images {
+		buf.WriteString(fmt.Sprintf(`    <item id="image%d" href="images/%s" media-type="%s"/>`+"\n",
+			i+1, html.EscapeString(image.name), imageContentType(image.name, image.data)))
+	}
+	buf.WriteString("  </manifest>\n")
+
+	buf.WriteString("  <spine>\n")
+	for i := range chapters {
+		buf.WriteString(fmt.Sprintf(`    <itemref idref="chapter%d"/>`+"\n", i+1))
+	}
+	buf.WriteString("  </spine>\n")
+	buf.WriteString("</package>\n")
+	return buf.String()
+}
+
+// navigationDocument builds nav.xhtml with a nested table of contents
+func (ee *EPUBExporter) navigationDocument(metadata epubMetadata, navPoints []epubNavPoint) string {
+	var body strings.Builder
+	body.WriteString(`<nav epub:type="toc" id="toc">` + "\n")
+	body.WriteString("<h1>" + html.EscapeString(metadata.title) + "</h1>\n")
+
+	// 标题级别跳跃时只嵌套一层
+	depth := 0
+	for _, point := range navPoints {
+		title := point.title
+		if title == "" {
+			title = metadata.title
+		}
+
+		target := min(max(point.level, 1), depth+1)
+		if target > depth {
+			body.WriteString("<ol>\n")
+			depth++
+		} else {
+			body.WriteString("</li>\n")
+			for depth > target {
+				body.WriteString("</ol>\n</li>\n")
+				depth--
+			}
+		}
+		body.WriteString(`<li><a href="` + html.EscapeString(point.href) + `">` + html.EscapeString(title) + "</a>")
+	}
+	for ; depth > 0; depth-- {
+		body.WriteString("</li>\n</ol>\n")
+	}
+	body.WriteString("</nav>\n")
+
+	return ee.contentDocument(metadata.title, metadata.language, body.String())
+}
+
+// contentDocument wraps body content in an XHTML content document
+func (ee *EPUBExporter) contentDocument(title, language, body string) string {
+	var buf strings.Builder
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n<!DOCTYPE html>\n")
+	lang := html.EscapeString(language)
+	buf.WriteString(`<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="` + lang + `" xml:lang="` + lang + `">` + "\n")
+	buf.WriteString("<head>\n<meta charset=\"UTF-8\"/>\n")
+	buf.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	buf.WriteString(`<link rel="stylesheet" type="text/css" href="styles.css"/>` + "\n")
+	buf.WriteString("</head>\n<body>\n")
+	buf.WriteString(body)
+	buf.WriteString("</body>\n</html>\n")
+	return buf.String()
+}
@@ -0,0 +1,169 @@
+package word
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+const epubTestDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+  xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+  xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"
+  xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">
+  <w:body>
+    <w:p><w:r><w:t>Preface</w:t></w:r></w:p>
+    <w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Getting Started</w:t></w:r></w:p>
+    <w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Bold is not a chapter</w:t></w:r><w:r><w:t xml:space="preserve"> &amp; more</w:t></w:r></w:p>
+    <w:p/>
+    <w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Install</w:t></w:r></w:p>
+    <w:p><w:r><w:drawing><wp:inline><wp:extent cx="952500" cy="476250"/>
+      <wp:docPr id="1" name="Picture 1" descr="Logo"/>
+      <a:graphic><a:graphicData><pic:pic><pic:blipFill><a:blip r:embed="rId11"/></pic:blipFill></pic:pic></a:graphicData></a:graphic>
+    </wp:inline></w:drawing></w:r></w:p>
+    <w:p><w:pPr><w:pStyle w:val="1"/></w:pPr><w:r><w:t>Reference</w:t></w:r></w:p>
+    <w:p><w:r><w:t>See note</w:t></w:r><w:r><w:footnoteReference w:id="1"/></w:r></w:p>
+  </w:body>
+</w:document>`
+
+const epubTestStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:style w:type="paragraph" w:styleId="1"><w:name w:val="heading 1"/><w:rPr><w:sz w:val="32"/></w:rPr></w:style>
+</w:styles>`
+
+const epubTestCore = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties"
+  xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/"
+  xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <dc:title>User Manual</dc:title>
+  <dc:creator>Docs Team</dc:creator>
+  <dc:language>en-US</dc:language>
+  <cp:keywords>manual, guide</cp:keywords>
+  <dcterms:modified xsi:type="dcterms:W3CDTF">2024-03-04T05:06:07Z</dcterms:modified>
+</cp:coreProperties>`
+
+func TestEPUBExporterWriteEPUB(t *testing.T) {
+	doc, err := Open(writeTestPackage(t, map[string]string{
+		"word/document.xml":            epubTestDocument,
+		"word/_rels/document.xml.rels": markdownTestRelationships,
+		"word/footnotes.xml":           markdownTestFootnotes,
+		"word/styles.xml":              epubTestStyles,
+		"word/media/image1.png":        "\x89PNG\r\n\x1a\nfake",
+		"docProps/core.xml":            epubTestCore,
+	}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	var buf bytes.Buffer
+	if err := NewEPUBExporter(doc, nil).WriteEPUB(&buf); err != nil {
+		t.Fatalf("EPUB导出失败: %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("读取EPUB失败: %v", err)
+	}
+
+	first := reader.File[0]
+	if first.Name != "mimetype" || first.Method != zip.Store || len(first.Extra) != 0 {
+		t.Errorf("mimetype应该是第一个未压缩的条目: %+v", first.FileHeader)
+	}
+
+	files := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("打开 %s 失败: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[file.Name] = string(data)
+	}
+
+	if files["mimetype"] != "application/epub+zip" {
+		t.Errorf("mimetype内容错误: %q", files["mimetype"])
+	}
+	if !strings.Contains(files["META-INF/container.xml"], `full-path="OEBPS/content.opf"`) {
+		t.Error("container.xml应该指向包文档")
+	}
+
+	// 前言加上两个一级标题
+	for _, name := range []string{"OEBPS/chapter001.xhtml", "OEBPS/chapter002.xhtml", "OEBPS/chapter003.xhtml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("缺少章节 %s", name)
+		}
+	}
+	if _, ok := files["OEBPS/chapter004.xhtml"]; ok {
+		t.Error("粗体段落不应该拆分章节")
+	}
+
+	// 所有XML文档必须是格式良好的
+	for name, content := range files {
+		if !strings.HasSuffix(name, ".xhtml") && !strings.HasSuffix(name, ".opf") && !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s 不是格式良好的XML: %v", name, err)
+				break
+			}
+		}
+	}
+
+	opf := files["OEBPS/content.opf"]
+	for _, expected := range []string{
+		`<dc:title>User Manual</dc:title>`,
+		`<dc:creator>Docs Team</dc:creator>`,
+		`<dc:language>en-US</dc:language>`,
+		`<dc:subject>guide</dc:subject>`,
+		`<meta property="dcterms:modified">2024-03-04T05:06:07Z</meta>`,
+		`<dc:identifier id="pub-id">urn:uuid:`,
+		`properties="nav"`,
+		`href="images/image1.png" media-type="image/png"`,
+		`<itemref idref="chapter3"/>`,
+	} {
+		if !strings.Contains(opf, expected) {
+			t.Errorf("content.opf应该包含 %q:\n%s", expected, opf)
+		}
+	}
+
+	nav := files["OEBPS/nav.xhtml"]
+	for _, expected := range []string{
+		`<nav epub:type="toc" id="toc">`,
+		`<li><a href="chapter001.xhtml">User Manual</a>`,
+		`<li><a href="chapter002.xhtml#h2">Getting Started</a>` + "<ol>\n" + `<li><a href="chapter002.xhtml#h5">Install</a>`,
+		`<li><a href="chapter003.xhtml#h7">Reference</a>`,
+	} {
+		if !strings.Contains(nav, expected) {
+			t.Errorf("nav.xhtml应该包含 %q:\n%s", expected, nav)
+		}
+	}
+
+	chapter := files["OEBPS/chapter002.xhtml"]
+	for _, expected := range []string{
+		`<h1 id="h2" class="s-Heading1">Getting Started</h1>`,
+		`<strong>Bold is not a chapter</strong> &amp; more`,
+		`<p><br/></p>`,
+		`<img src="images/image1.png" alt="Logo" width="100" height="50"/>`,
+		`<link rel="stylesheet" type="text/css" href="styles.css"/>`,
+	} {
+		if !strings.Contains(chapter, expected) {
+			t.Errorf("章节应该包含 %q:\n%s", expected, chapter)
+		}
+	}
+	if !strings.Contains(files["OEBPS/chapter003.xhtml"], `<li id="fn-1">`) {
+		t.Error("脚注应该输出在所在章节")
+	}
+	if files["OEBPS/images/image1.png"] == "" {
+		t.Error("图片应该打包")
+	}
+}
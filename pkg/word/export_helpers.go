@@ -0,0 +1,338 @@
+package word
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+var headingStylePattern = regexp.MustCompile(`(?i)^heading\s*([1-9]?)$`)
+
+// headingLevel returns the heading level (1-6) of a paragraph style, or 0.
+// Style IDs are looked up in styleNames so that localized IDs such as "1"
+// with the name "heading 1" are recognized.
+func headingLevel(style string, styleNames map[string]string) int {
+	if style == "" {
+		return 0
+	}
+	if strings.EqualFold(style, "Title") {
+		return 1
+	}
+
+	candidates := []string{style}
+	if name, ok := styleNames[style]; ok {
+		candidates = append(candidates, name)
+	}
+	for _, candidate := range candidates {
+		if match := headingStylePattern.FindStringSubmatch(candidate); match != nil {
+			// 没有级别的"Heading"是结构标签添加的标题
+			level := 1
+			if match[1] != "" {
+				level, _ = strconv.Atoi(match[1])
+			}
+			if level > 6 {
+				level = 6
+			}
+			return level
+		}
+	}
+	return 0
+}
+
+// exportMediaDirName returns the media directory that references to
+// extracted images point into, with forward slashes; empty means "media"
+func exportMediaDirName(mediaDir string) string {
+	if mediaDir == "" {
+		return "media"
+	}
+	return filepath.ToSlash(mediaDir)
+}
+
+// isCodeFont reports whether runs in font are exported as code
+func isCodeFont(font string, codeFonts []string) bool {
+	if font == "" {
+		return false
+	}
+	for _, codeFont := range codeFonts {
+		if strings.EqualFold(font, codeFont) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSafeLink reports whether a hyperlink target may be exported as a
+// link: http, https and mailto URLs, "#" destinations and relative
+// references. Other schemes, such as javascript: and data:, can run
+// script in the reader of the exported document.
+func isSafeLink(target string) bool {
+	link, err := url.Parse(strings.TrimSpace(target))
+	if err != nil || target == "" {
+		return false
+	}
+	switch strings.ToLower(link.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// exportMedia collects the images referenced while exporting a document
+// and optionally writes them to a media directory
+type exportMedia struct {
+	document *Document
+	// dir receives the extracted files; nothing is written when empty
+	dir    string
+	names  map[string]string
+	logger *utils.Logger
+	// files lists the extracted images in extraction order
+	files []exportedFile
+}
+
+// exportedFile is an image collected during an export
+type exportedFile struct {
+	name string
+	data []byte
+}
+
+// newExportMedia creates a media collector for an export
+func newExportMedia(document *Document, dir string, logger *utils.Logger) *exportMedia {
+	return &exportMedia{
+		document: document,
+		dir:      dir,
+		names:    make(map[string]string),
+		logger:   logger,
+	}
+}
+
+// extract returns the file name and data of an image. Every image is
+// written to the media directory once; images sharing a file name get a
+// numeric suffix.
+func (em *exportMedia) extract(image *types.Image) (string, []byte, error) {
+	data := em.imageData(image)
+	if data == nil {
+		return "", nil, fmt.Errorf("无法读取图片: %s", image.Path)
+	}
+
+	key := image.Path
+	if key == "" {
+		key = fmt.Sprintf("#%p", image)
+	}
+	if name, ok := em.names[key]; ok {
+		return name, data, nil
+	}
+
+	name := path.Base(image.Path)
+	if image.Path == "" || name == "." || name == "/" {
+		name = fmt.Sprintf("image%d.%s", len(em.names)+1, detectImageExtension(data))
+	}
+	name = em.uniqueName(name)
+	em.names[key] = name
+	em.files = append(em.files, exportedFile{name: name, data: data})
+
+	if em.dir != "" {
+		if err := os.MkdirAll(em.dir, 0755); err != nil {
+			return name, data, fmt.Errorf("无法创建媒体目录: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(em.dir, name), data, 0644); err != nil {
+			return name, data, fmt.Errorf("无法写入图片: %w", err)
+		}
+	}
+
+	return name, data, nil
+}
+
+// uniqueName avoids overwriting images that share a file name
+func (em *exportMedia) uniqueName(name string) string {
+	used := make(map[string]bool, len(em.names))
+	for _, existing := range em.names {
+		used[existing] = true
+	}
+	if !used[name] {
+		return name
+	}
+
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s_%d%s", base, i, ext)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// imageData returns the bytes of an image from the package, the image
+// itself or the file system
+func (em *exportMedia) imageData(image *types.Image) []byte {
+	if len(image.Data) > 0 {
+		return image.Data
+	}
+	if image.Path == "" {
+		return nil
+	}
+	if em.document != nil {
+		if data := em.document.readPart(image.Path); data != nil {
+			return data
+		}
+	}
+	if data, err := os.ReadFile(image.Path); err == nil {
+		return data
+	}
+	return nil
+}
+
+// readPart returns the content of a package part, or nil when the part
+// does not exist or the document has no package
+func (d *Document) readPart(name string) []byte {
+	if d.container == nil || d.container.Reader == nil {
+		return nil
+	}
+	part, err := d.container.GetPart(name)
+	if err != nil {
+		return nil
+	}
+	return part.Content
+}
+
+// walkBody visits paragraphs and tables in document order. Tables are
+// placed before the paragraph at their Position; tables without a valid
+// position follow the last paragraph.
+func walkBody(content *types.DocumentContent, visit func(paragraph *types.Paragraph, table *types.Table) error) error {
+	order := make([]int, len(content.Tables))
+	for i := range order {
+		order[i] = i
+	}
+	position := func(i int) int {
+		p := content.Tables[order[i]].Position
+		if p < 0 || p > len(content.Paragraphs) {
+			return len(content.Paragraphs)
+		}
+		return p
+	}
+	sort.SliceStable(order, func(a, b int) bool { return position(a) < position(b) })
+
+	next := 0
+	for i := 0; i <= len(content.Paragraphs); i++ {
+		for next < len(order) && position(next) == i {
+			if err := visit(nil, &content.Tables[order[next]]); err != nil {
+				return err
+			}
+			next++
+		}
+		if i < len(content.Paragraphs) {
+			if err := visit(&content.Paragraphs[i], nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// bodyBlock is a paragraph or a table of the document body
+type bodyBlock struct {
+	paragraph *types.Paragraph
+	table     *types.Table
+}
+
+// collectBody returns the body blocks in document order
+func collectBody(content *types.DocumentContent) []bodyBlock {
+	var blocks []bodyBlock
+	walkBody(content, func(paragraph *types.Paragraph, table *types.Table) error {
+		blocks = append(blocks, bodyBlock{paragraph: paragraph, table: table})
+		return nil
+	})
+	return blocks
+}
+
+// listCounters numbers list items the way Word does. List instances that
+// share an abstract definition continue one sequence, and the first item of
+// an instance with a start override restarts its level.
+type listCounters struct {
+	numbering  *parser.WordNumbering
+	levels     map[string][]int
+	overridden map[[2]int]bool
+}
+
+// newListCounters creates the counters for the given numbering definitions
+func newListCounters(numbering *parser.WordNumbering) *listCounters {
+	return &listCounters{
+		numbering:  numbering,
+		levels:     make(map[string][]int),
+		overridden: make(map[[2]int]bool),
+	}
+}
+
+// next advances the counters and returns the number of a list item
+func (lc *listCounters) next(numID, level int) int {
+	key := lc.numbering.AbstractNumID(numID)
+	if key == "" {
+		// 未知的列表各自编号
+		key = "num:" + strconv.Itoa(numID)
+	}
+
+	levels := lc.levels[key]
+	for len(levels) <= level {
+		levels = append(levels, 0)
+	}
+	// 较深层级在上层条目之后重新编号
+	for i := level + 1; i < len(levels); i++ {
+		levels[i] = 0
+	}
+	if start, ok := lc.numbering.StartOverride(numID, level); ok && !lc.overridden[[2]int{numID, level}] {
+		lc.overridden[[2]int{numID, level}] = true
+		levels[level] = start
+	} else if levels[level] == 0 {
+		levels[level] = lc.numbering.Start(numID, level)
+	} else {
+		levels[level]++
+	}
+	lc.levels[key] = levels
+	return levels[level]
+}
+
+// detectImageExtension returns the file extension for encoded image data
+func detectImageExtension(data []byte) string {
+	switch {
+	case len(data) >= 8 && string(data[:8]) == "\x89PNG\r\n\x1a\n":
+		return "png"
+	case len(data) >= 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF:
+		return "jpg"
+	case len(data) >= 6 && (string(data[:6]) == "GIF87a" || string(data[:6]) == "GIF89a"):
+		return "gif"
+	case len(data) >= 2 && string(data[:2]) == "BM":
+		return "bmp"
+	default:
+		return "bin"
+	}
+}
+
+// imageContentType returns the MIME type of an image, detected from its
+// data and falling back to the file extension
+func imageContentType(name string, data []byte) string {
+	switch detectImageExtension(data) {
+	case "png":
+		return "image/png"
+	case "jpg":
+		return "image/jpeg"
+	case "gif":
+		return "image/gif"
+	case "bmp":
+		return "image/bmp"
+	}
+	if contentType := mime.TypeByExtension(strings.ToLower(path.Ext(name))); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,201 @@
+package word
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// ReplaceOptions controls how text is matched by a TextReplacer
+type ReplaceOptions struct {
+	// Regexp treats the search text as a regular expression. The
+	// replacement may then refer to capture groups as $1 or ${name}.
+	Regexp bool
+	// IgnoreCase matches letters regardless of their case
+	IgnoreCase bool
+}
+
+// TextSegment is a piece of the text of a paragraph, such as the text of a
+// run. Fixed segments stand for content such as tabs and breaks; matches
+// that include them are not replaced.
+type TextSegment struct {
+	Text  string
+	Fixed bool
+}
+
+// TextReplacer finds and replaces text that Word may have split into
+// several runs, as it does at spelling, revision and formatting
+// boundaries. A replacement of the same length as the match keeps the
+// formatting of every character; other replacements take the formatting
+// of the first matched character.
+type TextReplacer struct {
+	pattern     *regexp.Regexp
+	replacement string
+	expand      bool
+}
+
+// NewTextReplacer creates a replacer of search with replacement
+func NewTextReplacer(search, replacement string, options ReplaceOptions) (*TextReplacer, error) {
+	if search == "" {
+		return nil, fmt.Errorf("search text is empty")
+	}
+	expression := search
+	if !options.Regexp {
+		expression = regexp.QuoteMeta(search)
+	}
+	if options.IgnoreCase {
+		expression = "(?i)" + expression
+	}
+	pattern, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern: %w", err)
+	}
+	return &TextReplacer{pattern: pattern, replacement: replacement, expand: options.Regexp}, nil
+}
+
+// Replace replaces the matches in the text of segments, which is matched
+// as one string, and returns the number of replacements. The segments are
+// changed in place and keep their number, so empty segments may remain.
+func (r *TextReplacer) Replace(segments []TextSegment) int {
+	var text strings.Builder
+	starts := make([]int, len(segments))
+	for i, segment := range segments {
+		starts[i] = text.Len()
+		text.WriteString(segment.Text)
+	}
+	content := text.String()
+	matches := r.pattern.FindAllStringSubmatchIndex(content, -1)
+
+	// 从后向前替换，前面片段的偏移不受影响
+	count := 0
+	for m := len(matches) - 1; m >= 0; m-- {
+		start, end := matches[m][0], matches[m][1]
+		if start == end {
+			continue
+		}
+		first, last := segmentAt(starts, start), segmentAt(starts, end-1)
+		fixed := false
+		for _, segment := range segments[first : last+1] {
+			fixed = fixed || segment.Fixed
+		}
+		if fixed {
+			continue
+		}
+
+		replacement := r.replacement
+		if r.expand {
+			replacement = string(r.pattern.ExpandString(nil, r.replacement, content, matches[m]))
+		}
+		runes := []rune(replacement)
+		perCharacter := len(runes) == utf8.RuneCountInString(content[start:end])
+		for i := last; i >= first; i-- {
+			from, to := max(start-starts[i], 0), min(end-starts[i], len(segments[i].Text))
+			part := ""
+			switch {
+			case perCharacter:
+				// 长度相同时逐字替换，保留每个字符的格式
+				offset := utf8.RuneCountInString(content[start : starts[i]+from])
+				part = string(runes[offset : offset+utf8.RuneCountInString(segments[i].Text[from:to])])
+			case i == first:
+				part = replacement
+			}
+			segments[i].Text = segments[i].Text[:from] + part + segments[i].Text[to:]
+		}
+		count++
+	}
+	return count
+}
+
+// segmentAt returns the index of the segment that holds the byte at offset
+func segmentAt(starts []int, offset int) int {
+	index := 0
+	for i, start := range starts {
+		if start > offset {
+			break
+		}
+		index = i
+	}
+	return index
+}
+
+// ReplaceParagraph replaces the matches in the runs of a paragraph and
+// returns the number of replacements
+func (r *TextReplacer) ReplaceParagraph(paragraph *types.Paragraph) int {
+	count := 0
+	if len(paragraph.Runs) > 0 {
+		segments := make([]TextSegment, len(paragraph.Runs))
+		for i, run := range paragraph.Runs {
+			segments[i].Text = run.Text
+		}
+		count = r.Replace(segments)
+		for i := range paragraph.Runs {
+			paragraph.Runs[i].Text = segments[i].Text
+		}
+	}
+
+	text := []TextSegment{{Text: paragraph.Text}}
+	if n := r.Replace(text); len(paragraph.Runs) == 0 {
+		count = n
+	}
+	paragraph.Text = text[0].Text
+	return count
+}
+
+// ReplaceContent replaces the matches in the paragraphs and table cells of
+// document content and returns the number of replacements
+func (r *TextReplacer) ReplaceContent(content *types.DocumentContent) int {
+	count := 0
+	for i := range content.Paragraphs {
+		count += r.ReplaceParagraph(&content.Paragraphs[i])
+	}
+	for i := range content.Tables {
+		for j := range content.Tables[i].Rows {
+			for k := range content.Tables[i].Rows[j].Cells {
+				cell := &content.Tables[i].Rows[j].Cells[k]
+				for p := range cell.Paragraphs {
+					count += r.ReplaceParagraph(&cell.Paragraphs[p])
+				}
+				text := []TextSegment{{Text: cell.Text}}
+				if n := r.Replace(text); len(cell.Paragraphs) == 0 {
+					count += n
+				}
+				cell.Text = text[0].Text
+			}
+		}
+	}
+
+	text := []TextSegment{{Text: content.Text}}
+	r.Replace(text)
+	content.Text = text[0].Text
+	return count
+}
+
+// ReplaceText replaces search with replacement in the content of the
+// document, also where the text is split across runs, hyperlinks and
+// simple fields.
+//
+// Parameters:
+//   - search: The text or, with options.Regexp, the regular expression to find
+//   - replacement: The new text; with options.Regexp it may use $1 or ${name}
+//   - options: How to match the search text
+//
+// Returns:
+//   - int: The number of replacements
+//   - error: An error if the content is not loaded or the pattern is invalid
+//
+// Example:
+//
+//	count, err := doc.ReplaceText(`\{\{(\w+)\}\}`, "<$1>", word.ReplaceOptions{Regexp: true})
+func (d *Document) ReplaceText(search, replacement string, options ReplaceOptions) (int, error) {
+	if d.mainPart == nil || d.mainPart.Content == nil {
+		return 0, fmt.Errorf("document content not loaded")
+	}
+	replacer, err := NewTextReplacer(search, replacement, options)
+	if err != nil {
+		return 0, err
+	}
+	return replacer.ReplaceContent(d.mainPart.Content), nil
+}
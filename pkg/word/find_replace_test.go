@@ -0,0 +1,134 @@
+package word
+
+import (
+	"testing"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// segmentTexts returns the text of every segment
+func segmentTexts(segments []TextSegment) []string {
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.Text
+	}
+	return texts
+}
+
+func TestTextReplacerReplace(t *testing.T) {
+	tests := []struct {
+		name        string
+		search      string
+		replacement string
+		options     ReplaceOptions
+		segments    []TextSegment
+		expected    []string
+		count       int
+	}{
+		{
+			name:        "跨运行替换，使用首字符格式",
+			search:      "{{customer_name}}",
+			replacement: "张三",
+			segments:    []TextSegment{{Text: "Dear {{cust"}, {Text: "omer_"}, {Text: "name}}, hi"}},
+			expected:    []string{"Dear 张三", "", ", hi"},
+			count:       1,
+		},
+		{
+			name:        "长度相同时逐字保留格式",
+			search:      "abcd",
+			replacement: "WXYZ",
+			segments:    []TextSegment{{Text: "xab"}, {Text: "c"}, {Text: "dx"}},
+			expected:    []string{"xWX", "Y", "Zx"},
+			count:       1,
+		},
+		{
+			name:        "正则捕获组",
+			search:      `(\d+)-(\d+)`,
+			replacement: "$2-$1",
+			options:     ReplaceOptions{Regexp: true},
+			segments:    []TextSegment{{Text: "1"}, {Text: "2-34 and 5-6"}},
+			expected:    []string{"3", "4-12 and 6-5"},
+			count:       2,
+		},
+		{
+			name:        "忽略大小写",
+			search:      "hello",
+			replacement: "bye",
+			options:     ReplaceOptions{IgnoreCase: true},
+			segments:    []TextSegment{{Text: "HeL"}, {Text: "lo hello"}},
+			expected:    []string{"bye", " bye"},
+			count:       2,
+		},
+		{
+			name:        "跳过包含制表符的匹配",
+			search:      "a b",
+			replacement: "x",
+			segments:    []TextSegment{{Text: "a"}, {Text: " ", Fixed: true}, {Text: "b a b"}},
+			expected:    []string{"a", " ", "b x"},
+			count:       1,
+		},
+		{
+			name:        "正则中的特殊字符按字面匹配",
+			search:      "$1.00",
+			replacement: "$2",
+			segments:    []TextSegment{{Text: "cost $1"}, {Text: ".00"}},
+			expected:    []string{"cost $2", ""},
+			count:       1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replacer, err := NewTextReplacer(tt.search, tt.replacement, tt.options)
+			if err != nil {
+				t.Fatalf("创建替换器失败: %v", err)
+			}
+			if count := replacer.Replace(tt.segments); count != tt.count {
+				t.Errorf("替换次数错误: 期望 %d, 实际 %d", tt.count, count)
+			}
+			texts := segmentTexts(tt.segments)
+			if len(texts) != len(tt.expected) {
+				t.Fatalf("片段数量错误: %q", texts)
+			}
+			for i := range texts {
+				if texts[i] != tt.expected[i] {
+					t.Errorf("替换结果错误: 期望 %q, 实际 %q", tt.expected, texts)
+					break
+				}
+			}
+		})
+	}
+
+	if _, err := NewTextReplacer("", "x", ReplaceOptions{}); err == nil {
+		t.Error("空的查找文本应该返回错误")
+	}
+	if _, err := NewTextReplacer("(", "x", ReplaceOptions{Regexp: true}); err == nil {
+		t.Error("无效的正则表达式应该返回错误")
+	}
+}
+
+func TestDocumentReplaceText(t *testing.T) {
+	doc := &Document{mainPart: &MainDocumentPart{Content: &types.DocumentContent{
+		Paragraphs: []types.Paragraph{
+			{Text: "Order {{id}}", Runs: []types.Run{{Text: "Order {{"}, {Text: "id}}", Bold: true}}},
+		},
+		Tables: []types.Table{{Rows: []types.TableRow{{Cells: []types.TableCell{{Text: "{{id}}"}}}}}},
+		Text:   "Order {{id}}\n",
+	}}}
+
+	count, err := doc.ReplaceText("{{ID}}", "42", ReplaceOptions{IgnoreCase: true})
+	if err != nil {
+		t.Fatalf("替换文本失败: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("替换次数错误: 期望 2, 实际 %d", count)
+	}
+	content := doc.GetMainPart().Content
+	paragraph := content.Paragraphs[0]
+	if paragraph.Text != "Order 42" || paragraph.Runs[0].Text != "Order 42" || paragraph.Runs[1].Text != "" {
+		t.Errorf("段落替换结果错误: %+v", paragraph)
+	}
+	if content.Tables[0].Rows[0].Cells[0].Text != "42" || content.Text != "Order 42\n" {
+		t.Error("表格和文档文本应该同步替换")
+	}
+}
@@ -0,0 +1,903 @@
+package word
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+// HTMLExporter exports a document as semantic HTML with a stylesheet
+// generated from the document styles
+type HTMLExporter struct {
+	Document *Document
+	Config   *HTMLExportConfig
+	Logger   *utils.Logger
+
+	numbering  *parser.WordNumbering
+	styles     *parser.WordStyles
+	styleNames map[string]string
+	footnotes  map[string][]types.Paragraph
+	comments   map[string]*parser.WordComment
+	// 每次导出时重置：提取的图片、用到的脚注和批注编号
+	media         *exportMedia
+	usedNotes     []string
+	commentNumber map[string]int
+
+	// xhtml closes void elements so the output is well-formed XML
+	xhtml bool
+	// headingIDs are the anchors written on heading paragraphs
+	headingIDs map[*types.Paragraph]string
+}
+
+// HTMLImageMode defines how images are written to the HTML output
+type HTMLImageMode string
+
+const (
+	// HTMLImageEmbed embeds images as data URIs
+	HTMLImageEmbed HTMLImageMode = "embed"
+	// HTMLImageExtract extracts images to files in the media directory
+	HTMLImageExtract HTMLImageMode = "extract"
+)
+
+// HTMLExportConfig HTML导出配置
+type HTMLExportConfig struct {
+	// Title is the document title; "Document" is used when empty
+	Title     string        `json:"title"`
+	ImageMode HTMLImageMode `json:"image_mode"`
+	// MediaDir is the directory, relative to the HTML file, that receives
+	// extracted images
+	MediaDir        string `json:"media_dir"`
+	IncludeComments bool   `json:"include_comments"`
+	// Fragment omits the html, head and body elements so the output can
+	// be embedded in an existing page
+	Fragment bool `json:"fragment"`
+	// CodeFonts are font names whose runs are exported as code
+	CodeFonts []string `json:"code_fonts"`
+}
+
+// htmlList is an open ol or ul element while rendering list paragraphs
+type htmlList struct {
+	tag    string
+	numID  int
+	itemOn bool
+}
+
+// htmlBaseStylesheet is emitted before the rules generated from styles
+const htmlBaseStylesheet = `body { font-family: Calibri, Arial, sans-serif; line-height: 1.4; max-width: 52em; margin: 2em auto; padding: 0 1em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #bfbfbf; padding: 4px 8px; vertical-align: top; }
+th { background-color: #f2f2f2; }
+td > p, th > p { margin: 0; }
+img { max-width: 100%; height: auto; }
+blockquote { margin: 1em 2em; padding-left: 1em; border-left: 3px solid #ccc; color: #555; }
+aside.comment { margin: 0.5em 0 0.5em 2em; padding: 0.5em 1em; border-left: 3px solid #f0ad4e; background: #fff8e6; font-size: 0.9em; }
+aside.comment .comment-meta { margin: 0 0 0.25em; font-weight: bold; }
+section.footnotes { margin-top: 2em; font-size: 0.9em; }
+`
+
+// NewHTMLExporter 创建HTML导出器
+func NewHTMLExporter(document *Document, config *HTMLExportConfig) *HTMLExporter {
+	if config == nil {
+		config = getDefaultHTMLConfig()
+	}
+
+	return &HTMLExporter{
+		Document: document,
+		Config:   config,
+		Logger:   utils.NewLogger(utils.LogLevelInfo, os.Stdout),
+	}
+}
+
+// getDefaultHTMLConfig 获取默认HTML配置
+func getDefaultHTMLConfig() *HTMLExportConfig {
+	return &HTMLExportConfig{
+		Title:           "Document",
+		ImageMode:       HTMLImageEmbed,
+		MediaDir:        "media",
+		IncludeComments: true,
+		CodeFonts:       []string{"Consolas", "Courier New", "Courier", "Menlo", "Monaco", "Source Code Pro"},
+	}
+}
+
+// ExportToHTML writes the document to outputPath. In extract mode images
+// are written to the media directory next to it.
+func (he *HTMLExporter) ExportToHTML(outputPath string) error {
+	// 未设置配置时使用默认配置
+	if he.Config == nil {
+		he.Config = getDefaultHTMLConfig()
+	}
+	mediaDir := ""
+	if he.Config.ImageMode == HTMLImageExtract {
+		mediaDir = filepath.Join(filepath.Dir(outputPath), filepath.FromSlash(exportMediaDirName(he.Config.MediaDir)))
+	}
+
+	content, err := he.render(mediaDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("保存HTML文件失败: %w", err)
+	}
+
+	he.Logger.Info("HTML导出完成: %s", outputPath)
+	return nil
+}
+
+// ToHTML returns the document as HTML. Extracted images are referenced
+// but not written.
+func (he *HTMLExporter) ToHTML() (string, error) {
+	return he.render("")
+}
+
+// render converts the document. Extracted images are written to mediaDir
+// unless it is empty.
+func (he *HTMLExporter) render(mediaDir string) (string, error) {
+	if he.Config == nil {
+		he.Config = getDefaultHTMLConfig()
+	}
+	if he.Document == nil || he.Document.mainPart == nil || he.Document.mainPart.Content == nil {
+		return "", fmt.Errorf("document content not loaded")
+	}
+
+	he.prepare(mediaDir)
+	body := he.renderBlocks(collectBody(he.Document.mainPart.Content))
+
+	if he.Config.Fragment {
+		return "<style>\n" + he.stylesheet() + "</style>\n" + body, nil
+	}
+
+	title := he.Config.Title
+	if title == "" {
+		title = "Document"
+	}
+
+	var out strings.Builder
+	out.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"UTF-8\">\n")
+	out.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	out.WriteString("<style>\n" + he.stylesheet() + "</style>\n")
+	out.WriteString("</head>\n<body>\n")
+	out.WriteString(body)
+	out.WriteString("</body>\n</html>\n")
+	return out.String(), nil
+}
+
+// prepare loads the document parts and resets the export state
+func (he *HTMLExporter) prepare(mediaDir string) {
+	he.loadParts()
+	he.media = newExportMedia(he.Document, mediaDir, he.Logger)
+	he.usedNotes = nil
+	he.commentNumber = make(map[string]int)
+}
+
+// renderBlocks renders body blocks followed by the footnotes they reference
+func (he *HTMLExporter) renderBlocks(blocks []bodyBlock) string {
+	var body strings.Builder
+	var lists []htmlList
+	counters := newListCounters(he.numbering)
+
+	closeLists := func(depth int) {
+		for len(lists) > depth {
+			top := lists[len(lists)-1]
+			if top.itemOn {
+				body.WriteString("</li>\n")
+			}
+			body.WriteString("</" + top.tag + ">\n")
+			lists = lists[:len(lists)-1]
+		}
+	}
+
+	for _, block := range blocks {
+		paragraph := block.paragraph
+		if block.table != nil {
+			closeLists(0)
+			body.WriteString(he.renderTable(block.table))
+			continue
+		}
+
+		if paragraph.NumID == 0 {
+			closeLists(0)
+			body.WriteString(he.renderParagraph(paragraph))
+			continue
+		}
+
+		level := paragraph.ListLevel
+		if level < 0 {
+			level = 0
+		}
+		// 编号不同的列表各自生成ol或ul元素
+		if len(lists) > 0 && level == 0 && lists[0].numID != paragraph.NumID {
+			closeLists(0)
+		}
+		closeLists(level + 1)
+		number := counters.next(paragraph.NumID, level)
+		if len(lists) == level+1 && lists[level].itemOn {
+			body.WriteString("</li>\n")
+			lists[level].itemOn = false
+		}
+		for len(lists) < level+1 {
+			if n := len(lists); n > 0 && !lists[n-1].itemOn {
+				body.WriteString("<li>")
+				lists[n-1].itemOn = true
+			}
+			// 新列表从当前条目的编号开始，跳过的层级从定义的起始值开始
+			start := he.numbering.Start(paragraph.NumID, len(lists))
+			if len(lists) == level {
+				start = number
+			}
+			tag, attrs := he.listElement(paragraph.NumID, len(lists), start)
+			body.WriteString("<" + tag + attrs + ">\n")
+			lists = append(lists, htmlList{tag: tag, numID: paragraph.NumID})
+		}
+
+		body.WriteString("<li" + he.paragraphAttributes(paragraph, false) + ">")
+		body.WriteString(he.renderInlines(paragraph.Runs))
+		body.WriteString(he.renderComments(paragraph.Runs))
+		lists[len(lists)-1].itemOn = true
+	}
+	closeLists(0)
+
+	body.WriteString(he.renderFootnotes())
+	return body.String()
+}
+
+// voidElement renders an element without content
+func (he *HTMLExporter) voidElement(tag, attrs string) string {
+	if he.xhtml {
+		return "<" + tag + attrs + "/>"
+	}
+	return "<" + tag + attrs + ">"
+}
+
+// loadParts loads numbering, styles, footnotes and comments from the package
+func (he *HTMLExporter) loadParts() {
+	he.numbering = nil
+	he.styles = nil
+	he.styleNames = nil
+	he.footnotes = nil
+	he.comments = nil
+
+	relationships := he.Document.documentRelationships()
+
+	if data := he.Document.readPart("word/numbering.xml"); data != nil {
+		if numbering, err := parser.ParseNumbering(data); err == nil {
+			he.numbering = numbering
+		} else {
+			he.Logger.Warning("无法解析编号定义: %v", err)
+		}
+	}
+	if data := he.Document.readPart("word/styles.xml"); data != nil {
+		if styles, err := parser.ParseStyles(data); err == nil {
+			he.styles = styles
+			he.styleNames = make(map[string]string, len(styles.Styles))
+			for _, style := range styles.Styles {
+				he.styleNames[style.ID] = style.Name.Val
+			}
+		} else {
+			he.Logger.Warning("无法解析样式: %v", err)
+		}
+	}
+	if data := he.Document.readPart("word/footnotes.xml"); data != nil {
+		if notes, err := parser.ParseFootnotes(data, relationships); err == nil {
+			he.footnotes = notes
+		} else {
+			he.Logger.Warning("无法解析脚注: %v", err)
+		}
+	}
+	if he.Config.IncludeComments {
+		if data := he.Document.readPart("word/comments.xml"); data != nil {
+			if comments, err := parser.ParseComments(data, relationships); err == nil {
+				he.comments = comments
+			} else {
+				he.Logger.Warning("无法解析批注: %v", err)
+			}
+		}
+	}
+}
+
+// listElement returns the element and attributes of a list level that
+// starts at the given number
+func (he *HTMLExporter) listElement(numID, level, start int) (string, string) {
+	format := he.numbering.Format(numID, level)
+	if format == "" || format == "bullet" || format == "none" {
+		return "ul", ""
+	}
+
+	var attrs string
+	if start != 1 {
+		attrs += fmt.Sprintf(` start="%d"`, start)
+	}
+	switch format {
+	case "lowerLetter":
+		attrs += ` type="a"`
+	case "upperLetter":
+		attrs += ` type="A"`
+	case "lowerRoman":
+		attrs += ` type="i"`
+	case "upperRoman":
+		attrs += ` type="I"`
+	case "chineseCounting", "chineseCountingThousand", "chineseLegalSimplified", "ideographTraditional":
+		attrs += ` style="list-style-type: cjk-ideographic"`
+	}
+	return "ol", attrs
+}
+
+// renderParagraph renders a paragraph outside of a list
+func (he *HTMLExporter) renderParagraph(paragraph *types.Paragraph) string {
+	content := he.renderInlines(paragraph.Runs)
+	comments := he.renderComments(paragraph.Runs)
+
+	if level := headingLevel(paragraph.Style, he.styleNames); level > 0 {
+		tag := "h" + strconv.Itoa(level)
+		attrs := he.paragraphAttributes(paragraph, true)
+		if id, ok := he.headingIDs[paragraph]; ok {
+			attrs = ` id="` + html.EscapeString(id) + `"` + attrs
+		}
+		return "<" + tag + attrs + ">" + content + "</" + tag + ">\n" + comments
+	}
+
+	if content == "" {
+		content = he.voidElement("br", "")
+	}
+	rendered := "<p" + he.paragraphAttributes(paragraph, true) + ">" + content + "</p>\n"
+	if paragraph.Style == "Quote" || paragraph.Style == "IntenseQuote" {
+		rendered = "<blockquote>" + rendered + "</blockquote>\n"
+	}
+	return rendered + comments
+}
+
+// paragraphAttributes returns the class and direct alignment of a paragraph
+func (he *HTMLExporter) paragraphAttributes(paragraph *types.Paragraph, withStyle bool) string {
+	var attrs string
+	if withStyle && paragraph.Style != "" {
+		attrs += ` class="` + cssClassName(paragraph.Style) + `"`
+	}
+	if align := cssTextAlign(paragraph.Alignment); align != "" {
+		attrs += ` style="text-align: ` + align + `"`
+	}
+	return attrs
+}
+
+// renderInlines renders runs with formatting, links, images and footnote references
+func (he *HTMLExporter) renderInlines(runs []types.Run) string {
+	var buf strings.Builder
+
+	for i := 0; i < len(runs); {
+		link := runs[i].Hyperlink
+		if link == "" {
+			buf.WriteString(he.renderRun(&runs[i]))
+			i++
+			continue
+		}
+
+		// 同一超链接中的连续内容合并为一个链接，不安全的链接只输出内容
+		safe := isSafeLink(link)
+		if safe {
+			buf.WriteString(`<a href="` + html.EscapeString(link) + `">`)
+		}
+		for i < len(runs) && runs[i].Hyperlink == link {
+			buf.WriteString(he.renderRun(&runs[i]))
+			i++
+		}
+		if safe {
+			buf.WriteString("</a>")
+		}
+	}
+
+	return buf.String()
+}
+
+// renderRun renders a single run
+func (he *HTMLExporter) renderRun(run *types.Run) string {
+	switch {
+	case run.Image != nil:
+		return he.renderImage(run.Image)
+	case run.FootnoteID != "":
+		return he.renderFootnoteReference(run.FootnoteID)
+	case run.CommentID != "":
+		if number, ok := he.commentNumberFor(run.CommentID); ok {
+			return fmt.Sprintf(`<sup class="comment-ref"><a href="#comment-%s">[%d]</a></sup>`, html.EscapeString(run.CommentID), number)
+		}
+		return ""
+	case run.Text == "" && !run.Tab && run.Break == "":
+		return ""
+	}
+
+	// 制表符和分隔符跟在运行文字之后，按文字中的制表符和换行输出
+	content := run.Text
+	if run.Tab {
+		content += "\t"
+	}
+	if run.Break != "" {
+		content += "\n"
+	}
+	text := html.EscapeString(content)
+	text = strings.ReplaceAll(text, "\n", he.voidElement("br", ""))
+	text = strings.ReplaceAll(text, "\t", "&#8195;")
+	if run.Ruby != nil && run.Ruby.Text != "" {
+		// 不支持注音的浏览器显示括号中的注音
+		text = "<ruby>" + text + "<rp>(</rp><rt>" + html.EscapeString(run.Ruby.Text) + "</rt><rp>)</rp></ruby>"
+	}
+
+	var styles []string
+	if color := cssColor(run.Color); color != "" {
+		styles = append(styles, "color: "+color)
+	}
+	if run.FontSize > 0 {
+		styles = append(styles, "font-size: "+formatPoints(float64(run.FontSize)/2))
+	}
+
+	code := isCodeFont(run.FontName, he.Config.CodeFonts)
+	if family := cssFontFamily(run.FontName); family != "" && !code {
+		styles = append(styles, "font-family: "+family)
+	}
+
+	if code {
+		text = "<code>" + text + "</code>"
+	}
+	if run.Strike {
+		text = "<s>" + text + "</s>"
+	}
+	if run.Underline && run.Hyperlink == "" {
+		text = "<u>" + text + "</u>"
+	}
+	if run.Italic {
+		text = "<em>" + text + "</em>"
+	}
+	if run.Bold {
+		text = "<strong>" + text + "</strong>"
+	}
+	if len(styles) > 0 {
+		text = `<span style="` + html.EscapeString(strings.Join(styles, "; ")) + `">` + text + "</span>"
+	}
+	return text
+}
+
+// renderImage renders an image as a data URI or as a reference to an
+// extracted file
+func (he *HTMLExporter) renderImage(image *types.Image) string {
+	name, data, err := he.media.extract(image)
+	if err != nil {
+		he.Logger.Warning("%v", err)
+		if data == nil {
+			return html.EscapeString(image.AltText)
+		}
+	}
+
+	src := exportMediaDirName(he.Config.MediaDir) + "/" + name
+	if he.Config.ImageMode != HTMLImageExtract {
+		src = "data:" + imageContentType(name, data) + ";base64," + base64.StdEncoding.EncodeToString(data)
+	}
+
+	attrs := ` src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(image.AltText) + `"`
+	if image.Title != "" {
+		attrs += ` title="` + html.EscapeString(image.Title) + `"`
+	}
+	if image.Width > 0 && image.Height > 0 {
+		attrs += fmt.Sprintf(` width="%d" height="%d"`, int(image.Width+0.5), int(image.Height+0.5))
+	}
+	return he.voidElement("img", attrs)
+}
+
+// renderFootnoteReference renders a footnote reference as an endnote anchor
+func (he *HTMLExporter) renderFootnoteReference(id string) string {
+	if _, ok := he.footnotes[id]; !ok {
+		return ""
+	}
+
+	number := 0
+	for i, used := range he.usedNotes {
+		if used == id {
+			number = i + 1
+		}
+	}
+	if number == 0 {
+		he.usedNotes = append(he.usedNotes, id)
+		number = len(he.usedNotes)
+	}
+
+	escaped := html.EscapeString(id)
+	return fmt.Sprintf(`<sup class="footnote-ref"><a href="#fn-%s" id="fnref-%s">%d</a></sup>`, escaped, escaped, number)
+}
+
+// renderFootnotes renders the referenced footnotes as a list of endnotes
+func (he *HTMLExporter) renderFootnotes() string {
+	if len(he.usedNotes) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	buf.WriteString("<section class=\"footnotes\">\n" + he.voidElement("hr", "") + "\n<ol>\n")
+	for _, id := range he.usedNotes {
+		var parts []string
+		for i := range he.footnotes[id] {
+			if content := strings.TrimSpace(he.renderInlines(he.footnotes[id][i].Runs)); content != "" {
+				parts = append(parts, content)
+			}
+		}
+		escaped := html.EscapeString(id)
+		buf.WriteString(fmt.Sprintf(`<li id="fn-%s">%s <a href="#fnref-%s" class="footnote-back">&#8617;</a></li>`+"\n",
+			escaped, strings.Join(parts, he.voidElement("br", "")), escaped))
+	}
+	buf.WriteString("</ol>\n</section>\n")
+	return buf.String()
+}
+
+// commentNumberFor returns the display number of a comment
+func (he *HTMLExporter) commentNumberFor(id string) (int, bool) {
+	if _, ok := he.comments[id]; !ok {
+		return 0, false
+	}
+	if number, ok := he.commentNumber[id]; ok {
+		return number, true
+	}
+	number := len(he.commentNumber) + 1
+	he.commentNumber[id] = number
+	return number, true
+}
+
+// renderComments renders the comments anchored in runs as asides
+func (he *HTMLExporter) renderComments(runs []types.Run) string {
+	var buf strings.Builder
+	for _, run := range runs {
+		if run.CommentID == "" {
+			continue
+		}
+		comment, ok := he.comments[run.CommentID]
+		if !ok {
+			continue
+		}
+		number, _ := he.commentNumberFor(run.CommentID)
+
+		meta := fmt.Sprintf("[%d] %s", number, html.EscapeString(comment.Author))
+		if comment.Date != "" {
+			meta += ` <time datetime="` + html.EscapeString(comment.Date) + `">` + html.EscapeString(comment.Date) + "</time>"
+		}
+		buf.WriteString(`<aside class="comment" id="comment-` + html.EscapeString(comment.ID) + `">` + "\n")
+		buf.WriteString(`<p class="comment-meta">` + meta + "</p>\n")
+		for i := range comment.Paragraphs {
+			buf.WriteString("<p>" + he.renderInlines(comment.Paragraphs[i].Runs) + "</p>\n")
+		}
+		buf.WriteString("</aside>\n")
+	}
+	return buf.String()
+}
+
+// renderTable renders a table with header rows and merged cells
+func (he *HTMLExporter) renderTable(table *types.Table) string {
+	if len(table.Rows) == 0 {
+		return ""
+	}
+
+	// 计算纵向合并的行数
+	rowSpans := make([][]int, len(table.Rows))
+	for r, row := range table.Rows {
+		rowSpans[r] = make([]int, len(row.Cells))
+		column := 0
+		for c, cell := range row.Cells {
+			rowSpans[r][c] = 1
+			if cell.VMerge == "restart" {
+				for next := r + 1; next < len(table.Rows); next++ {
+					below := cellAtColumn(table.Rows[next], column)
+					if below == nil || below.VMerge != "continue" {
+						break
+					}
+					rowSpans[r][c]++
+				}
+			}
+			column += cellSpan(cell)
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString("<table>\n")
+
+	inHeader := false
+	for r, row := range table.Rows {
+		header := row.Header && (r == 0 || table.Rows[r-1].Header)
+		switch {
+		case header && r == 0:
+			buf.WriteString("<thead>\n")
+			inHeader = true
+		case !header && inHeader:
+			buf.WriteString("</thead>\n<tbody>\n")
+			inHeader = false
+		case !header && r == 0:
+			buf.WriteString("<tbody>\n")
+		}
+
+		tag := "td"
+		if header {
+			tag = "th"
+		}
+
+		buf.WriteString("<tr>\n")
+		for c := range row.Cells {
+			cell := &row.Cells[c]
+			if cell.VMerge == "continue" {
+				continue
+			}
+
+			attrs := ""
+			if span := cellSpan(*cell); span > 1 {
+				attrs += fmt.Sprintf(` colspan="%d"`, span)
+			}
+			if rowSpans[r][c] > 1 {
+				attrs += fmt.Sprintf(` rowspan="%d"`, rowSpans[r][c])
+			}
+
+			buf.WriteString("<" + tag + attrs + ">")
+			if len(cell.Paragraphs) == 0 {
+				buf.WriteString(html.EscapeString(cell.Text))
+			}
+			for i := range cell.Paragraphs {
+				buf.WriteString(strings.TrimSuffix(he.renderParagraph(&cell.Paragraphs[i]), "\n"))
+			}
+			buf.WriteString("</" + tag + ">\n")
+		}
+		buf.WriteString("</tr>\n")
+	}
+
+	if inHeader {
+		buf.WriteString("</thead>\n")
+	} else {
+		buf.WriteString("</tbody>\n")
+	}
+	buf.WriteString("</table>\n")
+	return buf.String()
+}
+
+// cellSpan returns the number of grid columns a cell spans
+func cellSpan(cell types.TableCell) int {
+	if cell.ColSpan > 1 {
+		return cell.ColSpan
+	}
+	return 1
+}
+
+// cellAtColumn returns the cell of a row starting at a grid column
+func cellAtColumn(row types.TableRow, column int) *types.TableCell {
+	position := 0
+	for i := range row.Cells {
+		if position == column {
+			return &row.Cells[i]
+		}
+		position += cellSpan(row.Cells[i])
+		if position > column {
+			return nil
+		}
+	}
+	return nil
+}
+
+// stylesheet returns the base stylesheet followed by the rules generated
+// from the document styles
+func (he *HTMLExporter) stylesheet() string {
+	return htmlBaseStylesheet + he.styleRules()
+}
+
+// styleRules generates CSS from the document defaults and style definitions
+func (he *HTMLExporter) styleRules() string {
+	var buf strings.Builder
+
+	if he.styles == nil {
+		return buf.String()
+	}
+
+	if defaults := cssDeclarations(he.styles.Defaults.ParagraphProperties.Properties, he.styles.Defaults.RunProperties.Properties); len(defaults) > 0 {
+		buf.WriteString("body { " + strings.Join(defaults, " ") + " }\n")
+	}
+
+	byID := make(map[string]*parser.WordStyle, len(he.styles.Styles))
+	for i := range he.styles.Styles {
+		byID[he.styles.Styles[i].ID] = &he.styles.Styles[i]
+	}
+
+	ids := make([]string, 0, len(byID))
+	for id, style := range byID {
+		if style.Type == "paragraph" || style.Type == "character" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		// 按basedOn链从基础样式开始合并属性
+		var chain []*parser.WordStyle
+		seen := make(map[string]bool)
+		for style := byID[id]; style != nil && !seen[style.ID]; {
+			seen[style.ID] = true
+			chain = append([]*parser.WordStyle{style}, chain...)
+			if style.BasedOn == nil {
+				break
+			}
+			style = byID[style.BasedOn.Val]
+		}
+
+		properties := make(map[string]string)
+		var order []string
+		for _, style := range chain {
+			for _, declaration := range cssDeclarations(style.ParagraphProperties, style.RunProperties) {
+				name := declaration[:strings.Index(declaration, ":")]
+				if _, ok := properties[name]; !ok {
+					order = append(order, name)
+				}
+				properties[name] = declaration
+			}
+		}
+		if len(order) == 0 {
+			continue
+		}
+
+		declarations := make([]string, 0, len(order))
+		for _, name := range order {
+			declarations = append(declarations, properties[name])
+		}
+		buf.WriteString("." + cssClassName(id) + " { " + strings.Join(declarations, " ") + " }\n")
+	}
+
+	return buf.String()
+}
+
+// cssDeclarations converts paragraph and run properties to CSS declarations
+func cssDeclarations(pPr *parser.ParagraphProps, rPr *parser.RunProps) []string {
+	var declarations []string
+	add := func(name, value string) {
+		declarations = append(declarations, name+": "+value+";")
+	}
+
+	if pPr != nil {
+		if pPr.Alignment != nil {
+			if align := cssTextAlign(pPr.Alignment.Val); align != "" {
+				add("text-align", align)
+			}
+		}
+		if pPr.Spacing != nil {
+			if twips, err := strconv.Atoi(pPr.Spacing.Before); err == nil {
+				add("margin-top", formatPoints(float64(twips)/20))
+			}
+			if twips, err := strconv.Atoi(pPr.Spacing.After); err == nil {
+				add("margin-bottom", formatPoints(float64(twips)/20))
+			}
+		}
+		if pPr.Indentation != nil {
+			if twips, err := strconv.Atoi(pPr.Indentation.Left); err == nil {
+				add("margin-left", formatPoints(float64(twips)/20))
+			}
+			if twips, err := strconv.Atoi(pPr.Indentation.FirstLine); err == nil {
+				add("text-indent", formatPoints(float64(twips)/20))
+			} else if twips, err := strconv.Atoi(pPr.Indentation.Hanging); err == nil {
+				add("text-indent", formatPoints(-float64(twips)/20))
+			}
+		}
+	}
+
+	if rPr != nil {
+		if rPr.Font != nil {
+			font := rPr.Font.Ascii
+			if font == "" {
+				font = rPr.Font.HAnsi
+			}
+			if family := cssFontFamily(font); family != "" {
+				add("font-family", family)
+			}
+		}
+		if rPr.Size != nil {
+			if halfPoints, err := strconv.Atoi(rPr.Size.Val); err == nil {
+				add("font-size", formatPoints(float64(halfPoints)/2))
+			}
+		}
+		if rPr.Bold != nil {
+			if rPr.Bold.Val == "false" || rPr.Bold.Val == "0" {
+				add("font-weight", "normal")
+			} else {
+				add("font-weight", "bold")
+			}
+		}
+		if rPr.Italic != nil {
+			if rPr.Italic.Val == "false" || rPr.Italic.Val == "0" {
+				add("font-style", "normal")
+			} else {
+				add("font-style", "italic")
+			}
+		}
+		if rPr.Color != nil {
+			if color := cssColor(rPr.Color.Val); color != "" {
+				add("color", color)
+			}
+		}
+
+		var decorations []string
+		if rPr.Underline != nil && rPr.Underline.Val != "none" {
+			decorations = append(decorations, "underline")
+		}
+		if rPr.Strike.IsOn() || rPr.DStrike.IsOn() {
+			decorations = append(decorations, "line-through")
+		}
+		if len(decorations) > 0 {
+			add("text-decoration", strings.Join(decorations, " "))
+		}
+	}
+
+	return declarations
+}
+
+// cssTextAlign maps a paragraph justification to a CSS text-align value
+func cssTextAlign(alignment string) string {
+	switch alignment {
+	case "center", "right", "left":
+		return alignment
+	case "both", "distribute":
+		return "justify"
+	case "start":
+		return "left"
+	case "end":
+		return "right"
+	default:
+		return ""
+	}
+}
+
+// cssClassName converts a style ID to a CSS class name. Characters that
+// are not valid in an unescaped class name are replaced by their code point.
+func cssClassName(styleID string) string {
+	var buf strings.Builder
+	buf.WriteString("s-")
+	for _, r := range styleID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			buf.WriteRune(r)
+		default:
+			buf.WriteString(fmt.Sprintf("u%x", r))
+		}
+	}
+	return buf.String()
+}
+
+// cssFontFamily quotes a font name for a CSS font-family declaration.
+// Only letters, digits, spaces, hyphens, underscores and dots are kept, so
+// a font name cannot end the declaration or the style element; a name
+// without any of them gives "".
+func cssFontFamily(font string) string {
+	var buf strings.Builder
+	for _, r := range font {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '-' || r == '_' || r == '.' {
+			buf.WriteRune(r)
+		}
+	}
+	name := strings.TrimSpace(buf.String())
+	if name == "" {
+		return ""
+	}
+	return `"` + name + `"`
+}
+
+// cssColor returns the CSS color of a six digit hexadecimal color value,
+// with or without "#". Other values, including "auto", give "".
+func cssColor(value string) string {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) != 6 {
+		return ""
+	}
+	for _, r := range value {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return ""
+		}
+	}
+	return "#" + value
+}
+
+// formatPoints formats a length in points for CSS
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64) + "pt"
+}
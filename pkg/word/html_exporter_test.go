@@ -0,0 +1,254 @@
+package word
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const htmlTestDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+  xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+  xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"
+  xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">
+  <w:body>
+    <w:p><w:pPr><w:pStyle w:val="1"/></w:pPr><w:r><w:t>标题</w:t></w:r></w:p>
+    <w:p><w:pPr><w:pStyle w:val="Note"/><w:jc w:val="center"/></w:pPr>
+      <w:r><w:rPr><w:b/><w:color w:val="FF0000"/></w:rPr><w:t>A &amp; B</w:t></w:r>
+      <w:hyperlink r:id="rId10"><w:r><w:t>site</w:t></w:r></w:hyperlink>
+      <w:r><w:footnoteReference w:id="1"/></w:r>
+      <w:r><w:commentReference w:id="0"/></w:r>
+    </w:p>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>one</w:t></w:r></w:p>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>nested</w:t></w:r></w:p>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>two</w:t></w:r></w:p>
+    <w:tbl>
+      <w:tr><w:trPr><w:tblHeader/></w:trPr>
+        <w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p><w:r><w:t>Wide</w:t></w:r></w:p></w:tc>
+      </w:tr>
+      <w:tr>
+        <w:tc><w:tcPr><w:vMerge w:val="restart"/></w:tcPr><w:p><w:r><w:t>Tall</w:t></w:r></w:p></w:tc>
+        <w:tc><w:p><w:r><w:t>x</w:t></w:r></w:p></w:tc>
+      </w:tr>
+      <w:tr>
+        <w:tc><w:tcPr><w:vMerge/></w:tcPr><w:p/></w:tc>
+        <w:tc><w:p><w:r><w:t>y</w:t></w:r></w:p></w:tc>
+      </w:tr>
+    </w:tbl>
+    <w:p><w:r><w:drawing><wp:inline><wp:extent cx="952500" cy="476250"/>
+      <wp:docPr id="1" name="Picture 1" descr="Logo"/>
+      <a:graphic><a:graphicData><pic:pic><pic:blipFill><a:blip r:embed="rId11"/></pic:blipFill></pic:pic></a:graphicData></a:graphic>
+    </wp:inline></w:drawing></w:r></w:p>
+    <w:p><w:r><w:t>Tab</w:t><w:tab/></w:r><w:r><w:t>stop</w:t><w:br/></w:r><w:r><w:br w:type="page"/></w:r><w:r><w:t>next</w:t></w:r></w:p>
+  </w:body>
+</w:document>`
+
+const htmlTestStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri"/><w:sz w:val="22"/></w:rPr></w:rPrDefault></w:docDefaults>
+  <w:style w:type="paragraph" w:styleId="1"><w:name w:val="heading 1"/><w:rPr><w:sz w:val="32"/></w:rPr></w:style>
+  <w:style w:type="paragraph" w:styleId="Base"><w:name w:val="Base"/><w:pPr><w:spacing w:after="240"/></w:pPr><w:rPr><w:i/></w:rPr></w:style>
+  <w:style w:type="paragraph" w:styleId="Note"><w:name w:val="Note"/><w:basedOn w:val="Base"/><w:rPr><w:color w:val="336699"/></w:rPr></w:style>
+</w:styles>`
+
+const htmlTestComments = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:comment w:id="0" w:author="Reviewer" w:date="2024-01-02T03:04:05Z"><w:p><w:r><w:t>Check this</w:t></w:r></w:p></w:comment>
+</w:comments>`
+
+// htmlTestPackage writes the package used by the HTML exporter tests
+func htmlTestPackage(t *testing.T) string {
+	return writeTestPackage(t, map[string]string{
+		"word/document.xml":            htmlTestDocument,
+		"word/_rels/document.xml.rels": markdownTestRelationships,
+		"word/numbering.xml":           markdownTestNumbering,
+		"word/footnotes.xml":           markdownTestFootnotes,
+		"word/styles.xml":              htmlTestStyles,
+		"word/comments.xml":            htmlTestComments,
+		"word/media/image1.png":        "\x89PNG\r\n\x1a\nfake",
+	})
+}
+
+func TestHTMLExporterExport(t *testing.T) {
+	doc, err := Open(htmlTestPackage(t))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	content, err := NewHTMLExporter(doc, nil).ToHTML()
+	if err != nil {
+		t.Fatalf("HTML导出失败: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<h1 class="s-1">标题</h1>`,
+		`<p class="s-Note" style="text-align: center"><span style="color: #FF0000"><strong>A &amp; B</strong></span>`,
+		`<a href="https://example.com/a b">site</a>`,
+		`<sup class="footnote-ref"><a href="#fn-1" id="fnref-1">1</a></sup>`,
+		`<li id="fn-1">Footnote text <a href="#fnref-1" class="footnote-back">`,
+		`<aside class="comment" id="comment-0">`,
+		`Check this`,
+		"<ol>\n<li>one<ul>\n<li>nested</li>\n</ul>\n</li>\n<li>two</li>\n</ol>",
+		`<thead>`,
+		`<th colspan="2"><p>Wide</p></th>`,
+		`<td rowspan="2"><p>Tall</p></td>`,
+		`<img src="data:image/png;base64,`,
+		`width="100" height="50"`,
+		`<p>Tab&#8195;stop<br><br>next</p>`,
+		`body { font-family: "Calibri"; font-size: 11pt; }`,
+		`.s-Note { margin-bottom: 12pt; font-style: italic; color: #336699; }`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("HTML应该包含 %q, 实际:\n%s", expected, content)
+		}
+	}
+
+	// 被合并的单元格不应输出
+	if strings.Count(content, "<td") != 3 {
+		t.Errorf("期望3个数据单元格, 实际: %d", strings.Count(content, "<td"))
+	}
+}
+
+func TestHTMLExporterExtractImages(t *testing.T) {
+	doc, err := Open(htmlTestPackage(t))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	config := getDefaultHTMLConfig()
+	config.ImageMode = HTMLImageExtract
+	config.IncludeComments = false
+
+	outputDir := t.TempDir()
+	outputPath := filepath.Join(outputDir, "doc.html")
+	if err := NewHTMLExporter(doc, config).ExportToHTML(outputPath); err != nil {
+		t.Fatalf("HTML导出失败: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("读取HTML失败: %v", err)
+	}
+	content := string(data)
+
+	if !strings.Contains(content, `<img src="media/image1.png" alt="Logo"`) {
+		t.Errorf("图片应该引用提取的文件:\n%s", content)
+	}
+	if strings.Contains(content, "comment") && strings.Contains(content, "<aside") {
+		t.Error("禁用批注时不应输出批注")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "media", "image1.png")); err != nil {
+		t.Errorf("图片应该被提取: %v", err)
+	}
+}
+
+func TestExportersWithoutConfig(t *testing.T) {
+	doc, err := Open(htmlTestPackage(t))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	// 配置为nil时使用默认配置，图片引用默认的media目录
+	htmlExporter := &HTMLExporter{Document: doc, Logger: NewHTMLExporter(doc, nil).Logger}
+	if _, err := htmlExporter.ToHTML(); err != nil {
+		t.Errorf("HTML导出失败: %v", err)
+	}
+	markdownExporter := &MarkdownExporter{Document: doc, Logger: htmlExporter.Logger}
+	markdown, err := markdownExporter.ToMarkdown()
+	if err != nil || !strings.Contains(markdown, "](media/") {
+		t.Errorf("Markdown导出错误: %v\n%s", err, markdown)
+	}
+	latexExporter := &LaTeXExporter{Document: doc, Logger: htmlExporter.Logger}
+	if err := latexExporter.ExportToLaTeX(filepath.Join(t.TempDir(), "out.tex")); err != nil {
+		t.Errorf("LaTeX导出失败: %v", err)
+	}
+
+	if !isCodeFont("courier new", []string{"Courier New"}) || isCodeFont("", []string{""}) {
+		t.Error("代码字体判断错误")
+	}
+}
+
+func TestHTMLExporterUntrustedContent(t *testing.T) {
+	doc, err := Open(writeTestPackage(t, map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <w:body><w:p>
+    <w:hyperlink r:id="rId1"><w:r><w:t>script</w:t></w:r></w:hyperlink>
+    <w:hyperlink r:id="rId2"><w:r><w:t>spaced</w:t></w:r></w:hyperlink>
+    <w:hyperlink r:id="rId3"><w:r><w:t>data</w:t></w:r></w:hyperlink>
+    <w:hyperlink r:id="rId4"><w:r><w:t>mail</w:t></w:r></w:hyperlink>
+    <w:hyperlink w:anchor="top"><w:r><w:t>top</w:t></w:r></w:hyperlink>
+  </w:p>
+  <w:p><w:pPr><w:pStyle w:val="Evil"/></w:pPr><w:r><w:rPr><w:rFonts w:ascii="x&quot;;}&lt;/style&gt;&lt;script&gt;alert(5)&lt;/script&gt;"/><w:color w:val="red;background:url(x)"/></w:rPr><w:t>styled</w:t></w:r></w:p>
+  </w:body>
+</w:document>`,
+		"word/styles.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="x&lt;/style&gt;&lt;script&gt;alert(4)&lt;/script&gt;"/></w:rPr></w:rPrDefault></w:docDefaults>
+  <w:style w:type="paragraph" w:styleId="Evil"><w:name w:val="Evil"/><w:rPr><w:rFonts w:ascii="Noto Sans SC"/><w:color w:val="000000;}&lt;/style&gt;&lt;script&gt;alert(6)&lt;/script&gt;"/></w:rPr></w:style>
+</w:styles>`,
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="javascript:alert(1)" TargetMode="External"/>
+  <Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target=" JavaScript:alert(2)" TargetMode="External"/>
+  <Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="data:text/html,&lt;script&gt;alert(3)&lt;/script&gt;" TargetMode="External"/>
+  <Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="mailto:hr@example.com" TargetMode="External"/>
+</Relationships>`,
+	}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	content, err := NewHTMLExporter(doc, nil).ToHTML()
+	if err != nil {
+		t.Fatalf("HTML导出失败: %v", err)
+	}
+	// 只保留http、https、mailto和文档内的链接，其他协议只输出文字
+	for _, expected := range []string{`script`, `spaced`, `data`, `<a href="mailto:hr@example.com">mail</a>`, `<a href="#top">top</a>`} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("HTML应该包含 %q, 实际:\n%s", expected, content)
+		}
+	}
+	for _, unexpected := range []string{`alert(`, `JavaScript`, `<script`, `url(`} {
+		if strings.Contains(content, unexpected) {
+			t.Errorf("HTML不应该包含不安全的内容 %q:\n%s", unexpected, content)
+		}
+	}
+	// 字体名称只保留安全的字符，颜色只接受六位十六进制值
+	for _, expected := range []string{`body { font-family: "xstylescriptalert4script"; }`, `.s-Evil { font-family: "Noto Sans SC"; }`, `<span style="font-family: &#34;xstylescriptalert5script&#34;">styled</span>`} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("HTML应该包含 %q, 实际:\n%s", expected, content)
+		}
+	}
+
+	// EPUB的样式表使用相同的规则
+	var epub bytes.Buffer
+	if err := NewEPUBExporter(doc, nil).WriteEPUB(&epub); err != nil {
+		t.Fatalf("EPUB导出失败: %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(epub.Bytes()), int64(epub.Len()))
+	if err != nil {
+		t.Fatalf("EPUB无法读取: %v", err)
+	}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("读取 %s 失败: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if bytes.Contains(data, []byte("<script")) || bytes.Contains(data, []byte("alert(")) {
+			t.Errorf("EPUB的 %s 包含不安全的内容:\n%s", file.Name, data)
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package word
 
 import (
+    "bytes"
     "context"
     "fmt"
+    "image"
     "os"
     "sync"
 
+    "github.com/tanqiangyes/go-word/pkg/types"
     "github.com/tanqiangyes/go-word/pkg/utils"
 )
 
@@ -232,6 +235,95 @@ func (ip *ImageProcessor) InsertImage(ctx context.Context, imageID string, posit
     return nil
 }
 
+// InsertImageIntoDocument 将图片作为单独的段落插入文档末尾，保存文档时写入图片
+func (ip *ImageProcessor) InsertImageIntoDocument(ctx context.Context, doc *Document, imageID string, position *ImageProcessorPosition) error {
+    if err := ip.InsertImage(ctx, imageID, position); err != nil {
+        return err
+    }
+    if doc == nil || doc.mainPart == nil {
+        return utils.NewStructuredDocumentError(utils.ErrDocumentNotFound, "文档主部分未初始化")
+    }
+
+    ip.Mu.RLock()
+    image := ip.Images[imageID].ToImage()
+    ip.Mu.RUnlock()
+
+    paragraph := types.Paragraph{Runs: []types.Run{{Image: &image}}}
+    if !image.IsFloating() {
+        paragraph.Alignment = image.Alignment
+    }
+    if doc.mainPart.Content == nil {
+        doc.mainPart.Content = &types.DocumentContent{}
+    }
+    doc.mainPart.Content.Paragraphs = append(doc.mainPart.Content.Paragraphs, paragraph)
+
+    ip.Logger.Info("图片已插入文档，图片ID: %s, 段落数: %d", imageID, len(doc.mainPart.Content.Paragraphs))
+
+    return nil
+}
+
+// ToImage 转换为文档中的图片。尺寸为96 DPI下的像素，X和Y为相对段落的磅值；
+// 非嵌入型环绕的图片为浮动图片。
+func (img *ImageProcessorImage) ToImage() types.Image {
+    image := types.Image{
+        ID:       img.ID,
+        Path:     img.Path,
+        Data:     img.Data,
+        Format:   string(img.Format),
+        Size:     int64(len(img.Data)),
+        Position: types.ImagePositionInline,
+    }
+    if len(img.Data) > 0 {
+        // 数据已加载时不再读取文件，避免同一路径的图片共用旧数据
+        image.Path = ""
+    }
+    if altText, ok := img.Metadata["alt_text"].(string); ok {
+        image.AltText = altText
+    }
+    if title, ok := img.Metadata["title"].(string); ok {
+        image.Title = title
+    }
+
+    if size := img.Size; size != nil {
+        scaleX, scaleY := size.ScaleX, size.ScaleY
+        if scaleX <= 0 {
+            scaleX = 1
+        }
+        if scaleY <= 0 {
+            scaleY = 1
+        }
+        image.Width = float64(size.Width) * scaleX
+        image.Height = float64(size.Height) * scaleY
+    }
+
+    position := img.Position
+    if position == nil {
+        return image
+    }
+    switch position.Alignment {
+    case ImageProcessorAlignmentLeft, ImageProcessorAlignmentCenter, ImageProcessorAlignmentRight:
+        image.Alignment = string(position.Alignment)
+    }
+    switch position.Wrapping {
+    case ImageProcessorWrappingInline, "":
+        return image
+    case ImageProcessorWrappingThrough:
+        image.Wrapping = "tight"
+    case ImageProcessorWrappingInFront:
+        image.Wrapping = "inFront"
+    default:
+        image.Wrapping = string(position.Wrapping)
+    }
+    image.Position = types.ImagePositionFloating
+    image.Anchor = &types.ImageAnchor{X: position.X, Y: position.Y}
+    if image.Alignment == string(ImageProcessorAlignmentLeft) && position.X != 0 {
+        // 指定了水平偏移时使用偏移而不是左对齐
+        image.Alignment = ""
+    }
+
+    return image
+}
+
 // ResizeImage 调整图片尺寸
 func (ip *ImageProcessor) ResizeImage(ctx context.Context, imageID string, size *ImageProcessorSize) error {
 	ip.Mu.Lock()
@@ -387,6 +479,28 @@ func (ip *ImageProcessor) GetImageThumbnail(ctx context.Context, imageID string,
     return thumbnailData, nil
 }
 
+// GetDocumentThumbnail 获取文档第一页的PNG缩略图，按比例缩放到指定尺寸以内
+func (ip *ImageProcessor) GetDocumentThumbnail(ctx context.Context, doc *Document, width, height int) ([]byte, error) {
+    renderer := NewPageRenderer(doc, nil)
+    thumbnailData, err := renderer.Thumbnail(ctx, width, height)
+    if err != nil {
+        return nil, utils.NewStructuredDocumentError(utils.ErrContentInvalid, fmt.Sprintf("生成文档缩略图失败: %v", err))
+    }
+
+    return thumbnailData, nil
+}
+
+// GetDocumentPageImage 按指定分辨率渲染文档的一页为PNG图片，页码从0开始
+func (ip *ImageProcessor) GetDocumentPageImage(ctx context.Context, doc *Document, page int, dpi float64) ([]byte, error) {
+    renderer := NewPageRenderer(doc, &types.RenderConfig{DPI: dpi})
+    pageData, err := renderer.RenderPNG(ctx, page)
+    if err != nil {
+        return nil, utils.NewStructuredDocumentError(utils.ErrContentInvalid, fmt.Sprintf("渲染文档页面失败: %v", err))
+    }
+
+    return pageData, nil
+}
+
 // DeleteImage 删除图片
 func (ip *ImageProcessor) DeleteImage(ctx context.Context, imageID string) error {
     ip.Mu.Lock()
@@ -463,7 +577,7 @@ func (ip *ImageProcessor) detectImageFormat(data []byte) ImageProcessorImageForm
     }
 
     // 检查文件头
-    header := data[:4]
+    header := data
 
     switch {
     case len(header) >= 2 && header[0] == 0xFF && header[1] == 0xD8:
@@ -486,17 +600,18 @@ func (ip *ImageProcessor) detectImageFormat(data []byte) ImageProcessorImageForm
     return ""
 }
 
-// getImageSize 获取图片尺寸
+// getImageSize 获取图片尺寸，无法解码的图片宽高为0，插入文档时使用其原始尺寸
 func (ip *ImageProcessor) getImageSize(data []byte, format ImageProcessorImageFormat) (*ImageProcessorSize, error) {
-    // 这里应该实现真正的图片尺寸检测
-    // 为了简化，我们返回默认尺寸
-    return &ImageProcessorSize{
-        Width:               800,
-        Height:              600,
+    size := &ImageProcessorSize{
         ScaleX:              1.0,
         ScaleY:              1.0,
         MaintainAspectRatio: true,
-    }, nil
+    }
+    if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+        size.Width = config.Width
+        size.Height = config.Height
+    }
+    return size, nil
 }
 
 // isFormatSupported 检查格式是否支持
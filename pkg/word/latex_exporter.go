@@ -0,0 +1,629 @@
+package word
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+// LaTeXExporter exports a document as LaTeX source
+type LaTeXExporter struct {
+	Document *Document
+	Config   *LaTeXExportConfig
+	Logger   *utils.Logger
+
+	numbering  *parser.WordNumbering
+	styleNames map[string]string
+	footnotes  map[string][]types.Paragraph
+	// 每次导出时重置：提取的图片和文档标题
+	media *exportMedia
+	title string
+}
+
+// LaTeXExportConfig LaTeX导出配置
+type LaTeXExportConfig struct {
+	// DocumentClass is article, report or book. Heading 1 maps to
+	// \chapter for report and book, and to \section otherwise.
+	DocumentClass string `json:"document_class"`
+	// CJK uses the ctex document classes so Chinese text compiles with
+	// XeLaTeX
+	CJK bool `json:"cjk"`
+	// Fragment omits the preamble and the document environment
+	Fragment bool `json:"fragment"`
+	// MediaDir is the directory, relative to the LaTeX file, that
+	// receives the extracted images
+	MediaDir string `json:"media_dir"`
+	// CodeFonts are font names whose runs are exported as \texttt
+	CodeFonts []string `json:"code_fonts"`
+}
+
+// latexMaxListDepth is the deepest list nesting supported by LaTeX
+const latexMaxListDepth = 4
+
+var (
+	latexEscaper = strings.NewReplacer(
+		`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`, `$`, `\$`, `&`, `\&`,
+		`#`, `\#`, `%`, `\%`, `_`, `\_`, `~`, `\textasciitilde{}`, `^`, `\textasciicircum{}`,
+		"\t", `\quad{}`, "\u00a0", "~",
+	)
+	latexURLEscaper = strings.NewReplacer(`\`, `\\`, `#`, `\#`, `%`, `\%`, `{`, `\{`, `}`, `\}`)
+)
+
+// latexListLabels maps numbering formats to enumitem labels
+var latexListLabels = map[string]string{
+	"lowerLetter": `\alph*.`,
+	"upperLetter": `\Alph*.`,
+	"lowerRoman":  `\roman*.`,
+	"upperRoman":  `\Roman*.`,
+}
+
+// NewLaTeXExporter 创建LaTeX导出器
+func NewLaTeXExporter(document *Document, config *LaTeXExportConfig) *LaTeXExporter {
+	if config == nil {
+		config = getDefaultLaTeXConfig()
+	}
+
+	return &LaTeXExporter{
+		Document: document,
+		Config:   config,
+		Logger:   utils.NewLogger(utils.LogLevelInfo, os.Stdout),
+	}
+}
+
+// getDefaultLaTeXConfig 获取默认LaTeX配置
+func getDefaultLaTeXConfig() *LaTeXExportConfig {
+	return &LaTeXExportConfig{
+		DocumentClass: "article",
+		MediaDir:      "media",
+		CodeFonts:     []string{"Consolas", "Courier New", "Courier", "Menlo", "Monaco", "Source Code Pro"},
+	}
+}
+
+// ExportToLaTeX writes the document to outputPath and extracts its images
+// to the media directory next to it
+func (le *LaTeXExporter) ExportToLaTeX(outputPath string) error {
+	// 未设置配置时使用默认配置
+	if le.Config == nil {
+		le.Config = getDefaultLaTeXConfig()
+	}
+	mediaDir := filepath.Join(filepath.Dir(outputPath), filepath.FromSlash(exportMediaDirName(le.Config.MediaDir)))
+
+	content, err := le.render(mediaDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("保存LaTeX文件失败: %w", err)
+	}
+
+	le.Logger.Info("LaTeX导出完成: %s", outputPath)
+	return nil
+}
+
+// ToLaTeX returns the document as LaTeX without extracting images.
+// \includegraphics paths point into the configured media directory.
+func (le *LaTeXExporter) ToLaTeX() (string, error) {
+	return le.render("")
+}
+
+// render converts the document. Images are written to mediaDir unless it is empty.
+func (le *LaTeXExporter) render(mediaDir string) (string, error) {
+	if le.Config == nil {
+		le.Config = getDefaultLaTeXConfig()
+	}
+	if le.Document == nil || le.Document.mainPart == nil || le.Document.mainPart.Content == nil {
+		return "", fmt.Errorf("document content not loaded")
+	}
+
+	le.loadParts()
+	le.media = newExportMedia(le.Document, mediaDir, le.Logger)
+	le.title = le.Document.GetCoreProperties().Title
+
+	var blocks []string
+	var code []string
+	// lists holds the environments of the open nested lists
+	var lists []string
+	var list strings.Builder
+	var listNumID int
+	counters := newListCounters(le.numbering)
+
+	flushCode := func() {
+		if len(code) > 0 {
+			blocks = append(blocks, "\\begin{verbatim}\n"+strings.Join(code, "\n")+"\n\\end{verbatim}")
+			code = nil
+		}
+	}
+	closeLists := func(depth int) {
+		for len(lists) > depth {
+			list.WriteString(strings.Repeat("  ", len(lists)-1) + "\\end{" + lists[len(lists)-1] + "}\n")
+			lists = lists[:len(lists)-1]
+		}
+		if depth == 0 && list.Len() > 0 {
+			blocks = append(blocks, strings.TrimSuffix(list.String(), "\n"))
+			list.Reset()
+		}
+	}
+
+	err := walkBody(le.Document.mainPart.Content, func(paragraph *types.Paragraph, table *types.Table) error {
+		if table != nil {
+			flushCode()
+			closeLists(0)
+			if rendered := le.renderTable(table); rendered != "" {
+				blocks = append(blocks, rendered)
+			}
+			return nil
+		}
+
+		if le.isCodeParagraph(paragraph) {
+			closeLists(0)
+			code = append(code, paragraph.Text)
+			return nil
+		}
+		flushCode()
+
+		if paragraph.NumID == 0 {
+			closeLists(0)
+			if rendered := le.renderParagraph(paragraph); rendered != "" {
+				blocks = append(blocks, rendered)
+			}
+			return nil
+		}
+
+		level := min(max(paragraph.ListLevel, 0), latexMaxListDepth-1)
+		// 另一个列表开始时先结束当前的列表环境
+		if len(lists) > 0 && level == 0 && listNumID != paragraph.NumID {
+			closeLists(0)
+		}
+		if len(lists) == 0 {
+			listNumID = paragraph.NumID
+		}
+		closeLists(level + 1)
+		number := counters.next(paragraph.NumID, level)
+		for len(lists) < level+1 {
+			// 新列表从当前条目的编号开始，跳过的层级从定义的起始值开始
+			start := le.numbering.Start(paragraph.NumID, len(lists))
+			if len(lists) == level {
+				start = number
+			}
+			environment, options := le.listEnvironment(paragraph.NumID, len(lists), start)
+			list.WriteString(strings.Repeat("  ", len(lists)) + "\\begin{" + environment + "}" + options + "\n")
+			lists = append(lists, environment)
+		}
+		list.WriteString(strings.Repeat("  ", len(lists)) + "\\item " + le.renderInlines(paragraph.Runs) + "\n")
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	flushCode()
+	closeLists(0)
+
+	body := strings.Join(blocks, "\n\n")
+	if le.Config.Fragment {
+		if body == "" {
+			return "", nil
+		}
+		return body + "\n", nil
+	}
+
+	var out strings.Builder
+	out.WriteString(le.preamble())
+	out.WriteString("\\begin{document}\n")
+	if le.title != "" {
+		out.WriteString("\\maketitle\n")
+	}
+	if body != "" {
+		out.WriteString("\n" + body + "\n\n")
+	}
+	out.WriteString("\\end{document}\n")
+	return out.String(), nil
+}
+
+// preamble returns the document class, packages and title
+func (le *LaTeXExporter) preamble() string {
+	class := le.Config.DocumentClass
+	switch class {
+	case "report", "book":
+	default:
+		class = "article"
+	}
+
+	var buf strings.Builder
+	if le.Config.CJK {
+		// ctex的文档类对应 ctexart、ctexrep 和 ctexbook
+		ctexClass := map[string]string{"article": "ctexart", "report": "ctexrep", "book": "ctexbook"}[class]
+		buf.WriteString("\\documentclass[UTF8]{" + ctexClass + "}\n")
+	} else {
+		buf.WriteString("\\documentclass{" + class + "}\n")
+		buf.WriteString("\\usepackage[utf8]{inputenc}\n\\usepackage[T1]{fontenc}\n")
+	}
+	buf.WriteString("\\usepackage{graphicx}\n")
+	buf.WriteString("\\usepackage[normalem]{ulem}\n")
+	buf.WriteString("\\usepackage{xcolor}\n")
+	buf.WriteString("\\usepackage{multirow}\n")
+	buf.WriteString("\\usepackage{enumitem}\n")
+	if le.Config.CJK {
+		buf.WriteString("\\AddEnumerateCounter{\\chinese}{\\chinese}{十}\n")
+	}
+	buf.WriteString("\\usepackage{hyperref}\n")
+
+	if le.title != "" {
+		core := le.Document.GetCoreProperties()
+		buf.WriteString("\n\\title{" + escapeLaTeX(le.title) + "}\n")
+		buf.WriteString("\\author{" + escapeLaTeX(core.Creator) + "}\n")
+		buf.WriteString("\\date{}\n")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// loadParts loads numbering, styles and footnotes from the package
+func (le *LaTeXExporter) loadParts() {
+	le.numbering = nil
+	le.styleNames = nil
+	le.footnotes = nil
+
+	if data := le.Document.readPart("word/numbering.xml"); data != nil {
+		if numbering, err := parser.ParseNumbering(data); err == nil {
+			le.numbering = numbering
+		} else {
+			le.Logger.Warning("无法解析编号定义: %v", err)
+		}
+	}
+	if data := le.Document.readPart("word/styles.xml"); data != nil {
+		if names, err := parser.ParseStyleNames(data); err == nil {
+			le.styleNames = names
+		}
+	}
+	if data := le.Document.readPart("word/footnotes.xml"); data != nil {
+		if notes, err := parser.ParseFootnotes(data, le.Document.documentRelationships()); err == nil {
+			le.footnotes = notes
+		} else {
+			le.Logger.Warning("无法解析脚注: %v", err)
+		}
+	}
+}
+
+// isCodeParagraph reports whether the paragraph is preformatted source code
+func (le *LaTeXExporter) isCodeParagraph(paragraph *types.Paragraph) bool {
+	switch paragraph.Style {
+	case "SourceCode", "Code", "HTMLPreformatted", "PlainText":
+		return true
+	}
+	return false
+}
+
+// sectionCommand returns the sectioning command of a heading level
+func (le *LaTeXExporter) sectionCommand(level int) string {
+	commands := []string{"section", "subsection", "subsubsection", "paragraph", "subparagraph"}
+	if le.Config.DocumentClass == "report" || le.Config.DocumentClass == "book" {
+		commands = append([]string{"chapter"}, commands...)
+	}
+	return commands[min(level, len(commands))-1]
+}
+
+// renderParagraph renders a paragraph that is not part of a list or code block
+func (le *LaTeXExporter) renderParagraph(paragraph *types.Paragraph) string {
+	if paragraph.Style == "HorizontalLine" {
+		return `\noindent\rule{\linewidth}{0.4pt}`
+	}
+
+	text := le.renderInlines(paragraph.Runs)
+	if text == "" {
+		return ""
+	}
+
+	// 标题样式作为文档标题
+	if paragraph.Style == "Title" {
+		switch strings.TrimSpace(paragraph.Text) {
+		case le.title:
+			return ""
+		default:
+			if le.title == "" {
+				le.title = strings.TrimSpace(paragraph.Text)
+				return ""
+			}
+		}
+		return "{\\LARGE " + text + "\\par}"
+	}
+
+	if level := headingLevel(paragraph.Style, le.styleNames); level > 0 {
+		return "\\" + le.sectionCommand(level) + "{" + strings.ReplaceAll(text, "\\\\\n", " ") + "}"
+	}
+
+	switch {
+	case paragraph.Style == "Quote" || paragraph.Style == "IntenseQuote":
+		return "\\begin{quote}\n" + text + "\n\\end{quote}"
+	case paragraph.Alignment == "center":
+		return "\\begin{center}\n" + text + "\n\\end{center}"
+	case paragraph.Alignment == "right" || paragraph.Alignment == "end":
+		return "\\begin{flushright}\n" + text + "\n\\end{flushright}"
+	}
+	return text
+}
+
+// listEnvironment returns the environment and enumitem options of a list
+// level that starts at the given number
+func (le *LaTeXExporter) listEnvironment(numID, level, start int) (string, string) {
+	format := le.numbering.Format(numID, level)
+	if format == "" || format == "bullet" || format == "none" {
+		return "itemize", ""
+	}
+
+	var options []string
+	if label, ok := latexListLabels[format]; ok {
+		options = append(options, "label="+label)
+	}
+	if le.Config.CJK && strings.HasPrefix(format, "chinese") {
+		options = append(options, `label=\chinese*、`)
+	}
+	if start != 1 {
+		options = append(options, "start="+strconv.Itoa(start))
+	}
+	if len(options) == 0 {
+		return "enumerate", ""
+	}
+	return "enumerate", "[" + strings.Join(options, ", ") + "]"
+}
+
+// renderTable renders a table as a tabular environment. Horizontally merged
+// cells use \multicolumn and vertically merged cells use \multirow.
+func (le *LaTeXExporter) renderTable(table *types.Table) string {
+	if len(table.Rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range table.Rows {
+		width := 0
+		for _, cell := range row.Cells {
+			width += cellSpan(cell)
+		}
+		columns = max(columns, width)
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	buf.WriteString("\\begin{tabular}{|" + strings.Repeat("l|", columns) + "}\n\\hline\n")
+
+	for r, row := range table.Rows {
+		var cells []string
+		column := 0
+		for c := range row.Cells {
+			cell := &row.Cells[c]
+			span := cellSpan(*cell)
+
+			content := ""
+			switch cell.VMerge {
+			case "continue":
+			case "restart":
+				content = le.renderCell(cell)
+				if rows := latexRowSpan(table, r, column); rows > 1 {
+					content = fmt.Sprintf("\\multirow{%d}{*}{%s}", rows, content)
+				}
+			default:
+				content = le.renderCell(cell)
+			}
+
+			if span > 1 {
+				border := "l|"
+				if column == 0 {
+					border = "|l|"
+				}
+				content = fmt.Sprintf("\\multicolumn{%d}{%s}{%s}", span, border, content)
+			}
+			cells = append(cells, content)
+			column += span
+		}
+		for ; column < columns; column++ {
+			cells = append(cells, "")
+		}
+		buf.WriteString(strings.Join(cells, " & ") + " \\\\\n")
+		buf.WriteString(latexRowRule(table, r, columns) + "\n")
+	}
+
+	buf.WriteString("\\end{tabular}")
+	return "\\begin{center}\n" + buf.String() + "\n\\end{center}"
+}
+
+// latexRowSpan returns the number of rows covered by a cell that starts a
+// vertical merge
+func latexRowSpan(table *types.Table, row, column int) int {
+	rows := 1
+	for next := row + 1; next < len(table.Rows); next++ {
+		below := cellAtColumn(table.Rows[next], column)
+		if below == nil || below.VMerge != "continue" {
+			break
+		}
+		rows++
+	}
+	return rows
+}
+
+// latexRowRule returns the rule below a row. Columns that continue a
+// vertical merge in the next row are not ruled.
+func latexRowRule(table *types.Table, row, columns int) string {
+	if row+1 >= len(table.Rows) {
+		return "\\hline"
+	}
+
+	merged := make([]bool, columns)
+	column := 0
+	for _, cell := range table.Rows[row+1].Cells {
+		span := cellSpan(cell)
+		if cell.VMerge == "continue" {
+			for i := column; i < column+span && i < columns; i++ {
+				merged[i] = true
+			}
+		}
+		column += span
+	}
+
+	var rules []string
+	for start := 0; start < columns; {
+		if merged[start] {
+			start++
+			continue
+		}
+		end := start
+		for end+1 < columns && !merged[end+1] {
+			end++
+		}
+		rules = append(rules, fmt.Sprintf("\\cline{%d-%d}", start+1, end+1))
+		start = end + 1
+	}
+
+	if len(rules) == 1 && rules[0] == fmt.Sprintf("\\cline{1-%d}", columns) {
+		return "\\hline"
+	}
+	return strings.Join(rules, " ")
+}
+
+// renderCell renders the content of a table cell. Multiple paragraphs are
+// stacked with \shortstack because l columns do not wrap.
+func (le *LaTeXExporter) renderCell(cell *types.TableCell) string {
+	if len(cell.Paragraphs) == 0 {
+		return escapeLaTeX(cell.Text)
+	}
+
+	var parts []string
+	for i := range cell.Paragraphs {
+		if text := le.renderInlines(cell.Paragraphs[i].Runs); text != "" {
+			parts = append(parts, strings.ReplaceAll(text, "\\\\\n", "\\\\ "))
+		}
+	}
+	if len(parts) > 1 {
+		return "\\shortstack[l]{" + strings.Join(parts, " \\\\ ") + "}"
+	}
+	return strings.Join(parts, "")
+}
+
+// renderInlines renders runs with formatting, links, images and footnotes
+func (le *LaTeXExporter) renderInlines(runs []types.Run) string {
+	var buf strings.Builder
+
+	for i := 0; i < len(runs); {
+		link := runs[i].Hyperlink
+		if link == "" || runs[i].Image != nil {
+			buf.WriteString(le.renderRun(&runs[i]))
+			i++
+			continue
+		}
+
+		// 同一超链接中的连续内容合并为一个链接
+		var label strings.Builder
+		for i < len(runs) && runs[i].Hyperlink == link && runs[i].Image == nil {
+			label.WriteString(le.renderRun(&runs[i]))
+			i++
+		}
+		text := label.String()
+		if strings.HasPrefix(link, "#") {
+			// 书签没有导出，内部链接只保留文本
+			buf.WriteString(text)
+			continue
+		}
+		if text == "" {
+			buf.WriteString("\\url{" + latexURLEscaper.Replace(link) + "}")
+			continue
+		}
+		buf.WriteString("\\href{" + latexURLEscaper.Replace(link) + "}{" + text + "}")
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+// renderRun renders a single run
+func (le *LaTeXExporter) renderRun(run *types.Run) string {
+	switch {
+	case run.Image != nil:
+		return le.renderImage(run.Image)
+	case run.FootnoteID != "":
+		return le.renderFootnote(run.FootnoteID)
+	case run.Text == "" && !run.Tab && run.Break == "":
+		return ""
+	}
+
+	text := escapeLaTeX(run.Text)
+	text = strings.ReplaceAll(text, "\n", "\\\\\n")
+
+	if isCodeFont(run.FontName, le.Config.CodeFonts) {
+		text = "\\texttt{" + text + "}"
+	}
+	if run.Strike {
+		text = "\\sout{" + text + "}"
+	}
+	if run.Underline && run.Hyperlink == "" {
+		text = "\\uline{" + text + "}"
+	}
+	if run.Italic {
+		text = "\\emph{" + text + "}"
+	}
+	if run.Bold {
+		text = "\\textbf{" + text + "}"
+	}
+	if run.Color != "" && run.Color != "auto" && run.Color != "000000" {
+		text = "\\textcolor[HTML]{" + strings.ToUpper(run.Color) + "}{" + text + "}"
+	}
+
+	// 制表符和分隔符跟在运行文字之后
+	if run.Tab {
+		text += "\\quad{}"
+	}
+	switch run.Break {
+	case "":
+	case "page":
+		text += "\n\\newpage\n"
+	default:
+		text += "\\\\\n"
+	}
+	return text
+}
+
+// renderFootnote renders a footnote reference with the footnote text
+func (le *LaTeXExporter) renderFootnote(id string) string {
+	paragraphs, ok := le.footnotes[id]
+	if !ok {
+		return ""
+	}
+
+	var parts []string
+	for i := range paragraphs {
+		if text := le.renderInlines(paragraphs[i].Runs); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return "\\footnote{" + strings.Join(parts, "\n\n") + "}"
+}
+
+// renderImage extracts an image to the media directory and returns an
+// \includegraphics command sized like the original
+func (le *LaTeXExporter) renderImage(image *types.Image) string {
+	name, _, err := le.media.extract(image)
+	if err != nil {
+		le.Logger.Warning("%v", err)
+		if name == "" {
+			return escapeLaTeX(image.AltText)
+		}
+	}
+
+	options := ""
+	if image.Width > 0 {
+		// 96 DPI像素转换为点
+		options = "[width=" + formatPoints(image.Width*0.75) + "]"
+	}
+	return "\\includegraphics" + options + "{" + exportMediaDirName(le.Config.MediaDir) + "/" + name + "}"
+}
+
+// escapeLaTeX escapes characters with a special meaning in LaTeX
+func escapeLaTeX(text string) string {
+	return latexEscaper.Replace(text)
+}
@@ -0,0 +1,85 @@
+package word
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLaTeXExporterExport(t *testing.T) {
+	doc, err := Open(htmlTestPackage(t))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	outputDir := t.TempDir()
+	outputPath := filepath.Join(outputDir, "doc.tex")
+	if err := NewLaTeXExporter(doc, nil).ExportToLaTeX(outputPath); err != nil {
+		t.Fatalf("LaTeX导出失败: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("读取LaTeX失败: %v", err)
+	}
+	content := string(data)
+
+	for _, expected := range []string{
+		`\documentclass{article}`,
+		`\usepackage{multirow}`,
+		`\section{标题}`,
+		`\begin{center}` + "\n" + `\textcolor[HTML]{FF0000}{\textbf{A \& B}}\href{https://example.com/a b}{site}\footnote{Footnote text}`,
+		"\\begin{enumerate}\n  \\item one\n  \\begin{itemize}\n    \\item nested\n  \\end{itemize}\n  \\item two\n\\end{enumerate}",
+		`\begin{tabular}{|l|l|}`,
+		`\multicolumn{2}{|l|}{Wide} \\`,
+		`\multirow{2}{*}{Tall} & x \\` + "\n" + `\cline{2-2}`,
+		` & y \\` + "\n" + `\hline`,
+		`\includegraphics[width=75pt]{media/image1.png}`,
+		"Tab\\quad{}stop\\\\\n\n\\newpage\nnext",
+		`\end{document}`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("LaTeX应该包含 %q, 实际:\n%s", expected, content)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "media", "image1.png")); err != nil {
+		t.Errorf("图片应该被提取: %v", err)
+	}
+}
+
+func TestLaTeXExporterCJK(t *testing.T) {
+	doc, err := Open(htmlTestPackage(t))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	config := getDefaultLaTeXConfig()
+	config.CJK = true
+	config.DocumentClass = "report"
+
+	content, err := NewLaTeXExporter(doc, config).ToLaTeX()
+	if err != nil {
+		t.Fatalf("LaTeX导出失败: %v", err)
+	}
+
+	for _, expected := range []string{`\documentclass[UTF8]{ctexrep}`, `\chapter{标题}`} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("LaTeX应该包含 %q, 实际:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(content, "inputenc") {
+		t.Error("ctex文档不应该使用inputenc")
+	}
+}
+
+func TestEscapeLaTeX(t *testing.T) {
+	input := `50% of $x_1 & #2 {a} ~ ^ \`
+	expected := `50\% of \$x\_1 \& \#2 \{a\} \textasciitilde{} \textasciicircum{} \textbackslash{}`
+	if got := escapeLaTeX(input); got != expected {
+		t.Errorf("escapeLaTeX(%q) = %q, 期望 %q", input, got, expected)
+	}
+}
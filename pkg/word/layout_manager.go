@@ -1,6 +1,7 @@
 package word
 
 import (
+    "context"
     "fmt"
     "math"
     "time"
@@ -17,6 +18,12 @@ type LayoutManager struct {
     LayoutAlgorithm *LayoutAlgorithm
     Metrics         *LayoutMetrics
     Logger          *utils.Logger
+    // Document 提供样式、编号和图片，可以为空
+    Document *Document
+    // Config 提供字体和没有页面设置的节使用的页面尺寸，为空时使用默认配置
+    Config *types.PDFExportConfig
+    // Tree 是最近一次排版的结果
+    Tree *LayoutTree
 }
 
 // LayoutMetrics 排版性能指标
@@ -482,128 +489,98 @@ func NewLayoutManagerTableLayout() *LayoutManagerTableLayout {
     return tl
 }
 
-// ProcessLayout 处理布局
+// ProcessLayout 处理布局：按节的页面尺寸、页边距和分栏排版段落和表格，
+// 结果保存在 Tree 中
 func (lm *LayoutManager) ProcessLayout(content *types.DocumentContent) error {
-	if content == nil {
-		return fmt.Errorf("文档内容不能为空")
-	}
-
-	startTime := time.Now()
-
-	// 处理段落布局
-	for i := range content.Paragraphs {
-		if err := lm.processParagraphLayout(&content.Paragraphs[i]); err != nil {
-			lm.Metrics.Errors++
-			lm.Logger.Error(fmt.Sprintf("处理段落布局失败: %v", err))
-			return err
-		}
-		lm.Metrics.ElementsPositioned++
-		// 添加微小延迟以确保有可测量的处理时间
-		time.Sleep(time.Microsecond)
-	}
-
-	// 处理表格布局
-	for i := range content.Tables {
-		if err := lm.processTableLayout(&content.Tables[i]); err != nil {
-			lm.Metrics.Errors++
-			lm.Logger.Error(fmt.Sprintf("处理表格布局失败: %v", err))
-			return err
-		}
-		lm.Metrics.ElementsPositioned++
-		// 添加微小延迟以确保有可测量的处理时间
-		time.Sleep(time.Microsecond)
-	}
-
-	lm.Metrics.ProcessingTime = time.Since(startTime)
-	lm.Logger.Info(fmt.Sprintf("布局处理完成，处理了 %d 个元素，耗时 %v",
-		lm.Metrics.ElementsPositioned, lm.Metrics.ProcessingTime))
-
-	return nil
-}
-
-// processParagraphLayout 处理段落布局
-func (lm *LayoutManager) processParagraphLayout(paragraph *types.Paragraph) error {
-    // 计算段落位置
-    if err := lm.calculateParagraphPosition(paragraph); err != nil {
-        return err
+    if content == nil {
+        return fmt.Errorf("文档内容不能为空")
     }
 
-    // 计算段落大小
-    if err := lm.calculateParagraphSize(paragraph); err != nil {
-        return err
-    }
+    startTime := time.Now()
 
-    // 应用段落间距
-    if err := lm.applyParagraphSpacing(paragraph); err != nil {
+    tree, err := lm.layout(context.Background(), lm.Document, content)
+    if err != nil {
+        lm.Metrics.Errors++
+        lm.Logger.Error(fmt.Sprintf("处理布局失败: %v", err))
         return err
     }
+    lm.Tree = tree
+
+    lm.Metrics.ElementsPositioned += int64(len(content.Paragraphs) + len(content.Tables))
+    lm.Metrics.LayoutsCalculated++
+    lm.Metrics.ProcessingTime = time.Since(startTime)
+    lm.Logger.Info(fmt.Sprintf("布局处理完成，处理了 %d 个元素，共 %d 页，耗时 %v",
+        lm.Metrics.ElementsPositioned, len(tree.Pages), lm.Metrics.ProcessingTime))
 
     return nil
 }
 
-// processTableLayout 处理表格布局
-func (lm *LayoutManager) processTableLayout(table *types.Table) error {
-    // 计算表格位置
-    if err := lm.calculateTablePosition(table); err != nil {
-        return err
+// LayoutDocument 排版文档正文并返回排版树，样式、编号和图片从文档读取
+func (lm *LayoutManager) LayoutDocument(ctx context.Context, doc *Document) (*LayoutTree, error) {
+    if doc == nil || doc.mainPart == nil || doc.mainPart.Content == nil {
+        return nil, fmt.Errorf("文档内容不能为空")
     }
 
-    // 计算表格大小
-    if err := lm.calculateTableSize(table); err != nil {
-        return err
+    startTime := time.Now()
+    tree, err := lm.layout(ctx, doc, doc.mainPart.Content)
+    if err != nil {
+        lm.Metrics.Errors++
+        return nil, err
     }
-
-    // 应用表格间距
-    if err := lm.applyTableSpacing(table); err != nil {
-        return err
+    lm.Tree = tree
+    lm.Metrics.LayoutsCalculated++
+    lm.Metrics.ProcessingTime = time.Since(startTime)
+    return tree, nil
+}
+
+// layoutConfig 返回排版配置，节的页面设置优先于配置的页面尺寸和页边距
+func (lm *LayoutManager) layoutConfig() *types.PDFExportConfig {
+    config := getDefaultPDFConfig()
+    if lm.Config != nil {
+        copied := *lm.Config
+        config = &copied
+    } else {
+        // 按已安装字体的字形宽度排版
+        config.FontEmbedding = true
     }
+    config.PageSetupFromDocument = true
+    config.IncludeTables = true
+    // 页眉页脚不影响正文位置
+    config.IncludeHeaders, config.IncludeFooters = false, false
+    config.Tagged = false
+    return config
+}
+
+// layout 用PDF导出的排版引擎排版内容，记录行和单元格的位置后生成排版树
+func (lm *LayoutManager) layout(ctx context.Context, doc *Document, content *types.DocumentContent) (*LayoutTree, error) {
+    config := lm.layoutConfig()
+    layout := newPDFLayout(ctx, config)
+    layout.resolver.logger = lm.Logger
+    layout.record = &pdfLayoutRecord{}
+    if doc != nil {
+        exporter := NewPDFExporter(doc, config)
+        exporter.Logger = lm.Logger
+        layout.media = newExportMedia(doc, "", lm.Logger)
+        exporter.loadLayoutParts(layout)
+    }
+    layout.sections = content.Sections
 
-    return nil
-}
-
-// calculateParagraphPosition 计算段落位置
-func (lm *LayoutManager) calculateParagraphPosition(paragraph *types.Paragraph) error {
-    // 这里将实现段落位置计算逻辑
-    // 包括流式布局、绝对定位等
-    lm.PositionManager.Metrics.PositionsCalculated++
-    return nil
-}
-
-// calculateParagraphSize 计算段落大小
-func (lm *LayoutManager) calculateParagraphSize(paragraph *types.Paragraph) error {
-    // 这里将实现段落大小计算逻辑
-    // 包括自动大小、固定大小等
-    lm.SizeManager.Metrics.SizesCalculated++
-    return nil
-}
-
-// applyParagraphSpacing 应用段落间距
-func (lm *LayoutManager) applyParagraphSpacing(paragraph *types.Paragraph) error {
-    // 这里将实现段落间距应用逻辑
-    // 包括外边距、内边距、行间距等
-    lm.SpacingManager.Metrics.MarginsApplied++
-    return nil
-}
-
-// calculateTablePosition 计算表格位置
-func (lm *LayoutManager) calculateTablePosition(table *types.Table) error {
-    // 这里将实现表格位置计算逻辑
-    lm.PositionManager.Metrics.PositionsCalculated++
-    return nil
-}
-
-// calculateTableSize 计算表格大小
-func (lm *LayoutManager) calculateTableSize(table *types.Table) error {
-    // 这里将实现表格大小计算逻辑
-    lm.SizeManager.Metrics.SizesCalculated++
-    return nil
-}
+    if err := layout.layout(collectBody(content)); err != nil {
+        return nil, err
+    }
+    tree := buildLayoutTree(layout, content)
 
-// applyTableSpacing 应用表格间距
-func (lm *LayoutManager) applyTableSpacing(table *types.Table) error {
-    // 这里将实现表格间距应用逻辑
-    lm.SpacingManager.Metrics.PaddingsApplied++
-    return nil
+    lines, fragments := 0, 0
+    for _, line := range layout.record.lines {
+        lines++
+        fragments += len(line.positions)
+    }
+    lm.PositionManager.Metrics.PositionsCalculated += int64(lines + len(layout.record.cells))
+    lm.SizeManager.Metrics.SizesCalculated += int64(fragments)
+    lm.SpacingManager.Metrics.LineSpacingsApplied += int64(lines)
+    lm.LayoutAlgorithm.Metrics.FlowLayoutsUsed += int64(len(tree.Paragraphs))
+    lm.LayoutAlgorithm.Metrics.TableLayoutsUsed += int64(len(tree.Tables))
+    return tree, nil
 }
 
 // GetMetrics 获取性能指标
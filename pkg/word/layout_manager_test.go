@@ -1,7 +1,9 @@
 package word
 
 import (
+    "context"
     "fmt"
+    "math"
     "testing"
 
     "github.com/tanqiangyes/go-word/pkg/types"
@@ -235,3 +237,89 @@ func TestLayoutManagerErrorHandling(t *testing.T) {
         t.Error("nil内容应该被拒绝")
     }
 }
+
+// TestLayoutDocument 测试按节的页面设置生成排版树
+func TestLayoutDocument(t *testing.T) {
+    body := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Left column</w:t></w:r><w:r><w:br w:type="column"/></w:r><w:r><w:t>Right column</w:t></w:r></w:p>
+<w:p><w:pPr><w:sectPr><w:pgSz w:w="12240" w:h="15840"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440"/>
+<w:cols w:num="2" w:space="720"/></w:sectPr></w:pPr><w:r><w:t>End of section one</w:t></w:r></w:p>
+<w:p><w:r><w:t>7.2 Termination</w:t></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Cell A</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Cell B</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+<w:sectPr><w:pgSz w:w="16838" w:h="11906" w:orient="landscape"/><w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134"/>
+<w:pgNumType w:fmt="lowerRoman" w:start="1"/></w:sectPr>
+</w:body></w:document>`
+    doc, err := Open(writeTestPackage(t, map[string]string{"word/document.xml": body}))
+    if err != nil {
+        t.Fatalf("打开文档失败: %v", err)
+    }
+    defer doc.Close()
+
+    tree, err := NewLayoutManager().LayoutDocument(context.Background(), doc)
+    if err != nil {
+        t.Fatalf("排版失败: %v", err)
+    }
+    near := func(a, b float64) bool { return math.Abs(a-b) < 0.1 }
+
+    if len(tree.Pages) != 2 {
+        t.Fatalf("期望 2 页，实际 %d 页", len(tree.Pages))
+    }
+    if first, second := tree.Pages[0], tree.Pages[1]; first.Width != 612 || first.Height != 792 || !near(second.Width, 841.9) || !near(second.Height, 595.3) {
+        t.Errorf("页面尺寸错误: %+v %+v", first, second)
+    }
+    if tree.Pages[0].Number != "1" || tree.Pages[1].Number != "i" || tree.Pages[1].Section != 1 {
+        t.Errorf("页码错误: %q %q", tree.Pages[0].Number, tree.Pages[1].Number)
+    }
+
+    // 分栏符后的文字在第二栏顶部：栏宽 (468-36)/2 = 216
+    found := tree.FindText("Right column")
+    if len(found) != 1 || len(found[0].Lines) != 2 {
+        t.Fatalf("分栏段落应该有两行: %+v", found)
+    }
+    left, right := found[0].Lines[0], found[0].Lines[1]
+    if left.Page != 1 || !near(left.X, 72) || !near(left.Y, 72) {
+        t.Errorf("第一栏的行位置错误: %+v", left.LayoutBox)
+    }
+    if right.Page != 1 || !near(right.X, 324) || !near(right.Y, 72) {
+        t.Errorf("第二栏的行位置错误: %+v", right.LayoutBox)
+    }
+    if len(right.Fragments) != 2 || right.Fragments[0].Text != "Right" || right.Fragments[0].Run != 2 || right.Fragments[1].X <= right.Fragments[0].X+right.Fragments[0].Width {
+        t.Errorf("行内片段错误: %+v", right.Fragments)
+    }
+    if end := tree.FindText("End of section one"); len(end) != 1 || !near(end[0].X, 324) || end[0].Y <= right.Y {
+        t.Errorf("第一节的最后一段应该在第二栏: %+v", end)
+    }
+
+    // 第二节从新的一页开始，使用自己的页边距
+    clause := tree.FindText("7.2")
+    if len(clause) != 1 || clause[0].Page != 2 || clause[0].Index != 2 || !near(clause[0].X, 56.7) || !near(clause[0].Y, 56.7) {
+        t.Fatalf("条款位置错误: %+v", clause)
+    }
+    if tree.PageOf(clause[0].Paragraph) != 2 {
+        t.Errorf("条款应该在第 2 页")
+    }
+
+    if len(tree.Tables) != 1 || len(tree.Tables[0].Cells) != 2 {
+        t.Fatalf("表格排版错误: %+v", tree.Tables)
+    }
+    table := tree.Tables[0]
+    a, b := table.Cells[0], table.Cells[1]
+    if table.Page != 2 || table.Index != 0 || len(a.Boxes) != 1 || !near(a.Boxes[0].X, 56.7) || a.Boxes[0].Y <= clause[0].Y {
+        t.Errorf("单元格位置错误: %+v", a.Boxes)
+    }
+    if b.Column != 1 || b.Boxes[0].X <= a.Boxes[0].X || len(b.Paragraphs) != 1 || b.Paragraphs[0].Index != -1 || b.Paragraphs[0].Lines[0].Fragments[1].Text != "B" {
+        t.Errorf("第二个单元格错误: %+v", b)
+    }
+    if cell := tree.FindText("Cell B"); len(cell) != 1 || cell[0].Page != 2 {
+        t.Errorf("应该能找到单元格中的文字")
+    }
+
+    stats, err := NewEnhancedDocumentBuilder().GetDocumentStatistics(doc)
+    if err != nil {
+        t.Fatalf("获取统计信息失败: %v", err)
+    }
+    if stats.TotalPages != 2 || stats.TotalSections != 2 || stats.TotalTables != 1 {
+        t.Errorf("统计信息错误: %+v", stats)
+    }
+}
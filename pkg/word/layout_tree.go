@@ -0,0 +1,272 @@
+package word
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// LayoutTree 排版树。坐标以磅为单位，原点在页面左上角，页码从1开始
+type LayoutTree struct {
+	Pages []*LayoutPage
+	// Paragraphs 是正文段落，表格中的段落在单元格中
+	Paragraphs []*LayoutParagraph
+	Tables     []*LayoutTable
+	Images     []*LayoutImage
+}
+
+// LayoutPage 页面
+type LayoutPage struct {
+	// Index 是从1开始的物理页码
+	Index int
+	// Number 是页码域显示的页码，按节的页码格式和起始页码计算
+	Number  string
+	Section int
+	Width   float64
+	Height  float64
+}
+
+// LayoutBox 元素在页面上的区域
+type LayoutBox struct {
+	Page   int
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// LayoutParagraph 段落。区域是第一页上各行的范围，跨页的段落到 LastPage 结束
+type LayoutParagraph struct {
+	LayoutBox
+	Paragraph *types.Paragraph
+	// Index 是正文段落的下标，表格中的段落为-1
+	Index    int
+	LastPage int
+	Lines    []*LayoutLine
+}
+
+// LayoutLine 行
+type LayoutLine struct {
+	LayoutBox
+	// Baseline 是基线到页面顶部的距离
+	Baseline  float64
+	Fragments []*LayoutFragment
+}
+
+// LayoutFragment 行内片段：同一格式的一段文字或一张图片
+type LayoutFragment struct {
+	LayoutBox
+	// Run 是所属运行在段落中的下标，列表编号为-1
+	Run   int
+	Text  string
+	Image *types.Image
+}
+
+// LayoutTable 表格
+type LayoutTable struct {
+	Table *types.Table
+	// Index 是正文表格的下标
+	Index    int
+	Page     int
+	LastPage int
+	Cells    []*LayoutCell
+}
+
+// LayoutCell 单元格。跨页或跨行合并的单元格在每一页上各有一个区域
+type LayoutCell struct {
+	Cell       *types.TableCell
+	Row        int
+	Column     int
+	Boxes      []LayoutBox
+	Paragraphs []*LayoutParagraph
+}
+
+// LayoutImage 图片
+type LayoutImage struct {
+	LayoutBox
+	Image *types.Image
+}
+
+// TotalPages 返回页数
+func (t *LayoutTree) TotalPages() int {
+	return len(t.Pages)
+}
+
+// PageOf 返回段落第一行所在的页码，段落不在排版树中时返回0
+func (t *LayoutTree) PageOf(paragraph *types.Paragraph) int {
+	for _, p := range t.allParagraphs() {
+		if p.Paragraph == paragraph {
+			return p.Page
+		}
+	}
+	return 0
+}
+
+// FindText 返回包含指定文字的段落，包括表格中的段落，按排版顺序排列
+func (t *LayoutTree) FindText(text string) []*LayoutParagraph {
+	var found []*LayoutParagraph
+	for _, p := range t.allParagraphs() {
+		if strings.Contains(layoutParagraphText(p.Paragraph), text) {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// allParagraphs 返回正文和表格中的段落
+func (t *LayoutTree) allParagraphs() []*LayoutParagraph {
+	paragraphs := append([]*LayoutParagraph(nil), t.Paragraphs...)
+	for _, table := range t.Tables {
+		for _, cell := range table.Cells {
+			paragraphs = append(paragraphs, cell.Paragraphs...)
+		}
+	}
+	return paragraphs
+}
+
+// layoutParagraphText 返回段落文字，没有段落文字时拼接运行的文字
+func layoutParagraphText(paragraph *types.Paragraph) string {
+	if paragraph.Text != "" {
+		return paragraph.Text
+	}
+	var text strings.Builder
+	for _, run := range paragraph.Runs {
+		text.WriteString(run.Text)
+	}
+	return text.String()
+}
+
+// buildLayoutTree 把排版时记录的行和单元格位置转换为排版树
+func buildLayoutTree(layout *pdfLayout, content *types.DocumentContent) *LayoutTree {
+	tree := &LayoutTree{}
+
+	var numbers []int
+	if len(layout.sections) > 0 {
+		numbers, _ = layout.pageNumbers()
+	}
+	for i, page := range layout.pages {
+		number := strconv.Itoa(i + 1)
+		section := 0
+		if numbers != nil {
+			section = layout.pageSections[i]
+			number = formatPageNumber(numbers[i], layout.sections[section].PageNumberFormat)
+		}
+		tree.Pages = append(tree.Pages, &LayoutPage{Index: i + 1, Number: number, Section: section, Width: page.width, Height: page.height})
+	}
+	// top 把PDF坐标转换为到页面顶部的距离
+	top := func(page int, y float64) float64 {
+		return layout.pages[page].height - y
+	}
+
+	bodyParagraphs := make(map[*types.Paragraph]int)
+	for i := range content.Paragraphs {
+		bodyParagraphs[&content.Paragraphs[i]] = i
+	}
+	bodyTables := make(map[*types.Table]int)
+	for i := range content.Tables {
+		bodyTables[&content.Tables[i]] = i
+	}
+
+	// 单元格按记录顺序创建，单元格中的行归属于单元格
+	tables := make(map[*types.Table]*LayoutTable)
+	cells := make(map[*pdfTableCell]*LayoutCell)
+	lineCells := make(map[*pdfLine]*LayoutCell)
+	for _, box := range layout.record.cells {
+		table, ok := tables[box.table]
+		if !ok {
+			index, body := bodyTables[box.table]
+			if !body {
+				index = -1
+			}
+			table = &LayoutTable{Table: box.table, Index: index, Page: box.page + 1}
+			tables[box.table] = table
+			tree.Tables = append(tree.Tables, table)
+		}
+		table.LastPage = box.page + 1
+
+		cell, ok := cells[box.cell]
+		if !ok {
+			cell = &LayoutCell{Cell: box.cell.cell, Row: box.cell.firstRow, Column: box.cell.column}
+			cells[box.cell] = cell
+			table.Cells = append(table.Cells, cell)
+			for _, placed := range box.cell.lines {
+				lineCells[placed.line] = cell
+			}
+		}
+		area := LayoutBox{Page: box.page + 1, X: box.cell.x, Y: top(box.page, box.top), Width: box.cell.width, Height: box.height}
+		// 合并单元格在同一页上相邻行的区域合为一个
+		if n := len(cell.Boxes); n > 0 && cell.Boxes[n-1].Page == area.Page && math.Abs(cell.Boxes[n-1].Y+cell.Boxes[n-1].Height-area.Y) < 0.01 {
+			cell.Boxes[n-1].Height += area.Height
+		} else {
+			cell.Boxes = append(cell.Boxes, area)
+		}
+	}
+
+	paragraphs := make(map[*types.Paragraph]*LayoutParagraph)
+	for _, box := range layout.record.lines {
+		line := &LayoutLine{
+			LayoutBox: LayoutBox{Page: box.page + 1, Y: top(box.page, box.top), Height: box.height},
+			Baseline:  top(box.page, box.baseline),
+		}
+		left, right := math.Inf(1), math.Inf(-1)
+		for i, fragment := range box.line.fragments {
+			x := box.positions[i]
+			width := fragment.width
+			if i+1 < len(box.positions) {
+				width = box.positions[i+1] - x
+			}
+			if fragment.kind != pdfTextFragment && fragment.kind != pdfImageFragment {
+				continue
+			}
+			left, right = math.Min(left, x), math.Max(right, x+width)
+
+			// 片段区域与链接区域相同：文字从基线以下四分之一字号到字号的0.9倍
+			height := math.Max(fragment.size*0.9, fragment.height)
+			bottom := fragment.size * 0.25
+			if fragment.kind == pdfImageFragment {
+				bottom = 0
+			}
+			item := &LayoutFragment{
+				LayoutBox: LayoutBox{Page: box.page + 1, X: x, Y: top(box.page, box.baseline+height), Width: width, Height: height + bottom},
+				Run:       fragment.run,
+				Text:      fragment.text,
+			}
+			if fragment.kind == pdfImageFragment && fragment.run >= 0 && fragment.run < len(box.line.paragraph.Runs) {
+				item.Image = box.line.paragraph.Runs[fragment.run].Image
+				tree.Images = append(tree.Images, &LayoutImage{LayoutBox: item.LayoutBox, Image: item.Image})
+			}
+			line.Fragments = append(line.Fragments, item)
+		}
+		if len(line.Fragments) > 0 {
+			line.X, line.Width = left, right-left
+		} else if len(box.positions) > 0 {
+			line.X = box.positions[0]
+		}
+
+		paragraph, ok := paragraphs[box.line.paragraph]
+		if !ok {
+			index, body := bodyParagraphs[box.line.paragraph]
+			if !body {
+				index = -1
+			}
+			paragraph = &LayoutParagraph{LayoutBox: line.LayoutBox, Paragraph: box.line.paragraph, Index: index}
+			paragraphs[box.line.paragraph] = paragraph
+			if cell := lineCells[box.line]; cell != nil {
+				cell.Paragraphs = append(cell.Paragraphs, paragraph)
+			} else if body {
+				tree.Paragraphs = append(tree.Paragraphs, paragraph)
+			}
+		} else if paragraph.Page == line.Page {
+			// 第一页上的段落区域包含该页上的所有行
+			x := math.Min(paragraph.X, line.X)
+			paragraph.Width = math.Max(paragraph.X+paragraph.Width, line.X+line.Width) - x
+			paragraph.X = x
+			paragraph.Height = line.Y + line.Height - paragraph.Y
+		}
+		paragraph.LastPage = line.Page
+		paragraph.Lines = append(paragraph.Lines, line)
+	}
+	return tree
+}
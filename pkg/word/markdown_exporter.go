@@ -0,0 +1,543 @@
+package word
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+// MarkdownExporter exports a document as GitHub-flavored Markdown
+type MarkdownExporter struct {
+	Document *Document
+	Config   *MarkdownExportConfig
+	Logger   *utils.Logger
+
+	numbering  *parser.WordNumbering
+	styleNames map[string]string
+	footnotes  map[string][]types.Paragraph
+	// 导出过程中的状态
+	usedNotes []string
+	media     *exportMedia
+}
+
+// MarkdownExportConfig Markdown导出配置
+type MarkdownExportConfig struct {
+	// MediaDir is the directory, relative to the Markdown file, that
+	// receives the extracted images
+	MediaDir string `json:"media_dir"`
+	// CodeFonts are font names whose runs are exported as inline code
+	CodeFonts []string `json:"code_fonts"`
+}
+
+// markdownListIndent is the indentation of each nested list level
+const markdownListIndent = "    "
+
+var (
+	orderedLinePattern = regexp.MustCompile(`^(\d+)([.)])`)
+	markdownEscaper    = strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+		`<`, `\<`, `|`, `\|`, `~`, `\~`,
+	)
+)
+
+// NewMarkdownExporter 创建Markdown导出器
+func NewMarkdownExporter(document *Document, config *MarkdownExportConfig) *MarkdownExporter {
+	if config == nil {
+		config = getDefaultMarkdownConfig()
+	}
+
+	return &MarkdownExporter{
+		Document: document,
+		Config:   config,
+		Logger:   utils.NewLogger(utils.LogLevelInfo, os.Stdout),
+	}
+}
+
+// getDefaultMarkdownConfig 获取默认Markdown配置
+func getDefaultMarkdownConfig() *MarkdownExportConfig {
+	return &MarkdownExportConfig{
+		MediaDir:  "media",
+		CodeFonts: []string{"Consolas", "Courier New", "Courier", "Menlo", "Monaco", "Source Code Pro"},
+	}
+}
+
+// ExportToMarkdown writes the document to outputPath and extracts its
+// images to the media directory next to it
+func (me *MarkdownExporter) ExportToMarkdown(outputPath string) error {
+	// 未设置配置时使用默认配置
+	if me.Config == nil {
+		me.Config = getDefaultMarkdownConfig()
+	}
+	mediaDir := filepath.Join(filepath.Dir(outputPath), filepath.FromSlash(exportMediaDirName(me.Config.MediaDir)))
+
+	markdown, err := me.render(mediaDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(outputPath, []byte(markdown), 0644); err != nil {
+		return fmt.Errorf("failed to write markdown file: %w", err)
+	}
+
+	me.Logger.Info("Markdown导出完成: %s", outputPath)
+	return nil
+}
+
+// ToMarkdown returns the document as Markdown without extracting images.
+// Image references point into the configured media directory.
+func (me *MarkdownExporter) ToMarkdown() (string, error) {
+	return me.render("")
+}
+
+// render converts the document. Images are written to mediaDir unless it is empty.
+func (me *MarkdownExporter) render(mediaDir string) (string, error) {
+	if me.Config == nil {
+		me.Config = getDefaultMarkdownConfig()
+	}
+	if me.Document == nil || me.Document.mainPart == nil || me.Document.mainPart.Content == nil {
+		return "", fmt.Errorf("document content not loaded")
+	}
+
+	me.loadParts()
+	me.usedNotes = nil
+	me.media = newExportMedia(me.Document, mediaDir, me.Logger)
+
+	var blocks []string
+	var list []string
+	var listNumID int
+	var code []string
+	counters := newListCounters(me.numbering)
+
+	flushList := func() {
+		if len(list) > 0 {
+			blocks = append(blocks, strings.Join(list, "\n"))
+			list = nil
+		}
+	}
+	flushCode := func() {
+		if len(code) > 0 {
+			body := strings.Join(code, "\n")
+			fence := strings.Repeat("`", max(3, longestBacktickRun(body)+1))
+			blocks = append(blocks, fence+"\n"+body+"\n"+fence)
+			code = nil
+		}
+	}
+
+	err := walkBody(me.Document.mainPart.Content, func(paragraph *types.Paragraph, table *types.Table) error {
+		if table != nil {
+			flushList()
+			flushCode()
+			if rendered := me.renderTable(table); rendered != "" {
+				blocks = append(blocks, rendered)
+			}
+			return nil
+		}
+
+		if me.isCodeParagraph(paragraph) {
+			flushList()
+			code = append(code, paragraph.Text)
+			return nil
+		}
+		flushCode()
+
+		if paragraph.NumID > 0 {
+			// 相邻的不同列表需要分开
+			if paragraph.ListLevel == 0 && paragraph.NumID != listNumID {
+				flushList()
+			}
+			if len(list) == 0 {
+				listNumID = paragraph.NumID
+			}
+			list = append(list, me.renderListItem(paragraph, counters))
+			return nil
+		}
+		flushList()
+
+		if rendered := me.renderParagraph(paragraph); rendered != "" {
+			blocks = append(blocks, rendered)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	flushList()
+	flushCode()
+
+	// 脚注
+	for _, id := range me.usedNotes {
+		var parts []string
+		for i := range me.footnotes[id] {
+			if text := me.renderInlines(me.footnotes[id][i].Runs); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		blocks = append(blocks, fmt.Sprintf("[^%s]: %s", id, strings.Join(parts, " ")))
+	}
+
+	if len(blocks) == 0 {
+		return "", nil
+	}
+	return strings.Join(blocks, "\n\n") + "\n", nil
+}
+
+// loadParts loads numbering, styles and footnotes from the package
+func (me *MarkdownExporter) loadParts() {
+	me.numbering = nil
+	me.styleNames = nil
+	me.footnotes = nil
+
+	if data := me.Document.readPart("word/numbering.xml"); data != nil {
+		if numbering, err := parser.ParseNumbering(data); err == nil {
+			me.numbering = numbering
+		} else {
+			me.Logger.Warning("无法解析编号定义: %v", err)
+		}
+	}
+	if data := me.Document.readPart("word/styles.xml"); data != nil {
+		if names, err := parser.ParseStyleNames(data); err == nil {
+			me.styleNames = names
+		}
+	}
+	if data := me.Document.readPart("word/footnotes.xml"); data != nil {
+		if notes, err := parser.ParseFootnotes(data, me.Document.documentRelationships()); err == nil {
+			me.footnotes = notes
+		} else {
+			me.Logger.Warning("无法解析脚注: %v", err)
+		}
+	}
+}
+
+// isCodeParagraph reports whether the paragraph is preformatted source code
+func (me *MarkdownExporter) isCodeParagraph(paragraph *types.Paragraph) bool {
+	switch paragraph.Style {
+	case "SourceCode", "Code", "HTMLPreformatted", "PlainText":
+		return true
+	}
+	return false
+}
+
+// renderParagraph renders a paragraph that is not part of a list or code block
+func (me *MarkdownExporter) renderParagraph(paragraph *types.Paragraph) string {
+	if paragraph.Style == "HorizontalLine" {
+		return "---"
+	}
+
+	text := me.renderInlines(paragraph.Runs)
+	if text == "" {
+		return ""
+	}
+
+	if level := headingLevel(paragraph.Style, me.styleNames); level > 0 {
+		return strings.Repeat("#", level) + " " + strings.ReplaceAll(text, "\n", " ")
+	}
+
+	text = escapeLineStart(text)
+	if paragraph.Style == "Quote" || paragraph.Style == "IntenseQuote" {
+		return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+	}
+	return text
+}
+
+// renderListItem renders a numbered or bulleted list item and advances the
+// counters of its list
+func (me *MarkdownExporter) renderListItem(paragraph *types.Paragraph, counters *listCounters) string {
+	level := paragraph.ListLevel
+	if level < 0 {
+		level = 0
+	}
+
+	number := counters.next(paragraph.NumID, level)
+	marker := "-"
+	if format := me.numbering.Format(paragraph.NumID, level); format != "" && format != "bullet" && format != "none" {
+		marker = strconv.Itoa(number) + "."
+	}
+
+	text := me.renderInlines(paragraph.Runs)
+	indent := strings.Repeat(markdownListIndent, level)
+	text = strings.ReplaceAll(text, "\n", "\n"+indent+markdownListIndent)
+	return indent + marker + " " + text
+}
+
+// renderTable renders a table as a GFM pipe table. The first row is used
+// as header because GFM tables always have one.
+func (me *MarkdownExporter) renderTable(table *types.Table) string {
+	if len(table.Rows) == 0 {
+		return ""
+	}
+
+	columns := table.Columns
+	for _, row := range table.Rows {
+		width := 0
+		for _, cell := range row.Cells {
+			width += cellSpan(cell)
+		}
+		columns = max(columns, width)
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	var lines []string
+	for i, row := range table.Rows {
+		// GFM没有合并单元格，合并的列用空单元格补齐
+		cells := make([]string, columns)
+		for j, column := 0, 0; j < len(row.Cells) && column < columns; j++ {
+			cells[column] = me.renderCell(&row.Cells[j], i == 0)
+			column += cellSpan(row.Cells[j])
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+
+		if i == 0 {
+			separators := make([]string, columns)
+			for j := range separators {
+				separators[j] = "---"
+				if cell := cellAtColumn(row, j); cell != nil && len(cell.Paragraphs) > 0 {
+					switch cell.Paragraphs[0].Alignment {
+					case "center":
+						separators[j] = ":---:"
+					case "right", "end":
+						separators[j] = "---:"
+					}
+				}
+			}
+			lines = append(lines, "| "+strings.Join(separators, " | ")+" |")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderCell renders the content of a table cell on a single line. Bold is
+// dropped in the header row, which Markdown renderers already emphasize.
+func (me *MarkdownExporter) renderCell(cell *types.TableCell, header bool) string {
+	if len(cell.Paragraphs) == 0 {
+		return escapeMarkdown(cell.Text)
+	}
+
+	var parts []string
+	for i := range cell.Paragraphs {
+		runs := cell.Paragraphs[i].Runs
+		if header {
+			runs = append([]types.Run(nil), runs...)
+			for j := range runs {
+				runs[j].Bold = false
+			}
+		}
+		if text := me.renderInlines(runs); text != "" {
+			parts = append(parts, strings.ReplaceAll(text, "\n", "<br>"))
+		}
+	}
+	return strings.Join(parts, "<br>")
+}
+
+// renderInlines renders runs with emphasis, code spans, links, images and
+// footnote references
+func (me *MarkdownExporter) renderInlines(runs []types.Run) string {
+	var buf strings.Builder
+
+	for i := 0; i < len(runs); {
+		// 同一超链接中的连续文本合并为一个链接
+		if link := runs[i].Hyperlink; link != "" && runs[i].Image == nil {
+			j := i
+			for j < len(runs) && runs[j].Hyperlink == link && runs[j].Image == nil && runs[j].FootnoteID == "" {
+				j++
+			}
+			if j == i {
+				j = i + 1
+			}
+			label := me.renderTextRuns(runs[i:j])
+			if label == "" {
+				label = escapeMarkdown(link)
+			}
+			buf.WriteString("[" + label + "](" + markdownDestination(link) + ")")
+			i = j
+			continue
+		}
+
+		run := runs[i]
+		switch {
+		case run.Image != nil:
+			buf.WriteString(me.renderImage(run.Image))
+			i++
+		case run.FootnoteID != "":
+			if _, ok := me.footnotes[run.FootnoteID]; ok {
+				me.useFootnote(run.FootnoteID)
+				buf.WriteString("[^" + run.FootnoteID + "]")
+			}
+			i++
+		default:
+			j := i
+			for j < len(runs) && runs[j].Hyperlink == "" && runs[j].Image == nil && runs[j].FootnoteID == "" {
+				j++
+			}
+			buf.WriteString(me.renderTextRuns(runs[i:j]))
+			i = j
+		}
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+// renderTextRuns renders formatted text runs, merging adjacent runs that
+// share the same emphasis so that markers are not repeated
+func (me *MarkdownExporter) renderTextRuns(runs []types.Run) string {
+	type segment struct {
+		text                       string
+		bold, italic, strike, code bool
+		// raw segments are Markdown for tabs and breaks
+		raw bool
+	}
+
+	var segments []segment
+	for _, run := range runs {
+		seg := segment{
+			text:   run.Text,
+			bold:   run.Bold,
+			italic: run.Italic,
+			strike: run.Strike,
+			code:   isCodeFont(run.FontName, me.Config.CodeFonts),
+		}
+		// 没有文字的运行可能只有制表符或分隔符
+		if seg.text != "" {
+			n := len(segments)
+			if n > 0 && !segments[n-1].raw && segments[n-1].bold == seg.bold && segments[n-1].italic == seg.italic &&
+				segments[n-1].strike == seg.strike && segments[n-1].code == seg.code {
+				segments[n-1].text += seg.text
+			} else {
+				segments = append(segments, seg)
+			}
+		}
+
+		// 制表符输出为全角空格，分隔符输出为硬换行
+		if run.Tab {
+			segments = append(segments, segment{text: "&emsp;", raw: true})
+		}
+		if run.Break != "" {
+			segments = append(segments, segment{text: "  \n", raw: true})
+		}
+	}
+
+	var buf strings.Builder
+	for _, seg := range segments {
+		if seg.raw {
+			buf.WriteString(seg.text)
+			continue
+		}
+		if seg.code {
+			buf.WriteString(codeSpan(seg.text))
+			continue
+		}
+
+		// 强调标记不能紧挨空白，把首尾空白移到标记外
+		trimmed := strings.TrimSpace(seg.text)
+		if trimmed == "" {
+			buf.WriteString(seg.text)
+			continue
+		}
+		leading := seg.text[:strings.Index(seg.text, trimmed)]
+		trailing := seg.text[len(leading)+len(trimmed):]
+
+		open, close := "", ""
+		if seg.strike {
+			open, close = open+"~~", "~~"+close
+		}
+		if seg.bold {
+			open, close = open+"**", "**"+close
+		}
+		if seg.italic {
+			open, close = open+"*", "*"+close
+		}
+
+		buf.WriteString(leading + open + escapeMarkdown(trimmed) + close + trailing)
+	}
+
+	return buf.String()
+}
+
+// useFootnote records a referenced footnote so that its text is emitted
+func (me *MarkdownExporter) useFootnote(id string) {
+	for _, used := range me.usedNotes {
+		if used == id {
+			return
+		}
+	}
+	me.usedNotes = append(me.usedNotes, id)
+}
+
+// renderImage extracts an image to the media directory and returns its reference
+func (me *MarkdownExporter) renderImage(image *types.Image) string {
+	name, _, err := me.media.extract(image)
+	if err != nil {
+		me.Logger.Warning("%v", err)
+		if name == "" {
+			return escapeMarkdown(image.AltText)
+		}
+	}
+
+	reference := "![" + escapeMarkdown(image.AltText) + "](" + markdownDestination(exportMediaDirName(me.Config.MediaDir)+"/"+name)
+	if image.Title != "" {
+		reference += ` "` + strings.ReplaceAll(image.Title, `"`, `\"`) + `"`
+	}
+	return reference + ")"
+}
+
+// escapeMarkdown escapes characters with a special meaning in Markdown
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// escapeLineStart escapes block markers at the start of a paragraph so
+// that text is not mistaken for a heading, list or quote
+func escapeLineStart(text string) string {
+	switch {
+	case strings.HasPrefix(text, "#"), strings.HasPrefix(text, "+"), strings.HasPrefix(text, ">"),
+		strings.HasPrefix(text, "- "), strings.HasPrefix(text, "="):
+		return `\` + text
+	}
+	if match := orderedLinePattern.FindStringSubmatchIndex(text); match != nil {
+		return text[:match[3]] + `\` + text[match[3]:]
+	}
+	return text
+}
+
+// longestBacktickRun returns the length of the longest run of backticks
+func longestBacktickRun(text string) int {
+	longest, current := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			current++
+			if current > longest {
+				longest = current
+			}
+		} else {
+			current = 0
+		}
+	}
+	return longest
+}
+
+// codeSpan wraps text in enough backticks to contain the backticks it holds
+func codeSpan(text string) string {
+	fence := strings.Repeat("`", longestBacktickRun(text)+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return fence + " " + text + " " + fence
+	}
+	return fence + text + fence
+}
+
+// markdownDestination formats a link destination, wrapping it in angle
+// brackets when it contains spaces or parentheses
+func markdownDestination(destination string) string {
+	if strings.ContainsAny(destination, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(destination) + ">"
+	}
+	return destination
+}
@@ -0,0 +1,163 @@
+package word
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestPackage writes a minimal docx package with the given parts
+func writeTestPackage(t *testing.T, parts map[string]string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "test.docx")
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("创建测试文档失败: %v", err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("写入部件失败: %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("写入部件失败: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("关闭测试文档失败: %v", err)
+	}
+
+	return filename
+}
+
+const markdownTestDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+  xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+  xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"
+  xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">
+  <w:body>
+    <w:p><w:pPr><w:pStyle w:val="1"/></w:pPr><w:r><w:t>标题</w:t></w:r></w:p>
+    <w:p>
+      <w:r><w:t xml:space="preserve">Plain </w:t></w:r>
+      <w:r><w:rPr><w:b/></w:rPr><w:t>bold</w:t></w:r>
+      <w:r><w:t xml:space="preserve">, </w:t></w:r>
+      <w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">italic </w:t></w:r>
+      <w:r><w:rPr><w:strike/></w:rPr><w:t>gone</w:t></w:r>
+      <w:r><w:t xml:space="preserve"> </w:t></w:r>
+      <w:hyperlink r:id="rId10"><w:r><w:t>site</w:t></w:r></w:hyperlink>
+      <w:r><w:rPr><w:vertAlign w:val="superscript"/></w:rPr><w:footnoteReference w:id="1"/></w:r>
+      <w:r><w:t xml:space="preserve"> a*b</w:t></w:r>
+    </w:p>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>one</w:t></w:r></w:p>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>nested</w:t></w:r></w:p>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>two</w:t></w:r></w:p>
+    <w:tbl>
+      <w:tr><w:trPr><w:tblHeader/></w:trPr>
+        <w:tc><w:p><w:r><w:t>Name</w:t></w:r></w:p></w:tc>
+        <w:tc><w:p><w:pPr><w:jc w:val="right"/></w:pPr><w:r><w:t>Value</w:t></w:r></w:p></w:tc>
+      </w:tr>
+      <w:tr>
+        <w:tc><w:p><w:r><w:t>a|b</w:t></w:r></w:p></w:tc>
+        <w:tc><w:p><w:r><w:t>1</w:t></w:r></w:p></w:tc>
+      </w:tr>
+      <w:tr>
+        <w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p><w:r><w:t>Total</w:t></w:r></w:p></w:tc>
+      </w:tr>
+    </w:tbl>
+    <w:p><w:r><w:drawing><wp:inline><wp:extent cx="952500" cy="476250"/>
+      <wp:docPr id="1" name="Picture 1" descr="Logo"/>
+      <a:graphic><a:graphicData><pic:pic><pic:blipFill><a:blip r:embed="rId11"/></pic:blipFill></pic:pic></a:graphicData></a:graphic>
+    </wp:inline></w:drawing></w:r></w:p>
+    <w:p><w:pPr><w:pStyle w:val="SourceCode"/></w:pPr><w:r><w:t>x := 1</w:t></w:r></w:p>
+    <w:p><w:r><w:t>Tab</w:t><w:tab/></w:r><w:r><w:t>stop</w:t><w:br/></w:r><w:r><w:br w:type="page"/></w:r><w:r><w:t>next</w:t></w:r></w:p>
+  </w:body>
+</w:document>`
+
+const markdownTestRelationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId10" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com/a b" TargetMode="External"/>
+  <Relationship Id="rId11" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"/>
+</Relationships>`
+
+const markdownTestNumbering = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:abstractNum w:abstractNumId="0">
+    <w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="decimal"/></w:lvl>
+    <w:lvl w:ilvl="1"><w:numFmt w:val="bullet"/></w:lvl>
+  </w:abstractNum>
+  <w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+</w:numbering>`
+
+const markdownTestFootnotes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:footnotes xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:footnote w:type="separator" w:id="-1"><w:p><w:r><w:separator/></w:r></w:p></w:footnote>
+  <w:footnote w:id="1"><w:p><w:r><w:footnoteRef/></w:r><w:r><w:t xml:space="preserve"> Footnote text</w:t></w:r></w:p></w:footnote>
+</w:footnotes>`
+
+const markdownTestStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:style w:type="paragraph" w:styleId="1"><w:name w:val="heading 1"/></w:style>
+</w:styles>`
+
+func TestMarkdownExporterExport(t *testing.T) {
+	filename := writeTestPackage(t, map[string]string{
+		"word/document.xml":            markdownTestDocument,
+		"word/_rels/document.xml.rels": markdownTestRelationships,
+		"word/numbering.xml":           markdownTestNumbering,
+		"word/footnotes.xml":           markdownTestFootnotes,
+		"word/styles.xml":              markdownTestStyles,
+		"word/media/image1.png":        "\x89PNG\r\n\x1a\nfake",
+	})
+
+	doc, err := Open(filename)
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	outputDir := t.TempDir()
+	outputPath := filepath.Join(outputDir, "doc.md")
+	if err := NewMarkdownExporter(doc, nil).ExportToMarkdown(outputPath); err != nil {
+		t.Fatalf("Markdown导出失败: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("读取Markdown失败: %v", err)
+	}
+	markdown := string(data)
+
+	for _, expected := range []string{
+		"# 标题\n",
+		"Plain **bold**, *italic* ~~gone~~ [site](<https://example.com/a b>)[^1] a\\*b",
+		"1. one\n    - nested\n2. two",
+		"| Name | Value |\n| --- | ---: |\n| a\\|b | 1 |\n| Total |  |",
+		"![Logo](media/image1.png)",
+		"```\nx := 1\n```",
+		"[^1]: Footnote text",
+		"Tab&emsp;stop  \n  \nnext",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Markdown应该包含 %q, 实际:\n%s", expected, markdown)
+		}
+	}
+
+	// 图片应被提取到同级media目录
+	if _, err := os.Stat(filepath.Join(outputDir, "media", "image1.png")); err != nil {
+		t.Errorf("图片应该被提取: %v", err)
+	}
+}
+
+func TestMarkdownExporterWithoutContent(t *testing.T) {
+	exporter := NewMarkdownExporter(&Document{}, nil)
+	if _, err := exporter.ToMarkdown(); err == nil {
+		t.Error("未加载内容的文档应该返回错误")
+	}
+}
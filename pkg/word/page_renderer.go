@@ -0,0 +1,392 @@
+package word
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+// defaultRenderDPI is the resolution of rendered pages when none is set
+const defaultRenderDPI = 96
+
+// PageRenderer draws the pages of a document into images for previews and
+// thumbnails. Pages are laid out by the PDF layout engine, so they break
+// exactly like the exported PDF. Text is drawn with the embedded fonts of
+// the layout and the Go fonts in place of the standard PDF fonts; no
+// display or GPU is needed.
+type PageRenderer struct {
+	Document *Document
+	Config   *types.RenderConfig
+	Logger   *utils.Logger
+
+	// fonts and faces cache the parsed fonts and the faces per size
+	fonts  map[pdfFont]*sfnt.Font
+	faces  map[renderFaceKey]font.Face
+	images map[*pdfImage]image.Image
+}
+
+// renderFaceKey identifies a face of a font at a size
+type renderFaceKey struct {
+	font pdfFont
+	size float64
+}
+
+// NewPageRenderer creates a page renderer
+func NewPageRenderer(document *Document, config *types.RenderConfig) *PageRenderer {
+	if config == nil {
+		config = &types.RenderConfig{}
+	}
+	return &PageRenderer{
+		Document: document,
+		Config:   config,
+		Logger:   utils.NewLogger(utils.LogLevelInfo, os.Stdout),
+	}
+}
+
+// dpi returns the configured resolution
+func (r *PageRenderer) dpi() float64 {
+	if r.Config.DPI > 0 {
+		return r.Config.DPI
+	}
+	return defaultRenderDPI
+}
+
+// layout lays out the document with the layout configuration. Without
+// one, text is measured with the installed fonts it is drawn with.
+func (r *PageRenderer) layout(ctx context.Context) (*pdfLayout, error) {
+	if r.Document == nil {
+		return nil, fmt.Errorf("document is nil")
+	}
+	config := r.Config.Layout
+	if config == nil {
+		// 标准PDF字体的宽度与绘制所用的字体不同，按已安装字体排版
+		config = getDefaultPDFConfig()
+		config.FontEmbedding = true
+	}
+	exporter := NewPDFExporter(r.Document, config)
+	exporter.Logger = r.Logger
+	content, err := exporter.extractDocumentContent(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract document content: %w", err)
+	}
+	layout, _, err := exporter.layoutDocument(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	return layout, nil
+}
+
+// RenderPages draws every page of the document
+func (r *PageRenderer) RenderPages(ctx context.Context) ([]*image.RGBA, error) {
+	layout, err := r.layout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]*image.RGBA, 0, len(layout.pages))
+	for _, page := range layout.pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pages = append(pages, r.drawPage(page))
+	}
+	return pages, nil
+}
+
+// RenderPage draws the page with the given zero-based index
+func (r *PageRenderer) RenderPage(ctx context.Context, index int) (*image.RGBA, error) {
+	layout, err := r.layout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(layout.pages) {
+		return nil, fmt.Errorf("page %d out of range, the document has %d pages", index, len(layout.pages))
+	}
+	return r.drawPage(layout.pages[index]), nil
+}
+
+// RenderPNG draws the page with the given zero-based index as PNG
+func (r *PageRenderer) RenderPNG(ctx context.Context, index int) ([]byte, error) {
+	page, err := r.RenderPage(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	return encodePNG(page)
+}
+
+// Thumbnail draws the first page scaled to fit into width by height
+// pixels as PNG, keeping the aspect ratio of the page
+func (r *PageRenderer) Thumbnail(ctx context.Context, width, height int) ([]byte, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid thumbnail size %dx%d", width, height)
+	}
+	page, err := r.RenderPage(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := page.Bounds()
+	scale := math.Min(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()))
+	size := image.Rect(0, 0, max(int(math.Round(float64(bounds.Dx())*scale)), 1), max(int(math.Round(float64(bounds.Dy())*scale)), 1))
+	thumbnail := image.NewRGBA(size)
+	xdraw.CatmullRom.Scale(thumbnail, size, page, bounds, xdraw.Src, nil)
+	return encodePNG(thumbnail)
+}
+
+// encodePNG encodes an image as PNG
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderCanvas maps PDF coordinates, in points from the lower left
+// corner, to the pixels of a page image
+type renderCanvas struct {
+	img    *image.RGBA
+	scale  float64
+	height float64
+}
+
+// point returns the pixel position of a point
+func (c *renderCanvas) point(x, y float64) (float64, float64) {
+	return x * c.scale, (c.height - y) * c.scale
+}
+
+// drawPage draws the recorded items of a page on a white background
+func (r *PageRenderer) drawPage(page *pdfPage) *image.RGBA {
+	scale := r.dpi() / 72
+	width := int(math.Ceil(page.width * scale))
+	height := int(math.Ceil(page.height * scale))
+	canvas := &renderCanvas{img: image.NewRGBA(image.Rect(0, 0, width, height)), scale: scale, height: page.height}
+	xdraw.Draw(canvas.img, canvas.img.Bounds(), image.White, image.Point{}, xdraw.Src)
+
+	for _, item := range page.items {
+		switch item.kind {
+		case pdfTextItem:
+			r.drawText(canvas, item)
+		case pdfRectItem:
+			x0, y0 := canvas.point(item.x, item.y+item.height)
+			x1, y1 := canvas.point(item.x+item.width, item.y)
+			canvas.fill(renderColor(item.color), [][2]float64{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}})
+		case pdfLineItem:
+			canvas.stroke(renderColor(item.color), item)
+		case pdfImageItem:
+			r.drawImage(canvas, item)
+		}
+	}
+	return canvas.img
+}
+
+// drawText draws the text of a fragment from its baseline origin
+func (r *PageRenderer) drawText(canvas *renderCanvas, item pdfPageItem) {
+	face := r.face(item.font, item.size)
+	if face == nil {
+		return
+	}
+	x, y := canvas.point(item.x, item.y)
+	drawer := &font.Drawer{
+		Dst:  canvas.img,
+		Src:  image.NewUniform(renderColor(item.color)),
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.Int26_6(math.Round(x * 64)), Y: fixed.Int26_6(math.Round(y * 64))},
+	}
+	drawer.DrawString(item.text)
+}
+
+// drawImage draws a picture scaled to its extent
+func (r *PageRenderer) drawImage(canvas *renderCanvas, item pdfPageItem) {
+	if item.image == nil || item.image.source == nil {
+		return
+	}
+	if r.images == nil {
+		r.images = make(map[*pdfImage]image.Image)
+	}
+	src, ok := r.images[item.image]
+	if !ok {
+		decoded, _, err := image.Decode(bytes.NewReader(item.image.source))
+		if err != nil {
+			r.Logger.Warning("无法解码图片: %v", err)
+		}
+		src = decoded
+		r.images[item.image] = src
+	}
+	if src == nil {
+		return
+	}
+
+	x0, y0 := canvas.point(item.x, item.y+item.height)
+	x1, y1 := canvas.point(item.x+item.width, item.y)
+	rect := image.Rect(int(math.Round(x0)), int(math.Round(y0)), int(math.Round(x1)), int(math.Round(y1)))
+	if rect.Empty() {
+		return
+	}
+	xdraw.BiLinear.Scale(canvas.img, rect, src, src.Bounds(), xdraw.Over, nil)
+}
+
+// fill fills a polygon given in pixels with anti-aliased edges. The
+// rasterizer only covers the bounding box of the polygon.
+func (c *renderCanvas) fill(fill color.Color, polygon [][2]float64) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range polygon {
+		minX, maxX = math.Min(minX, p[0]), math.Max(maxX, p[0])
+		minY, maxY = math.Min(minY, p[1]), math.Max(maxY, p[1])
+	}
+	box := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY))).Intersect(c.img.Bounds())
+	if box.Empty() {
+		return
+	}
+
+	rasterizer := vector.NewRasterizer(box.Dx(), box.Dy())
+	for i, p := range polygon {
+		x, y := float32(p[0]-float64(box.Min.X)), float32(p[1]-float64(box.Min.Y))
+		if i == 0 {
+			rasterizer.MoveTo(x, y)
+		} else {
+			rasterizer.LineTo(x, y)
+		}
+	}
+	rasterizer.ClosePath()
+	rasterizer.Draw(c.img, box, image.NewUniform(fill), image.Point{})
+}
+
+// stroke draws a line with its width and dash pattern. Lines are at least
+// one pixel wide so that hairlines stay visible at low resolutions.
+func (c *renderCanvas) stroke(stroke color.Color, item pdfPageItem) {
+	x0, y0 := c.point(item.x, item.y)
+	x1, y1 := c.point(item.x+item.width, item.y+item.height)
+	length := math.Hypot(x1-x0, y1-y0)
+	if length == 0 {
+		return
+	}
+	dx, dy := (x1-x0)/length, (y1-y0)/length
+	half := math.Max(item.lineWidth*c.scale, 1) / 2
+
+	segment := func(from, to float64) {
+		ax, ay := x0+dx*from, y0+dy*from
+		bx, by := x0+dx*to, y0+dy*to
+		c.fill(stroke, [][2]float64{
+			{ax - dy*half, ay + dx*half}, {bx - dy*half, by + dx*half},
+			{bx + dy*half, by - dx*half}, {ax + dy*half, ay - dx*half},
+		})
+	}
+	if len(item.dash) < 2 || item.dash[0] <= 0 {
+		segment(0, length)
+		return
+	}
+	// 虚线按线型交替绘制线段和间隔
+	on, off := item.dash[0]*c.scale, item.dash[1]*c.scale
+	for start := 0.0; start < length; start += on + off {
+		segment(start, math.Min(start+on, length))
+	}
+}
+
+// face returns the face of a font at a size, or nil when the font cannot
+// be parsed
+func (r *PageRenderer) face(f pdfFont, size float64) font.Face {
+	key := renderFaceKey{font: f, size: size}
+	if face, ok := r.faces[key]; ok {
+		return face
+	}
+	if r.faces == nil {
+		r.faces = make(map[renderFaceKey]font.Face)
+		r.fonts = make(map[pdfFont]*sfnt.Font)
+	}
+
+	parsed, ok := r.fonts[f]
+	if !ok {
+		var err error
+		parsed, err = renderFont(f)
+		if err != nil {
+			r.Logger.Warning("无法加载字体 %s: %v", f.baseFont(), err)
+		}
+		r.fonts[f] = parsed
+	}
+	var face font.Face
+	if parsed != nil {
+		face, _ = opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: r.dpi(), Hinting: font.HintingNone})
+	}
+	r.faces[key] = face
+	return face
+}
+
+// renderFont parses the embedded font of the layout, or the Go font that
+// stands in for a standard PDF font
+func renderFont(f pdfFont) (*sfnt.Font, error) {
+	switch f := f.(type) {
+	case *trueTypeFont:
+		if bytes.HasPrefix(f.data, []byte("ttcf")) {
+			collection, err := sfnt.ParseCollection(f.data)
+			if err != nil {
+				return nil, err
+			}
+			return collection.Font(f.index)
+		}
+		return sfnt.Parse(f.data)
+	case *pdfStandardFont:
+		bold := strings.Contains(f.name, "Bold")
+		italic := strings.Contains(f.name, "Oblique") || strings.Contains(f.name, "Italic")
+		data := goregular.TTF
+		if strings.HasPrefix(f.name, "Courier") {
+			switch {
+			case bold && italic:
+				data = gomonobolditalic.TTF
+			case bold:
+				data = gomonobold.TTF
+			case italic:
+				data = gomonoitalic.TTF
+			default:
+				data = gomono.TTF
+			}
+		} else {
+			switch {
+			case bold && italic:
+				data = gobolditalic.TTF
+			case bold:
+				data = gobold.TTF
+			case italic:
+				data = goitalic.TTF
+			}
+		}
+		return opentype.Parse(data)
+	default:
+		return nil, fmt.Errorf("unsupported font %T", f)
+	}
+}
+
+// renderColor returns the color of a hexadecimal RRGGBB value; automatic
+// and invalid colors are black
+func renderColor(hex string) color.RGBA {
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return color.RGBA{A: 0xFF}
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xFF}
+}
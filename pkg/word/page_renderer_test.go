@@ -0,0 +1,116 @@
+package word
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+func TestPageRendererRenderPages(t *testing.T) {
+	doc := pdfTableTestDocument(t)
+	defer doc.Close()
+
+	renderer := NewPageRenderer(doc, &types.RenderConfig{DPI: 72})
+	pages, err := renderer.RenderPages(context.Background())
+	if err != nil {
+		t.Fatalf("渲染页面失败: %v", err)
+	}
+	if len(pages) < 2 {
+		t.Fatalf("表格应该跨页，实际 %d 页", len(pages))
+	}
+	// A4页面595.28x841.89磅在72 DPI下向上取整
+	if size := pages[0].Bounds().Size(); size != image.Pt(596, 842) {
+		t.Errorf("页面尺寸错误: %v", size)
+	}
+
+	first := pages[0]
+	var shading, red, ink int
+	for y := 0; y < first.Bounds().Dy(); y++ {
+		for x := 0; x < first.Bounds().Dx(); x++ {
+			c := first.RGBAAt(x, y)
+			switch {
+			case c.R == 0xD9 && c.G == 0xE2 && c.B == 0xF3:
+				shading++
+			case y < 100 && c.R > 240 && c.G < 16 && c.B < 16:
+				red++
+			case c.R < 64 && c.G < 64 && c.B < 64:
+				ink++
+			}
+			// 页边距以外没有内容
+			if (x < 70 || y < 70) && c != (first.RGBAAt(0, 0)) {
+				t.Fatalf("页边距内出现内容: (%d, %d)", x, y)
+			}
+		}
+	}
+	if shading == 0 {
+		t.Error("应该绘制表头单元格底纹")
+	}
+	if red == 0 {
+		t.Error("应该绘制图片")
+	}
+	if ink < 1000 {
+		t.Errorf("应该绘制文字，实际只有 %d 个深色像素", ink)
+	}
+
+	// 分辨率加倍时页面尺寸加倍
+	renderer.Config.DPI = 144
+	page, err := renderer.RenderPage(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("渲染页面失败: %v", err)
+	}
+	if size := page.Bounds().Size(); size != image.Pt(1191, 1684) {
+		t.Errorf("144 DPI页面尺寸错误: %v", size)
+	}
+	if _, err := renderer.RenderPage(context.Background(), len(pages)); err == nil {
+		t.Error("页码超出范围时应该返回错误")
+	}
+}
+
+func TestPageRendererThumbnail(t *testing.T) {
+	doc := pdfTestDocument(t, 3)
+	defer doc.Close()
+
+	data, err := NewPageRenderer(doc, nil).RenderPNG(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("渲染PNG失败: %v", err)
+	}
+	page, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("PNG无法解码: %v", err)
+	}
+	// 默认96 DPI
+	if size := page.Bounds().Size(); size != image.Pt(794, 1123) {
+		t.Errorf("页面尺寸错误: %v", size)
+	}
+	// 没有排版配置时按绘制所用的字体测量文字
+	layout, err := NewPageRenderer(doc, nil).layout(context.Background())
+	if err != nil {
+		t.Fatalf("排版失败: %v", err)
+	}
+	if !layout.config.FontEmbedding {
+		t.Error("默认排版应该使用已安装字体的字形宽度")
+	}
+
+	processor := NewImageProcessor(utils.Logger{}, nil)
+	data, err = processor.GetDocumentThumbnail(context.Background(), doc, 200, 200)
+	if err != nil {
+		t.Fatalf("生成文档缩略图失败: %v", err)
+	}
+	thumbnail, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("缩略图无法解码: %v", err)
+	}
+	// 保持页面比例缩放到200x200以内
+	if size := thumbnail.Bounds().Size(); size != image.Pt(141, 200) {
+		t.Errorf("缩略图尺寸错误: %v", size)
+	}
+
+	if _, err := processor.GetDocumentThumbnail(context.Background(), doc, 0, 200); err == nil {
+		t.Error("无效的缩略图尺寸应该返回错误")
+	}
+}
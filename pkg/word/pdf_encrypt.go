@@ -0,0 +1,392 @@
+package word
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// pdfPasswordPadding pads passwords of the revision 4 security handler
+var pdfPasswordPadding = []byte{
+	0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41, 0x64, 0x00, 0x4E, 0x56, 0xFF, 0xFA, 0x01, 0x08,
+	0x2E, 0x2E, 0x00, 0xB6, 0xD0, 0x68, 0x3E, 0x80, 0x2F, 0x0C, 0xA9, 0xFE, 0x64, 0x53, 0x69, 0x7A,
+}
+
+// pdfEncryption encrypts the strings and streams of a file with the
+// standard security handler. Revision 4 uses AES-128 with a key per
+// object; revision 6 uses AES-256 with the file key for all objects.
+type pdfEncryption struct {
+	revision int
+	key      []byte
+	// objectID is the number of the encryption dictionary
+	objectID int
+	// dictionary is the body of the encryption dictionary
+	dictionary string
+}
+
+// pdfEncrypted reports whether the configuration asks for encryption
+func pdfEncrypted(config *types.PDFExportConfig) bool {
+	return config.Encryption != types.PDFEncryptionNone ||
+		config.Permissions.UserPassword != "" || config.Permissions.OwnerPassword != ""
+}
+
+// pdfPermissionFlags returns the /P value for the permissions. The
+// reserved bits are set and extraction for accessibility is always allowed.
+func pdfPermissionFlags(permissions types.PDFPermissions) int32 {
+	flags := uint32(0xFFFFF0C0) | 1<<9
+	if permissions.AllowPrint {
+		// 允许打印和高质量打印
+		flags |= 1<<2 | 1<<11
+	}
+	if permissions.AllowModify {
+		// 允许修改和组合文档
+		flags |= 1<<3 | 1<<10
+	}
+	if permissions.AllowCopy {
+		flags |= 1 << 4
+	}
+	if permissions.AllowAnnotate {
+		// 允许注释和填写表单
+		flags |= 1<<5 | 1<<8
+	}
+	return int32(flags)
+}
+
+// newPDFEncryption derives the keys and the encryption dictionary for the
+// configuration. id is the first element of the file identifier.
+func newPDFEncryption(config *types.PDFExportConfig, id []byte) (*pdfEncryption, error) {
+	permissions := config.Permissions
+	owner := permissions.OwnerPassword
+	if owner == "" {
+		// 没有所有者密码时使用随机密码，打开文档的用户不能解除限制
+		random, err := pdfRandom(16)
+		if err != nil {
+			return nil, err
+		}
+		owner = hex.EncodeToString(random)
+	}
+	flags := pdfPermissionFlags(permissions)
+
+	switch config.Encryption {
+	case types.PDFEncryptionAES128:
+		return newPDFEncryptionR4(permissions.UserPassword, owner, flags, id), nil
+	case types.PDFEncryptionNone, types.PDFEncryptionAES256:
+		return newPDFEncryptionR6(permissions.UserPassword, owner, flags)
+	default:
+		return nil, fmt.Errorf("unsupported PDF encryption %q", config.Encryption)
+	}
+}
+
+// newPDFEncryptionR4 sets up AES-128 encryption with the revision 4
+// security handler
+func newPDFEncryptionR4(user, owner string, flags int32, id []byte) *pdfEncryption {
+	o := pdfOwnerHashR4(user, owner)
+	key := pdfFileKeyR4(user, o, flags, id)
+	u := pdfUserHashR4(key, id)
+
+	return &pdfEncryption{
+		revision: 4,
+		key:      key,
+		dictionary: fmt.Sprintf("<< /Filter /Standard /V 4 /R 4 /Length 128 /CF << /StdCF << /Type /CryptFilter /CFM /AESV2 /AuthEvent /DocOpen /Length 16 >> >> /StmF /StdCF /StrF /StdCF /O %s /U %s /P %d >>",
+			pdfHexString(o), pdfHexString(u), flags),
+	}
+}
+
+// pdfPadPassword pads or truncates a password to 32 bytes
+func pdfPadPassword(password string) []byte {
+	padded := []byte(password)
+	if len(padded) > 32 {
+		padded = padded[:32]
+	}
+	return append(padded, pdfPasswordPadding[:32-len(padded)]...)
+}
+
+// pdfOwnerHashR4 computes the /O value (algorithm 3)
+func pdfOwnerHashR4(user, owner string) []byte {
+	sum := md5.Sum(pdfPadPassword(owner))
+	for i := 0; i < 50; i++ {
+		sum = md5.Sum(sum[:])
+	}
+	return pdfRC4Rounds(sum[:], pdfPadPassword(user))
+}
+
+// pdfFileKeyR4 computes the file key from the user password (algorithm 2)
+func pdfFileKeyR4(user string, o []byte, flags int32, id []byte) []byte {
+	h := md5.New()
+	h.Write(pdfPadPassword(user))
+	h.Write(o)
+	binary.Write(h, binary.LittleEndian, flags)
+	h.Write(id)
+	sum := h.Sum(nil)
+	for i := 0; i < 50; i++ {
+		next := md5.Sum(sum)
+		sum = next[:]
+	}
+	return sum
+}
+
+// pdfUserHashR4 computes the /U value (algorithm 5). The last 16 bytes
+// are arbitrary padding.
+func pdfUserHashR4(key, id []byte) []byte {
+	h := md5.New()
+	h.Write(pdfPasswordPadding)
+	h.Write(id)
+	u := pdfRC4Rounds(key, h.Sum(nil))
+	return append(u, pdfPasswordPadding[:16]...)
+}
+
+// pdfRC4Rounds encrypts data with RC4 twenty times, the key XORed with the
+// round number
+func pdfRC4Rounds(key, data []byte) []byte {
+	result := append([]byte(nil), data...)
+	roundKey := make([]byte, len(key))
+	for round := 0; round < 20; round++ {
+		for i := range key {
+			roundKey[i] = key[i] ^ byte(round)
+		}
+		c, _ := rc4.NewCipher(roundKey)
+		c.XORKeyStream(result, result)
+	}
+	return result
+}
+
+// newPDFEncryptionR6 sets up AES-256 encryption with the revision 6
+// security handler
+func newPDFEncryptionR6(user, owner string, flags int32) (*pdfEncryption, error) {
+	random, err := pdfRandom(32 + 16 + 16 + 4)
+	if err != nil {
+		return nil, err
+	}
+	return newPDFEncryptionR6From(user, owner, flags, random), nil
+}
+
+// newPDFEncryptionR6From derives the revision 6 entries from random, which
+// holds the file key, the user and owner salts and four bytes of /Perms
+func newPDFEncryptionR6From(user, owner string, flags int32, random []byte) *pdfEncryption {
+	key, userSalts, ownerSalts := random[:32], random[32:48], random[48:64]
+	userPassword, ownerPassword := pdfPasswordR6(user), pdfPasswordR6(owner)
+
+	// 验证盐和密钥盐各8字节
+	u := append(pdfHashR6(userPassword, userSalts[:8], nil), userSalts...)
+	ue := pdfAESNoPadding(pdfHashR6(userPassword, userSalts[8:], nil), key)
+	o := append(pdfHashR6(ownerPassword, ownerSalts[:8], u), ownerSalts...)
+	oe := pdfAESNoPadding(pdfHashR6(ownerPassword, ownerSalts[8:], u), key)
+
+	perms := make([]byte, 16)
+	binary.LittleEndian.PutUint32(perms, uint32(flags))
+	copy(perms[4:], "\xFF\xFF\xFF\xFFTadb")
+	copy(perms[12:], random[64:])
+	block, _ := aes.NewCipher(key)
+	block.Encrypt(perms, perms)
+
+	return &pdfEncryption{
+		revision: 6,
+		key:      key,
+		dictionary: fmt.Sprintf("<< /Filter /Standard /V 5 /R 6 /Length 256 /CF << /StdCF << /Type /CryptFilter /CFM /AESV3 /AuthEvent /DocOpen /Length 32 >> >> /StmF /StdCF /StrF /StdCF /O %s /U %s /OE %s /UE %s /P %d /Perms %s >>",
+			pdfHexString(o), pdfHexString(u), pdfHexString(oe), pdfHexString(ue), flags, pdfHexString(perms)),
+	}
+}
+
+// pdfPasswordR6 returns the UTF-8 password truncated to 127 bytes.
+// Passwords are used as given without SASLprep normalization.
+func pdfPasswordR6(password string) []byte {
+	data := []byte(password)
+	if len(data) > 127 {
+		data = data[:127]
+	}
+	return data
+}
+
+// pdfHashR6 computes the revision 6 password hash (algorithm 2.B)
+func pdfHashR6(password, salt, userKey []byte) []byte {
+	sum := sha256.Sum256(bytes.Join([][]byte{password, salt, userKey}, nil))
+	k := sum[:]
+	for round := 0; ; round++ {
+		k1 := bytes.Repeat(bytes.Join([][]byte{password, k, userKey}, nil), 64)
+		block, _ := aes.NewCipher(k[:16])
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+
+		// 前16字节之和除以3的余数决定下一轮的散列算法
+		remainder := 0
+		for _, b := range e[:16] {
+			remainder += int(b)
+		}
+		var h hash.Hash
+		switch remainder % 3 {
+		case 0:
+			h = sha256.New()
+		case 1:
+			h = sha512.New384()
+		default:
+			h = sha512.New()
+		}
+		h.Write(e)
+		k = h.Sum(nil)
+
+		if round >= 63 && int(e[len(e)-1]) <= round+1-32 {
+			break
+		}
+	}
+	return k[:32]
+}
+
+// pdfAESNoPadding encrypts data whose length is a multiple of the block
+// size with AES-256 in CBC mode and a zero initialization vector
+func pdfAESNoPadding(key, data []byte) []byte {
+	block, _ := aes.NewCipher(key)
+	result := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(result, data)
+	return result
+}
+
+// pdfRandom returns n random bytes
+func pdfRandom(n int) ([]byte, error) {
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+	return data, nil
+}
+
+// objectKey returns the key that encrypts the strings and streams of an
+// object
+func (e *pdfEncryption) objectKey(id int) []byte {
+	if e.revision >= 5 {
+		return e.key
+	}
+	h := md5.New()
+	h.Write(e.key)
+	h.Write([]byte{byte(id), byte(id >> 8), byte(id >> 16), 0, 0})
+	h.Write([]byte("sAlT"))
+	return h.Sum(nil)
+}
+
+// encrypt encrypts data of an object with AES in CBC mode. The random
+// initialization vector is stored before the ciphertext.
+func (e *pdfEncryption) encrypt(id int, data []byte) []byte {
+	block, _ := aes.NewCipher(e.objectKey(id))
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	plain := append(append([]byte(nil), data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	result := make([]byte, aes.BlockSize+len(plain))
+	if _, err := rand.Read(result[:aes.BlockSize]); err != nil {
+		// 随机数不可用时退回到由对象内容得到的初始向量
+		sum := md5.Sum(plain)
+		copy(result, sum[:])
+	}
+	cipher.NewCBCEncrypter(block, result[:aes.BlockSize]).CryptBlocks(result[aes.BlockSize:], plain)
+	return result
+}
+
+// encryptStrings replaces the literal and hexadecimal strings in an
+// object body by encrypted hexadecimal strings
+func (e *pdfEncryption) encryptStrings(id int, body string) string {
+	var buf strings.Builder
+	for i := 0; i < len(body); {
+		switch {
+		case body[i] == '(':
+			text, end := pdfLiteralString(body, i)
+			buf.WriteString(pdfHexString(e.encrypt(id, text)))
+			i = end
+		case body[i] == '<' && i+1 < len(body) && body[i+1] == '<':
+			buf.WriteString("<<")
+			i += 2
+		case body[i] == '<':
+			end := strings.IndexByte(body[i:], '>')
+			if end < 0 {
+				buf.WriteString(body[i:])
+				return buf.String()
+			}
+			digits := strings.Map(func(r rune) rune {
+				if r <= ' ' {
+					return -1
+				}
+				return r
+			}, body[i+1:i+end])
+			if len(digits)%2 != 0 {
+				digits += "0"
+			}
+			text, _ := hex.DecodeString(digits)
+			buf.WriteString(pdfHexString(e.encrypt(id, text)))
+			i += end + 1
+		default:
+			buf.WriteByte(body[i])
+			i++
+		}
+	}
+	return buf.String()
+}
+
+// pdfLiteralString decodes the literal string that starts at body[start]
+// and returns its bytes and the offset after the closing parenthesis
+func pdfLiteralString(body string, start int) ([]byte, int) {
+	var text []byte
+	depth := 0
+	for i := start; i < len(body); i++ {
+		c := body[i]
+		switch c {
+		case '(':
+			if depth > 0 {
+				text = append(text, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return text, i + 1
+			}
+			text = append(text, c)
+		case '\\':
+			i++
+			if i >= len(body) {
+				return text, i
+			}
+			switch escaped := body[i]; escaped {
+			case 'n':
+				text = append(text, '\n')
+			case 'r':
+				text = append(text, '\r')
+			case 't':
+				text = append(text, '\t')
+			case 'b':
+				text = append(text, '\b')
+			case 'f':
+				text = append(text, '\f')
+			case '\r':
+				// 续行
+				if i+1 < len(body) && body[i+1] == '\n' {
+					i++
+				}
+			case '\n':
+			default:
+				if escaped >= '0' && escaped <= '7' {
+					value := 0
+					for n := 0; n < 3 && i < len(body) && body[i] >= '0' && body[i] <= '7'; n++ {
+						value = value*8 + int(body[i]-'0')
+						i++
+					}
+					i--
+					text = append(text, byte(value))
+				} else {
+					text = append(text, escaped)
+				}
+			}
+		default:
+			text = append(text, c)
+		}
+	}
+	return text, len(body)
+}
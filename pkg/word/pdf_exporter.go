@@ -1,8 +1,8 @@
 package word
 
 import (
-    "bytes"
     "context"
+    "crypto/md5"
     "fmt"
     "io"
     "os"
@@ -10,6 +10,7 @@ import (
     "sync"
     "time"
 
+    "github.com/tanqiangyes/go-word/pkg/parser"
     "github.com/tanqiangyes/go-word/pkg/types"
     "github.com/tanqiangyes/go-word/pkg/utils"
 )
@@ -21,6 +22,8 @@ type PDFExporter struct {
     Logger   *utils.Logger
     Mu       sync.RWMutex
     Metrics  *PDFExportMetrics
+    // Languages 提供各语言的字体回退链
+    Languages *LanguageSupport
 }
 
 // PDFExportMetrics PDF导出指标
@@ -56,6 +59,7 @@ func NewPDFExporter(document *Document, config *types.PDFExportConfig) *PDFExpor
         Config:   config,
         Logger:   utils.NewLogger(utils.LogLevelInfo, os.Stdout),
         Metrics:  &PDFExportMetrics{},
+        Languages: NewLanguageSupport(),
     }
 }
 
@@ -224,148 +228,276 @@ func (pe *PDFExporter) extractDocumentContent(ctx context.Context) (*PDFDocument
 
 // generatePDF 生成PDF数据
 func (pe *PDFExporter) generatePDF(ctx context.Context, content *PDFDocumentContent) ([]byte, int, error) {
-    // 这里实现PDF生成逻辑
-    // 为了简化，我们生成一个基本的PDF结构
-
-    var buffer bytes.Buffer
+    if err := validateConformance(pe.Config); err != nil {
+        return nil, 0, err
+    }
 
-    // PDF头部
-    buffer.WriteString("%PDF-1.4\n")
+    layout, body, err := pe.layoutDocument(ctx, content)
+    if err != nil {
+        return nil, 0, err
+    }
+    if isPDFA(pe.Config) {
+        if err := checkEmbeddedFonts(layout); err != nil {
+            return nil, 0, fmt.Errorf("PDF/A requires embedded fonts: %w", err)
+        }
+    } else if pe.Config.Tagged {
+        if err := checkEmbeddedFonts(layout); err != nil {
+            pe.Logger.Warning("带标签的PDF使用了未嵌入的字体: %v", err)
+        }
+    }
 
-    // 添加文档信息
-    pe.addPDFDocumentInfo(&buffer)
+    // 按文档结构的标题层次生成书签
+    if structure, err := pe.Document.ReorganizeDocument(); err == nil {
+        layout.outline = layout.buildOutline(structure.GetOutline().Sections, body.Paragraphs)
+    }
 
-    // 添加页面内容
-    pageCount := pe.addPDFContent(&buffer, content)
+    data, err := pe.writePDF(layout)
+    if err != nil {
+        return nil, 0, err
+    }
+    return data, len(layout.pages), nil
+}
 
-    // PDF尾部
-    pe.addPDFTrailer(&buffer)
+// layoutDocument 按页面尺寸和页边距排版文档内容，超出一页的内容自动分页。
+// PDF导出和页面渲染共用同一排版结果。
+func (pe *PDFExporter) layoutDocument(ctx context.Context, content *PDFDocumentContent) (*pdfLayout, *types.DocumentContent, error) {
+    layout := newPDFLayout(ctx, pe.Config)
+    if pe.Languages != nil {
+        layout.resolver.languages = pe.Languages
+    }
+    layout.resolver.logger = pe.Logger
+    layout.media = newExportMedia(pe.Document, "", pe.Logger)
+    pe.loadLayoutParts(layout)
 
-    return buffer.Bytes(), pageCount, nil
-}
+    body := &types.DocumentContent{}
+    for _, paragraph := range content.Paragraphs {
+        body.Paragraphs = append(body.Paragraphs, *paragraph)
+    }
+    for _, table := range content.Tables {
+        body.Tables = append(body.Tables, *table)
+    }
 
-// addPDFDocumentInfo 添加PDF文档信息
-func (pe *PDFExporter) addPDFDocumentInfo(buffer *bytes.Buffer) {
-    // 添加文档信息对象
-    buffer.WriteString("1 0 obj\n")
-    buffer.WriteString("<<\n")
-    buffer.WriteString("/Type /Catalog\n")
-    buffer.WriteString("/Pages 2 0 R\n")
-    buffer.WriteString(">>\n")
-    buffer.WriteString("endobj\n\n")
-
-    // 添加页面树
-    buffer.WriteString("2 0 obj\n")
-    buffer.WriteString("<<\n")
-    buffer.WriteString("/Type /Pages\n")
-    buffer.WriteString("/Kids [3 0 R]\n")
-    buffer.WriteString("/Count 1\n")
-    buffer.WriteString(">>\n")
-    buffer.WriteString("endobj\n\n")
+    if err := layout.layout(collectBody(body)); err != nil {
+        return nil, nil, err
+    }
+    return layout, body, nil
 }
 
-// addPDFContent 添加PDF内容
-func (pe *PDFExporter) addPDFContent(buffer *bytes.Buffer, content *PDFDocumentContent) int {
-    // 添加页面对象
-    buffer.WriteString("3 0 obj\n")
-    buffer.WriteString("<<\n")
-    buffer.WriteString("/Type /Page\n")
-    buffer.WriteString("/Parent 2 0 R\n")
-    buffer.WriteString("/MediaBox [0 0 612 792]\n") // A4 size
-    buffer.WriteString("/Contents 4 0 R\n")
-    buffer.WriteString("/Resources <<\n")
-    buffer.WriteString("/Font <<\n")
-    buffer.WriteString("/F1 5 0 R\n")
-    buffer.WriteString(">>\n")
-    buffer.WriteString(">>\n")
-    buffer.WriteString(">>\n")
-    buffer.WriteString("endobj\n\n")
-
-    // 添加内容流
-    contentStream := pe.generateContentStream(content)
-    buffer.WriteString("4 0 obj\n")
-    buffer.WriteString("<<\n")
-    buffer.WriteString(fmt.Sprintf("/Length %d\n", len(contentStream)))
-    buffer.WriteString(">>\n")
-    buffer.WriteString("stream\n")
-    buffer.WriteString(contentStream)
-    buffer.WriteString("\nendstream\n")
-    buffer.WriteString("endobj\n\n")
-
-    // 添加字体
-    buffer.WriteString("5 0 obj\n")
-    buffer.WriteString("<<\n")
-    buffer.WriteString("/Type /Font\n")
-    buffer.WriteString("/Subtype /Type1\n")
-    buffer.WriteString("/BaseFont /Helvetica\n")
-    buffer.WriteString(">>\n")
-    buffer.WriteString("endobj\n\n")
-
-    return 1 // 返回页面数
+// loadLayoutParts 加载排版需要的样式名称、编号定义、文档设置和脚注
+func (pe *PDFExporter) loadLayoutParts(layout *pdfLayout) {
+    if data := pe.Document.readPart("word/styles.xml"); data != nil {
+        if names, err := parser.ParseStyleNames(data); err == nil {
+            layout.styleNames = names
+        }
+    }
+    if data := pe.Document.readPart("word/numbering.xml"); data != nil {
+        if numbering, err := parser.ParseNumbering(data); err == nil {
+            layout.numbering = numbering
+        } else {
+            pe.Logger.Warning("无法解析编号定义: %v", err)
+        }
+    }
+    if main := pe.Document.mainPart; main != nil && main.Content != nil {
+        layout.sections = main.Content.Sections
+    }
+    var settings *parser.WordSettings
+    if data := pe.Document.readPart("word/settings.xml"); data != nil {
+        if parsed, err := parser.ParseSettings(data); err == nil {
+            settings = parsed
+        } else {
+            pe.Logger.Warning("无法解析文档设置: %v", err)
+        }
+    }
+    if pe.Config.IncludeHeaders || pe.Config.IncludeFooters {
+        pe.loadHeaderFooters(layout, settings)
+    }
+    pe.loadHyphenation(layout, settings)
+    if data := pe.Document.readPart("word/footnotes.xml"); data != nil {
+        if notes, err := parser.ParseFootnotes(data, pe.Document.documentRelationships()); err == nil {
+            layout.footnotes = notes
+        } else {
+            pe.Logger.Warning("无法解析脚注: %v", err)
+        }
+    }
 }
 
-// generateContentStream 生成内容流
-func (pe *PDFExporter) generateContentStream(content *PDFDocumentContent) string {
-    var stream strings.Builder
+// writePDF 写入排版后的页面
+func (pe *PDFExporter) writePDF(layout *pdfLayout) ([]byte, error) {
+    version := "1.4"
+    for _, font := range layout.fonts {
+        if embedded, ok := font.(*trueTypeFont); ok && embedded.pdfVersion() > version {
+            version = embedded.pdfVersion()
+        }
+    }
+    encrypted := pdfEncrypted(pe.Config)
+    aes256 := encrypted && pe.Config.Encryption != types.PDFEncryptionAES128
+    switch {
+    case isPDFA(pe.Config) || aes256:
+        // PDF/A-2以PDF 1.7为基础，AES-256加密需要1.7的Adobe扩展级别8
+        version = "1.7"
+    case encrypted && version < "1.6":
+        version = "1.6"
+    }
 
-    // 开始文本
-    stream.WriteString("BT\n")
-    stream.WriteString("/F1 12 Tf\n")
-    stream.WriteString("72 720 Td\n")
+    metadata := pe.metadata()
+    if metadata.title == "" && layout.tags != nil {
+        // PDF/UA要求文档标题，没有标题属性时使用第一个标题段落
+        metadata.title = layout.tags.title
+    }
+    w := newPDFWriter(version, pe.Config.Compression)
+    w.id = metadata.documentID()
+    if encrypted {
+        encryption, err := newPDFEncryption(pe.Config, w.id)
+        if err != nil {
+            return nil, err
+        }
+        w.encrypt(encryption)
+    }
+    catalogID := w.alloc()
+    pagesID := w.alloc()
+
+    pageIDs := make([]int, len(layout.pages))
+    contentIDs := make([]int, len(layout.pages))
+    for i := range layout.pages {
+        pageIDs[i] = w.alloc()
+        contentIDs[i] = w.alloc()
+    }
+    resourcesID := w.alloc()
+
+    kids := make([]string, len(pageIDs))
+    for i, page := range layout.pages {
+        kids[i] = fmt.Sprintf("%d 0 R", pageIDs[i])
+        mediaBox := "[0 0 " + pdfNumber(page.width) + " " + pdfNumber(page.height) + "]"
+        annots := writePDFLinks(w, layout, i, pageIDs)
+        if layout.tags != nil {
+            // 页面的标记内容在父结构树中以页面序号为键，注释按结构顺序切换焦点
+            annots += fmt.Sprintf(" /StructParents %d /Tabs /S", i)
+        }
+        w.object(pageIDs[i], fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox %s /Resources %d 0 R /Contents %d 0 R%s >>",
+            pagesID, mediaBox, resourcesID, contentIDs[i], annots))
+        w.stream(contentIDs[i], "", page.content.Bytes())
+    }
+    w.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pageIDs)))
+
+    // 字体资源
+    var fonts strings.Builder
+    for _, font := range layout.fonts {
+        var id int
+        switch font := font.(type) {
+        case *trueTypeFont:
+            // 嵌入字体子集，使用Identity-H编码和ToUnicode映射
+            id = font.embed(w)
+        default:
+            id = w.alloc()
+            w.object(id, "<< /Type /Font /Subtype /Type1 /BaseFont /"+font.baseFont()+" /Encoding /WinAnsiEncoding >>")
+        }
+        fmt.Fprintf(&fonts, "/%s %d 0 R ", layout.fontName(font), id)
+    }
+    resources := "<< /ProcSet [/PDF /Text] /Font << " + fonts.String() + ">> "
+    // 图片资源
+    if len(layout.images) > 0 {
+        var images strings.Builder
+        for _, img := range layout.images {
+            fmt.Fprintf(&images, "/%s %d 0 R ", layout.imageName(img), img.embed(w))
+        }
+        resources = "<< /ProcSet [/PDF /Text /ImageB /ImageC] /Font << " + fonts.String() + ">> /XObject << " + images.String() + ">> "
+    }
+    w.object(resourcesID, resources+">>")
 
-    // 添加文本内容
-    lines := strings.Split(content.Text, "\n")
-    for i, line := range lines {
-        if i > 0 {
-            stream.WriteString("0 -14 Td\n")
+    infoID := w.alloc()
+    w.object(infoID, metadata.info())
+    catalog := fmt.Sprintf("/Type /Catalog /Pages %d 0 R", pagesID)
+    if outlineID := writePDFOutline(w, layout.outline, pageIDs); outlineID != 0 {
+        catalog += fmt.Sprintf(" /Outlines %d 0 R /PageMode /UseOutlines", outlineID)
+    }
+    if isPDFA(pe.Config) || layout.tags != nil {
+        catalog += writePDFMetadata(w, metadata, pe.Config)
+    }
+    if isPDFA(pe.Config) {
+        catalog += writePDFOutputIntent(w)
+    }
+    if layout.tags != nil {
+        catalog += fmt.Sprintf(" /MarkInfo << /Marked true >> /StructTreeRoot %d 0 R /ViewerPreferences << /DisplayDocTitle true >>",
+            writePDFStructTree(w, layout.tags, pageIDs))
+        if language := pe.documentLanguage(); language != "" {
+            catalog += " /Lang " + pdfTextString(language)
         }
-        // 转义特殊字符
-        escapedLine := pe.escapePDFString(line)
-        stream.WriteString(fmt.Sprintf("(%s) Tj\n", escapedLine))
     }
+    if aes256 {
+        catalog += " /Extensions << /ADBE << /BaseVersion /1.7 /ExtensionLevel 8 >> >>"
+    }
+    w.object(catalogID, "<< "+catalog+" >>")
 
-    // 结束文本
-    stream.WriteString("ET\n")
+    return w.finish(fmt.Sprintf("/Root %d 0 R /Info %d 0 R", catalogID, infoID)), nil
+}
 
-    return stream.String()
+// pdfProducer 写入文档信息的生成程序名称
+const pdfProducer = "go-word"
+
+// pdfMetadata 文档信息字典和XMP元数据共用的文档信息
+type pdfMetadata struct {
+    title    string
+    author   string
+    subject  string
+    keywords string
+    creator  string
+    date     time.Time
 }
 
-// escapePDFString 转义PDF字符串
-func (pe *PDFExporter) escapePDFString(s string) string {
-    // 转义PDF字符串中的特殊字符
-    s = strings.ReplaceAll(s, "\\", "\\\\")
-    s = strings.ReplaceAll(s, "(", "\\(")
-    s = strings.ReplaceAll(s, ")", "\\)")
-    return s
+// metadata 从核心属性收集文档信息
+func (pe *PDFExporter) metadata() pdfMetadata {
+    properties := pe.Document.GetCoreProperties()
+    return pdfMetadata{
+        title:    properties.Title,
+        author:   properties.Creator,
+        subject:  properties.Subject,
+        keywords: strings.Join(properties.Keywords, ", "),
+        creator:  pe.Config.Creator,
+        // PDF日期只精确到秒，XMP中的日期必须与之一致
+        date: time.Now().Truncate(time.Second),
+    }
 }
 
-// addPDFTrailer 添加PDF尾部
-func (pe *PDFExporter) addPDFTrailer(buffer *bytes.Buffer) {
-    // 添加交叉引用表
-    buffer.WriteString("xref\n")
-    buffer.WriteString("0 6\n")
-    buffer.WriteString("0000000000 65535 f \n")
-    buffer.WriteString("0000000009 00000 n \n")
-    buffer.WriteString("0000000074 00000 n \n")
-    buffer.WriteString("0000000120 00000 n \n")
-    buffer.WriteString("0000000274 00000 n \n")
-    buffer.WriteString("0000000373 00000 n \n")
-
-    // 添加尾部
-    buffer.WriteString("trailer\n")
-    buffer.WriteString("<<\n")
-    buffer.WriteString("/Size 6\n")
-    buffer.WriteString("/Root 1 0 R\n")
-    buffer.WriteString(">>\n")
-    buffer.WriteString("startxref\n")
-    buffer.WriteString("492\n")
-    buffer.WriteString("%%EOF\n")
+// info 生成文档信息字典
+func (m pdfMetadata) info() string {
+    var info strings.Builder
+    info.WriteString("<< ")
+    for _, entry := range []struct{ key, value string }{
+        {"Title", m.title}, {"Author", m.author}, {"Subject", m.subject},
+        {"Keywords", m.keywords}, {"Creator", m.creator},
+    } {
+        if entry.value != "" {
+            info.WriteString("/" + entry.key + " " + pdfTextString(entry.value) + " ")
+        }
+    }
+    info.WriteString("/Producer (" + pdfProducer + ") ")
+    date := pdfTextString(pdfDate(m.date))
+    info.WriteString("/CreationDate " + date + " /ModDate " + date + " >>")
+    return info.String()
+}
+
+// documentID 根据导出时间和文档信息生成文件标识
+func (m pdfMetadata) documentID() []byte {
+    sum := md5.Sum([]byte(m.date.Format(time.RFC3339Nano) + "\x00" + m.info()))
+    return sum[:]
+}
+
+// pdfDate 格式化PDF日期
+func pdfDate(t time.Time) string {
+    _, offset := t.Zone()
+    sign := "+"
+    if offset < 0 {
+        sign = "-"
+        offset = -offset
+    }
+    return fmt.Sprintf("D:%s%s%02d'%02d'", t.Format("20060102150405"), sign, offset/3600, offset%3600/60)
 }
 
 // savePDFFile 保存PDF文件
 func (pe *PDFExporter) savePDFFile(outputPath string, data []byte) error {
-    // 这里应该实现文件保存逻辑
-    // 为了简化，我们只是模拟保存
+    if err := os.WriteFile(outputPath, data, 0644); err != nil {
+        return err
+    }
     pe.Logger.Info("保存PDF文件，路径: %s, 大小: %d", outputPath, len(data))
     return nil
 }
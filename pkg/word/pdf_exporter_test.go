@@ -0,0 +1,1055 @@
+package word
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// pdfTestDocument builds a document with the given number of long paragraphs
+func pdfTestDocument(t *testing.T, paragraphs int) *Document {
+	t.Helper()
+
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Annual Report</w:t></w:r></w:p>`)
+	for i := 0; i < paragraphs; i++ {
+		fmt.Fprintf(&body, `<w:p><w:pPr><w:jc w:val="both"/><w:spacing w:after="120"/><w:ind w:firstLine="360"/></w:pPr><w:r><w:t xml:space="preserve">Paragraph %d: </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>The quick brown fox jumps over the lazy dog (and (escapes) \ backslashes) while the layout engine measures every word.</w:t></w:r></w:p>`, i)
+	}
+	body.WriteString(`<w:p><w:r><w:t>Before break</w:t></w:r><w:r><w:br w:type="page"/></w:r><w:r><w:t>After break</w:t></w:r></w:p>`)
+	body.WriteString(`<w:p><w:pPr><w:pageBreakBefore/></w:pPr><w:r><w:t>Supercalifragilisticexpialidocious-supercalifragilisticexpialidocious-supercalifragilisticexpialidocious-supercalifragilisticexpialidocious</w:t></w:r></w:p>`)
+	body.WriteString(`</w:body></w:document>`)
+
+	doc, err := Open(writeTestPackage(t, map[string]string{"word/document.xml": body.String()}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	return doc
+}
+
+// checkPDFStructure verifies that every cross reference entry points at its object
+func checkPDFStructure(t *testing.T, data []byte) {
+	t.Helper()
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if match == nil {
+		t.Fatal("缺少startxref")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref没有指向交叉引用表")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if prefix := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(data[offset:], []byte(prefix)) {
+			t.Errorf("对象 %d 的偏移量错误", i+1)
+		}
+	}
+}
+
+func TestPDFExporterPagination(t *testing.T) {
+	doc := pdfTestDocument(t, 80)
+	defer doc.Close()
+
+	config := getDefaultPDFConfig()
+	config.PageSize = types.PDFPageSizeLetter
+	config.Orientation = types.PDFOrientationLandscape
+	config.Compression = false
+
+	var buf bytes.Buffer
+	result, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("PDF导出失败: %v", err)
+	}
+	data := buf.Bytes()
+	checkPDFStructure(t, data)
+
+	pages := bytes.Count(data, []byte("/Type /Page /Parent"))
+	if result.PageCount < 4 || result.PageCount != pages {
+		t.Errorf("页数应该与页面对象一致: PageCount=%d, 页面对象=%d", result.PageCount, pages)
+	}
+	if !bytes.Contains(data, []byte(fmt.Sprintf("/Count %d", pages))) {
+		t.Error("页面树的数量错误")
+	}
+	if !bytes.Contains(data, []byte("/MediaBox [0 0 792 612]")) {
+		t.Error("横向Letter页面尺寸错误")
+	}
+	for _, expected := range []string{
+		"/BaseFont /Helvetica-Bold",
+		`(\(escapes\)) Tj`,
+		`(\\) Tj`,
+		"(After) Tj",
+	} {
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("PDF应该包含 %q", expected)
+		}
+	}
+
+	// 所有文字都在页边距以内
+	for _, match := range regexp.MustCompile(`1 0 0 1 ([\d.]+) ([\d.]+) Tm`).FindAllSubmatch(data, -1) {
+		x, _ := strconv.ParseFloat(string(match[1]), 64)
+		y, _ := strconv.ParseFloat(string(match[2]), 64)
+		if x < 72-0.01 || x > 792-72 || y < 72 || y > 612-72 {
+			t.Errorf("文字位置超出页边距: %s %s", match[1], match[2])
+		}
+	}
+}
+
+func TestPDFLayoutBreakLines(t *testing.T) {
+	layout := newPDFLayout(context.Background(), getDefaultPDFConfig())
+	paragraph := &types.Paragraph{Runs: []types.Run{
+		{Text: "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore."},
+		{Text: "Averyveryveryveryverylongwordwithoutanyspacesthatcannotfitonasinglelineatall", Bold: true},
+	}}
+
+	fragments := layout.paragraphFragments(paragraph, 12, false)
+	lines := layout.breakLines(fragments, 200, 0, 12, nil)
+	if len(lines) < 4 {
+		t.Fatalf("应该折成多行，实际 %d 行", len(lines))
+	}
+
+	var text strings.Builder
+	for i, line := range lines {
+		if line.width > 200+0.01 {
+			t.Errorf("第 %d 行宽度 %.2f 超出可用宽度", i+1, line.width)
+		}
+		if i < len(lines)-1 && line.last {
+			t.Errorf("第 %d 行不应该标记为最后一行", i+1)
+		}
+		for _, fragment := range line.fragments {
+			text.WriteString(fragment.text)
+		}
+	}
+	if got, want := text.String(), paragraph.Runs[0].Text+paragraph.Runs[1].Text; got != want {
+		t.Errorf("折行后文字丢失:\n%s\n%s", got, want)
+	}
+}
+
+func TestPDFLayoutKinsokuAndBidi(t *testing.T) {
+	config := getDefaultPDFConfig()
+	// 没有字体时中文字符按问号的宽度计算，每个字符 5.56 磅
+	config.FontDirectories = []string{t.TempDir()}
+	layout := newPDFLayout(context.Background(), config)
+
+	lineTexts := func(lines []pdfPlacedLine) []string {
+		var texts []string
+		for _, placed := range lines {
+			var text strings.Builder
+			for _, fragment := range placed.line.fragments {
+				text.WriteString(fragment.text)
+			}
+			texts = append(texts, text.String())
+		}
+		return texts
+	}
+
+	// 一行能放下5个字符，但逗号不能位于行首
+	chinese := &types.Paragraph{Runs: []types.Run{{Text: "一二三四五，六七", FontSize: 20}}}
+	lines, _ := layout.paragraphLines(chinese, 5*5.56+1)
+	if got, want := lineTexts(lines), []string{"一二三四", "五，六七"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("避头尾折行错误: %q", got)
+	}
+	if len(lines[0].line.fragments) != 1 {
+		t.Errorf("同一行的字符应该合并为一个片段，实际 %d 个", len(lines[0].line.fragments))
+	}
+
+	// 从右到左段落中的数字和拉丁文字保持从左到右，括号使用镜像字形
+	hebrew := &types.Paragraph{Bidi: true, Runs: []types.Run{{Text: "שלום (abc) 123", FontSize: 20}}}
+	lines, _ = layout.paragraphLines(hebrew, 400)
+	if got := lineTexts(lines); len(got) != 1 || got[0] != "123 (abc) םולש" {
+		t.Errorf("双向文字的显示顺序错误: %q", got)
+	}
+	if lines[0].alignment != "right" || lines[0].offset != 0 {
+		t.Errorf("从右到左段落应该靠右对齐: %q %.2f", lines[0].alignment, lines[0].offset)
+	}
+
+	// 从左到右段落中的希伯来文单词倒序
+	mixed := &types.Paragraph{Runs: []types.Run{{Text: "see שלום here", FontSize: 20}}}
+	lines, _ = layout.paragraphLines(mixed, 400)
+	if got := lineTexts(lines); len(got) != 1 || got[0] != "see םולש here" {
+		t.Errorf("双向文字的显示顺序错误: %q", got)
+	}
+}
+
+func TestPDFLayoutHyphenation(t *testing.T) {
+	dir := t.TempDir()
+	// Liang论文中断开 hyphenation 的规则
+	patterns := "hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n\n"
+	if err := os.WriteFile(filepath.Join(dir, "hyph-en-us.pat"), []byte(patterns), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Open(writeTestPackage(t, map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:suppressAutoHyphens/></w:pPr><w:r><w:rPr><w:lang w:val="de-DE"/></w:rPr><w:t>Text</w:t></w:r></w:p></w:body></w:document>`,
+		"word/settings.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:autoHyphenation/><w:consecutiveHyphenLimit w:val="1"/><w:hyphenationZone w:val="100"/></w:settings>`,
+	}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+	parsed := doc.GetMainPart().Content.Paragraphs[0]
+	if !parsed.SuppressAutoHyphens || parsed.Runs[0].Language != "de-DE" {
+		t.Errorf("段落的禁止断字或运行的语言解析错误: %v %q", parsed.SuppressAutoHyphens, parsed.Runs[0].Language)
+	}
+
+	config := getDefaultPDFConfig()
+	config.HyphenationDirectory = dir
+	layout := newPDFLayout(context.Background(), config)
+	NewPDFExporter(doc, config).loadLayoutParts(layout)
+	if layout.hyphenation == nil {
+		t.Fatal("文档启用了自动断字，应该加载断字规则")
+	}
+	if layout.hyphenation.zone != 5 || layout.hyphenation.limit != 1 {
+		t.Errorf("断字区域或连续断字限制错误: %.2f %d", layout.hyphenation.zone, layout.hyphenation.limit)
+	}
+
+	breakText := func(paragraph *types.Paragraph) string {
+		lines, _ := layout.paragraphLines(paragraph, 90)
+		var texts []string
+		for _, placed := range lines {
+			var text strings.Builder
+			for _, fragment := range placed.line.fragments {
+				text.WriteString(fragment.text)
+			}
+			texts = append(texts, strings.TrimSpace(text.String()))
+		}
+		return strings.Join(texts, "|")
+	}
+	const sample = "The hyphenation, the hyphenation, the hyphenation."
+	// 第二行紧跟断字的行，受连续断字限制；第三行末尾的空白小于断字区域
+	if got, want := breakText(&types.Paragraph{Runs: []types.Run{{Text: sample}}}), "The hyphen-|ation, the|hyphenation, the|hyphenation."; got != want {
+		t.Errorf("断字折行错误: %s", got)
+	}
+	if got, want := breakText(&types.Paragraph{Runs: []types.Run{{Text: sample, Language: "en-US"}}}), "The hyphen-|ation, the|hyphenation, the|hyphenation."; got != want {
+		t.Errorf("英文运行应该按英文规则断字: %s", got)
+	}
+
+	// 禁止断字的段落和没有断字规则的语言不断字
+	unhyphenated := "The|hyphenation, the|hyphenation, the|hyphenation."
+	if got := breakText(&types.Paragraph{SuppressAutoHyphens: true, Runs: []types.Run{{Text: sample}}}); got != unhyphenated {
+		t.Errorf("禁止断字的段落不应该断字: %s", got)
+	}
+	if got := breakText(&types.Paragraph{Runs: []types.Run{{Text: sample, Language: "de-DE"}}}); got != unhyphenated {
+		t.Errorf("没有德文断字规则的运行不应该断字: %s", got)
+	}
+
+	// 没有启用自动断字的文档不断字
+	layout = newPDFLayout(context.Background(), config)
+	NewPDFExporter(pdfTestDocument(t, 1), config).loadLayoutParts(layout)
+	if layout.hyphenation != nil {
+		t.Error("文档没有启用自动断字")
+	}
+}
+
+func TestPDFExporterExportToPDF(t *testing.T) {
+	doc := pdfTestDocument(t, 3)
+	defer doc.Close()
+
+	output := filepath.Join(t.TempDir(), "out.pdf")
+	result, err := NewPDFExporter(doc, nil).ExportToPDF(context.Background(), output)
+	if err != nil {
+		t.Fatalf("PDF导出失败: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("PDF文件没有写入: %v", err)
+	}
+	if int64(len(data)) != result.FileSize || !bytes.HasPrefix(data, []byte("%PDF-1.4")) {
+		t.Error("写入的文件与导出结果不一致")
+	}
+	// 分页符和段前分页各开始一页
+	if result.PageCount != 3 {
+		t.Errorf("应该有3页，实际 %d 页", result.PageCount)
+	}
+	checkPDFStructure(t, data)
+}
+
+// pdfTableTestDocument builds a document with pictures and a long table
+// with a header row, borders, shading, a merged region and a spanned row
+func pdfTableTestDocument(t *testing.T) *Document {
+	t.Helper()
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	transparent.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	transparent.Set(1, 1, color.NRGBA{B: 255, A: 128})
+	var pngData, jpegData bytes.Buffer
+	png.Encode(&pngData, transparent)
+	jpeg.Encode(&jpegData, image.NewRGBA(image.Rect(0, 0, 4, 4)), nil)
+
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+  xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+  xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"
+  xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><w:body>
+<w:p><w:r><w:t xml:space="preserve">Logo </w:t></w:r><w:r><w:drawing><wp:inline><wp:extent cx="952500" cy="476250"/><wp:docPr id="1" name="Picture 1" descr="Logo"/>
+<a:graphic><a:graphicData><pic:pic><pic:blipFill><a:blip r:embed="rId1"/></pic:blipFill></pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r>
+<w:r><w:drawing><wp:inline><wp:extent cx="381000" cy="381000"/><wp:docPr id="2" name="Picture 2" descr="Photo"/>
+<a:graphic><a:graphicData><pic:pic><pic:blipFill><a:blip r:embed="rId2"/></pic:blipFill></pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p>
+<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblBorders>
+<w:top w:val="single" w:sz="8" w:color="FF0000"/><w:left w:val="single" w:sz="4"/><w:bottom w:val="single" w:sz="8" w:color="FF0000"/>
+<w:right w:val="single" w:sz="4"/><w:insideH w:val="dashed" w:sz="4"/><w:insideV w:val="single" w:sz="4"/></w:tblBorders></w:tblPr>
+<w:tblGrid><w:gridCol w:w="2880"/><w:gridCol w:w="5760"/></w:tblGrid>
+<w:tr><w:trPr><w:tblHeader/></w:trPr><w:tc><w:tcPr><w:shd w:val="clear" w:fill="D9E2F3"/></w:tcPr><w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Header</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Value</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:tcPr><w:vMerge w:val="restart"/></w:tcPr><w:p><w:r><w:t>Merged</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>first</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:tcPr><w:vMerge/></w:tcPr><w:p/></w:tc><w:tc><w:p><w:r><w:t>second</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t>Spanned</w:t></w:r></w:p></w:tc></w:tr>`)
+	for i := 0; i < 60; i++ {
+		fmt.Fprintf(&body, `<w:tr><w:tc><w:p><w:r><w:t>Row %d</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Contract clause text that is long enough to wrap onto a second line inside the wide column of the table.</w:t></w:r></w:p></w:tc></w:tr>`, i)
+	}
+	body.WriteString(`</w:tbl><w:p><w:r><w:t>End</w:t></w:r></w:p></w:body></w:document>`)
+
+	doc, err := Open(writeTestPackage(t, map[string]string{
+		"word/document.xml": body.String(),
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"/>
+  <Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image2.jpeg"/>
+</Relationships>`,
+		"word/media/image1.png":  pngData.String(),
+		"word/media/image2.jpeg": jpegData.String(),
+	}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	return doc
+}
+
+func TestPDFExporterTablesAndImages(t *testing.T) {
+	doc := pdfTableTestDocument(t)
+	defer doc.Close()
+
+	config := getDefaultPDFConfig()
+	config.Compression = false
+	var buf bytes.Buffer
+	result, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("PDF导出失败: %v", err)
+	}
+	data := buf.Bytes()
+	checkPDFStructure(t, data)
+
+	if result.PageCount < 2 {
+		t.Fatalf("表格应该跨页，实际 %d 页", result.PageCount)
+	}
+	// 表头行在每一页重复
+	if headers := bytes.Count(data, []byte("(Header) Tj")); headers != result.PageCount {
+		t.Errorf("表头应该出现 %d 次，实际 %d 次", result.PageCount, headers)
+	}
+	if merged := bytes.Count(data, []byte("(Merged) Tj")); merged != 1 {
+		t.Errorf("合并单元格的内容应该只出现一次，实际 %d 次", merged)
+	}
+
+	for _, expected := range []string{
+		"/Filter /DCTDecode",
+		"/Width 2 /Height 2 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /SMask",
+		"/XObject << /Im1",
+		// 100x50像素的图片按96 DPI换算为75x37.5磅
+		"q 75 0 0 37.5 ",
+		"q 30 0 0 30 ",
+		// 单元格底纹和表格边框
+		"q 0.85 0.89 0.95 rg ",
+		"q 1 0 0 RG 1 w [] 0 d",
+		"q 0 0 0 RG 0.5 w [2 1] 0 d",
+	} {
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("PDF应该包含 %q", expected)
+		}
+	}
+
+	// 第一列宽144磅，列之间的竖线在 x=216
+	if !regexp.MustCompile(`q 0 0 0 RG 0\.5 w \[\] 0 d 216 [\d.]+ m 216 [\d.]+ l S Q`).Match(data) {
+		t.Error("表格列宽错误")
+	}
+	// 所有文字都在页边距以内
+	for _, match := range regexp.MustCompile(`1 0 0 1 ([\d.]+) ([\d.]+) Tm`).FindAllSubmatch(data, -1) {
+		y, _ := strconv.ParseFloat(string(match[2]), 64)
+		if y < 72 || y > 841.89-72 {
+			t.Errorf("文字位置超出页边距: %s", match[2])
+		}
+	}
+}
+
+func TestPDFLayoutTableSplitsTallRows(t *testing.T) {
+	layout := newPDFLayout(context.Background(), getDefaultPDFConfig())
+	layout.newPage()
+
+	long := strings.Repeat("word ", 2000)
+	table := &types.Table{Rows: []types.TableRow{
+		{Cells: []types.TableCell{{Text: "short"}, {Text: long}}},
+		{Cells: []types.TableCell{{Text: "after"}, {Text: "after"}}},
+	}}
+	layout.layoutTable(table)
+
+	if len(layout.pages) < 3 {
+		t.Fatalf("超过一页的行应该拆分到多页，实际 %d 页", len(layout.pages))
+	}
+	words := 0
+	for _, page := range layout.pages {
+		words += bytes.Count(page.content.Bytes(), []byte("(word) Tj"))
+		if layout.y < layout.bottom-0.01 {
+			t.Error("表格超出了页面底部")
+		}
+	}
+	if words != 2000 {
+		t.Errorf("拆分后文字丢失: %d", words)
+	}
+}
+
+func TestPDFExporterOutlineAndLinks(t *testing.T) {
+	doc, err := Open(writeTestPackage(t, map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body>
+<w:p><w:hyperlink w:anchor="_Toc1"><w:r><w:t>Contents entry</w:t></w:r></w:hyperlink></w:p>
+<w:p><w:r><w:t xml:space="preserve">Visit </w:t></w:r><w:hyperlink r:id="rId1"><w:r><w:t>our site</w:t></w:r></w:hyperlink><w:r><w:footnoteReference w:id="1"/></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/><w:pageBreakBefore/></w:pPr><w:bookmarkStart w:id="0" w:name="_Toc1"/><w:r><w:t>Intro</w:t></w:r><w:bookmarkEnd w:id="0"/></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Details</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading3"/></w:pPr><w:r><w:t>Deep</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Summary</w:t></w:r></w:p>
+</w:body></w:document>`,
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com/a b" TargetMode="External"/>
+</Relationships>`,
+		"word/footnotes.xml": markdownTestFootnotes,
+	}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	config := getDefaultPDFConfig()
+	config.Compression = false
+	var buf bytes.Buffer
+	if _, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &buf); err != nil {
+		t.Fatalf("PDF导出失败: %v", err)
+	}
+	data := buf.Bytes()
+	checkPDFStructure(t, data)
+
+	for _, expected := range []string{
+		"/PageMode /UseOutlines",
+		"/A << /S /URI /URI (https://example.com/a%20b) >>",
+		"(our) Tj",
+		// 脚注编号上标显示，脚注放在正文之后
+		"/F1 7.8 Tf",
+		"(1) Tj",
+		"(1.) Tj",
+		"(Footnote) Tj",
+	} {
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("PDF应该包含 %q", expected)
+		}
+	}
+
+	objects := make(map[string]string)
+	for _, match := range regexp.MustCompile(`(?s)(\d+) 0 obj\n(.*?)\nendobj`).FindAllSubmatch(data, -1) {
+		objects[string(match[1])] = string(match[2])
+	}
+	pageRefs := regexp.MustCompile(`/Kids \[([^\]]*)\]`).FindSubmatch(data)
+	if pageRefs == nil {
+		t.Fatal("缺少页面树")
+	}
+	pages := strings.Fields(strings.ReplaceAll(string(pageRefs[1]), " 0 R", ""))
+	if len(pages) != 2 {
+		t.Fatalf("应该有2页，实际 %d 页", len(pages))
+	}
+
+	// 目录链接和书签都指向第二页
+	itemFor := func(title string) (string, string) {
+		for id, object := range objects {
+			if strings.Contains(object, "/Title ("+title+")") {
+				return id, object
+			}
+		}
+		t.Fatalf("缺少书签 %s", title)
+		return "", ""
+	}
+	introID, intro := itemFor("Intro")
+	detailsID, details := itemFor("Details")
+	_, deep := itemFor("Deep")
+	summaryID, _ := itemFor("Summary")
+	if !strings.Contains(intro, "/Dest ["+pages[1]+" 0 R /XYZ null") || !strings.Contains(intro, "/Next "+summaryID+" 0 R") {
+		t.Errorf("一级书签错误: %s", intro)
+	}
+	if !strings.Contains(details, "/Parent "+introID+" 0 R") || !strings.Contains(deep, "/Parent "+detailsID+" 0 R") {
+		t.Errorf("书签层次错误: %s / %s", details, deep)
+	}
+	if !strings.Contains(intro, "/Count 2") {
+		t.Errorf("书签子项数量错误: %s", intro)
+	}
+
+	var internal []string
+	for _, object := range objects {
+		if strings.Contains(object, "/Subtype /Link") && strings.Contains(object, "/Dest [") {
+			internal = append(internal, object)
+		}
+	}
+	if len(internal) != 2 {
+		t.Fatalf("目录和脚注应该各有一个内部链接，实际 %d 个", len(internal))
+	}
+	toTOC := 0
+	for _, object := range internal {
+		if strings.Contains(object, "/Dest ["+pages[1]+" 0 R /XYZ null 769.89 null]") {
+			toTOC++
+		}
+	}
+	if toTOC != 1 {
+		t.Errorf("目录链接应该指向第二页的标题: %v", internal)
+	}
+}
+
+func TestPDFExporterPDFA(t *testing.T) {
+	transparent := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	transparent.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	var pngData bytes.Buffer
+	png.Encode(&pngData, transparent)
+
+	doc, err := Open(writeTestPackage(t, map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+  xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+  xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"
+  xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Contract</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Signed by both parties. </w:t></w:r><w:r><w:drawing><wp:inline><wp:extent cx="190500" cy="190500"/><wp:docPr id="1" name="Picture 1" descr="Seal"/>
+<a:graphic><a:graphicData><pic:pic><pic:blipFill><a:blip r:embed="rId1"/></pic:blipFill></pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p>
+</w:body></w:document>`,
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"/>
+</Relationships>`,
+		"word/media/image1.png": pngData.String(),
+		"docProps/core.xml":     epubTestCore,
+	}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	config := getDefaultPDFConfig()
+	config.Compression = false
+	config.Conformance = types.PDFConformancePDFA2B
+	config.DefaultFont = "Go"
+	config.FontDirectories = []string{filepath.Join("testdata", "fonts")}
+
+	var buf bytes.Buffer
+	if _, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &buf); err != nil {
+		t.Fatalf("PDF/A导出失败: %v", err)
+	}
+	data := buf.Bytes()
+	checkPDFStructure(t, data)
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.7\n")) {
+		t.Error("PDF/A-2应该使用PDF 1.7")
+	}
+	for _, expected := range []string{
+		"/Type /Metadata /Subtype /XML",
+		"<pdfaid:part>2</pdfaid:part>",
+		"<pdfaid:conformance>B</pdfaid:conformance>",
+		`<dc:title><rdf:Alt><rdf:li xml:lang="x-default">User Manual</rdf:li></rdf:Alt></dc:title>`,
+		"<dc:creator><rdf:Seq><rdf:li>Docs Team</rdf:li></rdf:Seq></dc:creator>",
+		"<pdf:Keywords>manual, guide</pdf:Keywords>",
+		"/Title (User Manual) /Author (Docs Team) /Keywords (manual, guide)",
+		"/OutputIntents [",
+		"/S /GTS_PDFA1 /OutputConditionIdentifier (sRGB IEC61966-2.1)",
+		"/N 3",
+		"/FontFile2",
+		// 透明图片合成到白色背景上
+		"/Width 2 /Height 2 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode",
+	} {
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("PDF/A应该包含 %q", expected)
+		}
+	}
+	for _, forbidden := range []string{"/Subtype /Type1", "/SMask", "/Encrypt"} {
+		if bytes.Contains(data, []byte(forbidden)) {
+			t.Errorf("PDF/A不应该包含 %q", forbidden)
+		}
+	}
+
+	// 信息字典和XMP中的日期与文件标识一致
+	id := regexp.MustCompile(`/ID \[<([0-9A-F]{32})> <([0-9A-F]{32})>\]`).FindSubmatch(data)
+	if id == nil || !bytes.Equal(id[1], id[2]) {
+		t.Fatal("缺少文件标识")
+	}
+	uuid := strings.ToLower(string(id[1][:8]) + "-" + string(id[1][8:12]))
+	if !bytes.Contains(data, []byte("<xmpMM:DocumentID>uuid:"+uuid)) {
+		t.Error("XMP中的文档标识应该与文件标识一致")
+	}
+	infoDate := regexp.MustCompile(`/CreationDate \(D:(\d{4})(\d\d)(\d\d)(\d\d)(\d\d)(\d\d)`).FindSubmatch(data)
+	if infoDate == nil || !bytes.Contains(data, []byte(fmt.Sprintf("<xmp:CreateDate>%s-%s-%sT%s:%s:%s", infoDate[1], infoDate[2], infoDate[3], infoDate[4], infoDate[5], infoDate[6]))) {
+		t.Error("XMP中的创建日期应该与信息字典一致")
+	}
+
+	// 找不到可嵌入的字体时不能生成PDF/A
+	config.FontDirectories = []string{t.TempDir()}
+	if _, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &bytes.Buffer{}); err == nil {
+		t.Error("没有可嵌入的字体时PDF/A导出应该失败")
+	}
+}
+
+func TestSRGBICCProfile(t *testing.T) {
+	profile := srgbICCProfile()
+	if size := binary.BigEndian.Uint32(profile); int(size) != len(profile) {
+		t.Errorf("配置文件大小错误: %d / %d", size, len(profile))
+	}
+	if string(profile[12:24]) != "mntrRGB XYZ " || string(profile[36:40]) != "acsp" {
+		t.Error("配置文件头错误")
+	}
+	count := int(binary.BigEndian.Uint32(profile[128:]))
+	for i := 0; i < count; i++ {
+		entry := profile[132+12*i:]
+		offset, size := binary.BigEndian.Uint32(entry[4:]), binary.BigEndian.Uint32(entry[8:])
+		if offset%4 != 0 || int(offset+size) > len(profile) {
+			t.Errorf("标签 %s 的位置错误", entry[:4])
+		}
+	}
+}
+
+// pdfTestStream returns the data of a stream object
+func pdfTestStream(t *testing.T, data []byte, id string) []byte {
+	t.Helper()
+	match := regexp.MustCompile(`(?s)\n` + id + ` 0 obj\n<<[^\n]*/Length (\d+) >>\nstream\n`).FindSubmatchIndex(data)
+	if match == nil {
+		t.Fatalf("找不到流对象 %s", id)
+	}
+	length, _ := strconv.Atoi(string(data[match[2]:match[3]]))
+	return data[match[1] : match[1]+length]
+}
+
+// pdfTestDecrypt decrypts AES-CBC data whose initialization vector comes first
+func pdfTestDecrypt(t *testing.T, key, data []byte) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil || len(data) < 32 || len(data)%16 != 0 {
+		t.Fatalf("无法解密: %v", err)
+	}
+	plain := make([]byte, len(data)-16)
+	cipher.NewCBCDecrypter(block, data[:16]).CryptBlocks(plain, data[16:])
+	return plain[:len(plain)-int(plain[len(plain)-1])]
+}
+
+func TestPDFExporterEncryption(t *testing.T) {
+	doc := pdfTestDocument(t, 2)
+	defer doc.Close()
+
+	hexValue := func(data []byte, key string) []byte {
+		match := regexp.MustCompile(`/` + key + ` <([0-9A-F]+)>`).FindSubmatch(data)
+		if match == nil {
+			t.Fatalf("加密字典缺少 /%s", key)
+		}
+		value, _ := hex.DecodeString(string(match[1]))
+		return value
+	}
+
+	for _, encryption := range []types.PDFEncryption{types.PDFEncryptionAES128, types.PDFEncryptionAES256} {
+		t.Run(string(encryption), func(t *testing.T) {
+			config := getDefaultPDFConfig()
+			config.Compression = false
+			config.Encryption = encryption
+			config.Permissions = types.PDFPermissions{AllowPrint: true, UserPassword: "reader", OwnerPassword: "hr-owner"}
+
+			var buf bytes.Buffer
+			if _, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &buf); err != nil {
+				t.Fatalf("PDF导出失败: %v", err)
+			}
+			data := buf.Bytes()
+			checkPDFStructure(t, data)
+
+			if !regexp.MustCompile(`/Encrypt \d+ 0 R`).Match(data) {
+				t.Fatal("trailer缺少 /Encrypt")
+			}
+			if bytes.Contains(data, []byte("(go-word)")) || bytes.Contains(data, []byte("(Annual) Tj")) {
+				t.Error("字符串和内容流应该被加密")
+			}
+			// 允许打印和辅助功能提取，不允许修改、复制和注释
+			if !bytes.Contains(data, []byte("/P -1340 ")) {
+				t.Error("权限标志错误")
+			}
+
+			var key []byte
+			if encryption == types.PDFEncryptionAES128 {
+				id := regexp.MustCompile(`/ID \[<([0-9A-F]+)>`).FindSubmatch(data)
+				documentID, _ := hex.DecodeString(string(id[1]))
+				key = pdfFileKeyR4("reader", hexValue(data, "O"), -1340, documentID)
+				// 用户密码正确时重新计算的 /U 与文件中的一致
+				if !bytes.Equal(pdfUserHashR4(key, documentID)[:16], hexValue(data, "U")[:16]) {
+					t.Fatal("用户密码验证失败")
+				}
+			} else {
+				u := hexValue(data, "U")
+				if !bytes.Equal(pdfHashR6([]byte("reader"), u[32:40], nil), u[:32]) {
+					t.Fatal("用户密码验证失败")
+				}
+				if bytes.Equal(pdfHashR6([]byte("wrong"), u[32:40], nil), u[:32]) {
+					t.Fatal("错误的密码不应该通过验证")
+				}
+				intermediate := pdfHashR6([]byte("reader"), u[40:48], nil)
+				block, _ := aes.NewCipher(intermediate)
+				key = make([]byte, 32)
+				cipher.NewCBCDecrypter(block, make([]byte, 16)).CryptBlocks(key, hexValue(data, "UE"))
+
+				perms := hexValue(data, "Perms")
+				block, _ = aes.NewCipher(key)
+				block.Decrypt(perms, perms)
+				if int32(binary.LittleEndian.Uint32(perms)) != -1340 || string(perms[9:12]) != "adb" {
+					t.Error("/Perms 与权限标志不一致")
+				}
+				if !bytes.Contains(data, []byte("/ExtensionLevel 8")) {
+					t.Error("AES-256加密需要扩展级别8")
+				}
+			}
+
+			contents := regexp.MustCompile(`/Contents (\d+) 0 R`).FindSubmatch(data)
+			objectKey := (&pdfEncryption{revision: map[types.PDFEncryption]int{types.PDFEncryptionAES128: 4, types.PDFEncryptionAES256: 6}[encryption], key: key})
+			id, _ := strconv.Atoi(string(contents[1]))
+			content := pdfTestDecrypt(t, objectKey.objectKey(id), pdfTestStream(t, data, string(contents[1])))
+			if !bytes.Contains(content, []byte("(Annual) Tj")) {
+				t.Error("解密后的内容流错误")
+			}
+		})
+	}
+
+	config := getDefaultPDFConfig()
+	config.Conformance = types.PDFConformancePDFA2B
+	config.Permissions.UserPassword = "reader"
+	if _, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &bytes.Buffer{}); err == nil {
+		t.Error("PDF/A不允许加密")
+	}
+}
+
+// TestPDFEncryptionKnownAnswers checks the security handlers against values
+// computed with an independent implementation of the algorithms of ISO
+// 32000-2 for fixed passwords, file identifier and random bytes
+func TestPDFEncryptionKnownAnswers(t *testing.T) {
+	random := make([]byte, 68)
+	for i := range random {
+		random[i] = byte(i)
+	}
+	id := random[:16]
+
+	for _, test := range []struct {
+		name       string
+		encryption *pdfEncryption
+		entries    map[string]string
+		objectKey  string
+		// stream is an encrypted content stream of object 7 with the
+		// initialization vector A0..AF
+		stream string
+	}{
+		{
+			name:       "R4",
+			encryption: newPDFEncryptionR4("reader", "hr-owner", -1340, id),
+			entries: map[string]string{
+				"O": "99BEAEDCA5B4997C1076E88149DE4F976B147FC2A598389E0B6FF50C870F8E43",
+				// 后16字节是任意填充
+				"U": "105B8A3D200EB58BDC4E4027EE5802A528BF4E5E4E758A4164004E56FFFA0108",
+			},
+			objectKey: "A5C38D4F247B1438CCA6E39FC4E1C11B",
+			stream:    "A0A1A2A3A4A5A6A7A8A9AAABACADAEAF77586BB06E14C62C439BA9BD994D6F235E48A5FC596BF4EB8314834ED6349087",
+		},
+		{
+			name:       "R6",
+			encryption: newPDFEncryptionR6From("reader", "hr-owner", -1340, random),
+			entries: map[string]string{
+				"O":     "97305917DFDE1BD2C131512968D50065BAC27AAD431EEF2EC04678954C37DB1E303132333435363738393A3B3C3D3E3F",
+				"U":     "3E7CE5E9EA89DF3544AFACD00F72FA98974F901D9F444E5128A433F1219500CB202122232425262728292A2B2C2D2E2F",
+				"OE":    "0A9E613E635CD5B207999A9593E0AEA318FDDDCD167405F2AF630DD96E55CE95",
+				"UE":    "B857DE32BBD114C7ADD758F69F6FE5CDC0A6F56C7BB1E33815612C2C8F876D30",
+				"Perms": "EAEFF1C76BDDC78467A798ABE5AD203E",
+			},
+			objectKey: "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			stream:    "A0A1A2A3A4A5A6A7A8A9AAABACADAEAF29C8BB184D0848D613AA52989EE475F9236024422DA0D47E9A117B48D57BCF14",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for key, want := range test.entries {
+				match := regexp.MustCompile(`/` + key + ` <([0-9A-F]+)>`).FindStringSubmatch(test.encryption.dictionary)
+				if match == nil || match[1] != want {
+					t.Errorf("/%s 错误: %v", key, match)
+				}
+			}
+			if got := strings.ToUpper(hex.EncodeToString(test.encryption.objectKey(7))); got != test.objectKey {
+				t.Errorf("对象密钥错误: %s", got)
+			}
+
+			const content = "BT /F1 12 Tf (Annual) Tj ET"
+			objectKey, _ := hex.DecodeString(test.objectKey)
+			stream, _ := hex.DecodeString(test.stream)
+			if plain := pdfTestDecrypt(t, test.encryption.objectKey(7), stream); string(plain) != content {
+				t.Errorf("已知密文解密错误: %q", plain)
+			}
+			if plain := pdfTestDecrypt(t, objectKey, test.encryption.encrypt(7, []byte(content))); string(plain) != content {
+				t.Errorf("加密的流无法用已知密钥解密: %q", plain)
+			}
+		})
+	}
+}
+
+func TestPDFEncryptionStrings(t *testing.T) {
+	text, end := pdfLiteralString(`(a\(b\) (nested) \\ \101\n\
+c) rest`, 0)
+	if string(text) != "a(b) (nested) \\ A\nc" || end != 30 {
+		t.Errorf("字符串解码错误: %q %d", text, end)
+	}
+
+	e := &pdfEncryption{revision: 6, key: bytes.Repeat([]byte{7}, 32)}
+	body := e.encryptStrings(3, "<< /Title (Salary \\(2026\\)) /Kids [1 0 R] /ID <0A0B> >>")
+	match := regexp.MustCompile(`^<< /Title <([0-9A-F]+)> /Kids \[1 0 R\] /ID <([0-9A-F]+)> >>$`).FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("加密后的对象结构错误: %s", body)
+	}
+	title, _ := hex.DecodeString(match[1])
+	id, _ := hex.DecodeString(match[2])
+	if string(pdfTestDecrypt(t, e.key, title)) != "Salary (2026)" || !bytes.Equal(pdfTestDecrypt(t, e.key, id), []byte{10, 11}) {
+		t.Error("字符串加密错误")
+	}
+}
+
+func TestPDFExporterTagged(t *testing.T) {
+	var pngData bytes.Buffer
+	png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+
+	doc, err := Open(writeTestPackage(t, map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+  xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+  xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"
+  xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Quarterly Report</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">See </w:t></w:r><w:hyperlink r:id="rId2"><w:r><w:t>the website</w:t></w:r></w:hyperlink></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>First item</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Nested item</w:t></w:r></w:p>
+<w:tbl><w:tblPr><w:tblBorders><w:insideH w:val="single" w:sz="4"/></w:tblBorders></w:tblPr><w:tblGrid><w:gridCol w:w="2880"/><w:gridCol w:w="2880"/></w:tblGrid>
+<w:tr><w:trPr><w:tblHeader/></w:trPr><w:tc><w:p><w:r><w:t>Region</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Sales</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>North</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>42</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+<w:p><w:r><w:drawing><wp:inline><wp:extent cx="190500" cy="190500"/><wp:docPr id="1" name="Picture 1" descr="Sales chart"/>
+<a:graphic><a:graphicData><pic:pic><pic:blipFill><a:blip r:embed="rId1"/></pic:blipFill></pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p>
+</w:body></w:document>`,
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"/>
+  <Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com" TargetMode="External"/>
+</Relationships>`,
+		"word/media/image1.png": pngData.String(),
+		"word/numbering.xml":    markdownTestNumbering,
+		"word/styles.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:docDefaults><w:rPrDefault><w:rPr><w:lang w:val="de-DE" w:eastAsia="zh-CN"/></w:rPr></w:rPrDefault></w:docDefaults>
+</w:styles>`,
+	}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	config := getDefaultPDFConfig()
+	config.Compression = false
+	config.Tagged = true
+	config.DefaultFont = "Go"
+	config.FontDirectories = []string{filepath.Join("testdata", "fonts")}
+
+	var buf bytes.Buffer
+	if _, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &buf); err != nil {
+		t.Fatalf("带标签的PDF导出失败: %v", err)
+	}
+	data := buf.Bytes()
+	checkPDFStructure(t, data)
+
+	for _, expected := range []string{
+		"/MarkInfo << /Marked true >>",
+		"/StructTreeRoot",
+		"/Type /StructTreeRoot",
+		"/ViewerPreferences << /DisplayDocTitle true >>",
+		"/Lang (de-DE)",
+		// 没有标题属性时使用第一个标题段落
+		"/Title (Quarterly Report)",
+		"<pdfuaid:part>1</pdfuaid:part>",
+		"/StructParents 0 /Tabs /S",
+		"/S /Document",
+		"/S /H1",
+		"/S /P",
+		"/S /L",
+		"/S /LI",
+		"/S /Lbl",
+		"/S /LBody",
+		"/S /Table",
+		"/S /TR",
+		"/S /TH",
+		"/S /TD",
+		"/A << /O /Table /Scope /Column >>",
+		"/S /Figure",
+		"/Alt (Sales chart)",
+		"/S /Link",
+		"/Type /OBJR",
+		"/StructParent 1 /Contents (the website)",
+		"/H1 <</MCID 0>> BDC",
+		"/Artifact BMC",
+		"/FontFile2",
+	} {
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("带标签的PDF应该包含 %q", expected)
+		}
+	}
+
+	// 嵌套列表位于上级列表项的正文中
+	if nested := regexp.MustCompile(`/S /L /P (\d+) 0 R`).FindAllSubmatch(data, -1); len(nested) != 2 {
+		t.Errorf("应该有两个列表, 实际 %d 个", len(nested))
+	} else if !bytes.Contains(data, []byte(fmt.Sprintf("%s 0 obj\n<< /Type /StructElem /S /LBody", nested[1][1]))) {
+		t.Error("嵌套列表的父元素应该是列表项正文")
+	}
+
+	// 未启用标签时不生成结构树
+	config.Tagged = false
+	buf.Reset()
+	if _, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &buf); err != nil {
+		t.Fatalf("PDF导出失败: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("/StructTreeRoot")) || bytes.Contains(buf.Bytes(), []byte("BDC")) {
+		t.Error("未启用标签时不应该生成结构树")
+	}
+}
+
+// pdfTestPageTexts returns the text drawn on every page, with the words
+// joined by spaces
+func pdfTestPageTexts(t *testing.T, data []byte) []string {
+	t.Helper()
+	var texts []string
+	for _, page := range regexp.MustCompile(`/Type /Page /Parent \d+ 0 R [^\n]*/Contents (\d+) 0 R`).FindAllSubmatch(data, -1) {
+		var words []string
+		for _, word := range regexp.MustCompile(`\(([^)]*)\) Tj`).FindAllSubmatch(pdfTestStream(t, data, string(page[1])), -1) {
+			words = append(words, string(word[1]))
+		}
+		texts = append(texts, strings.Join(words, " "))
+	}
+	return texts
+}
+
+func TestPDFExporterHeadersAndFooters(t *testing.T) {
+	part := func(root, text string) string {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:` + root + ` xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` + text + `</w:` + root + `>`
+	}
+	doc, err := Open(writeTestPackage(t, map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body>
+<w:p><w:r><w:t>Cover</w:t></w:r></w:p>
+<w:p><w:pPr><w:sectPr><w:headerReference w:type="default" r:id="rId1"/><w:headerReference w:type="first" r:id="rId2"/>
+<w:footerReference w:type="default" r:id="rId3"/><w:footerReference w:type="even" r:id="rId3"/><w:pgMar w:top="1440" w:bottom="1440" w:header="708" w:footer="567"/><w:titlePg/></w:sectPr></w:pPr>
+<w:r><w:br w:type="page"/></w:r><w:r><w:t>Summary</w:t></w:r></w:p>
+<w:p><w:r><w:t>Appendix</w:t></w:r><w:r><w:br w:type="page"/></w:r><w:r><w:t>Tables</w:t></w:r></w:p>
+<w:sectPr><w:headerReference w:type="even" r:id="rId4"/><w:footerReference w:type="default" r:id="rId5"/>
+<w:pgNumType w:fmt="lowerRoman" w:start="1"/></w:sectPr>
+</w:body></w:document>`,
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>
+  <Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header2.xml"/>
+  <Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>
+  <Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header3.xml"/>
+  <Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer2.xml"/>
+</Relationships>`,
+		"word/header1.xml": part("hdr", `<w:p><w:r><w:t>Board Pack</w:t></w:r></w:p>`),
+		"word/header2.xml": part("hdr", `<w:p><w:r><w:t>Confidential Cover</w:t></w:r></w:p>`),
+		"word/header3.xml": part("hdr", `<w:p><w:r><w:t>Even Header</w:t></w:r></w:p>`),
+		"word/footer1.xml": part("ftr", `<w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t xml:space="preserve">Page </w:t></w:r>
+<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> PAGE </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r>
+<w:r><w:t>9</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r><w:r><w:t xml:space="preserve"> of </w:t></w:r>
+<w:fldSimple w:instr=" NUMPAGES \* MERGEFORMAT "><w:r><w:t>9</w:t></w:r></w:fldSimple></w:p>
+<w:p><w:r><w:t>Strictly confidential</w:t></w:r></w:p>`),
+		"word/footer2.xml": part("ftr", `<w:p><w:r><w:t xml:space="preserve">Appendix page </w:t></w:r><w:fldSimple w:instr="PAGE"/>
+<w:r><w:t xml:space="preserve"> of </w:t></w:r><w:fldSimple w:instr="SECTIONPAGES \* ROMAN"/></w:p>`),
+		"word/settings.xml": part("settings", `<w:evenAndOddHeaders/>`),
+	}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	config := getDefaultPDFConfig()
+	config.Compression = false
+	var buf bytes.Buffer
+	if _, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &buf); err != nil {
+		t.Fatalf("PDF导出失败: %v", err)
+	}
+	data := buf.Bytes()
+	checkPDFStructure(t, data)
+
+	texts := pdfTestPageTexts(t, data)
+	if len(texts) != 4 {
+		t.Fatalf("应该有4页, 实际 %d 页", len(texts))
+	}
+	for i, expected := range [][]string{
+		{"Confidential Cover"},
+		// 第一节没有偶数页页眉，第二页的页眉为空
+		{"Page 2 of 4", "Strictly confidential"},
+		// 第二节继承第一节的默认页眉和偶数页页脚
+		{"Board Pack", "Appendix page i of II"},
+		{"Even Header", "Page ii of 4"},
+	} {
+		for _, text := range expected {
+			if !strings.Contains(texts[i], text) {
+				t.Errorf("第%d页应该包含 %q: %s", i+1, text, texts[i])
+			}
+		}
+	}
+	if strings.Contains(texts[1], "Board Pack") || strings.Contains(texts[3], "Board Pack") {
+		t.Error("启用奇偶页不同时偶数页不应该使用默认页眉")
+	}
+	if strings.Contains(texts[0], "Page") {
+		t.Error("首页不同但没有首页页脚时首页页脚应该为空")
+	}
+
+	// 页眉位于上页边距内，页脚底部位于页脚距离处
+	positions := regexp.MustCompile(`1 0 0 1 [\d.]+ ([\d.]+) Tm \((Confidential|Strictly)\) Tj`).FindAllSubmatch(data, -1)
+	if len(positions) < 2 {
+		t.Fatalf("找不到页眉页脚的位置")
+	}
+	header, _ := strconv.ParseFloat(string(positions[0][1]), 64)
+	footer, _ := strconv.ParseFloat(string(positions[1][1]), 64)
+	if header < 841.89-72 || header > 841.89-35.4 {
+		t.Errorf("页眉位置错误: %v", header)
+	}
+	if footer < 28.35 || footer > 72 {
+		t.Errorf("页脚位置错误: %v", footer)
+	}
+
+	// 不包含页眉页脚时只输出正文
+	config.IncludeHeaders, config.IncludeFooters = false, false
+	buf.Reset()
+	if _, err := NewPDFExporter(doc, config).ExportToPDFStream(context.Background(), &buf); err != nil {
+		t.Fatalf("PDF导出失败: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("(Board) Tj")) || bytes.Contains(buf.Bytes(), []byte("(Strictly) Tj")) {
+		t.Error("不应该包含页眉页脚")
+	}
+}
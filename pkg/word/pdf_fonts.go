@@ -0,0 +1,533 @@
+package word
+
+import (
+	"encoding/binary"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode"
+
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+// pdfFont is a font that the PDF layout can measure and draw with
+type pdfFont interface {
+	// baseFont returns the PostScript name of the font
+	baseFont() string
+	// advance returns the advance width of r in 1/1000 em
+	advance(r rune) float64
+	// encode returns the string operand that draws text
+	encode(text string) string
+}
+
+// pdfTextWidth returns the width of text in points
+func pdfTextWidth(font pdfFont, text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		width += font.advance(r)
+	}
+	return width * size / 1000
+}
+
+// pdfStandardFont is one of the standard Type 1 fonts that every PDF
+// reader provides. Text is drawn with WinAnsiEncoding.
+type pdfStandardFont struct {
+	name string
+	// widths are the advance widths of the characters 32 to 126
+	widths *[95]int16
+	// fixed is the advance width of every glyph of a monospaced font
+	fixed int
+}
+
+// Widths of the printable ASCII characters taken from the Adobe Core 14
+// font metrics. The italic faces share the widths of the upright faces;
+// for Times this is a close approximation.
+var (
+	helveticaWidths = [95]int16{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int16{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+	timesWidths = [95]int16{
+		250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 278, 278, 564, 564, 564, 444,
+		921, 722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889, 722, 722,
+		556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611, 333, 278, 333, 469, 500,
+		333, 444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778, 500, 500,
+		500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444, 480, 200, 480, 541,
+	}
+	timesBoldWidths = [95]int16{
+		250, 333, 555, 500, 500, 1000, 833, 278, 333, 333, 500, 570, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 570, 570, 570, 500,
+		930, 722, 667, 722, 722, 667, 611, 778, 778, 389, 500, 778, 667, 944, 722, 778,
+		611, 778, 722, 556, 667, 722, 722, 1000, 722, 722, 667, 333, 278, 333, 581, 500,
+		333, 500, 556, 444, 556, 444, 333, 500, 556, 278, 333, 556, 278, 833, 556, 500,
+		556, 556, 444, 389, 333, 556, 500, 722, 500, 500, 444, 394, 220, 394, 520,
+	}
+)
+
+// winAnsiSpecials maps the characters of WinAnsiEncoding between 0x80 and
+// 0x9F that differ from Latin-1
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// winAnsiWidths are the widths of the WinAnsi punctuation outside ASCII.
+// Other characters are measured like their unaccented base letter.
+var winAnsiWidths = map[rune]int{
+	'‘': 222, '’': 222, '‚': 222, '“': 333, '”': 333, '„': 333, '•': 350, '–': 556,
+	'—': 1000, '…': 1000, '™': 1000, '‰': 1000, '€': 556,
+}
+
+// latin1BaseLetters maps the Latin-1 letters from U+00C0 to their base
+// letters for measuring
+const latin1BaseLetters = "AAAAAAACEEEEIIIIDNOOOOOxOUUUUYPsaaaaaaaceeeeiiiidnooooo/ouuuuypy"
+
+// pdfStandardFonts are the standard fonts by family and face
+var pdfStandardFonts = map[string]*pdfStandardFont{
+	"Helvetica":             {name: "Helvetica", widths: &helveticaWidths},
+	"Helvetica-Bold":        {name: "Helvetica-Bold", widths: &helveticaBoldWidths},
+	"Helvetica-Oblique":     {name: "Helvetica-Oblique", widths: &helveticaWidths},
+	"Helvetica-BoldOblique": {name: "Helvetica-BoldOblique", widths: &helveticaBoldWidths},
+	"Times-Roman":           {name: "Times-Roman", widths: &timesWidths},
+	"Times-Bold":            {name: "Times-Bold", widths: &timesBoldWidths},
+	"Times-Italic":          {name: "Times-Italic", widths: &timesWidths},
+	"Times-BoldItalic":      {name: "Times-BoldItalic", widths: &timesBoldWidths},
+	"Courier":               {name: "Courier", fixed: 600},
+	"Courier-Bold":          {name: "Courier-Bold", fixed: 600},
+	"Courier-Oblique":       {name: "Courier-Oblique", fixed: 600},
+	"Courier-BoldOblique":   {name: "Courier-BoldOblique", fixed: 600},
+}
+
+// standardPDFFont returns the standard font for a Word font name and face.
+// Serif fonts map to Times, monospaced fonts to Courier and all others to
+// Helvetica.
+func standardPDFFont(fontName string, bold, italic bool) *pdfStandardFont {
+	family := "Helvetica"
+	lower := strings.ToLower(fontName)
+	switch {
+	case containsAny(lower, "courier", "mono", "consolas", "code", "menlo"):
+		family = "Courier"
+	case containsAny(lower, "times", "roman", "serif", "georgia", "cambria", "garamond", "book", "宋", "simsun", "mincho"):
+		if !strings.Contains(lower, "sans") {
+			family = "Times"
+		}
+	}
+
+	name := family
+	switch {
+	case family == "Times" && bold && italic:
+		name = "Times-BoldItalic"
+	case family == "Times" && bold:
+		name = "Times-Bold"
+	case family == "Times" && italic:
+		name = "Times-Italic"
+	case family == "Times":
+		name = "Times-Roman"
+	case bold && italic:
+		name += "-BoldOblique"
+	case bold:
+		name += "-Bold"
+	case italic:
+		name += "-Oblique"
+	}
+	return pdfStandardFonts[name]
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrings ...string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *pdfStandardFont) baseFont() string {
+	return f.name
+}
+
+func (f *pdfStandardFont) advance(r rune) float64 {
+	if f.fixed > 0 {
+		return float64(f.fixed)
+	}
+	if width, ok := winAnsiWidths[r]; ok {
+		return float64(width)
+	}
+	switch {
+	case r == 0xA0:
+		r = ' '
+	case r >= 0xC0 && r <= 0xFF:
+		r = rune(latin1BaseLetters[r-0xC0])
+	}
+	if r < 32 || r > 126 {
+		// 无法编码的字符按问号计算
+		r = '?'
+	}
+	return float64(f.widths[r-32])
+}
+
+// encode returns a literal string in WinAnsiEncoding. Characters that the
+// encoding cannot represent are replaced by "?".
+func (f *pdfStandardFont) encode(text string) string {
+	var buf strings.Builder
+	buf.WriteByte('(')
+	for _, r := range text {
+		var b byte
+		switch {
+		case r >= 32 && r <= 126 || r >= 0xA0 && r <= 0xFF:
+			b = byte(r)
+		case winAnsiSpecials[r] != 0:
+			b = winAnsiSpecials[r]
+		default:
+			b = '?'
+		}
+		switch b {
+		case '(', ')', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(b)
+		default:
+			if b < 128 {
+				buf.WriteByte(b)
+			} else {
+				// 非ASCII字节使用八进制转义，保持文件为纯文本
+				buf.WriteString("\\" + octal3(b))
+			}
+		}
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// octal3 returns b as three octal digits
+func octal3(b byte) string {
+	return string([]byte{'0' + b>>6, '0' + b>>3&7, '0' + b&7})
+}
+
+// pdfFontFace is a face found in the font directories
+type pdfFontFace struct {
+	path   string
+	index  int
+	bold   bool
+	italic bool
+}
+
+// pdfFontKey identifies a font choice of the resolver
+type pdfFontKey struct {
+	name   string
+	bold   bool
+	italic bool
+	r      rune
+}
+
+// pdfCJKFallbacks are tried for CJK text after the language fallbacks.
+// They cover the fonts that common Linux and macOS systems ship.
+var pdfCJKFallbacks = []string{
+	"Microsoft YaHei", "SimSun", "Noto Sans CJK SC", "Noto Sans SC", "Noto Serif CJK SC",
+	"Source Han Sans SC", "WenQuanYi Micro Hei", "WenQuanYi Zen Hei", "PingFang SC",
+	"Hiragino Sans GB", "Droid Sans Fallback", "Arial Unicode MS",
+}
+
+// pdfGenericFallbacks are tried for all other text
+var pdfGenericFallbacks = []string{"Arial", "Liberation Sans", "DejaVu Sans", "Noto Sans", "Helvetica"}
+
+// pdfFontResolver chooses the font that draws each character. TrueType
+// and OpenType fonts are searched in the font directories by the run font
+// name and then by the fallback chain of the character's language.
+type pdfFontResolver struct {
+	directories []string
+	// embed uses TrueType fonts for all text; otherwise they are only used
+	// for characters that the standard fonts cannot encode
+	embed     bool
+	languages *LanguageSupport
+	logger    *utils.Logger
+
+	// faces are the scanned faces by normalized name; nil until scanned
+	faces   map[string][]pdfFontFace
+	loaded  map[pdfFontFace]*trueTypeFont
+	choices map[pdfFontKey]pdfFont
+}
+
+// newPDFFontResolver creates a resolver. The system font directories are
+// used when directories is empty.
+func newPDFFontResolver(directories []string, embed bool, languages *LanguageSupport) *pdfFontResolver {
+	if len(directories) == 0 {
+		directories = systemFontDirectories()
+	}
+	if languages == nil {
+		languages = NewLanguageSupport()
+	}
+	return &pdfFontResolver{
+		directories: directories,
+		embed:       embed,
+		languages:   languages,
+		loaded:      make(map[pdfFontFace]*trueTypeFont),
+		choices:     make(map[pdfFontKey]pdfFont),
+	}
+}
+
+// systemFontDirectories returns the usual font directories of the platform
+func systemFontDirectories() []string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		windir := os.Getenv("WINDIR")
+		if windir == "" {
+			windir = `C:\Windows`
+		}
+		return []string{filepath.Join(windir, "Fonts"), filepath.Join(os.Getenv("LOCALAPPDATA"), "Microsoft", "Windows", "Fonts")}
+	case "darwin":
+		return []string{"/System/Library/Fonts", "/Library/Fonts", filepath.Join(home, "Library", "Fonts")}
+	default:
+		return []string{"/usr/share/fonts", "/usr/local/share/fonts", filepath.Join(home, ".fonts"), filepath.Join(home, ".local", "share", "fonts")}
+	}
+}
+
+// fontFor returns the font that draws r for a run with the font name and face
+func (fr *pdfFontResolver) fontFor(fontName string, bold, italic bool, r rune) pdfFont {
+	standard := standardPDFFont(fontName, bold, italic)
+	if !fr.embed && pdfWinAnsiEncodable(r) {
+		return standard
+	}
+
+	key := pdfFontKey{name: fontName, bold: bold, italic: italic, r: r}
+	if font, ok := fr.choices[key]; ok {
+		return font
+	}
+
+	var chosen pdfFont = standard
+	for _, name := range fr.chain(fontName, r) {
+		if font := fr.load(name, bold, italic); font != nil && font.covers(r) {
+			chosen = font
+			break
+		}
+	}
+	fr.choices[key] = chosen
+	return chosen
+}
+
+// chain returns the font names to try for a character: the run font, the
+// fallbacks of the character's language and the built-in fallbacks
+func (fr *pdfFontResolver) chain(fontName string, r rune) []string {
+	names := []string{fontName}
+	language := pdfScriptLanguage(r)
+	names = append(names, fr.languageFallbacks(language)...)
+	switch language {
+	case "ja-JP", "ko-KR", "zh-CN":
+		// 日文和韩文字体缺字时再尝试中文字体
+		names = append(names, fr.languageFallbacks("zh-CN")...)
+		names = append(names, pdfCJKFallbacks...)
+	}
+	return append(names, pdfGenericFallbacks...)
+}
+
+// languageFallbacks returns the fallback fonts of a language from
+// LanguageSupport, preferring an explicit FontFallbacks entry
+func (fr *pdfFontResolver) languageFallbacks(language string) []string {
+	if fallbacks := fr.languages.FontFallbacks[language]; len(fallbacks) > 0 {
+		return fallbacks
+	}
+	info, ok := fr.languages.SupportedLanguages[language]
+	if !ok {
+		return nil
+	}
+	return append([]string{info.DefaultFont}, info.Fallbacks...)
+}
+
+// pdfScriptLanguage returns the language whose fonts cover the script of r
+func pdfScriptLanguage(r rune) string {
+	switch {
+	case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+		return "ja-JP"
+	case unicode.Is(unicode.Hangul, r):
+		return "ko-KR"
+	case unicode.In(r, unicode.Han, unicode.Bopomofo) || r >= 0x3000 && r <= 0x303F || r >= 0xFF00 && r <= 0xFFEF:
+		return "zh-CN"
+	case unicode.Is(unicode.Arabic, r):
+		return "ar-SA"
+	case unicode.Is(unicode.Hebrew, r):
+		return "he-IL"
+	default:
+		return "en-US"
+	}
+}
+
+// pdfWinAnsiEncodable reports whether a standard font can draw r
+func pdfWinAnsiEncodable(r rune) bool {
+	return r >= 32 && r <= 126 || r >= 0xA0 && r <= 0xFF || winAnsiSpecials[r] != 0
+}
+
+// load returns the face of a font family closest to the requested style,
+// or nil when the family is not installed or cannot be embedded
+func (fr *pdfFontResolver) load(name string, bold, italic bool) *trueTypeFont {
+	if name == "" {
+		return nil
+	}
+	fr.scan()
+
+	faces := fr.faces[normalizeFontName(name)]
+	if len(faces) == 0 {
+		return nil
+	}
+	best, bestScore := faces[0], -1
+	for _, face := range faces {
+		score := 0
+		if face.bold == bold {
+			score += 2
+		}
+		if face.italic == italic {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = face, score
+		}
+	}
+
+	if font, ok := fr.loaded[best]; ok {
+		return font
+	}
+	font, err := loadTrueTypeFont(best.path, best.index)
+	if err != nil && fr.logger != nil {
+		fr.logger.Warning("无法加载字体 %s: %v", best.path, err)
+	}
+	fr.loaded[best] = font
+	return font
+}
+
+// scan indexes the faces in the font directories by their family, full
+// and PostScript names in every language
+func (fr *pdfFontResolver) scan() {
+	if fr.faces != nil {
+		return
+	}
+	fr.faces = make(map[string][]pdfFontFace)
+
+	for _, directory := range fr.directories {
+		filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".ttf", ".otf", ".ttc", ".otc":
+			default:
+				return nil
+			}
+			for _, described := range describeFontFile(path) {
+				for _, name := range described.names {
+					key := normalizeFontName(name)
+					fr.faces[key] = append(fr.faces[key], described.face)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// normalizeFontName makes font names comparable regardless of case,
+// spaces and hyphens
+func normalizeFontName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '_' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// describedFace is a face with the names it is known by
+type describedFace struct {
+	face  pdfFontFace
+	names []string
+}
+
+// describeFontFile reads the names and style of every face in a font file
+// without loading the whole file
+func describeFontFile(path string) []describedFace {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	read := func(offset int64, length int) []byte {
+		data := make([]byte, length)
+		if _, err := file.ReadAt(data, offset); err != nil {
+			return nil
+		}
+		return data
+	}
+
+	header := read(0, 12)
+	if header == nil {
+		return nil
+	}
+	offsets := []int64{0}
+	if string(header[:4]) == "ttcf" {
+		count := int(binary.BigEndian.Uint32(header[8:]))
+		if count > 256 {
+			return nil
+		}
+		list := read(12, 4*count)
+		if list == nil {
+			return nil
+		}
+		offsets = offsets[:0]
+		for i := 0; i < count; i++ {
+			offsets = append(offsets, int64(binary.BigEndian.Uint32(list[4*i:])))
+		}
+	}
+
+	var faces []describedFace
+	for index, offset := range offsets {
+		directory := read(offset, 12)
+		if directory == nil {
+			continue
+		}
+		count := int(binary.BigEndian.Uint16(directory[4:]))
+		records := read(offset+12, 16*count)
+		if records == nil {
+			continue
+		}
+
+		described := describedFace{face: pdfFontFace{path: path, index: index}}
+		for i := 0; i < count; i++ {
+			record := records[16*i:]
+			tag := string(record[:4])
+			tableOffset := int64(binary.BigEndian.Uint32(record[8:]))
+			length := int(binary.BigEndian.Uint32(record[12:]))
+			switch {
+			case tag == "head" && length >= 46:
+				if head := read(tableOffset, 46); head != nil {
+					macStyle := binary.BigEndian.Uint16(head[44:])
+					described.face.bold, described.face.italic = macStyle&1 != 0, macStyle&2 != 0
+				}
+			case tag == "name" && length < 1<<20:
+				for _, name := range sfntNameRecords(read(tableOffset, length)) {
+					switch name.id {
+					case 1, 4, 6, 16:
+						described.names = append(described.names, name.value)
+					}
+				}
+			}
+		}
+		faces = append(faces, described)
+	}
+	return faces
+}
@@ -0,0 +1,275 @@
+package word
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// pdfHeaderDistance is the default distance of headers and footers from
+// the page edge in points
+const pdfHeaderDistance = 36
+
+// pdfPageFields are the values of the page number fields of a page
+type pdfPageFields struct {
+	page         int
+	pages        int
+	sectionPages int
+	// format is the page number format of the section
+	format string
+}
+
+// loadHeaderFooters parses the header and footer parts referenced by the
+// sections and takes the even and odd page setting from the settings
+func (pe *PDFExporter) loadHeaderFooters(layout *pdfLayout, settings *parser.WordSettings) {
+	layout.headerFooters = make(map[string]*types.DocumentContent)
+	for _, section := range layout.sections {
+		for _, parts := range []map[string]string{section.Headers, section.Footers} {
+			for _, name := range parts {
+				if _, ok := layout.headerFooters[name]; ok {
+					continue
+				}
+				data := pe.Document.readPart(name)
+				if data == nil {
+					pe.Logger.Warning("找不到页眉页脚部件: %s", name)
+					layout.headerFooters[name] = nil
+					continue
+				}
+				content, err := parser.ParseHeaderFooter(data, pe.Document.partRelationships(name))
+				if err != nil {
+					pe.Logger.Warning("无法解析页眉页脚 %s: %v", name, err)
+				}
+				layout.headerFooters[name] = content
+			}
+		}
+	}
+
+	if settings != nil {
+		layout.evenAndOddHeaders = settings.EvenAndOddHeaders.IsOn()
+	}
+}
+
+// startSection starts the section with the given index, on a new page
+// unless the section is continuous. Even and odd page sections leave a
+// blank page when the next page has the wrong parity. The columns of a
+// continuous section start below the content of the page.
+func (l *pdfLayout) startSection(index int) {
+	l.section = index
+	// 新节的页面设置会改变页边距和分栏，先记下当前页是否为空
+	empty, column := l.pageEmpty(), l.column
+	l.pageSetup(l.sections[index])
+	kind := l.sections[index].Break
+	if kind == "continuous" {
+		if column > 0 {
+			l.newPage()
+		} else {
+			l.columnTop = l.y
+			l.setColumn(0)
+		}
+		return
+	}
+	if empty {
+		l.pageSections[len(l.pageSections)-1] = index
+		l.startPage()
+	} else {
+		l.newPage()
+	}
+	if (kind == "evenPage" && len(l.pages)%2 != 0) || (kind == "oddPage" && len(l.pages)%2 == 0) {
+		l.newPage()
+	}
+}
+
+// pageNumbers returns the page number of every page and the number of
+// pages of every section. Numbering continues across sections unless a
+// section restarts it.
+func (l *pdfLayout) pageNumbers() ([]int, []int) {
+	numbers := make([]int, len(l.pages))
+	sectionPages := make([]int, len(l.sections))
+	for i, section := range l.pageSections {
+		switch {
+		case i > 0 && l.pageSections[i-1] == section:
+			numbers[i] = numbers[i-1] + 1
+		case l.sections[section].PageNumberStart > 0:
+			numbers[i] = l.sections[section].PageNumberStart
+		case i > 0:
+			numbers[i] = numbers[i-1] + 1
+		default:
+			numbers[i] = 1
+		}
+		sectionPages[section]++
+	}
+	return numbers, sectionPages
+}
+
+// headerFooterPart returns the name of the header or footer part of a
+// page. Kinds that a section does not define are inherited from the
+// previous sections.
+func (l *pdfLayout) headerFooterPart(section int, first, even, footer bool) string {
+	kind := "default"
+	switch {
+	case first && l.sections[section].TitlePage:
+		kind = "first"
+	case even && l.evenAndOddHeaders:
+		kind = "even"
+	}
+	for s := section; s >= 0; s-- {
+		parts := l.sections[s].Headers
+		if footer {
+			parts = l.sections[s].Footers
+		}
+		if name, ok := parts[kind]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// layoutHeaderFooters draws the header and footer of every page with the
+// page number fields of that page. Footers are laid out twice: first on a
+// scratch page to measure them, so that their bottom sits at the footer
+// distance.
+func (l *pdfLayout) layoutHeaderFooters() {
+	if len(l.headerFooters) == 0 || len(l.sections) == 0 {
+		return
+	}
+
+	numbers, sectionPages := l.pageNumbers()
+	page, top, bottom := l.page, l.top, l.bottom
+	// 页眉页脚不分页，也不属于带标签PDF的文档内容
+	l.running = true
+	l.top, l.bottom = math.Inf(1), math.Inf(-1)
+	if l.tags != nil {
+		l.tags.artifact = true
+	}
+
+	for i := range l.pages {
+		s := l.pageSections[i]
+		section := l.sections[s]
+		first := i == 0 || l.pageSections[i-1] != s
+		even := numbers[i]%2 == 0
+		l.fields = &pdfPageFields{page: numbers[i], pages: len(l.pages), sectionPages: sectionPages[s], format: section.PageNumberFormat}
+		// 页眉页脚在最后排版，之后不再需要正文的页面设置；页眉页脚不分栏
+		l.pageSetup(section)
+		l.columns = 1
+		l.setColumn(0)
+
+		if l.config.IncludeHeaders {
+			if content := l.headerFooters[l.headerFooterPart(s, first, even, false)]; content != nil {
+				l.page = l.pages[i]
+				l.y = l.page.height - pdfDistance(section.HeaderDistance)
+				l.layoutContent(content)
+			}
+		}
+		if l.config.IncludeFooters {
+			if content := l.headerFooters[l.headerFooterPart(s, first, even, true)]; content != nil {
+				l.page = &pdfPage{}
+				l.y = 0
+				l.layoutContent(content)
+				l.page = l.pages[i]
+				l.y = pdfDistance(section.FooterDistance) - l.y
+				l.layoutContent(content)
+			}
+		}
+	}
+
+	l.page, l.top, l.bottom = page, top, bottom
+	l.running, l.fields = false, nil
+	if l.tags != nil {
+		l.tags.artifact = false
+	}
+}
+
+// layoutContent lays out the paragraphs and tables of a header or footer
+func (l *pdfLayout) layoutContent(content *types.DocumentContent) {
+	for _, block := range collectBody(content) {
+		if block.table != nil {
+			l.layoutTable(block.table)
+			continue
+		}
+		l.layoutParagraph(block.paragraph)
+	}
+}
+
+// pdfDistance returns a header or footer distance, or the default when it
+// is not set
+func pdfDistance(distance float64) float64 {
+	if distance > 0 {
+		return distance
+	}
+	return pdfHeaderDistance
+}
+
+// fieldText returns the value of a PAGE, NUMPAGES or SECTIONPAGES field
+// while headers and footers are drawn, else the last computed result
+func (l *pdfLayout) fieldText(instruction, result string) string {
+	words := strings.Fields(instruction)
+	if l.fields == nil || len(words) == 0 {
+		return result
+	}
+
+	var value int
+	format := ""
+	switch strings.ToUpper(words[0]) {
+	case "PAGE":
+		value, format = l.fields.page, l.fields.format
+	case "NUMPAGES":
+		value = l.fields.pages
+	case "SECTIONPAGES":
+		value = l.fields.sectionPages
+	default:
+		return result
+	}
+	// 格式开关如 \* roman 优先于节的页码格式
+	for i := 1; i+1 < len(words); i++ {
+		if words[i] == `\*` && !strings.EqualFold(words[i+1], "MERGEFORMAT") && !strings.EqualFold(words[i+1], "CHARFORMAT") {
+			format = words[i+1]
+		}
+	}
+	return formatPageNumber(value, format)
+}
+
+// formatPageNumber formats a page number in a page numbering format
+// (w:pgNumType) or the format of a field switch such as "roman"
+func formatPageNumber(number int, format string) string {
+	if number <= 0 {
+		return strconv.Itoa(number)
+	}
+	switch format {
+	case "lowerRoman", "roman":
+		return strings.ToLower(romanNumeral(number))
+	case "upperRoman", "Roman", "ROMAN":
+		return romanNumeral(number)
+	case "lowerLetter", "alphabetic":
+		return letterNumeral(number)
+	case "upperLetter", "ALPHABETIC":
+		return strings.ToUpper(letterNumeral(number))
+	case "numberInDash":
+		return "- " + strconv.Itoa(number) + " -"
+	default:
+		return strconv.Itoa(number)
+	}
+}
+
+// romanNumeral returns a positive number in upper case Roman numerals
+func romanNumeral(number int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	var roman strings.Builder
+	for i, value := range values {
+		for number >= value {
+			roman.WriteString(symbols[i])
+			number -= value
+		}
+	}
+	return roman.String()
+}
+
+// letterNumeral returns a positive number as Word letters: a to z, then
+// aa to zz and so on
+func letterNumeral(number int) string {
+	letter := string(rune('a' + (number-1)%26))
+	return strings.Repeat(letter, (number-1)/26+1)
+}
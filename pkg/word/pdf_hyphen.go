@@ -0,0 +1,149 @@
+package word
+
+import (
+	"strconv"
+	"unicode"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/text"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+// pdfHyphenZone is the default hyphenation zone of Word in points
+const pdfHyphenZone = 18
+
+// pdfHyphenation hyphenates the words that overflow a line with the
+// patterns of the language of their run
+type pdfHyphenation struct {
+	// directory holds the pattern files of the languages
+	directory string
+	// language is the language of runs that do not set one
+	language string
+	// hyphenators caches the patterns by language; nil when a language has
+	// no patterns
+	hyphenators map[string]*text.Hyphenator
+	logger      *utils.Logger
+	// zone is the space that may be left at the end of a line before a
+	// word is hyphenated
+	zone float64
+	// limit is the most lines in a row that may end with a hyphen; 0 means
+	// no limit
+	limit int
+}
+
+// loadHyphenation turns on hyphenation when the settings of the document
+// ask for it. Patterns are loaded from the hyphenation directory for each
+// language the first time a word of that language is hyphenated.
+func (pe *PDFExporter) loadHyphenation(layout *pdfLayout, settings *parser.WordSettings) {
+	if pe.Config.HyphenationDirectory == "" || settings == nil || !settings.AutoHyphenation.IsOn() {
+		return
+	}
+	language := pe.documentLanguage()
+	if language == "" {
+		language = "en-US"
+	}
+
+	hyphenation := &pdfHyphenation{
+		directory:   pe.Config.HyphenationDirectory,
+		language:    language,
+		hyphenators: make(map[string]*text.Hyphenator),
+		logger:      pe.Logger,
+		zone:        pdfHyphenZone,
+	}
+	if settings.HyphenationZone != nil {
+		if twips, err := strconv.Atoi(settings.HyphenationZone.Val); err == nil && twips >= 0 {
+			hyphenation.zone = float64(twips) / 20
+		}
+	}
+	if settings.ConsecutiveHyphenLimit != nil {
+		if limit, err := strconv.Atoi(settings.ConsecutiveHyphenLimit.Val); err == nil && limit > 0 {
+			hyphenation.limit = limit
+		}
+	}
+	layout.hyphenation = hyphenation
+}
+
+// hyphenator returns the patterns of a language, or nil when the
+// hyphenation directory has none; empty means the document language
+func (h *pdfHyphenation) hyphenator(language string) *text.Hyphenator {
+	if language == "" {
+		language = h.language
+	}
+	if hyphenator, ok := h.hyphenators[language]; ok {
+		return hyphenator
+	}
+	hyphenator, err := text.LoadHyphenator(h.directory, language)
+	if err != nil {
+		// 没有断字规则的语言不断字，只警告一次
+		if h.logger != nil {
+			h.logger.Warning("无法加载断字规则: %v", err)
+		}
+		hyphenator = nil
+	}
+	h.hyphenators[language] = hyphenator
+	return hyphenator
+}
+
+// allows reports whether a word may be hyphenated at the end of a line
+// that leaves space unused after hyphens ended the previous lines
+func (h *pdfHyphenation) allows(space float64, hyphens int) bool {
+	return h != nil && space > h.zone && (h.limit == 0 || hyphens < h.limit)
+}
+
+// split hyphenates the word of fragments[start:end] at the last point
+// where the start of the word and a hyphen fit into width, with the
+// patterns of the language of the word. It returns the fragments with the
+// split applied and the index after the hyphenated start, which equals
+// start when the word cannot be hyphenated.
+func (h *pdfHyphenation) split(fragments []pdfFragment, start, end int, width float64) ([]pdfFragment, int) {
+	hyphenator := h.hyphenator(fragments[start].language)
+	if hyphenator == nil {
+		return fragments, start
+	}
+	var runes []rune
+	for _, fragment := range fragments[start:end] {
+		runes = append(runes, []rune(fragment.text)...)
+	}
+	// 只对字母部分断字，忽略前后的标点
+	first, last := 0, len(runes)
+	for first < last && !unicode.IsLetter(runes[first]) {
+		first++
+	}
+	for last > first && !unicode.IsLetter(runes[last-1]) {
+		last--
+	}
+	for _, r := range runes[first:last] {
+		if !unicode.IsLetter(r) {
+			return fragments, start
+		}
+	}
+
+	points := hyphenator.Hyphenate(string(runes[first:last]))
+	for p := len(points) - 1; p >= 0; p-- {
+		point := first + points[p]
+		// 找到断字点所在的片段
+		i, offset := start, point
+		for offset >= len([]rune(fragments[i].text)) {
+			offset -= len([]rune(fragments[i].text))
+			i++
+		}
+		fragment := []rune(fragments[i].text)
+		head := fragments[i].with(pdfTextFragment, string(fragment[:offset])+"-")
+		used := head.width
+		for _, before := range fragments[start:i] {
+			used += before.width
+		}
+		if used > width {
+			continue
+		}
+
+		tail := fragments[i].with(pdfTextFragment, string(fragment[offset:]))
+		tail.breakBefore = true
+		split := make([]pdfFragment, 0, len(fragments)+1)
+		split = append(split, fragments[:i]...)
+		split = append(split, head, tail)
+		split = append(split, fragments[i+1:]...)
+		return split, i + 1
+	}
+	return fragments, start
+}
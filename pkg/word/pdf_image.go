@@ -0,0 +1,176 @@
+package word
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"strconv"
+
+	_ "golang.org/x/image/bmp"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// pdfPixelsToPoints converts image sizes at 96 DPI to points
+const pdfPixelsToPoints = 0.75
+
+// pdfImage is a picture XObject. JPEG files are embedded unchanged with
+// DCTDecode; other formats are decoded and stored with FlateDecode, with
+// the alpha channel as a soft mask.
+type pdfImage struct {
+	width      int
+	height     int
+	colorSpace string
+	filter     string
+	// decode is the /Decode array; Adobe CMYK JPEGs store inverted values
+	decode string
+	data   []byte
+	// alpha is the deflated soft mask; nil for opaque pictures
+	alpha []byte
+	// source is the encoded picture, decoded again for rasterization
+	source []byte
+}
+
+// newPDFImage prepares encoded image data for embedding
+func newPDFImage(data []byte) (*pdfImage, error) {
+	if detectImageExtension(data) == "jpg" {
+		config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("无法解析JPEG图片: %w", err)
+		}
+		img := &pdfImage{width: config.Width, height: config.Height, colorSpace: "DeviceRGB", filter: "DCTDecode", data: data}
+		switch config.ColorModel {
+		case color.GrayModel:
+			img.colorSpace = "DeviceGray"
+		case color.CMYKModel:
+			img.colorSpace = "DeviceCMYK"
+			img.decode = "[1 0 1 0 1 0 1 0]"
+		}
+		return img, nil
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("无法解码图片: %w", err)
+	}
+	bounds := decoded.Bounds()
+	img := &pdfImage{width: bounds.Dx(), height: bounds.Dy(), colorSpace: "DeviceRGB", filter: "FlateDecode"}
+
+	gray := false
+	switch decoded.(type) {
+	case *image.Gray, *image.Gray16:
+		gray = true
+		img.colorSpace = "DeviceGray"
+	}
+
+	pixels := make([]byte, 0, img.width*img.height*3)
+	alpha := make([]byte, 0, img.width*img.height)
+	opaque := true
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// 使用非预乘颜色，透明度单独写入软蒙版
+			c := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+			if gray {
+				pixels = append(pixels, c.R)
+			} else {
+				pixels = append(pixels, c.R, c.G, c.B)
+			}
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 0xFF
+		}
+	}
+	img.data = pdfDeflate(pixels)
+	if !opaque {
+		img.alpha = pdfDeflate(alpha)
+	}
+	return img, nil
+}
+
+// embed writes the image XObject and its soft mask and returns the
+// object number of the image
+func (img *pdfImage) embed(w *pdfWriter) int {
+	id := w.alloc()
+	entries := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /%s",
+		img.width, img.height, img.colorSpace, img.filter)
+	if img.decode != "" {
+		entries += " /Decode " + img.decode
+	}
+	if img.alpha != nil {
+		maskID := w.alloc()
+		w.encodedStream(maskID, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode",
+			img.width, img.height), img.alpha)
+		entries += fmt.Sprintf(" /SMask %d 0 R", maskID)
+	}
+	w.encodedStream(id, entries, img.data)
+	return id
+}
+
+// image returns the decoded picture of a document image, or nil when it
+// cannot be read. Pictures used more than once are embedded once.
+func (l *pdfLayout) image(image *types.Image) *pdfImage {
+	key := image.Path
+	if key == "" {
+		key = fmt.Sprintf("#%p", image)
+	}
+	if img, ok := l.imageCache[key]; ok {
+		return img
+	}
+
+	var img *pdfImage
+	if l.media != nil {
+		data := l.media.imageData(image)
+		decode := newPDFImage
+		if isPDFA(l.config) && pdfImageNeedsFlattening(data) {
+			// PDF/A的sRGB输出意图无法描述CMYK图片，透明图片也合成到白色背景上
+			decode = flattenPDFImage
+		}
+		if data == nil {
+			l.media.logger.Warning("无法读取图片: %s", image.Path)
+		} else if decoded, err := decode(data); err != nil {
+			l.media.logger.Warning("%v", err)
+		} else {
+			img = decoded
+			img.source = data
+		}
+	}
+	l.imageCache[key] = img
+	return img
+}
+
+// imageFragment returns an inline picture scaled to its document extent.
+// Pictures that cannot be read are replaced by their alternative text.
+func (l *pdfLayout) imageFragment(image *types.Image, size float64) []pdfFragment {
+	var img *pdfImage
+	if l.config.IncludeImages {
+		img = l.image(image)
+	}
+	if img == nil {
+		format := pdfFragment{font: l.resolver.fontFor(l.config.DefaultFont, false, true, ' '), size: size}
+		return l.textFragments(format, l.config.DefaultFont, false, true, image.AltText)
+	}
+
+	width, height := image.Width*pdfPixelsToPoints, image.Height*pdfPixelsToPoints
+	if width <= 0 || height <= 0 {
+		width, height = float64(img.width)*pdfPixelsToPoints, float64(img.height)*pdfPixelsToPoints
+	}
+	// 图片高度不超过页面内容区域
+	if limit := (l.top - l.bottom) * 0.95; height > limit {
+		width, height = width*limit/height, limit
+	}
+	return []pdfFragment{{kind: pdfImageFragment, image: img, size: size, width: width, height: height, alt: image.AltText}}
+}
+
+// imageName returns the resource name of a picture
+func (l *pdfLayout) imageName(img *pdfImage) string {
+	if name, ok := l.imageNames[img]; ok {
+		return name
+	}
+	l.images = append(l.images, img)
+	name := "Im" + strconv.Itoa(len(l.images))
+	l.imageNames[img] = name
+	return name
+}
@@ -0,0 +1,1039 @@
+package word
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// pdfPageSizes are the portrait page sizes in points
+var pdfPageSizes = map[types.PDFPageSize][2]float64{
+	types.PDFPageSizeA3:     {841.89, 1190.55},
+	types.PDFPageSizeA4:     {595.28, 841.89},
+	types.PDFPageSizeA5:     {419.53, 595.28},
+	types.PDFPageSizeLetter: {612, 792},
+	types.PDFPageSizeLegal:  {612, 1008},
+}
+
+// pdfHeadingSizes are the font sizes of heading levels 1 to 6 in points
+var pdfHeadingSizes = [6]float64{20, 16, 14, 12, 12, 12}
+
+const (
+	// pdfLineHeight is the single line height relative to the font size
+	pdfLineHeight = 1.2
+	// pdfTabStop is the distance between default tab stops in points
+	pdfTabStop = 36
+	// pdfListIndent is the indentation of each list level in points
+	pdfListIndent = 24
+	// pdfListHanging is the hanging indent that holds the list marker
+	pdfListHanging = 18
+)
+
+// pdfPage is a laid out page
+type pdfPage struct {
+	// width and height are the page size in points
+	width   float64
+	height  float64
+	content bytes.Buffer
+	// links are the link areas of the page
+	links []pdfLink
+	// items repeat the drawing operations of the content for rasterization
+	items []pdfPageItem
+}
+
+// pdfItemKind identifies a drawing operation of a page
+type pdfItemKind int
+
+const (
+	pdfTextItem pdfItemKind = iota
+	pdfRectItem
+	pdfLineItem
+	pdfImageItem
+)
+
+// pdfPageItem is a drawing operation of a page in PDF coordinates, kept so
+// that pages can be rasterized without interpreting the content stream
+type pdfPageItem struct {
+	kind pdfItemKind
+	// x and y are the baseline origin of text, the lower left corner of
+	// rectangles and pictures and the start of lines
+	x, y float64
+	// width and height are the size of rectangles and pictures and the
+	// offset of the end of lines
+	width, height float64
+	color         string
+	text          string
+	font          pdfFont
+	size          float64
+	// lineWidth and dash are the stroke of lines; dash alternates the
+	// lengths of dashes and gaps
+	lineWidth float64
+	dash      []float64
+	image     *pdfImage
+}
+
+// pdfFragmentKind identifies an item of a laid out paragraph
+type pdfFragmentKind int
+
+const (
+	pdfTextFragment pdfFragmentKind = iota
+	// pdfSpaceFragment is a space where a line may break
+	pdfSpaceFragment
+	pdfTabFragment
+	pdfLineBreakFragment
+	pdfPageBreakFragment
+	pdfColumnBreakFragment
+	// pdfImageFragment is an inline picture that sits on the baseline
+	pdfImageFragment
+)
+
+// pdfFragment is a piece of text with a single format
+type pdfFragment struct {
+	kind      pdfFragmentKind
+	text      string
+	font      pdfFont
+	size      float64
+	color     string
+	underline bool
+	strike    bool
+	width     float64
+	// image and height are set for inline pictures
+	image  *pdfImage
+	height float64
+	// link is the hyperlink target; "#" targets are destinations
+	link string
+	// rise raises superscripts above the baseline
+	rise float64
+	// label is set for the marker of a list item
+	label bool
+	// alt is the alternative text of a picture
+	alt string
+	// run is the index of the run in its paragraph, -1 for list markers
+	run int
+	// language is the language of the run; empty means the document
+	// language
+	language string
+	// breakBefore allows a line break between the fragment and the text
+	// fragment before it
+	breakBefore bool
+}
+
+// pdfLine is a line of a paragraph
+type pdfLine struct {
+	paragraph *types.Paragraph
+	fragments []pdfFragment
+	// width excludes trailing spaces
+	width float64
+	size  float64
+	// last is set for the last line of a paragraph and lines ended by a
+	// break, which are never justified
+	last        bool
+	pageBreak   bool
+	columnBreak bool
+	// imageHeight is the height of the tallest picture on the line
+	imageHeight float64
+}
+
+// height returns the height of the line for a line spacing multiple. The
+// baseline is a quarter of the font size above the bottom of the line.
+func (line *pdfLine) height(spacing float64) float64 {
+	return math.Max(line.size*pdfLineHeight*spacing, line.imageHeight+line.size*0.25)
+}
+
+// pdfPlacedLine is a line of a paragraph with its position relative to
+// the left edge and the top of the paragraph's column
+type pdfPlacedLine struct {
+	line      *pdfLine
+	offset    float64
+	available float64
+	alignment string
+	height    float64
+	// before is the space above the line
+	before float64
+	// paragraph is set on the first line of a paragraph
+	paragraph *types.Paragraph
+}
+
+// pdfLayout breaks the document into lines and pages
+type pdfLayout struct {
+	ctx        context.Context
+	config     *types.PDFExportConfig
+	styleNames map[string]string
+	numbering  *parser.WordNumbering
+	counters   *listCounters
+
+	pageWidth  float64
+	pageHeight float64
+	// left, right, top and bottom are the edges of the margin box in PDF
+	// coordinates, with the origin at the bottom left of the page
+	left   float64
+	right  float64
+	top    float64
+	bottom float64
+	// marginLeft and marginRight are the edges of the margin box; left and
+	// right are the edges of the current column within it
+	marginLeft  float64
+	marginRight float64
+	// columns is the number of text columns, column the current one and
+	// columnTop the top of the columns, which is below the top margin
+	// after a continuous section break
+	columns   int
+	columnGap float64
+	column    int
+	columnTop float64
+
+	pages []*pdfPage
+	page  *pdfPage
+	// y is the top of the next line
+	y float64
+
+	resolver  *pdfFontResolver
+	fonts     []pdfFont
+	fontNames map[pdfFont]string
+
+	// media reads the pictures of the document
+	media      *exportMedia
+	images     []*pdfImage
+	imageNames map[*pdfImage]string
+	// imageCache holds the decoded pictures by path; nil entries are
+	// pictures that could not be read
+	imageCache map[string]*pdfImage
+
+	// dests are the destinations of bookmarks and notes by name and
+	// paragraphDests the positions of the laid out paragraphs
+	dests          map[string]pdfDest
+	paragraphDests map[*types.Paragraph]pdfDest
+	// footnotes are the notes of the document; notes lists the referenced
+	// ones in reference order
+	footnotes map[string][]types.Paragraph
+	notes     []string
+	outline   []*pdfOutlineItem
+
+	// tags builds the structure tree of a tagged PDF; nil when untagged
+	tags *pdfTagger
+
+	// sections are the sections of the body and section the index of the
+	// current one; pageSections holds the section of every page
+	sections     []types.Section
+	section      int
+	pageSections []int
+	// headerFooters are the parsed header and footer parts by part name
+	headerFooters     map[string]*types.DocumentContent
+	evenAndOddHeaders bool
+	// hyphenation hyphenates words at the end of lines; nil when off
+	hyphenation *pdfHyphenation
+	// running is set while headers and footers are drawn; fields are the
+	// values of the page number fields of the page
+	running bool
+	fields  *pdfPageFields
+	// repeating is set while the header rows of a table are repeated on a
+	// following page
+	repeating bool
+	// record receives the positions of the body lines and table cells when
+	// a layout tree is built; nil otherwise
+	record *pdfLayoutRecord
+}
+
+// pdfLayoutRecord holds the positions of the laid out lines and table
+// cells in drawing order
+type pdfLayoutRecord struct {
+	lines []pdfLineBox
+	cells []pdfCellBox
+}
+
+// pdfLineBox is a drawn line. top and height are the line box in PDF
+// coordinates; positions are the left edges of the fragments.
+type pdfLineBox struct {
+	line      *pdfLine
+	page      int
+	top       float64
+	height    float64
+	baseline  float64
+	positions []float64
+}
+
+// pdfCellBox is the part of a table cell drawn in one row on one page
+type pdfCellBox struct {
+	table  *types.Table
+	cell   *pdfTableCell
+	row    int
+	page   int
+	top    float64
+	height float64
+}
+
+// newPDFLayout creates a layout for the page size, orientation and margins
+// of the configuration
+func newPDFLayout(ctx context.Context, config *types.PDFExportConfig) *pdfLayout {
+	size, ok := pdfPageSizes[config.PageSize]
+	if !ok {
+		size = pdfPageSizes[types.PDFPageSizeA4]
+	}
+	if config.Orientation == types.PDFOrientationLandscape {
+		size[0], size[1] = size[1], size[0]
+	}
+
+	l := &pdfLayout{
+		ctx:        ctx,
+		config:     config,
+		pageWidth:  size[0],
+		pageHeight: size[1],
+		columns:    1,
+		resolver:   newPDFFontResolver(config.FontDirectories, config.FontEmbedding || isPDFA(config) || config.Tagged, nil),
+		fontNames:  make(map[pdfFont]string),
+		imageNames: make(map[*pdfImage]string),
+		imageCache: make(map[string]*pdfImage),
+
+		dests:          make(map[string]pdfDest),
+		paragraphDests: make(map[*types.Paragraph]pdfDest),
+	}
+	if config.Tagged {
+		l.tags = newPDFTagger()
+	}
+	l.setMargins(config.Margins)
+	return l
+}
+
+// setMargins sets the margin box of the page size and a single column
+func (l *pdfLayout) setMargins(margins types.PDFMargins) {
+	l.marginLeft, l.marginRight = margins.Left, l.pageWidth-margins.Right
+	l.top, l.bottom = l.pageHeight-margins.Top, margins.Bottom
+	// 页边距过大时保留至少一英寸的内容区域
+	if l.marginRight-l.marginLeft < 72 {
+		l.marginLeft, l.marginRight = (l.pageWidth-72)/2, (l.pageWidth+72)/2
+	}
+	if l.top-l.bottom < 72 {
+		l.bottom, l.top = (l.pageHeight-72)/2, (l.pageHeight+72)/2
+	}
+	l.setColumn(0)
+}
+
+// pageSetup applies the page size, margins and columns of a section when
+// the configuration takes the page setup from the document. A new page
+// size takes effect on the next page.
+func (l *pdfLayout) pageSetup(section types.Section) {
+	if !l.config.PageSetupFromDocument {
+		return
+	}
+	if section.PageWidth > 0 && section.PageHeight > 0 {
+		l.pageWidth, l.pageHeight = section.PageWidth, section.PageHeight
+	}
+	margins := l.config.Margins
+	if section.Margins != nil {
+		margins = *section.Margins
+	}
+	l.columns, l.columnGap = max(section.Columns, 1), section.ColumnSpace
+	l.setMargins(margins)
+}
+
+// setColumn makes column the current column. Columns narrower than half
+// an inch fall back to a single column.
+func (l *pdfLayout) setColumn(column int) {
+	width := (l.marginRight - l.marginLeft - l.columnGap*float64(l.columns-1)) / float64(l.columns)
+	if width < 36 {
+		l.columns, column, width = 1, 0, l.marginRight-l.marginLeft
+	}
+	l.column = column
+	l.left = l.marginLeft + float64(column)*(width+l.columnGap)
+	l.right = l.left + width
+}
+
+// layout lays out the body blocks. The result always has at least one page.
+func (l *pdfLayout) layout(blocks []bodyBlock) error {
+	if len(l.sections) > 0 {
+		l.pageSetup(l.sections[0])
+	}
+	l.newPage()
+	paragraphs := 0
+	for _, block := range blocks {
+		if err := l.ctx.Err(); err != nil {
+			return err
+		}
+		if block.table != nil {
+			l.layoutTable(block.table)
+			continue
+		}
+		l.layoutParagraph(block.paragraph)
+		if l.section+1 < len(l.sections) && paragraphs == l.sections[l.section].End {
+			l.startSection(l.section + 1)
+		}
+		paragraphs++
+	}
+	l.layoutNotes()
+	l.layoutHeaderFooters()
+	return nil
+}
+
+// newPage starts a page. Headers and footers never start a page.
+func (l *pdfLayout) newPage() {
+	if l.running {
+		return
+	}
+	l.page = &pdfPage{}
+	l.pages = append(l.pages, l.page)
+	l.pageSections = append(l.pageSections, l.section)
+	l.startPage()
+}
+
+// startPage gives the current page the current page size and starts its
+// first column at the top margin
+func (l *pdfLayout) startPage() {
+	l.page.width, l.page.height = l.pageWidth, l.pageHeight
+	l.y, l.columnTop = l.top, l.top
+	l.setColumn(0)
+}
+
+// newColumn continues in the next column, or on a new page after the last
+// column
+func (l *pdfLayout) newColumn() {
+	if l.running {
+		return
+	}
+	if l.column+1 < l.columns {
+		l.setColumn(l.column + 1)
+		l.y = l.columnTop
+		return
+	}
+	l.newPage()
+}
+
+// atPageTop reports whether nothing has been placed in the current column
+func (l *pdfLayout) atPageTop() bool {
+	return l.y >= l.columnTop
+}
+
+// pageEmpty reports whether nothing has been placed on the current page
+func (l *pdfLayout) pageEmpty() bool {
+	return l.column == 0 && l.y >= l.top
+}
+
+// fontName returns the resource name of a font
+func (l *pdfLayout) fontName(font pdfFont) string {
+	if name, ok := l.fontNames[font]; ok {
+		return name
+	}
+	l.fonts = append(l.fonts, font)
+	name := "F" + strconv.Itoa(len(l.fonts))
+	l.fontNames[font] = name
+	return name
+}
+
+// defaultFontSize returns the body font size in points
+func (l *pdfLayout) defaultFontSize() float64 {
+	if l.config.FontSize > 0 {
+		return float64(l.config.FontSize)
+	}
+	return 12
+}
+
+// layoutParagraph breaks a paragraph into lines and places them, starting
+// new pages as needed
+func (l *pdfLayout) layoutParagraph(paragraph *types.Paragraph) {
+	lines, spaceAfter := l.paragraphLines(paragraph, l.right-l.left)
+	if l.tags != nil && !l.tags.artifact {
+		l.tags.paragraph(paragraph, headingLevel(paragraph.Style, l.styleNames))
+	}
+
+	if paragraph.PageBreakBefore && !l.pageEmpty() {
+		l.newPage()
+	}
+	for _, placed := range lines {
+		if !l.atPageTop() {
+			l.y -= placed.before
+		}
+		if l.y-placed.height < l.bottom && !l.atPageTop() {
+			l.newColumn()
+		}
+
+		l.markParagraph(placed.paragraph, l.y)
+		l.placeLine(placed, l.left+placed.offset, l.y)
+		l.y -= placed.height
+
+		switch {
+		case placed.line.pageBreak:
+			l.newPage()
+		case placed.line.columnBreak:
+			l.newColumn()
+		}
+	}
+
+	l.y -= spaceAfter
+	if l.y < l.bottom {
+		l.y = l.bottom
+	}
+}
+
+// paragraphLines breaks a paragraph into lines for a column of the given
+// width. The space before the paragraph is on the first line; the space
+// after it is returned separately.
+func (l *pdfLayout) paragraphLines(paragraph *types.Paragraph, width float64) ([]pdfPlacedLine, float64) {
+	level := headingLevel(paragraph.Style, l.styleNames)
+	size := l.defaultFontSize()
+	spaceBefore, spaceAfter := paragraph.SpaceBefore, paragraph.SpaceAfter
+	if level > 0 {
+		size = pdfHeadingSizes[level-1]
+		if spaceBefore == 0 {
+			spaceBefore = 12
+		}
+		if spaceAfter == 0 {
+			spaceAfter = 6
+		}
+	}
+
+	leftIndent, firstLine := paragraph.LeftIndent, paragraph.FirstLineIndent
+	var fragments []pdfFragment
+	if paragraph.NumID > 0 {
+		if leftIndent == 0 {
+			leftIndent = float64(pdfListIndent * (paragraph.ListLevel + 1))
+		}
+		if firstLine == 0 {
+			firstLine = -pdfListHanging
+		}
+		marker := pdfFragment{size: size, label: true, run: -1}
+		fragments = append(fragments, l.textFragments(marker, l.config.DefaultFont, false, false, l.listMarker(paragraph))...)
+		fragments = append(fragments, marker.with(pdfTabFragment, ""))
+	}
+	fragments = append(fragments, l.paragraphFragments(paragraph, size, level > 0)...)
+	fragments = breakOpportunities(fragments)
+
+	width -= leftIndent + paragraph.RightIndent
+	spacing := paragraph.LineSpacing
+	if spacing <= 0 {
+		spacing = 1
+	}
+	// 从右到左段落的缩进和对齐从右边开始
+	offset, alignment := leftIndent, paragraph.Alignment
+	if paragraph.Bidi {
+		offset, alignment = paragraph.RightIndent, bidiAlignment(alignment)
+	}
+
+	hyphenation := l.hyphenation
+	if paragraph.SuppressAutoHyphens {
+		hyphenation = nil
+	}
+	lines := l.breakLines(fragments, width, firstLine, size, hyphenation)
+	reorderLines(lines, paragraph.Bidi)
+	var placed []pdfPlacedLine
+	for i, line := range lines {
+		line.paragraph = paragraph
+		p := pdfPlacedLine{
+			line:      line,
+			offset:    offset,
+			available: width,
+			alignment: alignment,
+			height:    line.height(spacing),
+		}
+		if paragraph.Bidi && line.last && (alignment == "both" || alignment == "distribute") {
+			// 两端对齐段落的最后一行靠右
+			p.alignment = "right"
+		}
+		if i == 0 {
+			if !paragraph.Bidi {
+				p.offset += firstLine
+			}
+			p.available -= firstLine
+			p.before = spaceBefore
+			p.paragraph = paragraph
+		}
+		placed = append(placed, p)
+	}
+	return placed, spaceAfter
+}
+
+// listMarker returns the bullet or number of a list item and advances the
+// counters of its list
+func (l *pdfLayout) listMarker(paragraph *types.Paragraph) string {
+	level := paragraph.ListLevel
+	if level < 0 {
+		level = 0
+	}
+
+	if l.counters == nil {
+		l.counters = newListCounters(l.numbering)
+	}
+	number := l.counters.next(paragraph.NumID, level)
+	switch format := l.numbering.Format(paragraph.NumID, level); format {
+	case "", "bullet", "none":
+		return "•"
+	case "lowerLetter":
+		return string(rune('a'+(number-1)%26)) + "."
+	case "upperLetter":
+		return string(rune('A'+(number-1)%26)) + "."
+	default:
+		return strconv.Itoa(number) + "."
+	}
+}
+
+// paragraphFragments splits the runs of a paragraph into words, spaces,
+// tabs and breaks
+func (l *pdfLayout) paragraphFragments(paragraph *types.Paragraph, size float64, heading bool) []pdfFragment {
+	runs := paragraph.Runs
+	if len(runs) == 0 && paragraph.Text != "" {
+		// 只有文字没有运行的段落按一个运行排版
+		runs = []types.Run{{Text: paragraph.Text}}
+	}
+	var fragments []pdfFragment
+	for index, run := range runs {
+		if run.Image != nil {
+			image := l.imageFragment(run.Image, size)
+			for i := range image {
+				image[i].link = run.Hyperlink
+				image[i].run = index
+			}
+			fragments = append(fragments, image...)
+			continue
+		}
+
+		runSize := size
+		if run.FontSize > 0 && !heading {
+			runSize = float64(run.FontSize) / 2
+		}
+		fontName := run.FontName
+		if fontName == "" {
+			fontName = l.config.DefaultFont
+		}
+		format := pdfFragment{
+			font:      l.resolver.fontFor(fontName, run.Bold || heading, run.Italic, ' '),
+			size:      runSize,
+			color:     strings.TrimPrefix(run.Color, "#"),
+			underline: run.Underline,
+			strike:    run.Strike,
+			link:      run.Hyperlink,
+			run:       index,
+			language:  run.Language,
+		}
+		if run.FootnoteID != "" {
+			fragments = append(fragments, l.noteReference(format, fontName, run.FootnoteID)...)
+			continue
+		}
+		text := run.Text
+		if run.Field != "" {
+			text = l.fieldText(run.Field, text)
+		}
+		fragments = append(fragments, l.textFragments(format, fontName, run.Bold || heading, run.Italic, text)...)
+
+		if run.Tab {
+			fragments = append(fragments, format.with(pdfTabFragment, ""))
+		}
+		switch run.Break {
+		case "page":
+			fragments = append(fragments, format.with(pdfPageBreakFragment, ""))
+		case "column":
+			fragments = append(fragments, format.with(pdfColumnBreakFragment, ""))
+		case "":
+		default:
+			fragments = append(fragments, format.with(pdfLineBreakFragment, ""))
+		}
+	}
+	return fragments
+}
+
+// textFragments splits text into words and spaces. Words are split
+// further where the resolver picks another font, for example for CJK
+// characters in a Latin run.
+func (l *pdfLayout) textFragments(format pdfFragment, fontName string, bold, italic bool, text string) []pdfFragment {
+	var fragments []pdfFragment
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			fragments = append(fragments, format.with(pdfTextFragment, word.String()))
+			word.Reset()
+		}
+	}
+
+	for _, r := range text {
+		if r == ' ' || r == '\t' {
+			flush()
+			fragments = append(fragments, format.with(pdfSpaceFragment, " "))
+			continue
+		}
+		if font := l.resolver.fontFor(fontName, bold, italic, r); font != format.font {
+			flush()
+			format.font = font
+		}
+		word.WriteRune(r)
+	}
+	flush()
+	return fragments
+}
+
+// with returns a fragment of kind with the format of f
+func (f pdfFragment) with(kind pdfFragmentKind, text string) pdfFragment {
+	f.kind = kind
+	f.text = text
+	if text != "" {
+		f.width = pdfTextWidth(f.font, text, f.size)
+	}
+	return f
+}
+
+// breakLines breaks fragments into lines of at most width points. Lines
+// break at spaces and before fragments marked by breakOpportunities. With
+// hyphenation a word that overflows a line is hyphenated; a word wider
+// than the line is otherwise split between characters. Tabs advance to
+// the next default tab stop or to the left indent when the first line
+// hangs.
+func (l *pdfLayout) breakLines(fragments []pdfFragment, width, firstLine, size float64, hyphenation *pdfHyphenation) []*pdfLine {
+	var lines []*pdfLine
+	line := &pdfLine{size: size}
+	// lineWidth includes trailing spaces
+	lineWidth := 0.0
+	available := width - firstLine
+	hasWord := false
+	// hyphens counts the lines in a row that end with a hyphen
+	hyphens, hyphenated := 0, false
+
+	finish := func() {
+		line.fragments = joinFragments(line.fragments)
+		line.width = trimmedLineWidth(line.fragments)
+		lines = append(lines, line)
+		line = &pdfLine{size: size}
+		lineWidth = 0
+		available = width
+		hasWord = false
+		if hyphenated {
+			hyphens++
+		} else {
+			hyphens = 0
+		}
+		hyphenated = false
+	}
+	place := func(words []pdfFragment) {
+		for _, word := range words {
+			line.fragments = append(line.fragments, word)
+			line.size = math.Max(line.size, word.size)
+			lineWidth += word.width
+		}
+		hasWord = true
+	}
+
+	for i := 0; i < len(fragments); {
+		fragment := fragments[i]
+		switch fragment.kind {
+		case pdfSpaceFragment:
+			// 换行后的行首空格不显示
+			if len(line.fragments) > 0 || len(lines) == 0 {
+				line.fragments = append(line.fragments, fragment)
+				lineWidth += fragment.width
+			}
+			i++
+
+		case pdfTabFragment:
+			// 位置相对于段落左缩进
+			position := lineWidth
+			if len(lines) == 0 {
+				position += firstLine
+			}
+			next := (math.Floor(position/pdfTabStop+1e-6) + 1) * pdfTabStop
+			if position < 0 {
+				next = 0
+			}
+			fragment.width = next - position
+			line.fragments = append(line.fragments, fragment)
+			lineWidth += fragment.width
+			i++
+
+		case pdfImageFragment:
+			// 图片比整行还宽时按比例缩小
+			if fragment.width > width-math.Max(firstLine, 0) {
+				scale := (width - math.Max(firstLine, 0)) / fragment.width
+				fragment.width *= scale
+				fragment.height *= scale
+			}
+			if hasWord && lineWidth+fragment.width > available {
+				finish()
+				continue
+			}
+			line.fragments = append(line.fragments, fragment)
+			line.imageHeight = math.Max(line.imageHeight, fragment.height)
+			lineWidth += fragment.width
+			hasWord = true
+			i++
+
+		case pdfLineBreakFragment, pdfPageBreakFragment, pdfColumnBreakFragment:
+			line.size = math.Max(line.size, fragment.size)
+			line.last = true
+			line.pageBreak = fragment.kind == pdfPageBreakFragment
+			line.columnBreak = fragment.kind == pdfColumnBreakFragment
+			finish()
+			i++
+
+		default:
+			// 相邻的文字片段组成一个单词，单词内只在换行机会处断开
+			end := i
+			wordWidth := 0.0
+			for end < len(fragments) && fragments[end].kind == pdfTextFragment && (end == i || !fragments[end].breakBefore) {
+				wordWidth += fragments[end].width
+				end++
+			}
+			if lineWidth+wordWidth > available {
+				if hyphenation.allows(available-trimmedLineWidth(line.fragments), hyphens) {
+					var fit int
+					fragments, fit = hyphenation.split(fragments, i, end, available-lineWidth)
+					if fit > i {
+						place(fragments[i:fit])
+						i = fit
+						hyphenated = true
+						finish()
+						continue
+					}
+				}
+				if hasWord {
+					finish()
+					continue
+				}
+			}
+			if wordWidth > available-lineWidth {
+				// 单词比整行还宽，按字符拆分
+				fragments, end = splitPDFWord(fragments, i, end, available-lineWidth, len(line.fragments) == 0)
+				if end == i {
+					finish()
+					continue
+				}
+			}
+			place(fragments[i:end])
+			i = end
+		}
+	}
+
+	line.last = true
+	finish()
+	return lines
+}
+
+// splitPDFWord makes fragments[start:end] fit into width by splitting the
+// fragment that overflows after its last fitting character. It returns the
+// fragments with the split applied and the index after the fitting part,
+// which equals start when nothing fits. With force at least one character
+// is taken.
+func splitPDFWord(fragments []pdfFragment, start, end int, width float64, force bool) ([]pdfFragment, int) {
+	used := 0.0
+	for i := start; i < end; i++ {
+		fragment := fragments[i]
+		if used+fragment.width <= width {
+			used += fragment.width
+			continue
+		}
+
+		fit := 0
+		for j, r := range fragment.text {
+			advance := pdfTextWidth(fragment.font, string(r), fragment.size)
+			if used+advance > width && (fit > 0 || i > start || !force) {
+				break
+			}
+			used += advance
+			fit = j + len(string(r))
+		}
+		if fit == 0 {
+			return fragments, i
+		}
+		if fit == len(fragment.text) {
+			return fragments, i + 1
+		}
+
+		head, tail := fragment.with(pdfTextFragment, fragment.text[:fit]), fragment.with(pdfTextFragment, fragment.text[fit:])
+		split := make([]pdfFragment, 0, len(fragments)+1)
+		split = append(split, fragments[:i]...)
+		split = append(split, head, tail)
+		split = append(split, fragments[i+1:]...)
+		return split, i + 1
+	}
+	return fragments, end
+}
+
+// trimmedLineWidth returns the width of a line without trailing spaces
+func trimmedLineWidth(fragments []pdfFragment) float64 {
+	end := len(fragments)
+	for end > 0 && fragments[end-1].kind == pdfSpaceFragment {
+		end--
+	}
+	width := 0.0
+	for _, fragment := range fragments[:end] {
+		width += fragment.width
+	}
+	return width
+}
+
+// placeLine draws a placed line with its top at top and records its
+// position for the layout tree
+func (l *pdfLayout) placeLine(placed pdfPlacedLine, x, top float64) {
+	baseline := top - placed.height + placed.line.size*0.25
+	positions := l.drawLine(placed.line, x, baseline, placed.available, placed.alignment)
+	if l.record != nil && !l.running && !l.repeating {
+		l.record.lines = append(l.record.lines, pdfLineBox{
+			line: placed.line, page: len(l.pages) - 1, top: top, height: placed.height, baseline: baseline, positions: positions,
+		})
+	}
+}
+
+// drawLine writes the fragments of a line with the baseline at y and
+// returns the left edge of every fragment
+func (l *pdfLayout) drawLine(line *pdfLine, x, baseline, available float64, alignment string) []float64 {
+	extra := available - line.width
+	spaces := 0
+	for _, fragment := range line.fragments {
+		if fragment.kind == pdfSpaceFragment {
+			spaces++
+		}
+	}
+
+	gap := 0.0
+	switch alignment {
+	case "center":
+		x += extra / 2
+	case "right", "end":
+		x += extra
+	case "both", "distribute":
+		if !line.last && spaces > 0 && extra > 0 {
+			// 两端对齐时把剩余宽度分配给行内空格（不含行尾空格）
+			trailing := 0
+			for i := len(line.fragments) - 1; i >= 0 && line.fragments[i].kind == pdfSpaceFragment; i-- {
+				trailing++
+			}
+			if spaces > trailing {
+				gap = extra / float64(spaces-trailing)
+			}
+		}
+	}
+
+	content := &l.page.content
+	// 下划线、删除线和图片在文字对象之后绘制
+	var decorations strings.Builder
+	var font pdfFont
+	var size float64
+	color := "-"
+	started := false
+	// open is the element of the marked-content sequence in the text object
+	var open *pdfStructElem
+	marking := false
+
+	// 同一链接的相邻片段合并为一个链接区域
+	var link *pdfLink
+	flushLink := func() {
+		if link != nil {
+			l.page.links = append(l.page.links, *link)
+			link = nil
+		}
+	}
+
+	positions := make([]float64, 0, len(line.fragments))
+	for _, fragment := range line.fragments {
+		positions = append(positions, x)
+		if fragment.link == "" {
+			flushLink()
+		} else {
+			width := fragment.width
+			if fragment.kind == pdfSpaceFragment {
+				width += gap
+			}
+			top := baseline + math.Max(fragment.size*0.9, fragment.height)
+			if link == nil || link.target != fragment.link {
+				flushLink()
+				link = &pdfLink{x1: x, y1: baseline - fragment.size*0.25, x2: x, y2: top, target: fragment.link}
+				if l.tags != nil && !l.tags.artifact {
+					link.elem = l.tags.add(l.fragmentElem(line, fragment, nil), "Link")
+				}
+			}
+			link.x2 = x + width
+			link.text += fragment.text
+			link.y2 = math.Max(link.y2, top)
+		}
+
+		if fragment.kind == pdfSpaceFragment {
+			x += fragment.width + gap
+			continue
+		}
+		if fragment.kind == pdfImageFragment {
+			image := "q " + pdfNumber(fragment.width) + " 0 0 " + pdfNumber(fragment.height) + " " +
+				pdfNumber(x) + " " + pdfNumber(baseline) + " cm /" + l.imageName(fragment.image) + " Do Q\n"
+			if l.tags != nil {
+				var figure *pdfStructElem
+				if parent := l.fragmentElem(line, fragment, link); parent != nil {
+					figure = l.tags.add(parent, "Figure")
+					figure.alt = fragment.alt
+				}
+				image = l.markedContent(figure) + image + "EMC\n"
+			}
+			decorations.WriteString(image)
+			l.page.items = append(l.page.items, pdfPageItem{kind: pdfImageItem, x: x, y: baseline, width: fragment.width, height: fragment.height, image: fragment.image})
+			x += fragment.width
+			continue
+		}
+		if fragment.kind != pdfTextFragment || fragment.text == "" {
+			x += fragment.width
+			continue
+		}
+
+		if !started {
+			content.WriteString("BT\n")
+			started = true
+		}
+		if l.tags != nil {
+			// 标记内容序列在文字对象内部切换，列表标签、链接和段落各自对应结构元素
+			if elem := l.fragmentElem(line, fragment, link); !marking || elem != open {
+				if marking {
+					content.WriteString("EMC\n")
+				}
+				content.WriteString(l.markedContent(elem))
+				open, marking = elem, true
+			}
+		}
+		if fragment.font != font || fragment.size != size {
+			font, size = fragment.font, fragment.size
+			content.WriteString("/" + l.fontName(font) + " " + pdfNumber(size) + " Tf\n")
+		}
+		if fragment.color != color {
+			color = fragment.color
+			content.WriteString(pdfColor(color) + " rg\n")
+		}
+		content.WriteString("1 0 0 1 " + pdfNumber(x) + " " + pdfNumber(baseline+fragment.rise) + " Tm " + fragment.font.encode(fragment.text) + " Tj\n")
+		l.page.items = append(l.page.items, pdfPageItem{kind: pdfTextItem, x: x, y: baseline + fragment.rise, color: fragment.color, text: fragment.text, font: fragment.font, size: fragment.size})
+
+		thickness := math.Max(fragment.size/18, 0.5)
+		if fragment.underline {
+			decorations.WriteString(l.artifact(pdfColor(fragment.color) + " rg " + pdfRect(x, baseline-fragment.size*0.12, fragment.width, thickness) + " re f\n"))
+			l.page.items = append(l.page.items, pdfPageItem{kind: pdfRectItem, x: x, y: baseline - fragment.size*0.12, width: fragment.width, height: thickness, color: fragment.color})
+		}
+		if fragment.strike {
+			decorations.WriteString(l.artifact(pdfColor(fragment.color) + " rg " + pdfRect(x, baseline+fragment.size*0.28, fragment.width, thickness) + " re f\n"))
+			l.page.items = append(l.page.items, pdfPageItem{kind: pdfRectItem, x: x, y: baseline + fragment.size*0.28, width: fragment.width, height: thickness, color: fragment.color})
+		}
+		x += fragment.width
+	}
+
+	flushLink()
+	if marking {
+		content.WriteString("EMC\n")
+	}
+	if started {
+		content.WriteString("ET\n")
+	}
+	content.WriteString(decorations.String())
+	return positions
+}
+
+// pdfRect returns the operands of a rectangle
+func pdfRect(x, y, width, height float64) string {
+	return pdfNumber(x) + " " + pdfNumber(y) + " " + pdfNumber(width) + " " + pdfNumber(height)
+}
+
+// pdfColor returns the RGB operands of a hex color; empty or automatic
+// colors are black
+func pdfColor(hex string) string {
+	if len(hex) != 6 || strings.EqualFold(hex, "auto") {
+		return "0 0 0"
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return "0 0 0"
+	}
+	component := func(shift uint) string {
+		return pdfNumber(float64(value>>shift&0xFF) / 255)
+	}
+	return component(16) + " " + component(8) + " " + component(0)
+}
@@ -0,0 +1,249 @@
+package word
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// pdfNoteDestPrefix prefixes the destination names of notes. Word bookmark
+// names cannot contain a colon, so they never clash.
+const pdfNoteDestPrefix = "fn:"
+
+// pdfLink is a link area of a page. Targets starting with "#" name a
+// destination, all others are URIs.
+type pdfLink struct {
+	x1, y1, x2, y2 float64
+	target         string
+	// elem is the Link element of a tagged PDF and text the linked text
+	elem *pdfStructElem
+	text string
+}
+
+// pdfDest is a position in the laid out document
+type pdfDest struct {
+	page int
+	y    float64
+}
+
+// pdfOutlineItem is an entry of the document outline
+type pdfOutlineItem struct {
+	title    string
+	dest     pdfDest
+	children []*pdfOutlineItem
+}
+
+// markParagraph records the position of a paragraph whose first line
+// starts at y, together with the bookmarks that start in it
+func (l *pdfLayout) markParagraph(paragraph *types.Paragraph, y float64) {
+	if paragraph == nil || l.running {
+		return
+	}
+	dest := pdfDest{page: len(l.pages) - 1, y: y}
+	if _, ok := l.paragraphDests[paragraph]; !ok {
+		l.paragraphDests[paragraph] = dest
+	}
+	for _, name := range paragraph.Bookmarks {
+		if _, ok := l.dests[name]; !ok {
+			l.dests[name] = dest
+		}
+	}
+}
+
+// noteReference returns the superscript number of a footnote reference,
+// linked to the note at the end of the document
+func (l *pdfLayout) noteReference(format pdfFragment, fontName, id string) []pdfFragment {
+	if _, ok := l.footnotes[id]; !ok {
+		return nil
+	}
+
+	number := 0
+	for i, used := range l.notes {
+		if used == id {
+			number = i + 1
+		}
+	}
+	if number == 0 {
+		l.notes = append(l.notes, id)
+		number = len(l.notes)
+	}
+
+	format.link = "#" + pdfNoteDestPrefix + id
+	format.rise = format.size * 0.35
+	format.size *= 0.65
+	return l.textFragments(format, fontName, false, false, strconv.Itoa(number))
+}
+
+// layoutNotes places the referenced footnotes after the body, below a
+// short separator line like the footnote separator of Word
+func (l *pdfLayout) layoutNotes() {
+	if len(l.notes) == 0 {
+		return
+	}
+
+	size := l.defaultFontSize()
+	if l.y-2*size*pdfLineHeight < l.bottom {
+		l.newPage()
+	} else {
+		l.y -= size
+	}
+	l.page.content.WriteString(l.artifact("q 0 0 0 RG 0.5 w " + pdfNumber(l.left) + " " + pdfNumber(l.y) + " m " + pdfNumber(l.left+144) + " " + pdfNumber(l.y) + " l S Q\n"))
+	l.page.items = append(l.page.items, pdfPageItem{kind: pdfLineItem, x: l.left, y: l.y, width: 144, lineWidth: 0.5})
+	l.y -= size / 2
+
+	// 脚注中也可能引用新的脚注，所以按下标遍历
+	for i := 0; i < len(l.notes); i++ {
+		id := l.notes[i]
+		paragraphs := append([]types.Paragraph(nil), l.footnotes[id]...)
+		if len(paragraphs) == 0 {
+			paragraphs = []types.Paragraph{{}}
+		}
+
+		// 第一段以脚注编号开头，并作为链接的目标
+		first := paragraphs[0]
+		runs := []types.Run{{Text: strconv.Itoa(i+1) + ". "}}
+		trimming := true
+		for _, run := range first.Runs {
+			if trimming && run.Image == nil {
+				run.Text = strings.TrimLeft(run.Text, " ")
+				trimming = run.Text == ""
+			}
+			runs = append(runs, run)
+		}
+		first.Runs = runs
+		first.Bookmarks = append([]string{pdfNoteDestPrefix + id}, first.Bookmarks...)
+		paragraphs[0] = first
+
+		for j := range paragraphs {
+			l.layoutParagraph(&paragraphs[j])
+		}
+	}
+}
+
+// buildOutline converts the outline of the document structure into outline
+// items that point at the laid out headings. body holds the paragraphs in
+// the order of the structure's paragraph indexes.
+func (l *pdfLayout) buildOutline(sections []DocumentSection, body []types.Paragraph) []*pdfOutlineItem {
+	var items []*pdfOutlineItem
+	for _, section := range sections {
+		children := l.buildOutline(section.SubSections, body)
+		if section.StartIndex < 0 || section.StartIndex >= len(body) || !isHeadingParagraph(body[section.StartIndex]) {
+			// 第一个标题之前的正文不是大纲条目，其子条目上移一级
+			items = append(items, children...)
+			continue
+		}
+		dest, ok := l.paragraphDests[&body[section.StartIndex]]
+		if !ok || section.Title == "" {
+			items = append(items, children...)
+			continue
+		}
+		items = append(items, &pdfOutlineItem{title: section.Title, dest: dest, children: children})
+	}
+	return items
+}
+
+// pdfDestArray returns an explicit destination that shows the page with
+// the position at the top of the window
+func pdfDestArray(dest pdfDest, pageIDs []int) string {
+	return fmt.Sprintf("[%d 0 R /XYZ null %s null]", pageIDs[dest.page], pdfNumber(dest.y))
+}
+
+// writePDFLinks writes the link annotations of a page and returns the
+// /Annots entry of the page dictionary. Links to unknown destinations are
+// dropped.
+func writePDFLinks(w *pdfWriter, layout *pdfLayout, index int, pageIDs []int) string {
+	var annots []string
+	for _, link := range layout.pages[index].links {
+		action := ""
+		if strings.HasPrefix(link.target, "#") {
+			dest, ok := layout.dests[strings.TrimPrefix(link.target, "#")]
+			if !ok {
+				continue
+			}
+			action = "/Dest " + pdfDestArray(dest, pageIDs)
+		} else {
+			action = "/A << /S /URI /URI " + pdfURIString(link.target) + " >>"
+		}
+
+		if isPDFA(layout.config) {
+			// PDF/A要求注释设置打印标志
+			action += " /F 4"
+		}
+		id := w.alloc()
+		if link.elem != nil {
+			// 带标签的PDF中注释属于Link结构元素并带有说明文字
+			action += fmt.Sprintf(" /StructParent %d /Contents %s", len(layout.pages)+len(layout.tags.annotations), pdfTextString(strings.TrimSpace(link.text)))
+			layout.tags.annotations = append(layout.tags.annotations, link.elem)
+			link.elem.kids = append(link.elem.kids, pdfStructKid{page: index, annot: id})
+		}
+		w.object(id, fmt.Sprintf("<< /Type /Annot /Subtype /Link /Rect [%s %s %s %s] /Border [0 0 0] %s >>",
+			pdfNumber(link.x1), pdfNumber(link.y1), pdfNumber(link.x2), pdfNumber(link.y2), action))
+		annots = append(annots, fmt.Sprintf("%d 0 R", id))
+	}
+	if len(annots) == 0 {
+		return ""
+	}
+	return " /Annots [" + strings.Join(annots, " ") + "]"
+}
+
+// writePDFOutline writes the outline tree and returns the object number of
+// its root, or 0 when there are no items
+func writePDFOutline(w *pdfWriter, items []*pdfOutlineItem, pageIDs []int) int {
+	if len(items) == 0 {
+		return 0
+	}
+	rootID := w.alloc()
+	first, last, count := writePDFOutlineItems(w, items, rootID, pageIDs)
+	w.object(rootID, fmt.Sprintf("<< /Type /Outlines /First %d 0 R /Last %d 0 R /Count %d >>", first, last, count))
+	return rootID
+}
+
+// writePDFOutlineItems writes sibling outline items and returns the first
+// and last object numbers and the number of items including descendants
+func writePDFOutlineItems(w *pdfWriter, items []*pdfOutlineItem, parentID int, pageIDs []int) (int, int, int) {
+	ids := make([]int, len(items))
+	for i := range items {
+		ids[i] = w.alloc()
+	}
+
+	total := len(items)
+	for i, item := range items {
+		entries := fmt.Sprintf("/Title %s /Parent %d 0 R /Dest %s", pdfTextString(item.title), parentID, pdfDestArray(item.dest, pageIDs))
+		if i > 0 {
+			entries += fmt.Sprintf(" /Prev %d 0 R", ids[i-1])
+		}
+		if i < len(items)-1 {
+			entries += fmt.Sprintf(" /Next %d 0 R", ids[i+1])
+		}
+		if len(item.children) > 0 {
+			first, last, count := writePDFOutlineItems(w, item.children, ids[i], pageIDs)
+			entries += fmt.Sprintf(" /First %d 0 R /Last %d 0 R /Count %d", first, last, count)
+			total += count
+		}
+		w.object(ids[i], "<< "+entries+" >>")
+	}
+	return ids[0], ids[len(ids)-1], total
+}
+
+// pdfURIString returns a URI as a PDF string. URIs are 7-bit ASCII, so
+// other bytes are percent-encoded.
+func pdfURIString(uri string) string {
+	var buf strings.Builder
+	buf.WriteByte('(')
+	for i := 0; i < len(uri); i++ {
+		c := uri[i]
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c <= ' ' || c > '~':
+			fmt.Fprintf(&buf, "%%%02X", c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
@@ -0,0 +1,254 @@
+package word
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// pdfSRGBName identifies the output condition of the sRGB output intent
+const pdfSRGBName = "sRGB IEC61966-2.1"
+
+// isPDFA reports whether the configuration asks for PDF/A output
+func isPDFA(config *types.PDFExportConfig) bool {
+	return config.Conformance == types.PDFConformancePDFA2B
+}
+
+// validateConformance checks that the configuration can produce the
+// requested conformance level
+func validateConformance(config *types.PDFExportConfig) error {
+	switch config.Conformance {
+	case types.PDFConformanceNone:
+		return nil
+	case types.PDFConformancePDFA2B:
+		if pdfEncrypted(config) {
+			return fmt.Errorf("PDF/A does not allow encryption")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported PDF conformance %q", config.Conformance)
+	}
+}
+
+// checkEmbeddedFonts reports an error when text would be drawn with a
+// standard font, which PDF/A and PDF/UA forbid because standard fonts are
+// not embedded
+func checkEmbeddedFonts(layout *pdfLayout) error {
+	for _, font := range layout.fonts {
+		if _, ok := font.(*pdfStandardFont); ok {
+			return fmt.Errorf("no installed font can replace %s for embedding", font.baseFont())
+		}
+	}
+	return nil
+}
+
+// xmp returns the XMP metadata packet with the same values as the
+// information dictionary and the PDF/A and PDF/UA identification
+func (m pdfMetadata) xmp(id []byte, config *types.PDFExportConfig) []byte {
+	escape := func(s string) string {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(s))
+		return buf.String()
+	}
+	uuid := fmt.Sprintf("uuid:%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+	date := m.date.Format("2006-01-02T15:04:05-07:00")
+
+	var buf bytes.Buffer
+	buf.WriteString("<?xpacket begin=\"\xEF\xBB\xBF\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	buf.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about=""
+  xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/"
+  xmlns:pdfuaid="http://www.aiim.org/pdfua/ns/id/"
+  xmlns:dc="http://purl.org/dc/elements/1.1/"
+  xmlns:pdf="http://ns.adobe.com/pdf/1.3/"
+  xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+  xmlns:xmpMM="http://ns.adobe.com/xap/1.0/mm/">
+<dc:format>application/pdf</dc:format>
+`)
+	if isPDFA(config) {
+		buf.WriteString("<pdfaid:part>2</pdfaid:part>\n<pdfaid:conformance>B</pdfaid:conformance>\n")
+	}
+	if config.Tagged {
+		buf.WriteString("<pdfuaid:part>1</pdfuaid:part>\n")
+	}
+	if m.title != "" {
+		fmt.Fprintf(&buf, "<dc:title><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:title>\n", escape(m.title))
+	}
+	if m.author != "" {
+		fmt.Fprintf(&buf, "<dc:creator><rdf:Seq><rdf:li>%s</rdf:li></rdf:Seq></dc:creator>\n", escape(m.author))
+	}
+	if m.subject != "" {
+		fmt.Fprintf(&buf, "<dc:description><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:description>\n", escape(m.subject))
+	}
+	if m.keywords != "" {
+		fmt.Fprintf(&buf, "<pdf:Keywords>%s</pdf:Keywords>\n", escape(m.keywords))
+	}
+	fmt.Fprintf(&buf, "<pdf:Producer>%s</pdf:Producer>\n", pdfProducer)
+	if m.creator != "" {
+		fmt.Fprintf(&buf, "<xmp:CreatorTool>%s</xmp:CreatorTool>\n", escape(m.creator))
+	}
+	fmt.Fprintf(&buf, "<xmp:CreateDate>%s</xmp:CreateDate>\n<xmp:ModifyDate>%s</xmp:ModifyDate>\n<xmp:MetadataDate>%s</xmp:MetadataDate>\n", date, date, date)
+	fmt.Fprintf(&buf, "<xmpMM:DocumentID>%s</xmpMM:DocumentID>\n<xmpMM:InstanceID>%s</xmpMM:InstanceID>\n", uuid, uuid)
+	buf.WriteString("</rdf:Description>\n</rdf:RDF>\n</x:xmpmeta>\n<?xpacket end=\"w\"?>")
+	return buf.Bytes()
+}
+
+// writePDFMetadata writes the XMP metadata stream and returns the catalog
+// entry that refers to it
+func writePDFMetadata(w *pdfWriter, metadata pdfMetadata, config *types.PDFExportConfig) string {
+	metadataID := w.alloc()
+	// 元数据流不压缩，便于不解析PDF的工具读取
+	w.encodedStream(metadataID, "/Type /Metadata /Subtype /XML", metadata.xmp(w.id, config))
+	return fmt.Sprintf(" /Metadata %d 0 R", metadataID)
+}
+
+// writePDFOutputIntent writes the sRGB output intent of PDF/A and returns
+// the catalog entry that refers to it
+func writePDFOutputIntent(w *pdfWriter) string {
+	profileID := w.alloc()
+	w.stream(profileID, "/N 3", srgbICCProfile())
+	intentID := w.alloc()
+	w.object(intentID, fmt.Sprintf("<< /Type /OutputIntent /S /GTS_PDFA1 /OutputConditionIdentifier (%s) /Info (%s) /DestOutputProfile %d 0 R >>",
+		pdfSRGBName, pdfSRGBName, profileID))
+
+	return fmt.Sprintf(" /OutputIntents [%d 0 R]", intentID)
+}
+
+// srgbICCProfile builds an ICC version 2 display profile for sRGB with
+// the primaries adapted to D50 and the sRGB tone curve as a table
+func srgbICCProfile() []byte {
+	s15 := func(values ...float64) []byte {
+		data := make([]byte, 4*len(values))
+		for i, v := range values {
+			binary.BigEndian.PutUint32(data[4*i:], uint32(int32(math.Round(v*65536))))
+		}
+		return data
+	}
+	xyz := func(x, y, z float64) []byte {
+		return append([]byte("XYZ \x00\x00\x00\x00"), s15(x, y, z)...)
+	}
+	text := func(s string) []byte {
+		return append([]byte("text\x00\x00\x00\x00"+s), 0)
+	}
+
+	description := "sRGB IEC61966-2.1"
+	desc := []byte("desc\x00\x00\x00\x00")
+	desc = binary.BigEndian.AppendUint32(desc, uint32(len(description)+1))
+	desc = append(desc, description...)
+	// 空的Unicode和ScriptCode描述
+	desc = append(desc, make([]byte, 1+4+4+2+1+67)...)
+
+	const points = 1024
+	curve := []byte("curv\x00\x00\x00\x00")
+	curve = binary.BigEndian.AppendUint32(curve, points)
+	for i := 0; i < points; i++ {
+		v := float64(i) / (points - 1)
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		curve = binary.BigEndian.AppendUint16(curve, uint16(math.Round(v*65535)))
+	}
+
+	tags := []struct {
+		signature string
+		data      []byte
+	}{
+		{"desc", desc},
+		{"cprt", text("No copyright, use freely")},
+		{"wtpt", xyz(0.9642, 1, 0.8249)},
+		{"rXYZ", xyz(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", xyz(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", xyz(0.1431, 0.0606, 0.7141)},
+		{"rTRC", curve},
+		{"gTRC", curve},
+		{"bTRC", curve},
+	}
+
+	// 标签表之后依次存放标签数据，相同的数据只存放一次
+	table := binary.BigEndian.AppendUint32(nil, uint32(len(tags)))
+	var data []byte
+	offsets := make(map[string]int)
+	start := 128 + 4 + 12*len(tags)
+	for _, tag := range tags {
+		offset, ok := offsets[string(tag.data)]
+		if !ok {
+			offset = start + len(data)
+			offsets[string(tag.data)] = offset
+			data = append(data, tag.data...)
+			for len(data)%4 != 0 {
+				data = append(data, 0)
+			}
+		}
+		table = append(table, tag.signature...)
+		table = binary.BigEndian.AppendUint32(table, uint32(offset))
+		table = binary.BigEndian.AppendUint32(table, uint32(len(tag.data)))
+	}
+
+	header := make([]byte, 128)
+	binary.BigEndian.PutUint32(header, uint32(start+len(data)))
+	binary.BigEndian.PutUint32(header[8:], 0x02100000)
+	copy(header[12:], "mntrRGB XYZ ")
+	for i, v := range []int{2024, 1, 1} {
+		binary.BigEndian.PutUint16(header[24+2*i:], uint16(v))
+	}
+	copy(header[36:], "acsp")
+	copy(header[68:], s15(0.9642, 1, 0.8249))
+
+	profile := append(header, table...)
+	return append(profile, data...)
+}
+
+// pdfImageNeedsFlattening reports whether a picture has CMYK samples or
+// may have an alpha channel
+func pdfImageNeedsFlattening(data []byte) bool {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	if format == "jpeg" {
+		config, _ := jpeg.DecodeConfig(bytes.NewReader(data))
+		return config.ColorModel == color.CMYKModel
+	}
+	return format != "bmp"
+}
+
+// flattenPDFImage decodes a picture into opaque RGB samples, painting
+// transparent pixels over white. PDF/A output uses it for pictures with an
+// alpha channel or CMYK samples that the sRGB output intent cannot describe.
+func flattenPDFImage(data []byte) (*pdfImage, error) {
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("无法解码图片: %w", err)
+	}
+	bounds := decoded.Bounds()
+	img := &pdfImage{width: bounds.Dx(), height: bounds.Dy(), colorSpace: "DeviceRGB", filter: "FlateDecode"}
+
+	pixels := make([]byte, 0, img.width*img.height*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// 预乘颜色加上白色背景的剩余部分
+			c := color.RGBAModel.Convert(decoded.At(x, y)).(color.RGBA)
+			white := 0xFF - c.A
+			pixels = append(pixels, c.R+white, c.G+white, c.B+white)
+		}
+	}
+	img.data = pdfDeflate(pixels)
+	return img, nil
+}
+
+// pdfHexString returns data as a hexadecimal string
+func pdfHexString(data []byte) string {
+	return "<" + strings.ToUpper(hex.EncodeToString(data)) + ">"
+}
@@ -0,0 +1,501 @@
+package word
+
+import (
+	"math"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+const (
+	// pdfCellPaddingX is the default left and right cell margin of Word
+	pdfCellPaddingX = 5.4
+	// pdfCellPaddingY is the space above and below the cell content
+	pdfCellPaddingY = 1.5
+)
+
+// pdfDefaultBorder is used for tables with a style but without direct
+// borders, which in practice are grid styles such as TableGrid
+var pdfDefaultBorder = &types.TableBorder{Style: "single", Width: 0.5}
+
+// pdfTableCell is a cell of a laid out table. A vertically merged cell is
+// one pdfTableCell for all rows of the merged region.
+type pdfTableCell struct {
+	cell     *types.TableCell
+	column   int
+	span     int
+	x        float64
+	width    float64
+	firstRow int
+	lastRow  int
+	lines    []pdfPlacedLine
+	// height is the content height including the padding
+	height float64
+	// next is the first line not yet drawn and cursor the top of that
+	// line on the current page
+	next   int
+	cursor float64
+}
+
+// pdfTableLayout holds the grid of a table during layout
+type pdfTableLayout struct {
+	table   *types.Table
+	columns []float64
+	// rows lists the cell of each grid column per row; merged cells appear
+	// in every row and column they cover
+	rows    [][]*pdfTableCell
+	heights []float64
+	headers int
+	// pageTop is set while nothing but header rows has been drawn on a
+	// page that the table continues on
+	pageTop bool
+}
+
+// layoutTable lays out a table with its column widths, borders, shading
+// and merged cells. Rows that do not fit start a new page, where the
+// header rows are repeated; rows taller than a page are split between
+// lines.
+func (l *pdfLayout) layoutTable(table *types.Table) {
+	t := l.tableGrid(table)
+	if len(t.rows) == 0 {
+		return
+	}
+	if l.tags != nil && !l.tags.artifact {
+		l.tagTable(t)
+	}
+
+	headerHeight := 0.0
+	for _, height := range t.heights[:t.headers] {
+		headerHeight += height
+	}
+	full := l.top - l.bottom
+
+	for r := 0; r < len(t.rows); r++ {
+		remaining := t.heights[r]
+		first := true
+		for {
+			if err := l.ctx.Err(); err != nil {
+				return
+			}
+			available := l.y - l.bottom
+			if remaining <= available+0.01 {
+				l.drawTableRow(t, r, remaining, first, true)
+				break
+			}
+			if !l.atPageTop() && !t.pageTop && (remaining <= full-headerHeight || available < remaining/4 || r < t.headers) {
+				// 整行移到下一页
+				l.closeTablePage(t, r, first)
+				l.newTablePage(t, r)
+				continue
+			}
+
+			// 行比整页还高，在行之间拆分
+			l.drawTableRow(t, r, available, first, false)
+			remaining = math.Max(remaining-available, t.remainingHeight(r))
+			first = false
+			l.newTablePage(t, r)
+		}
+	}
+}
+
+// tableGrid measures the columns, cells and rows of a table
+func (l *pdfLayout) tableGrid(table *types.Table) *pdfTableLayout {
+	t := &pdfTableLayout{table: table}
+
+	columns := len(table.ColumnWidths)
+	for _, row := range table.Rows {
+		span := 0
+		for _, cell := range row.Cells {
+			span += max(cell.ColSpan, 1)
+		}
+		columns = max(columns, span)
+	}
+	if columns == 0 {
+		return t
+	}
+	t.columns = l.tableColumns(table, columns)
+
+	// 单元格位置与纵向合并
+	for r := range table.Rows {
+		row := make([]*pdfTableCell, columns)
+		column := 0
+		for i := range table.Rows[r].Cells {
+			cell := &table.Rows[r].Cells[i]
+			span := min(max(cell.ColSpan, 1), columns-column)
+			if span <= 0 {
+				break
+			}
+
+			var placed *pdfTableCell
+			if cell.VMerge == "continue" && r > 0 {
+				if above := t.rows[r-1][column]; above != nil && above.column == column && above.span == span {
+					placed = above
+					placed.lastRow = r
+				}
+			}
+			if placed == nil {
+				placed = &pdfTableCell{cell: cell, column: column, span: span, firstRow: r, lastRow: r}
+			}
+			for c := column; c < column+span; c++ {
+				row[c] = placed
+			}
+			column += span
+		}
+		t.rows = append(t.rows, row)
+	}
+
+	// 测量单元格内容
+	x := l.left
+	offsets := make([]float64, columns+1)
+	for c, width := range t.columns {
+		offsets[c] = x
+		x += width
+	}
+	offsets[columns] = x
+	for _, row := range t.rows {
+		for _, cell := range row {
+			if cell == nil || cell.lines != nil {
+				continue
+			}
+			cell.x = offsets[cell.column]
+			cell.width = offsets[cell.column+cell.span] - cell.x
+			cell.lines, cell.height = l.cellLines(cell.cell, cell.width-2*pdfCellPaddingX)
+		}
+	}
+
+	// 行高取单行单元格的最大高度，合并单元格不足的高度加到最后一行
+	t.heights = make([]float64, len(t.rows))
+	for r, row := range t.rows {
+		for _, cell := range row {
+			if cell != nil && cell.firstRow == r && cell.lastRow == r {
+				t.heights[r] = math.Max(t.heights[r], cell.height)
+			}
+		}
+		if t.heights[r] == 0 {
+			t.heights[r] = l.defaultFontSize()*pdfLineHeight + 2*pdfCellPaddingY
+		}
+	}
+	for r, row := range t.rows {
+		for c, cell := range row {
+			if cell == nil || cell.firstRow != r || cell.lastRow == r || (c > 0 && row[c-1] == cell) {
+				continue
+			}
+			total := 0.0
+			for _, height := range t.heights[cell.firstRow : cell.lastRow+1] {
+				total += height
+			}
+			if total < cell.height {
+				t.heights[cell.lastRow] += cell.height - total
+			}
+		}
+	}
+
+	// 表头行只在没有占满整个表格时重复
+	for t.headers < len(table.Rows) && table.Rows[t.headers].Header {
+		t.headers++
+	}
+	if t.headers == len(table.Rows) {
+		t.headers = 0
+	}
+	return t
+}
+
+// tableColumns returns the widths of the grid columns. Missing widths come
+// from single-column cells or share the remaining space; tables wider than
+// the text area are scaled down.
+func (l *pdfLayout) tableColumns(table *types.Table, columns int) []float64 {
+	widths := make([]float64, columns)
+	copy(widths, table.ColumnWidths)
+	for _, row := range table.Rows {
+		column := 0
+		for _, cell := range row.Cells {
+			span := max(cell.ColSpan, 1)
+			if span == 1 && column < columns && widths[column] == 0 && cell.Width > 0 {
+				widths[column] = cell.Width
+			}
+			column += span
+		}
+	}
+
+	available := l.right - l.left
+	total, missing := 0.0, 0
+	for _, width := range widths {
+		total += width
+		if width <= 0 {
+			missing++
+		}
+	}
+	if missing > 0 {
+		share := math.Max(available-total, 0) / float64(missing)
+		if share < 18 {
+			share = available / float64(columns)
+		}
+		for c := range widths {
+			if widths[c] <= 0 {
+				widths[c] = share
+				total += share
+			}
+		}
+	}
+	if total > available {
+		for c := range widths {
+			widths[c] *= available / total
+		}
+	}
+	return widths
+}
+
+// cellLines breaks the paragraphs of a cell into lines and returns them
+// with the height of the cell content including the padding
+func (l *pdfLayout) cellLines(cell *types.TableCell, width float64) ([]pdfPlacedLine, float64) {
+	paragraphs := cell.Paragraphs
+	if len(paragraphs) == 0 {
+		paragraphs = []types.Paragraph{{Text: cell.Text, Runs: []types.Run{{Text: cell.Text}}}}
+	}
+
+	lines := []pdfPlacedLine{}
+	height := 2 * pdfCellPaddingY
+	after := 0.0
+	for i := range paragraphs {
+		paragraphLines, spaceAfter := l.paragraphLines(&paragraphs[i], width)
+		for j, placed := range paragraphLines {
+			if j == 0 {
+				// 段前距与上一段的段后距取较大值，单元格顶部不留段前距
+				placed.before = math.Max(placed.before, after)
+				if len(lines) == 0 {
+					placed.before = 0
+				}
+			}
+			placed.line.pageBreak, placed.line.columnBreak = false, false
+			height += placed.before + placed.height
+			lines = append(lines, placed)
+		}
+		after = spaceAfter
+	}
+	return lines, height
+}
+
+// remainingHeight returns the height that the undrawn lines of the cells
+// ending in row r still need
+func (t *pdfTableLayout) remainingHeight(r int) float64 {
+	height := 0.0
+	for c, cell := range t.rows[r] {
+		if cell == nil || cell.lastRow != r || (c > 0 && t.rows[r][c-1] == cell) {
+			continue
+		}
+		needed := pdfCellPaddingY
+		for _, placed := range cell.lines[cell.next:] {
+			needed += placed.before + placed.height
+		}
+		if cell.next < len(cell.lines) {
+			height = math.Max(height, needed+pdfCellPaddingY)
+		}
+	}
+	return height
+}
+
+// newTablePage starts a page in the middle of a table, repeats the header
+// rows and moves the cells that continue from the previous page to the top
+func (l *pdfLayout) newTablePage(t *pdfTableLayout, r int) {
+	left := l.left
+	l.newColumn()
+	if shift := l.left - left; shift != 0 {
+		// 表格在下一栏继续时整体平移
+		moved := make(map[*pdfTableCell]bool)
+		for _, row := range t.rows {
+			for _, cell := range row {
+				if cell != nil && !moved[cell] {
+					cell.x += shift
+					moved[cell] = true
+				}
+			}
+		}
+	}
+	if r >= t.headers {
+		// 重复的表头行不属于文档内容
+		l.repeating = true
+		if l.tags != nil {
+			l.tags.artifact = true
+		}
+		for h := 0; h < t.headers; h++ {
+			for _, cell := range t.rows[h] {
+				if cell != nil {
+					cell.next = 0
+				}
+			}
+			l.drawTableRow(t, h, t.heights[h], true, true)
+		}
+		l.repeating = false
+		if l.tags != nil {
+			l.tags.artifact = false
+		}
+	}
+	for _, cell := range t.rows[r] {
+		if cell != nil {
+			cell.cursor = l.y - pdfCellPaddingY
+		}
+	}
+	t.pageTop = true
+}
+
+// closeTablePage draws the bottom border of merged cells that continue on
+// the next page before row r
+func (l *pdfLayout) closeTablePage(t *pdfTableLayout, r int, first bool) {
+	if r == 0 || !first {
+		return
+	}
+	for c, cell := range t.rows[r] {
+		if cell == nil || cell.firstRow == r || (c > 0 && t.rows[r][c-1] == cell) {
+			continue
+		}
+		l.drawBorder(t.cellBorder(cell, "bottom", true), cell.x, l.y, cell.x+cell.width, l.y)
+	}
+}
+
+// drawTableRow draws height points of row r at the current position. first
+// is set for the first part of the row and last for the part that ends it.
+func (l *pdfLayout) drawTableRow(t *pdfTableLayout, r int, height float64, first, last bool) {
+	top, bottom := l.y, l.y-height
+	content := &l.page.content
+	pageTop := t.pageTop
+	if r >= t.headers {
+		t.pageTop = false
+	}
+
+	var cells []*pdfTableCell
+	for c, cell := range t.rows[r] {
+		if cell != nil && (c == 0 || t.rows[r][c-1] != cell) {
+			cells = append(cells, cell)
+		}
+	}
+
+	for _, cell := range cells {
+		if cell.cell.Shading != "" {
+			content.WriteString(l.artifact("q " + pdfColor(cell.cell.Shading) + " rg " + pdfRect(cell.x, bottom, cell.width, height) + " re f Q\n"))
+			l.page.items = append(l.page.items, pdfPageItem{kind: pdfRectItem, x: cell.x, y: bottom, width: cell.width, height: height, color: cell.cell.Shading})
+		}
+	}
+
+	if l.record != nil && !l.running && !l.repeating {
+		for _, cell := range cells {
+			l.record.cells = append(l.record.cells, pdfCellBox{table: t.table, cell: cell, row: r, page: len(l.pages) - 1, top: top, height: height})
+		}
+	}
+
+	for _, cell := range cells {
+		if first && cell.firstRow == r {
+			cell.cursor = top - pdfCellPaddingY
+		}
+		// 合并单元格的内容可以一直排到合并区域的底部
+		limit := bottom
+		if cell.lastRow == r && last {
+			limit += pdfCellPaddingY
+		}
+		for cell.next < len(cell.lines) {
+			placed := cell.lines[cell.next]
+			if cell.cursor-placed.before-placed.height < limit-0.01 && cell.cursor < top-pdfCellPaddingY {
+				break
+			}
+			cell.cursor -= placed.before
+			l.markParagraph(placed.paragraph, cell.cursor)
+			l.placeLine(placed, cell.x+pdfCellPaddingX+placed.offset, cell.cursor)
+			cell.cursor -= placed.height
+			cell.next++
+		}
+	}
+
+	for _, cell := range cells {
+		// 跨页继续的单元格在新页面顶部也画上边框
+		starts := first && cell.firstRow == r
+		if starts || pageTop || r < t.headers {
+			l.drawBorder(t.cellBorder(cell, "top", !starts), cell.x, top, cell.x+cell.width, top)
+		}
+		if cell.lastRow == r && last || !last {
+			l.drawBorder(t.cellBorder(cell, "bottom", !last), cell.x, bottom, cell.x+cell.width, bottom)
+		}
+		l.drawBorder(t.cellBorder(cell, "left", false), cell.x, top, cell.x, bottom)
+		l.drawBorder(t.cellBorder(cell, "right", false), cell.x+cell.width, top, cell.x+cell.width, bottom)
+	}
+
+	l.y = bottom
+}
+
+// cellBorder returns the border of a cell edge: the cell's own border,
+// else the outer or inside border of the table. split is set for edges
+// where the table is split between pages, which use the outer border.
+func (t *pdfTableLayout) cellBorder(cell *pdfTableCell, edge string, split bool) *types.TableBorder {
+	if own := bordersEdge(cell.cell.Borders, edge, false); own != nil {
+		return own
+	}
+
+	borders := t.table.Borders
+	if borders == nil {
+		if t.table.Style == "" {
+			return nil
+		}
+		return pdfDefaultBorder
+	}
+	outer := split
+	switch edge {
+	case "top":
+		outer = outer || cell.firstRow == 0
+	case "bottom":
+		outer = outer || cell.lastRow == len(t.rows)-1
+	case "left":
+		outer = cell.column == 0
+	case "right":
+		outer = cell.column+cell.span == len(t.columns)
+	}
+	return bordersEdge(borders, edge, !outer)
+}
+
+// bordersEdge returns the border of an edge, or the inside border
+func bordersEdge(borders *types.TableBorders, edge string, inside bool) *types.TableBorder {
+	if borders == nil {
+		return nil
+	}
+	switch {
+	case inside && (edge == "top" || edge == "bottom"):
+		return borders.InsideH
+	case inside:
+		return borders.InsideV
+	case edge == "top":
+		return borders.Top
+	case edge == "bottom":
+		return borders.Bottom
+	case edge == "left":
+		return borders.Left
+	default:
+		return borders.Right
+	}
+}
+
+// drawBorder strokes a border line
+func (l *pdfLayout) drawBorder(border *types.TableBorder, x1, y1, x2, y2 float64) {
+	if border == nil {
+		return
+	}
+	style := strings.ToLower(border.Style)
+	if style == "" || style == "none" || style == "nil" {
+		return
+	}
+	width := border.Width
+	if width <= 0 {
+		width = 0.5
+	}
+
+	var pattern []float64
+	switch {
+	case strings.Contains(style, "dot"):
+		pattern = []float64{width, width * 2}
+	case strings.Contains(style, "dash"):
+		pattern = []float64{width * 4, width * 2}
+	}
+	dash := "[] 0 d"
+	if pattern != nil {
+		dash = "[" + pdfNumber(pattern[0]) + " " + pdfNumber(pattern[1]) + "] 0 d"
+	}
+	l.page.content.WriteString(l.artifact("q " + pdfColor(border.Color) + " RG " + pdfNumber(width) + " w " + dash + " " +
+		pdfNumber(x1) + " " + pdfNumber(y1) + " m " + pdfNumber(x2) + " " + pdfNumber(y2) + " l S Q\n"))
+	l.page.items = append(l.page.items, pdfPageItem{kind: pdfLineItem, x: x1, y: y1, width: x2 - x1, height: y2 - y1, color: border.Color, lineWidth: width, dash: pattern})
+}
@@ -0,0 +1,307 @@
+package word
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// pdfStructElem is an element of the structure tree of a tagged PDF
+type pdfStructElem struct {
+	tag    string
+	parent *pdfStructElem
+	kids   []pdfStructKid
+	// alt is the alternate description of a figure
+	alt string
+	// attributes is the /A entry, such as the spans of a table cell
+	attributes string
+	// id is the object number, assigned when the tree is written
+	id int
+}
+
+// pdfStructKid is a child element, a marked-content sequence on a page or
+// an annotation
+type pdfStructKid struct {
+	elem *pdfStructElem
+	page int
+	mcid int
+	// annot is the object number of a link annotation
+	annot int
+}
+
+// pdfTagger builds the structure tree while the layout draws the pages.
+// Elements are created in document order, which is the reading order.
+type pdfTagger struct {
+	root *pdfStructElem
+	// container receives the elements of paragraphs and tables
+	container *pdfStructElem
+	// lists are the open lists of the container by level
+	lists      []*pdfStructElem
+	paragraphs map[*types.Paragraph]*pdfStructElem
+	// labels are the Lbl elements of list items, which hold the markers
+	labels map[*types.Paragraph]*pdfStructElem
+	// marked holds the element of every marked-content id per page
+	marked [][]*pdfStructElem
+	// annotations are the elements of the link annotations, whose parent
+	// tree keys follow the keys of the pages
+	annotations []*pdfStructElem
+	// artifact is set while content that repeats, such as the header rows
+	// of a table on the following pages, is drawn
+	artifact bool
+	// title is the text of the first heading
+	title string
+}
+
+// newPDFTagger creates a structure tree with an empty Document element
+func newPDFTagger() *pdfTagger {
+	root := &pdfStructElem{tag: "Document"}
+	return &pdfTagger{
+		root:       root,
+		container:  root,
+		paragraphs: make(map[*types.Paragraph]*pdfStructElem),
+		labels:     make(map[*types.Paragraph]*pdfStructElem),
+	}
+}
+
+// add appends a child element
+func (t *pdfTagger) add(parent *pdfStructElem, tag string) *pdfStructElem {
+	elem := &pdfStructElem{tag: tag, parent: parent}
+	parent.kids = append(parent.kids, pdfStructKid{elem: elem})
+	return elem
+}
+
+// enter makes elem the container of the following paragraphs and returns
+// the previous container
+func (t *pdfTagger) enter(elem *pdfStructElem) *pdfStructElem {
+	previous := t.container
+	t.container = elem
+	t.lists = nil
+	return previous
+}
+
+// paragraph returns the element of a paragraph, creating a heading, list
+// item or P element in the container on first use
+func (t *pdfTagger) paragraph(paragraph *types.Paragraph, level int) *pdfStructElem {
+	if elem, ok := t.paragraphs[paragraph]; ok {
+		return elem
+	}
+
+	var elem *pdfStructElem
+	switch {
+	case level > 0:
+		t.lists = nil
+		elem = t.add(t.container, "H"+strconv.Itoa(level))
+		if t.title == "" {
+			t.title = strings.TrimSpace(paragraph.Text)
+		}
+	case paragraph.NumID > 0:
+		item := t.add(t.list(max(paragraph.ListLevel, 0)), "LI")
+		t.labels[paragraph] = t.add(item, "Lbl")
+		elem = t.add(item, "LBody")
+	default:
+		t.lists = nil
+		elem = t.add(t.container, "P")
+	}
+	t.paragraphs[paragraph] = elem
+	return elem
+}
+
+// list returns the open list of a level. Deeper lists are closed and
+// missing ones opened in the body of the last item of the enclosing list.
+func (t *pdfTagger) list(level int) *pdfStructElem {
+	if len(t.lists) > level+1 {
+		t.lists = t.lists[:level+1]
+	}
+	for len(t.lists) <= level {
+		parent := t.container
+		if n := len(t.lists); n > 0 {
+			parent = t.lists[n-1]
+			if kids := parent.kids; len(kids) > 0 && kids[len(kids)-1].elem.tag == "LI" {
+				item := kids[len(kids)-1].elem
+				parent = item.kids[len(item.kids)-1].elem
+			}
+		}
+		t.lists = append(t.lists, t.add(parent, "L"))
+	}
+	return t.lists[level]
+}
+
+// mark assigns the next marked-content id of a page to elem
+func (t *pdfTagger) mark(page int, elem *pdfStructElem) int {
+	for len(t.marked) <= page {
+		t.marked = append(t.marked, nil)
+	}
+	mcid := len(t.marked[page])
+	t.marked[page] = append(t.marked[page], elem)
+	elem.kids = append(elem.kids, pdfStructKid{page: page, mcid: mcid})
+	return mcid
+}
+
+// tagTable creates the Table, TR and TH or TD elements of a laid out table
+// and the elements of the paragraphs in its cells
+func (l *pdfLayout) tagTable(t *pdfTableLayout) {
+	tags := l.tags
+	table := tags.add(tags.container, "Table")
+	for r, row := range t.rows {
+		tr := tags.add(table, "TR")
+		for c, cell := range row {
+			if cell == nil || cell.firstRow != r || (c > 0 && row[c-1] == cell) {
+				continue
+			}
+
+			tag, attributes := "TD", ""
+			if t.table.Rows[r].Header {
+				tag, attributes = "TH", " /Scope /Column"
+			}
+			if rows := cell.lastRow - cell.firstRow + 1; rows > 1 {
+				attributes += " /RowSpan " + strconv.Itoa(rows)
+			}
+			if cell.span > 1 {
+				attributes += " /ColSpan " + strconv.Itoa(cell.span)
+			}
+			elem := tags.add(tr, tag)
+			if attributes != "" {
+				elem.attributes = "<< /O /Table" + attributes + " >>"
+			}
+
+			previous := tags.enter(elem)
+			for _, placed := range cell.lines {
+				tags.paragraph(placed.line.paragraph, headingLevel(placed.line.paragraph.Style, l.styleNames))
+			}
+			tags.enter(previous)
+		}
+	}
+}
+
+// fragmentElem returns the element that a fragment of a line belongs to,
+// or nil while artifacts are drawn
+func (l *pdfLayout) fragmentElem(line *pdfLine, fragment pdfFragment, link *pdfLink) *pdfStructElem {
+	if l.tags.artifact {
+		return nil
+	}
+	if link != nil && link.elem != nil {
+		return link.elem
+	}
+	if label := l.tags.labels[line.paragraph]; fragment.label && label != nil {
+		return label
+	}
+	return l.tags.paragraph(line.paragraph, 0)
+}
+
+// markedContent starts the marked-content sequence of elem on the current
+// page, or an artifact when elem is nil
+func (l *pdfLayout) markedContent(elem *pdfStructElem) string {
+	if elem == nil {
+		return "/Artifact BMC\n"
+	}
+	mcid := l.tags.mark(len(l.pages)-1, elem)
+	return "/" + elem.tag + " <</MCID " + strconv.Itoa(mcid) + ">> BDC\n"
+}
+
+// artifact marks page content that is not part of the document content,
+// such as borders and shading, when the output is tagged
+func (l *pdfLayout) artifact(content string) string {
+	if l.tags == nil || content == "" {
+		return content
+	}
+	return "/Artifact BMC\n" + content + "EMC\n"
+}
+
+// pruneStructElem removes the elements without content below elem
+func pruneStructElem(elem *pdfStructElem) {
+	kids := elem.kids[:0]
+	for _, kid := range elem.kids {
+		if kid.elem != nil {
+			pruneStructElem(kid.elem)
+			if len(kid.elem.kids) == 0 {
+				continue
+			}
+		}
+		kids = append(kids, kid)
+	}
+	elem.kids = kids
+}
+
+// writePDFStructTree writes the structure tree and its parent tree and
+// returns the object number of the structure tree root
+func writePDFStructTree(w *pdfWriter, tags *pdfTagger, pageIDs []int) int {
+	rootID := w.alloc()
+	pruneStructElem(tags.root)
+
+	var elems []*pdfStructElem
+	var collect func(elem *pdfStructElem)
+	collect = func(elem *pdfStructElem) {
+		elem.id = w.alloc()
+		elems = append(elems, elem)
+		for _, kid := range elem.kids {
+			if kid.elem != nil {
+				collect(kid.elem)
+			}
+		}
+	}
+	collect(tags.root)
+
+	for _, elem := range elems {
+		parentID := rootID
+		if elem.parent != nil {
+			parentID = elem.parent.id
+		}
+		kids := make([]string, 0, len(elem.kids))
+		for _, kid := range elem.kids {
+			switch {
+			case kid.elem != nil:
+				kids = append(kids, fmt.Sprintf("%d 0 R", kid.elem.id))
+			case kid.annot != 0:
+				kids = append(kids, fmt.Sprintf("<< /Type /OBJR /Pg %d 0 R /Obj %d 0 R >>", pageIDs[kid.page], kid.annot))
+			default:
+				kids = append(kids, fmt.Sprintf("<< /Type /MCR /Pg %d 0 R /MCID %d >>", pageIDs[kid.page], kid.mcid))
+			}
+		}
+		body := fmt.Sprintf("<< /Type /StructElem /S /%s /P %d 0 R /K [%s]", elem.tag, parentID, strings.Join(kids, " "))
+		if elem.alt != "" {
+			body += " /Alt " + pdfTextString(elem.alt)
+		}
+		if elem.attributes != "" {
+			body += " /A " + elem.attributes
+		}
+		w.object(elem.id, body+" >>")
+	}
+
+	// 父结构树按页面的StructParents和注释的StructParent查找元素
+	var nums strings.Builder
+	for page := range pageIDs {
+		var refs []string
+		if page < len(tags.marked) {
+			for _, elem := range tags.marked[page] {
+				refs = append(refs, fmt.Sprintf("%d 0 R", elem.id))
+			}
+		}
+		fmt.Fprintf(&nums, "%d [%s] ", page, strings.Join(refs, " "))
+	}
+	for i, elem := range tags.annotations {
+		fmt.Fprintf(&nums, "%d %d 0 R ", len(pageIDs)+i, elem.id)
+	}
+	parentTreeID := w.alloc()
+	w.object(parentTreeID, "<< /Nums ["+strings.TrimSpace(nums.String())+"] >>")
+
+	w.object(rootID, fmt.Sprintf("<< /Type /StructTreeRoot /K [%d 0 R] /ParentTree %d 0 R /ParentTreeNextKey %d >>",
+		tags.root.id, parentTreeID, len(pageIDs)+len(tags.annotations)))
+	return rootID
+}
+
+// documentLanguage returns the language of the document from the core
+// properties, else from the default run properties of the styles
+func (pe *PDFExporter) documentLanguage() string {
+	if language := pe.Document.GetCoreProperties().Language; language != "" {
+		return language
+	}
+	if data := pe.Document.readPart("word/styles.xml"); data != nil {
+		if language, err := parser.ParseDefaultLanguage(data); err == nil {
+			return language
+		}
+	}
+	return ""
+}
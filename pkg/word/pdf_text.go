@@ -0,0 +1,183 @@
+package word
+
+import "github.com/tanqiangyes/go-word/pkg/text"
+
+// fragmentRunes returns the characters that a fragment stands for in the
+// logical text of its paragraph
+func fragmentRunes(fragment pdfFragment) []rune {
+	switch fragment.kind {
+	case pdfTextFragment:
+		return []rune(fragment.text)
+	case pdfSpaceFragment:
+		return []rune{' '}
+	case pdfTabFragment:
+		return []rune{'\t'}
+	case pdfImageFragment:
+		return []rune{0xFFFC}
+	default:
+		// 换行、分页和分栏符按行分隔符处理
+		return []rune{0x2028}
+	}
+}
+
+// breakOpportunities splits text fragments at the line break opportunities
+// of UAX #14 and marks the pieces that may start a line with breakBefore.
+// CJK text thus breaks between characters, except where kinsoku keeps
+// closing punctuation off the start of a line. Spaces remain break
+// opportunities of their own.
+func breakOpportunities(fragments []pdfFragment) []pdfFragment {
+	var logical []rune
+	for _, fragment := range fragments {
+		logical = append(logical, fragmentRunes(fragment)...)
+	}
+	breaks := text.LineBreaks(logical)
+
+	split := make([]pdfFragment, 0, len(fragments))
+	position := 0
+	for _, fragment := range fragments {
+		runes := fragmentRunes(fragment)
+		if fragment.kind != pdfTextFragment {
+			split = append(split, fragment)
+			position += len(runes)
+			continue
+		}
+		from := 0
+		for to := 1; to <= len(runes); to++ {
+			if to < len(runes) && breaks[position+to] == text.BreakProhibited {
+				continue
+			}
+			piece := fragment
+			if from > 0 || to < len(runes) {
+				piece = fragment.with(pdfTextFragment, string(runes[from:to]))
+			}
+			piece.breakBefore = breaks[position+from] != text.BreakProhibited
+			split = append(split, piece)
+			from = to
+		}
+		position += len(runes)
+	}
+	return split
+}
+
+// joinFragments joins adjacent text fragments of a line that only differ
+// in their text, undoing the splits of breakOpportunities that did not
+// end the line
+func joinFragments(fragments []pdfFragment) []pdfFragment {
+	joined := make([]pdfFragment, 0, len(fragments))
+	for _, fragment := range fragments {
+		if n := len(joined); n > 0 && fragment.kind == pdfTextFragment && joined[n-1].kind == pdfTextFragment {
+			last, next := joined[n-1], fragment
+			last.text, last.width, last.breakBefore = "", 0, false
+			next.text, next.width, next.breakBefore = "", 0, false
+			if last == next {
+				joined[n-1].text += fragment.text
+				joined[n-1].width += fragment.width
+				continue
+			}
+		}
+		joined = append(joined, fragment)
+	}
+	return joined
+}
+
+// reorderLines puts the fragments of the lines of a paragraph in visual
+// order with the bidirectional algorithm of UAX #9. Right-to-left text is
+// reversed with mirrored glyphs, so that lines still draw from left to
+// right. Paragraphs without right-to-left text are left alone.
+func reorderLines(lines []*pdfLine, rtl bool) {
+	var logical []rune
+	for _, line := range lines {
+		for _, fragment := range line.fragments {
+			logical = append(logical, fragmentRunes(fragment)...)
+		}
+	}
+	if !rtl && !text.HasRTL(logical) {
+		return
+	}
+	direction := text.DirectionLTR
+	if rtl {
+		direction = text.DirectionRTL
+	}
+	paragraph := text.NewBidiParagraph(logical, direction)
+
+	start := 0
+	for _, line := range lines {
+		end := start
+		for _, fragment := range line.fragments {
+			end += len(fragmentRunes(fragment))
+		}
+		levels := paragraph.LineLevels(start, end)
+		start = end
+
+		fragments := line.fragments
+		if rtl {
+			// 行尾空格不显示，重排后会出现在行首，因此直接去掉
+			for len(fragments) > 0 && fragments[len(fragments)-1].kind == pdfSpaceFragment {
+				fragments = fragments[:len(fragments)-1]
+			}
+		}
+
+		// 文字片段按级别拆开，从右到左的部分倒序并使用镜像字形
+		var pieces []pdfFragment
+		var pieceLevels []text.Level
+		offset := 0
+		for _, fragment := range fragments {
+			runes := fragmentRunes(fragment)
+			if fragment.kind != pdfTextFragment || len(runes) == 0 {
+				level := paragraph.Level()
+				if len(runes) > 0 {
+					level = levels[offset]
+				}
+				pieces = append(pieces, fragment)
+				pieceLevels = append(pieceLevels, level)
+				offset += len(runes)
+				continue
+			}
+			for from := 0; from < len(runes); {
+				level := levels[offset+from]
+				to := from + 1
+				for to < len(runes) && levels[offset+to] == level {
+					to++
+				}
+				piece := fragment
+				if from > 0 || to < len(runes) {
+					piece = fragment.with(pdfTextFragment, string(runes[from:to]))
+				}
+				if level.RTL() {
+					piece.text = mirroredReverse(runes[from:to])
+				}
+				pieces = append(pieces, piece)
+				pieceLevels = append(pieceLevels, level)
+				from = to
+			}
+			offset += len(runes)
+		}
+
+		line.fragments = make([]pdfFragment, 0, len(pieces))
+		for _, index := range text.ReorderLevels(pieceLevels) {
+			line.fragments = append(line.fragments, pieces[index])
+		}
+	}
+}
+
+// mirroredReverse returns right-to-left characters in drawing order
+func mirroredReverse(runes []rune) string {
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = text.Mirror(r)
+	}
+	return string(reversed)
+}
+
+// bidiAlignment returns the drawing alignment of a right-to-left
+// paragraph, whose left and start alignments are at the right edge
+func bidiAlignment(alignment string) string {
+	switch alignment {
+	case "", "left", "start":
+		return "right"
+	case "right", "end":
+		return "left"
+	default:
+		return alignment
+	}
+}
@@ -0,0 +1,740 @@
+package word
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// trueTypeFont is a TrueType or OpenType font that is embedded in the PDF
+// as a CID font with Identity-H encoding. Glyph IDs are used as CIDs, so
+// any character that the font maps can be drawn.
+type trueTypeFont struct {
+	// name is the PostScript name
+	name string
+	data []byte
+	// index is the face of a font collection
+	index int
+	// tables are the table records of the face by tag
+	tables map[string]sfntTable
+	// cff is set for OpenType fonts with CFF outlines
+	cff bool
+
+	unitsPerEm  float64
+	numGlyphs   int
+	longLoca    bool
+	bbox        [4]int16
+	ascent      int16
+	descent     int16
+	capHeight   int16
+	italicAngle float64
+	fixedPitch  bool
+	bold        bool
+	italic      bool
+
+	cmap     map[rune]uint16
+	advances []uint16
+	// used maps the glyphs drawn so far to their characters
+	used map[uint16]rune
+}
+
+// sfntTable is the location of a table in the font file
+type sfntTable struct {
+	offset uint32
+	length uint32
+}
+
+// loadTrueTypeFont reads the face with the given index from a TrueType,
+// OpenType or collection file
+func loadTrueTypeFont(path string, index int) (*trueTypeFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取字体文件: %w", err)
+	}
+	return parseTrueTypeFont(data, index)
+}
+
+// parseTrueTypeFont parses a face of a font file
+func parseTrueTypeFont(data []byte, index int) (*trueTypeFont, error) {
+	tables, cff, err := sfntTables(data, index)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &trueTypeFont{data: data, index: index, tables: tables, cff: cff, used: make(map[uint16]rune)}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "cmap"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("字体缺少 %s 表", tag)
+		}
+	}
+
+	head := f.table("head")
+	if len(head) < 54 {
+		return nil, fmt.Errorf("head 表不完整")
+	}
+	f.unitsPerEm = float64(binary.BigEndian.Uint16(head[18:]))
+	if f.unitsPerEm == 0 {
+		f.unitsPerEm = 1000
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int16(binary.BigEndian.Uint16(head[36+2*i:]))
+	}
+	macStyle := binary.BigEndian.Uint16(head[44:])
+	f.bold, f.italic = macStyle&1 != 0, macStyle&2 != 0
+	f.longLoca = binary.BigEndian.Uint16(head[50:]) == 1
+
+	maxp := f.table("maxp")
+	if len(maxp) < 6 {
+		return nil, fmt.Errorf("maxp 表不完整")
+	}
+	f.numGlyphs = int(binary.BigEndian.Uint16(maxp[4:]))
+
+	hhea := f.table("hhea")
+	if len(hhea) < 36 {
+		return nil, fmt.Errorf("hhea 表不完整")
+	}
+	f.ascent = int16(binary.BigEndian.Uint16(hhea[4:]))
+	f.descent = int16(binary.BigEndian.Uint16(hhea[6:]))
+	metrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := f.table("hmtx")
+	if metrics == 0 || len(hmtx) < 4*metrics {
+		return nil, fmt.Errorf("hmtx 表不完整")
+	}
+	f.advances = make([]uint16, metrics)
+	for i := range f.advances {
+		f.advances[i] = binary.BigEndian.Uint16(hmtx[4*i:])
+	}
+
+	f.capHeight = f.ascent
+	if os2 := f.table("OS/2"); len(os2) >= 72 {
+		if fsType := binary.BigEndian.Uint16(os2[8:]); fsType&0x000F == 0x0002 {
+			return nil, fmt.Errorf("字体许可不允许嵌入")
+		}
+		f.ascent = int16(binary.BigEndian.Uint16(os2[68:]))
+		f.descent = int16(binary.BigEndian.Uint16(os2[70:]))
+		if version := binary.BigEndian.Uint16(os2); version >= 2 && len(os2) >= 90 {
+			f.capHeight = int16(binary.BigEndian.Uint16(os2[88:]))
+		}
+	}
+	if post := f.table("post"); len(post) >= 16 {
+		f.italicAngle = float64(int32(binary.BigEndian.Uint32(post[4:]))) / 65536
+		f.fixedPitch = binary.BigEndian.Uint32(post[12:]) != 0
+	}
+
+	if f.cmap, err = parseCmap(f.table("cmap")); err != nil {
+		return nil, err
+	}
+
+	names := parseSfntNames(f.table("name"))
+	f.name = names[6]
+	if f.name == "" {
+		f.name = strings.ReplaceAll(names[4], " ", "")
+	}
+	if f.name == "" {
+		f.name = "Font"
+	}
+	// PDF名称中不能出现空白和分隔符
+	f.name = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || strings.ContainsRune("()<>[]{}/%#", r) {
+			return -1
+		}
+		return r
+	}, f.name)
+
+	return f, nil
+}
+
+// sfntTables returns the table records of a face. Collections are
+// resolved to the face at index.
+func sfntTables(data []byte, index int) (map[string]sfntTable, bool, error) {
+	if len(data) < 12 {
+		return nil, false, fmt.Errorf("字体文件太短")
+	}
+
+	offset := uint32(0)
+	if string(data[:4]) == "ttcf" {
+		count := int(binary.BigEndian.Uint32(data[8:]))
+		if index < 0 || index >= count || len(data) < 12+4*count {
+			return nil, false, fmt.Errorf("字体集合中没有第 %d 个字体", index)
+		}
+		offset = binary.BigEndian.Uint32(data[12+4*index:])
+	}
+	if int(offset)+12 > len(data) {
+		return nil, false, fmt.Errorf("字体目录超出文件范围")
+	}
+
+	version := string(data[offset : offset+4])
+	cff := version == "OTTO"
+	if !cff && version != "\x00\x01\x00\x00" && version != "true" {
+		return nil, false, fmt.Errorf("不支持的字体格式")
+	}
+
+	count := int(binary.BigEndian.Uint16(data[offset+4:]))
+	tables := make(map[string]sfntTable, count)
+	for i := 0; i < count; i++ {
+		record := int(offset) + 12 + 16*i
+		if record+16 > len(data) {
+			return nil, false, fmt.Errorf("字体目录超出文件范围")
+		}
+		table := sfntTable{
+			offset: binary.BigEndian.Uint32(data[record+8:]),
+			length: binary.BigEndian.Uint32(data[record+12:]),
+		}
+		if uint64(table.offset)+uint64(table.length) > uint64(len(data)) {
+			return nil, false, fmt.Errorf("字体表超出文件范围")
+		}
+		tables[string(data[record:record+4])] = table
+	}
+	return tables, cff, nil
+}
+
+// table returns the bytes of a table, or nil
+func (f *trueTypeFont) table(tag string) []byte {
+	table, ok := f.tables[tag]
+	if !ok {
+		return nil
+	}
+	return f.data[table.offset : table.offset+table.length]
+}
+
+// parseCmap reads the Unicode character map. Format 12 subtables are
+// preferred over format 4 because they cover supplementary planes.
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, fmt.Errorf("cmap 表不完整")
+	}
+
+	best, bestRank := -1, 0
+	count := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < count && 4+8*i+8 <= len(cmap); i++ {
+		record := cmap[4+8*i:]
+		platform, encoding := binary.BigEndian.Uint16(record), binary.BigEndian.Uint16(record[2:])
+		offset := int(binary.BigEndian.Uint32(record[4:]))
+		if offset+2 > len(cmap) {
+			continue
+		}
+		format := binary.BigEndian.Uint16(cmap[offset:])
+		rank := 0
+		switch {
+		case format == 12 && (platform == 3 && encoding == 10 || platform == 0):
+			rank = 3
+		case format == 4 && (platform == 3 && encoding == 1 || platform == 0):
+			rank = 2
+		case format == 4 && platform == 3 && encoding == 0:
+			// 符号字体
+			rank = 1
+		}
+		if rank > bestRank {
+			best, bestRank = offset, rank
+		}
+	}
+	if best < 0 {
+		return nil, fmt.Errorf("字体没有Unicode字符映射")
+	}
+
+	mapping := make(map[rune]uint16)
+	table := cmap[best:]
+	switch binary.BigEndian.Uint16(table) {
+	case 4:
+		if len(table) < 14 {
+			return nil, fmt.Errorf("cmap 子表不完整")
+		}
+		segments := int(binary.BigEndian.Uint16(table[6:])) / 2
+		if len(table) < 16+8*segments {
+			return nil, fmt.Errorf("cmap 子表不完整")
+		}
+		ends := table[14:]
+		starts := table[16+2*segments:]
+		deltas := table[16+4*segments:]
+		rangeOffsets := table[16+6*segments:]
+		for i := 0; i < segments; i++ {
+			end := binary.BigEndian.Uint16(ends[2*i:])
+			start := binary.BigEndian.Uint16(starts[2*i:])
+			delta := binary.BigEndian.Uint16(deltas[2*i:])
+			rangeOffset := int(binary.BigEndian.Uint16(rangeOffsets[2*i:]))
+			for c := uint32(start); c <= uint32(end) && c != 0xFFFF; c++ {
+				var glyph uint16
+				if rangeOffset == 0 {
+					glyph = uint16(c) + delta
+				} else {
+					// idRangeOffset相对于自身位置
+					position := 16 + 6*segments + 2*i + rangeOffset + 2*int(c-uint32(start))
+					if position+2 > len(table) {
+						continue
+					}
+					glyph = binary.BigEndian.Uint16(table[position:])
+					if glyph != 0 {
+						glyph += delta
+					}
+				}
+				if glyph != 0 {
+					mapping[rune(c)] = glyph
+				}
+			}
+		}
+	case 12:
+		if len(table) < 16 {
+			return nil, fmt.Errorf("cmap 子表不完整")
+		}
+		groups := int(binary.BigEndian.Uint32(table[12:]))
+		for i := 0; i < groups && 16+12*i+12 <= len(table); i++ {
+			group := table[16+12*i:]
+			start, end := binary.BigEndian.Uint32(group), binary.BigEndian.Uint32(group[4:])
+			glyph := binary.BigEndian.Uint32(group[8:])
+			if end > 0x10FFFF || end-start > 0x10000 {
+				continue
+			}
+			for c := start; c <= end; c++ {
+				mapping[rune(c)] = uint16(glyph + c - start)
+			}
+		}
+	}
+	return mapping, nil
+}
+
+// sfntName is a record of the name table
+type sfntName struct {
+	id      int
+	english bool
+	value   string
+}
+
+// sfntNameRecords decodes the Unicode and Macintosh records of a name table
+func sfntNameRecords(table []byte) []sfntName {
+	if len(table) < 6 {
+		return nil
+	}
+
+	var names []sfntName
+	count := int(binary.BigEndian.Uint16(table[2:]))
+	storage := int(binary.BigEndian.Uint16(table[4:]))
+	for i := 0; i < count && 6+12*i+12 <= len(table); i++ {
+		record := table[6+12*i:]
+		platform := binary.BigEndian.Uint16(record)
+		language := binary.BigEndian.Uint16(record[4:])
+		length := int(binary.BigEndian.Uint16(record[8:]))
+		offset := storage + int(binary.BigEndian.Uint16(record[10:]))
+		if offset+length > len(table) {
+			continue
+		}
+
+		raw := table[offset : offset+length]
+		var value string
+		switch platform {
+		case 0, 3:
+			units := make([]uint16, len(raw)/2)
+			for j := range units {
+				units[j] = binary.BigEndian.Uint16(raw[2*j:])
+			}
+			value = string(utf16.Decode(units))
+		case 1:
+			value = string(raw)
+		default:
+			continue
+		}
+		names = append(names, sfntName{
+			id:      int(binary.BigEndian.Uint16(record[6:])),
+			english: platform == 3 && language == 0x0409 || platform == 1 && language == 0,
+			value:   value,
+		})
+	}
+	return names
+}
+
+// parseSfntNames returns the English or first available name of each
+// name ID
+func parseSfntNames(table []byte) map[int]string {
+	names := make(map[int]string)
+	english := make(map[int]bool)
+	for _, name := range sfntNameRecords(table) {
+		if english[name.id] {
+			continue
+		}
+		if _, ok := names[name.id]; !ok || name.english {
+			names[name.id] = name.value
+			english[name.id] = name.english
+		}
+	}
+	return names
+}
+
+func (f *trueTypeFont) baseFont() string {
+	return f.name
+}
+
+// glyph returns the glyph of a character, or 0 for the missing glyph
+func (f *trueTypeFont) glyph(r rune) uint16 {
+	return f.cmap[r]
+}
+
+// covers reports whether the font has a glyph for r
+func (f *trueTypeFont) covers(r rune) bool {
+	_, ok := f.cmap[r]
+	return ok
+}
+
+func (f *trueTypeFont) advance(r rune) float64 {
+	return f.glyphAdvance(f.glyph(r))
+}
+
+// glyphAdvance returns the advance width of a glyph in 1/1000 em
+func (f *trueTypeFont) glyphAdvance(glyph uint16) float64 {
+	index := int(glyph)
+	if index >= len(f.advances) {
+		index = len(f.advances) - 1
+	}
+	return float64(f.advances[index]) * 1000 / f.unitsPerEm
+}
+
+// encode returns the glyph IDs of text as a hex string and records the
+// glyphs for subsetting and the ToUnicode map
+func (f *trueTypeFont) encode(text string) string {
+	var buf strings.Builder
+	buf.WriteByte('<')
+	for _, r := range text {
+		glyph := f.glyph(r)
+		if _, ok := f.used[glyph]; !ok && glyph != 0 {
+			f.used[glyph] = r
+		}
+		fmt.Fprintf(&buf, "%04X", glyph)
+	}
+	buf.WriteByte('>')
+	return buf.String()
+}
+
+// pdfVersion returns the PDF version that the embedded font requires.
+// Whole OpenType files can only be embedded since PDF 1.6.
+func (f *trueTypeFont) pdfVersion() string {
+	if f.cff {
+		return "1.6"
+	}
+	return "1.4"
+}
+
+// embed writes the Type 0 font, its CID font, descriptor, font file and
+// ToUnicode map, and returns the number of the Type 0 font object
+func (f *trueTypeFont) embed(w *pdfWriter) int {
+	fontID := w.alloc()
+	cidFontID := w.alloc()
+	descriptorID := w.alloc()
+	fileID := w.alloc()
+	toUnicodeID := w.alloc()
+
+	name := f.subsetTag() + "+" + f.name
+	scale := func(v int16) string {
+		return pdfNumber(float64(v) * 1000 / f.unitsPerEm)
+	}
+
+	w.object(fontID, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		name, cidFontID, toUnicodeID))
+
+	subtype, fileKey := "CIDFontType2", "FontFile2"
+	extra := " /CIDToGIDMap /Identity"
+	if f.cff {
+		subtype, fileKey, extra = "CIDFontType0", "FontFile3", ""
+	}
+	w.object(cidFontID, fmt.Sprintf("<< /Type /Font /Subtype /%s /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /DW %s /W %s%s >>",
+		subtype, name, descriptorID, pdfNumber(f.glyphAdvance(0)), f.widthArray(), extra))
+
+	flags := 4
+	if f.fixedPitch {
+		flags |= 1
+	}
+	if f.italic || f.italicAngle != 0 {
+		flags |= 64
+	}
+	stemV := 80
+	if f.bold {
+		stemV = 140
+	}
+	w.object(descriptorID, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags %d /FontBBox [%s %s %s %s] /ItalicAngle %s /Ascent %s /Descent %s /CapHeight %s /StemV %d /%s %d 0 R >>",
+		name, flags, scale(f.bbox[0]), scale(f.bbox[1]), scale(f.bbox[2]), scale(f.bbox[3]),
+		pdfNumber(f.italicAngle), scale(f.ascent), scale(f.descent), scale(f.capHeight), stemV, fileKey, fileID))
+
+	if f.cff {
+		w.stream(fileID, "/Subtype /OpenType", f.faceData())
+	} else {
+		subset := f.subset()
+		w.stream(fileID, fmt.Sprintf("/Length1 %d", len(subset)), subset)
+	}
+	w.stream(toUnicodeID, "", f.toUnicode())
+	return fontID
+}
+
+// sortedGlyphs returns the used glyphs in ascending order
+func (f *trueTypeFont) sortedGlyphs() []uint16 {
+	glyphs := make([]uint16, 0, len(f.used))
+	for glyph := range f.used {
+		glyphs = append(glyphs, glyph)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+	return glyphs
+}
+
+// subsetTag derives the six letter subset prefix from the used glyphs
+func (f *trueTypeFont) subsetTag() string {
+	hash := fnv.New32a()
+	hash.Write([]byte(f.name))
+	for _, glyph := range f.sortedGlyphs() {
+		hash.Write([]byte{byte(glyph >> 8), byte(glyph)})
+	}
+	sum := hash.Sum32()
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = byte('A' + sum%26)
+		sum /= 26
+	}
+	return string(tag)
+}
+
+// widthArray returns the /W array with the widths of the used glyphs
+func (f *trueTypeFont) widthArray() string {
+	var buf strings.Builder
+	buf.WriteString("[")
+	glyphs := f.sortedGlyphs()
+	for i := 0; i < len(glyphs); {
+		// 连续的字形共用一个起始CID
+		j := i + 1
+		for j < len(glyphs) && glyphs[j] == glyphs[j-1]+1 {
+			j++
+		}
+		fmt.Fprintf(&buf, "%d [", glyphs[i])
+		for k := i; k < j; k++ {
+			if k > i {
+				buf.WriteString(" ")
+			}
+			buf.WriteString(pdfNumber(math.Round(f.glyphAdvance(glyphs[k]))))
+		}
+		buf.WriteString("] ")
+		i = j
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+// toUnicode returns the ToUnicode CMap that maps the used glyphs back to
+// their characters, so text can be searched and copied
+func (f *trueTypeFont) toUnicode() []byte {
+	var buf strings.Builder
+	buf.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	buf.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	buf.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	buf.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	glyphs := f.sortedGlyphs()
+	for start := 0; start < len(glyphs); start += 100 {
+		end := start + 100
+		if end > len(glyphs) {
+			end = len(glyphs)
+		}
+		fmt.Fprintf(&buf, "%d beginbfchar\n", end-start)
+		for _, glyph := range glyphs[start:end] {
+			fmt.Fprintf(&buf, "<%04X> <", glyph)
+			for _, unit := range utf16.Encode([]rune{f.used[glyph]}) {
+				fmt.Fprintf(&buf, "%04X", unit)
+			}
+			buf.WriteString(">\n")
+		}
+		buf.WriteString("endbfchar\n")
+	}
+
+	buf.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return []byte(buf.String())
+}
+
+// faceData returns a standalone font file for the face, which extracts
+// the face from a collection
+func (f *trueTypeFont) faceData() []byte {
+	tables := make(map[string][]byte, len(f.tables))
+	for tag := range f.tables {
+		tables[tag] = f.table(tag)
+	}
+	version := "\x00\x01\x00\x00"
+	if f.cff {
+		version = "OTTO"
+	}
+	return buildSfnt(version, tables)
+}
+
+// subset returns a TrueType font that only keeps the outlines of the used
+// glyphs and the glyphs they are composed of. Glyph IDs are not changed,
+// so the CIDs in the content streams stay valid.
+func (f *trueTypeFont) subset() []byte {
+	glyf, loca := f.table("glyf"), f.table("loca")
+	if glyf == nil || loca == nil {
+		return f.faceData()
+	}
+
+	offsets := make([]uint32, f.numGlyphs+1)
+	for i := range offsets {
+		if f.longLoca {
+			if 4*i+4 > len(loca) {
+				return f.faceData()
+			}
+			offsets[i] = binary.BigEndian.Uint32(loca[4*i:])
+		} else {
+			if 2*i+2 > len(loca) {
+				return f.faceData()
+			}
+			offsets[i] = uint32(binary.BigEndian.Uint16(loca[2*i:])) * 2
+		}
+	}
+	outline := func(glyph int) []byte {
+		if glyph >= f.numGlyphs || offsets[glyph] >= offsets[glyph+1] || int(offsets[glyph+1]) > len(glyf) {
+			return nil
+		}
+		return glyf[offsets[glyph]:offsets[glyph+1]]
+	}
+
+	keep := map[int]bool{0: true}
+	queue := []int{0}
+	for glyph := range f.used {
+		if !keep[int(glyph)] {
+			keep[int(glyph)] = true
+			queue = append(queue, int(glyph))
+		}
+	}
+	// 复合字形引用的部件也要保留
+	for len(queue) > 0 {
+		glyph := queue[0]
+		queue = queue[1:]
+		for _, component := range compositeComponents(outline(glyph)) {
+			if !keep[component] {
+				keep[component] = true
+				queue = append(queue, component)
+			}
+		}
+	}
+
+	var newGlyf []byte
+	newLoca := make([]byte, 4*(f.numGlyphs+1))
+	for glyph := 0; glyph < f.numGlyphs; glyph++ {
+		binary.BigEndian.PutUint32(newLoca[4*glyph:], uint32(len(newGlyf)))
+		if keep[glyph] {
+			newGlyf = append(newGlyf, outline(glyph)...)
+			for len(newGlyf)%4 != 0 {
+				newGlyf = append(newGlyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*f.numGlyphs:], uint32(len(newGlyf)))
+
+	head := append([]byte(nil), f.table("head")...)
+	binary.BigEndian.PutUint32(head[8:], 0)
+	binary.BigEndian.PutUint16(head[50:], 1)
+
+	tables := map[string][]byte{
+		"head": head,
+		"glyf": newGlyf,
+		"loca": newLoca,
+	}
+	for _, tag := range []string{"hhea", "hmtx", "maxp", "cvt ", "fpgm", "prep", "cmap", "OS/2", "post", "name"} {
+		if data := f.table(tag); data != nil {
+			tables[tag] = data
+		}
+	}
+
+	font := buildSfnt("\x00\x01\x00\x00", tables)
+	// 整个文件的校验和调整值
+	if position := sfntTableOffset(font, "head"); position >= 0 {
+		binary.BigEndian.PutUint32(font[position+8:], 0xB1B0AFBA-sfntChecksum(font))
+	}
+	return font
+}
+
+// compositeComponents returns the glyphs that a composite glyph refers to
+func compositeComponents(outline []byte) []int {
+	if len(outline) < 10 || int16(binary.BigEndian.Uint16(outline)) >= 0 {
+		return nil
+	}
+
+	var components []int
+	for position := 10; position+4 <= len(outline); {
+		flags := binary.BigEndian.Uint16(outline[position:])
+		components = append(components, int(binary.BigEndian.Uint16(outline[position+2:])))
+		position += 4
+		if flags&0x0001 != 0 {
+			position += 4
+		} else {
+			position += 2
+		}
+		switch {
+		case flags&0x0008 != 0:
+			position += 2
+		case flags&0x0040 != 0:
+			position += 4
+		case flags&0x0080 != 0:
+			position += 8
+		}
+		if flags&0x0020 == 0 {
+			break
+		}
+	}
+	return components
+}
+
+// buildSfnt writes a font file from its tables
+func buildSfnt(version string, tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	count := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= count {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 16
+
+	header := make([]byte, 12+16*count)
+	copy(header, version)
+	binary.BigEndian.PutUint16(header[4:], uint16(count))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(count*16-searchRange))
+
+	body := make([]byte, 0)
+	for i, tag := range tags {
+		data := tables[tag]
+		record := header[12+16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], sfntChecksum(data))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(header)+len(body)))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(data)))
+		body = append(body, data...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	return append(header, body...)
+}
+
+// sfntTableOffset returns the offset of a table in a font file, or -1
+func sfntTableOffset(font []byte, tag string) int {
+	count := int(binary.BigEndian.Uint16(font[4:]))
+	for i := 0; i < count; i++ {
+		record := font[12+16*i:]
+		if string(record[:4]) == tag {
+			return int(binary.BigEndian.Uint32(record[8:]))
+		}
+	}
+	return -1
+}
+
+// sfntChecksum returns the checksum of a table
+func sfntChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
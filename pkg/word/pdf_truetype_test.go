@@ -0,0 +1,208 @@
+package word
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+	"unicode/utf16"
+)
+
+// testCJKFont builds a small TrueType font named "Test CJK" that maps the
+// given characters to glyphs 1..n with an advance of 1000 units
+func testCJKFont(chars ...rune) []byte {
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+	numGlyphs := len(chars) + 1
+	u16 := func(values ...int) []byte {
+		data := make([]byte, 2*len(values))
+		for i, value := range values {
+			binary.BigEndian.PutUint16(data[2*i:], uint16(value))
+		}
+		return data
+	}
+
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint32(head, 0x00010000)
+	binary.BigEndian.PutUint32(head[12:], 0x5F0F3CF5)
+	copy(head[18:], u16(1000))
+	copy(head[36:], u16(0, 0xFF88, 1000, 880))
+
+	hhea := make([]byte, 36)
+	binary.BigEndian.PutUint32(hhea, 0x00010000)
+	copy(hhea[4:], u16(880, 0xFF88))
+	copy(hhea[34:], u16(numGlyphs))
+
+	maxp := append([]byte{0, 0, 0x50, 0}, u16(numGlyphs)...)
+
+	var hmtx, glyf, loca []byte
+	for glyph := 0; glyph < numGlyphs; glyph++ {
+		hmtx = append(hmtx, u16(1000, 0)...)
+		loca = append(loca, u16(len(glyf)/2)...)
+		// 只有一个矩形轮廓的简单字形
+		glyf = append(glyf, u16(1, 0, 0, 1000, 880, 3, 0)...)
+		glyf = append(glyf, 1, 1, 1, 1)
+		glyf = append(glyf, u16(0, 1000, 0, -1000&0xFFFF, 0, 0, 880, 0)...)
+	}
+	loca = append(loca, u16(len(glyf)/2)...)
+
+	// 每个字符一个分段的format 4子表
+	segments := len(chars) + 1
+	var ends, starts, deltas, ranges []int
+	for i, r := range chars {
+		ends, starts = append(ends, int(r)), append(starts, int(r))
+		deltas, ranges = append(deltas, (i+1-int(r))&0xFFFF), append(ranges, 0)
+	}
+	ends, starts, deltas, ranges = append(ends, 0xFFFF), append(starts, 0xFFFF), append(deltas, 1), append(ranges, 0)
+	subtable := u16(4, 0, 0, 2*segments, 0, 0, 0)
+	subtable = append(subtable, u16(ends...)...)
+	subtable = append(subtable, 0, 0)
+	subtable = append(subtable, u16(starts...)...)
+	subtable = append(subtable, u16(deltas...)...)
+	subtable = append(subtable, u16(ranges...)...)
+	binary.BigEndian.PutUint16(subtable[2:], uint16(len(subtable)))
+	cmap := append(u16(0, 1, 3, 1, 0, 12), subtable...)
+
+	var storage []byte
+	var records []byte
+	for _, record := range []struct {
+		id    int
+		value string
+	}{{1, "Test CJK"}, {4, "Test CJK"}, {6, "TestCJK"}} {
+		value := u16(func() []int {
+			var units []int
+			for _, unit := range utf16.Encode([]rune(record.value)) {
+				units = append(units, int(unit))
+			}
+			return units
+		}()...)
+		records = append(records, u16(3, 1, 0x0409, record.id, len(value), len(storage))...)
+		storage = append(storage, value...)
+	}
+	name := append(u16(0, 3, 6+len(records)), records...)
+	name = append(name, storage...)
+
+	post := make([]byte, 32)
+	binary.BigEndian.PutUint32(post, 0x00030000)
+
+	return buildSfnt("\x00\x01\x00\x00", map[string][]byte{
+		"head": head, "hhea": hhea, "maxp": maxp, "hmtx": hmtx, "loca": loca,
+		"glyf": glyf, "cmap": cmap, "name": name, "post": post,
+	})
+}
+
+func TestPDFExporterEmbedsCJKFont(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "TestCJK.ttf"), testCJKFont('中', '文', '档', '。'), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Open(writeTestPackage(t, map[string]string{"word/document.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Hello 中文文档。</w:t></w:r></w:p>
+</w:body></w:document>`}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	defer doc.Close()
+
+	config := getDefaultPDFConfig()
+	config.Compression = false
+	config.FontDirectories = []string{dir}
+	exporter := NewPDFExporter(doc, config)
+	exporter.Languages.FontFallbacks["zh-CN"] = []string{"Test CJK"}
+
+	var buf bytes.Buffer
+	if _, err := exporter.ExportToPDFStream(context.Background(), &buf); err != nil {
+		t.Fatalf("PDF导出失败: %v", err)
+	}
+	data := buf.Bytes()
+	checkPDFStructure(t, data)
+
+	for _, expected := range []string{
+		"/Subtype /Type0",
+		"/Encoding /Identity-H",
+		"/Subtype /CIDFontType2",
+		"/CIDToGIDMap /Identity",
+		"/FontFile2",
+		"/ToUnicode",
+		"/BaseFont /Helvetica",
+		"(Hello) Tj",
+		// 字形编号按码位排序: 。=1 中=2 文=3 档=4
+		"<00020003000300040001> Tj",
+		"4 beginbfchar",
+		"<0002> <4E2D>",
+	} {
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("PDF应该包含 %q", expected)
+		}
+	}
+	if !regexp.MustCompile(`/BaseFont /[A-Z]{6}\+TestCJK`).Match(data) {
+		t.Error("嵌入的子集字体名称应该带有6个字母的前缀")
+	}
+}
+
+func TestTrueTypeFontSubset(t *testing.T) {
+	font, err := loadTrueTypeFont(filepath.Join("testdata", "fonts", "Go-Regular.ttf"), 0)
+	if err != nil {
+		t.Fatalf("加载字体失败: %v", err)
+	}
+	if font.baseFont() != "GoRegular" && font.baseFont() != "Go-Regular" {
+		t.Errorf("PostScript名称错误: %s", font.baseFont())
+	}
+	if width := font.advance('i'); width <= 0 || width >= font.advance('W') {
+		t.Errorf("字宽错误: i=%.0f W=%.0f", width, font.advance('W'))
+	}
+
+	encoded := font.encode("Aé")
+	if len(font.used) != 2 || encoded != fmt.Sprintf("<%04X%04X>", font.glyph('A'), font.glyph('é')) {
+		t.Errorf("编码错误: %s", encoded)
+	}
+
+	subset := font.subset()
+	if len(subset) >= len(font.data)/2 {
+		t.Errorf("子集没有变小: %d / %d", len(subset), len(font.data))
+	}
+	parsed, err := parseTrueTypeFont(subset, 0)
+	if err != nil {
+		t.Fatalf("子集字体无法解析: %v", err)
+	}
+	if parsed.numGlyphs != font.numGlyphs || parsed.glyph('A') != font.glyph('A') {
+		t.Error("子集应该保留原来的字形编号")
+	}
+	if sfntChecksum(subset) != 0xB1B0AFBA {
+		t.Error("子集字体的校验和调整值错误")
+	}
+}
+
+func TestPDFFontResolverFallback(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile(filepath.Join("testdata", "fonts", "Go-Regular.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "Go-Regular.ttf"), data, 0644)
+	os.WriteFile(filepath.Join(dir, "TestCJK.ttf"), testCJKFont('中'), 0644)
+
+	languages := NewLanguageSupport()
+	languages.FontFallbacks["zh-CN"] = []string{"Missing Font", "TestCJK"}
+	resolver := newPDFFontResolver([]string{dir}, false, languages)
+
+	if _, ok := resolver.fontFor("Go", false, false, 'A').(*pdfStandardFont); !ok {
+		t.Error("不嵌入字体时拉丁字符应该使用标准字体")
+	}
+	if font := resolver.fontFor("Go", false, false, '中'); font.baseFont() != "TestCJK" {
+		t.Errorf("中文应该回退到FontFallbacks中的字体，实际 %s", font.baseFont())
+	}
+	if font := resolver.fontFor("Go", false, false, 'Ω'); font.baseFont() != "GoRegular" && font.baseFont() != "Go-Regular" {
+		t.Errorf("希腊字母应该使用运行字体，实际 %s", font.baseFont())
+	}
+
+	resolver = newPDFFontResolver([]string{dir}, true, languages)
+	if _, ok := resolver.fontFor("Go", true, false, 'A').(*trueTypeFont); !ok {
+		t.Error("嵌入字体时应该使用TrueType字体")
+	}
+}
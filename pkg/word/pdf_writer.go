@@ -0,0 +1,146 @@
+package word
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// pdfWriter writes the objects of a PDF file and builds the cross
+// reference table from their real offsets
+type pdfWriter struct {
+	buf bytes.Buffer
+	// offsets are the byte offsets of the objects; index 0 is object 1
+	offsets  []int
+	compress bool
+	// id is the file identifier written to the trailer
+	id []byte
+	// encryption encrypts strings and streams; nil writes them in clear
+	encryption *pdfEncryption
+}
+
+// newPDFWriter starts a PDF file. The comment with high-bit characters
+// marks the file as binary for transfer programs.
+func newPDFWriter(version string, compress bool) *pdfWriter {
+	w := &pdfWriter{compress: compress}
+	w.buf.WriteString("%PDF-" + version + "\n%\xE2\xE3\xCF\xD3\n")
+	return w
+}
+
+// alloc reserves an object number
+func (w *pdfWriter) alloc() int {
+	w.offsets = append(w.offsets, -1)
+	return len(w.offsets)
+}
+
+// object writes an object with a reserved number
+func (w *pdfWriter) object(id int, body string) {
+	if w.encryption != nil {
+		body = w.encryption.encryptStrings(id, body)
+	}
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream writes a stream object. entries are the dictionary entries
+// besides /Length and /Filter. The data is deflated when compression is
+// enabled.
+func (w *pdfWriter) stream(id int, entries string, data []byte) {
+	if w.compress {
+		data = pdfDeflate(data)
+		entries = strings.TrimSpace(entries + " /Filter /FlateDecode")
+	}
+	w.encodedStream(id, entries, data)
+}
+
+// encodedStream writes a stream object whose data is already encoded with
+// the filter named in entries
+func (w *pdfWriter) encodedStream(id int, entries string, data []byte) {
+	if w.encryption != nil {
+		data = w.encryption.encrypt(id, data)
+	}
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", id, entries, len(data))
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+// pdfDeflate compresses data for the FlateDecode filter
+func pdfDeflate(data []byte) []byte {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(data)
+	zw.Close()
+	return compressed.Bytes()
+}
+
+// encrypt encrypts the strings and streams written from now on and
+// writes the encryption dictionary
+func (w *pdfWriter) encrypt(encryption *pdfEncryption) {
+	encryption.objectID = w.alloc()
+	w.object(encryption.objectID, encryption.dictionary)
+	w.encryption = encryption
+}
+
+// finish writes the cross reference table and the trailer. trailer holds
+// the entries besides /Size, /ID and /Encrypt.
+func (w *pdfWriter) finish(trailer string) []byte {
+	if w.id != nil {
+		trailer += " /ID [" + pdfHexString(w.id) + " " + pdfHexString(w.id) + "]"
+	}
+	if w.encryption != nil {
+		trailer += fmt.Sprintf(" /Encrypt %d 0 R", w.encryption.objectID)
+	}
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		if offset < 0 {
+			// 预留但未写入的对象标记为空闲
+			w.buf.WriteString("0000000000 65535 f \n")
+			continue
+		}
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d %s >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, trailer, xref)
+	return w.buf.Bytes()
+}
+
+// pdfNumber formats a coordinate with at most two decimals
+func pdfNumber(value float64) string {
+	s := fmt.Sprintf("%.2f", value)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// pdfTextString returns a text string for the document information or
+// outlines. Text outside ASCII is written as UTF-16BE with a byte order mark.
+func pdfTextString(text string) string {
+	ascii := true
+	for _, r := range text {
+		if r > 126 || r < 32 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		r := strings.NewReplacer("\\", "\\\\", "(", "\\(", ")", "\\)")
+		return "(" + r.Replace(text) + ")"
+	}
+
+	var buf strings.Builder
+	buf.WriteString("<FEFF")
+	for _, r := range text {
+		if r > 0xFFFF {
+			r -= 0x10000
+			fmt.Fprintf(&buf, "%04X%04X", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+			continue
+		}
+		fmt.Fprintf(&buf, "%04X", r)
+	}
+	buf.WriteString(">")
+	return buf.String()
+}
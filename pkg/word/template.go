@@ -160,16 +160,11 @@ func (t *Template) replaceTextPlaceholder(placeholder TemplatePlaceholder, value
 	// 在文档中查找并替换占位符
 	placeholderText := fmt.Sprintf("{{%s}}", placeholder.Key)
 
-	// 获取文档内容
-	content, err := t.Document.GetText()
-	if err != nil {
-		return fmt.Errorf("failed to get document content: %w", err)
+	// 占位符可能被拆分到多个运行中
+	if _, err := t.Document.ReplaceText(placeholderText, textValue, ReplaceOptions{}); err != nil {
+		return fmt.Errorf("failed to replace placeholder: %w", err)
 	}
 
-	// 替换占位符
-	newContent := strings.ReplaceAll(content, placeholderText, textValue)
-	_ = newContent // 暂时不使用，因为Document没有SetText方法
-
 	t.logger.Info("文本占位符已替换，占位符: %s, 值: %s", placeholder.Key, textValue)
 
 	return nil
@@ -197,14 +192,10 @@ func (t *Template) replaceNumberPlaceholder(placeholder TemplatePlaceholder, val
 
 	// 替换占位符
 	placeholderText := fmt.Sprintf("{{%s}}", placeholder.Key)
-	content, err := t.Document.GetText()
-	if err != nil {
-		return fmt.Errorf("failed to get document content: %w", err)
+	if _, err := t.Document.ReplaceText(placeholderText, formattedValue, ReplaceOptions{}); err != nil {
+		return fmt.Errorf("failed to replace placeholder: %w", err)
 	}
 
-	newContent := strings.ReplaceAll(content, placeholderText, formattedValue)
-	_ = newContent // 暂时不使用，因为Document没有SetText方法
-
 	t.logger.Info("数字占位符已替换，占位符: %s, 值: %s", placeholder.Key, formattedValue)
 
 	return nil
@@ -233,14 +224,10 @@ func (t *Template) replaceDatePlaceholder(placeholder TemplatePlaceholder, value
 
 	// 替换占位符
 	placeholderText := fmt.Sprintf("{{%s}}", placeholder.Key)
-	content, err := t.Document.GetText()
-	if err != nil {
-		return fmt.Errorf("failed to get document content: %w", err)
+	if _, err := t.Document.ReplaceText(placeholderText, formattedValue, ReplaceOptions{}); err != nil {
+		return fmt.Errorf("failed to replace placeholder: %w", err)
 	}
 
-	newContent := strings.ReplaceAll(content, placeholderText, formattedValue)
-	_ = newContent // 暂时不使用，因为Document没有SetText方法
-
 	t.logger.Info("日期占位符已替换，占位符: %s, 值: %s", placeholder.Key, formattedValue)
 
 	return nil
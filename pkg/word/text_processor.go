@@ -4,6 +4,7 @@ import (
     "fmt"
     "time"
 
+    "github.com/tanqiangyes/go-word/pkg/text"
     "github.com/tanqiangyes/go-word/pkg/types"
     "github.com/tanqiangyes/go-word/pkg/utils"
 )
@@ -473,9 +474,73 @@ func (ls *LanguageSupport) initializeLanguages() {
         Script:      "Latn",
     }
 
+    // 阿拉伯文
+    ls.SupportedLanguages["ar-SA"] = &LanguageInfo{
+        Code:        "ar-SA",
+        Name:        "العربية",
+        Direction:   TextProcessorTextDirectionRTL,
+        DefaultFont: "Arial",
+        Fallbacks:   []string{"Times New Roman", "Noto Naskh Arabic", "Noto Sans Arabic", "DejaVu Sans"},
+        Script:      "Arab",
+    }
+
+    // 希伯来文
+    ls.SupportedLanguages["he-IL"] = &LanguageInfo{
+        Code:        "he-IL",
+        Name:        "עברית",
+        Direction:   TextProcessorTextDirectionRTL,
+        DefaultFont: "Arial",
+        Fallbacks:   []string{"Times New Roman", "Noto Sans Hebrew", "DejaVu Sans"},
+        Script:      "Hebr",
+    }
+
     // 设置文本方向
     ls.TextDirections["zh-CN"] = TextProcessorTextDirectionLTR
     ls.TextDirections["en-US"] = TextProcessorTextDirectionLTR
+    ls.TextDirections["ar-SA"] = TextProcessorTextDirectionRTL
+    ls.TextDirections["he-IL"] = TextProcessorTextDirectionRTL
+}
+
+// DetectDirection 按第一个强方向字符判断文本方向，没有强方向字符时为从左到右
+func (ls *LanguageSupport) DetectDirection(content string) TextProcessorTextDirection {
+    if text.StrongDirection(content) == text.DirectionRTL {
+        return TextProcessorTextDirectionRTL
+    }
+    return TextProcessorTextDirectionLTR
+}
+
+// VisualOrder 按Unicode双向算法把一行文本排成显示顺序，从右到左的括号等字符使用镜像字形
+func (ls *LanguageSupport) VisualOrder(content string, direction TextProcessorTextDirection) string {
+    runes := []rune(content)
+    base := text.DirectionLTR
+    if direction == TextProcessorTextDirectionRTL {
+        base = text.DirectionRTL
+    }
+    paragraph := text.NewBidiParagraph(runes, base)
+    levels := paragraph.LineLevels(0, len(runes))
+
+    visual := make([]rune, 0, len(runes))
+    for _, i := range paragraph.Reorder(0, len(runes)) {
+        if levels[i].RTL() {
+            visual = append(visual, text.Mirror(runes[i]))
+        } else {
+            visual = append(visual, runes[i])
+        }
+    }
+    return string(visual)
+}
+
+// LineBreakOpportunities 返回可以换行的字符下标（按字符计数），换行位于该字符之前。
+// 规则遵循UAX #14，并使用中日文的避头尾规则
+func (ls *LanguageSupport) LineBreakOpportunities(content string) []int {
+    var positions []int
+    breaks := text.LineBreaks([]rune(content))
+    for i := 1; i < len(breaks)-1; i++ {
+        if breaks[i] != text.BreakProhibited {
+            positions = append(positions, i)
+        }
+    }
+    return positions
 }
 
 // ProcessText 处理文本内容
@@ -515,6 +580,12 @@ func (tp *TextProcessor) processParagraph(paragraph *types.Paragraph) error {
         tp.Metrics.ProcessedCharacters += int64(len(paragraph.Runs[i].Text))
     }
 
+    // 第一个强方向字符是从右到左的段落设为从右到左段落
+    if !paragraph.Bidi && tp.LanguageSupport.DetectDirection(layoutParagraphText(paragraph)) == TextProcessorTextDirectionRTL {
+        paragraph.Bidi = true
+        tp.LanguageSupport.Metrics.DirectionChanges++
+    }
+
     return nil
 }
 
@@ -564,9 +635,13 @@ func (tp *TextProcessor) applyTextEffects(run *types.Run) error {
 
 // handleLanguageSupport 处理语言支持
 func (tp *TextProcessor) handleLanguageSupport(run *types.Run) error {
-    // 这里将实现语言支持的处理逻辑
-    // 包括字体回退、文本方向等
     tp.LanguageSupport.Metrics.LanguageDetections++
+
+    // 从右到左的文字标记为从右到左运行，保存时写出 w:rtl
+    if !run.RTL && tp.LanguageSupport.DetectDirection(run.Text) == TextProcessorTextDirectionRTL {
+        run.RTL = true
+        tp.LanguageSupport.Metrics.DirectionChanges++
+    }
     return nil
 }
 
@@ -0,0 +1,920 @@
+package word
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+// xliffNamespace is the XLIFF 2.0 core namespace
+const xliffNamespace = "urn:oasis:names:tc:xliff:document:2.0"
+
+// xliffPartPattern matches the parts that contain translatable paragraphs
+var xliffPartPattern = regexp.MustCompile(`^word/(document|header|footer|footnotes|endnotes|comments)(\d*)\.xml$`)
+
+// xliffPartOrder orders the translatable parts in the XLIFF file
+var xliffPartOrder = map[string]int{
+	"document": 0, "header": 1, "footer": 2, "footnotes": 3, "endnotes": 4, "comments": 5,
+}
+
+// XLIFFExporter extracts the translatable text of a document into an
+// XLIFF 2.0 file. Every paragraph of the body, headers, footers,
+// footnotes, endnotes, comments and text boxes becomes a unit. Run
+// formatting, hyperlinks and fields are represented as paired codes and
+// other inline content as placeholders, so translators cannot break them.
+type XLIFFExporter struct {
+	Document *Document
+	Config   *XLIFFConfig
+	Logger   *utils.Logger
+}
+
+// XLIFFImporter writes translated XLIFF targets back into the original
+// package. Only the translated paragraphs are rewritten; all other XML is
+// kept byte for byte.
+type XLIFFImporter struct {
+	Document *Document
+	Logger   *utils.Logger
+}
+
+// XLIFFConfig XLIFF导出配置
+type XLIFFConfig struct {
+	// SourceLanguage defaults to the document language or "en"
+	SourceLanguage string `json:"source_language"`
+	TargetLanguage string `json:"target_language"`
+	// CopySource pre-fills every target with the source content
+	CopySource bool `json:"copy_source"`
+}
+
+// xliffCodeKind identifies what an inline code stands for
+type xliffCodeKind int
+
+const (
+	// xliffFormatCode is a pc code for text with run properties
+	xliffFormatCode xliffCodeKind = iota
+	// xliffContainerCode is a pc code for hyperlinks, fields and similar
+	// elements that enclose runs
+	xliffContainerCode
+	// xliffPlaceholderCode is a ph code for non-text content
+	xliffPlaceholderCode
+)
+
+// xliffCode is an inline code of a unit
+type xliffCode struct {
+	kind xliffCodeKind
+	// props are the run properties of formatted text, or of the run that
+	// holds a placeholder
+	props *parser.XMLNode
+	// node is the container or placeholder element
+	node *parser.XMLNode
+	// inRun is set for placeholders that are children of a run
+	inRun    bool
+	codeType string
+	disp     string
+}
+
+// xliffInlineKind identifies an item of inline content
+type xliffInlineKind int
+
+const (
+	xliffText xliffInlineKind = iota
+	xliffPlaceholder
+	xliffOpen
+	xliffClose
+)
+
+// xliffInline is text or a code of inline content
+type xliffInline struct {
+	kind xliffInlineKind
+	text string
+	id   string
+}
+
+// xliffUnit is a translatable paragraph
+type xliffUnit struct {
+	id        string
+	paragraph *parser.XMLNode
+	// base are the run properties of text outside format codes
+	base    *parser.XMLNode
+	codes   map[string]*xliffCode
+	content []xliffInline
+}
+
+// xliffPart is a parsed part with its units
+type xliffPart struct {
+	name  string
+	tree  *parser.XMLNode
+	units []*xliffUnit
+}
+
+// xliffContainers are elements that enclose runs of a paragraph
+var xliffContainers = map[string]bool{
+	"hyperlink": true, "smartTag": true, "fldSimple": true, "ins": true, "customXml": true,
+	"moveTo": true, "dir": true, "bdo": true, "sdt": true, "sdtContent": true,
+}
+
+// NewXLIFFExporter 创建XLIFF导出器
+func NewXLIFFExporter(document *Document, config *XLIFFConfig) *XLIFFExporter {
+	if config == nil {
+		config = &XLIFFConfig{}
+	}
+
+	return &XLIFFExporter{
+		Document: document,
+		Config:   config,
+		Logger:   utils.NewLogger(utils.LogLevelInfo, os.Stdout),
+	}
+}
+
+// NewXLIFFImporter 创建XLIFF导入器
+func NewXLIFFImporter(document *Document) *XLIFFImporter {
+	return &XLIFFImporter{
+		Document: document,
+		Logger:   utils.NewLogger(utils.LogLevelInfo, os.Stdout),
+	}
+}
+
+// ExportToXLIFF writes the translatable segments to outputPath
+func (xe *XLIFFExporter) ExportToXLIFF(outputPath string) error {
+	content, err := xe.ToXLIFF()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("保存XLIFF文件失败: %w", err)
+	}
+
+	xe.Logger.Info("XLIFF导出完成: %s", outputPath)
+	return nil
+}
+
+// ToXLIFF returns the translatable segments as an XLIFF 2.0 document
+func (xe *XLIFFExporter) ToXLIFF() (string, error) {
+	parts, err := loadXLIFFParts(xe.Document)
+	if err != nil {
+		return "", err
+	}
+
+	sourceLanguage := firstNonEmpty(xe.Config.SourceLanguage, xe.Document.GetCoreProperties().Language, "en")
+
+	var buf strings.Builder
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<xliff xmlns="` + xliffNamespace + `" version="2.0" srcLang="` + html.EscapeString(sourceLanguage) + `"`)
+	if xe.Config.TargetLanguage != "" {
+		buf.WriteString(` trgLang="` + html.EscapeString(xe.Config.TargetLanguage) + `"`)
+	}
+	buf.WriteString(">\n")
+
+	for i, part := range parts {
+		if len(part.units) == 0 {
+			continue
+		}
+		buf.WriteString(fmt.Sprintf(`  <file id="f%d" original="%s" xml:space="preserve">`+"\n", i+1, html.EscapeString(part.name)))
+		for _, unit := range part.units {
+			content := unit.renderContent()
+			buf.WriteString(`    <unit id="` + unit.id + `">` + "\n      <segment>\n")
+			buf.WriteString("        <source>" + content + "</source>\n")
+			if xe.Config.CopySource {
+				buf.WriteString("        <target>" + content + "</target>\n")
+			}
+			buf.WriteString("      </segment>\n    </unit>\n")
+		}
+		buf.WriteString("  </file>\n")
+	}
+
+	buf.WriteString("</xliff>\n")
+	return buf.String(), nil
+}
+
+// ImportXLIFF applies the translations in xliffPath to the document and
+// writes the translated package to outputPath
+func (xi *XLIFFImporter) ImportXLIFF(xliffPath, outputPath string) error {
+	data, err := os.ReadFile(xliffPath)
+	if err != nil {
+		return fmt.Errorf("读取XLIFF文件失败: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := xi.Apply(data, &buf); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("保存翻译文档失败: %w", err)
+	}
+
+	xi.Logger.Info("XLIFF导入完成: %s", outputPath)
+	return nil
+}
+
+// Apply writes the package with the translated targets of an XLIFF 2.0
+// document to w. Units without a target are left unchanged.
+func (xi *XLIFFImporter) Apply(xliffData []byte, w io.Writer) error {
+	translations, err := parseXLIFFTargets(xliffData)
+	if err != nil {
+		return err
+	}
+
+	parts, err := loadXLIFFParts(xi.Document)
+	if err != nil {
+		return err
+	}
+
+	replaced := make(map[string][]byte)
+	for _, part := range parts {
+		targets := translations[part.name]
+		if len(targets) == 0 {
+			continue
+		}
+		applied := 0
+		for _, unit := range part.units {
+			if target, ok := targets[unit.id]; ok {
+				unit.apply(target)
+				applied++
+			}
+		}
+		if applied > 0 {
+			replaced[part.name] = part.tree.Bytes()
+			xi.Logger.Info("已翻译 %s 中的 %d 个段落", part.name, applied)
+		}
+	}
+
+	if err := xi.Document.container.WriteModified(w, replaced); err != nil {
+		return fmt.Errorf("写入翻译文档失败: %w", err)
+	}
+	return nil
+}
+
+// loadXLIFFParts parses the translatable parts of a document and
+// extracts their units. The order and unit IDs only depend on the package,
+// so export and import see the same units.
+func loadXLIFFParts(document *Document) ([]*xliffPart, error) {
+	if document == nil || document.container == nil || document.container.Reader == nil {
+		return nil, fmt.Errorf("document package not loaded")
+	}
+
+	names, err := document.container.ListParts()
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []string
+	for _, name := range names {
+		if xliffPartPattern.MatchString(name) {
+			selected = append(selected, name)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		a := xliffPartPattern.FindStringSubmatch(selected[i])
+		b := xliffPartPattern.FindStringSubmatch(selected[j])
+		if a[1] != b[1] {
+			return xliffPartOrder[a[1]] < xliffPartOrder[b[1]]
+		}
+		na, _ := strconv.Atoi(a[2])
+		nb, _ := strconv.Atoi(b[2])
+		return na < nb
+	})
+
+	var parts []*xliffPart
+	for _, name := range selected {
+		data := document.readPart(name)
+		if data == nil {
+			continue
+		}
+		tree, err := parser.ParseXMLTree(data)
+		if err != nil {
+			return nil, fmt.Errorf("无法解析 %s: %w", name, err)
+		}
+
+		part := &xliffPart{name: name, tree: tree}
+		count := 0
+		tree.Walk(func(node *parser.XMLNode) bool {
+			if node.Is(parser.WordNamespace, "p") {
+				count++
+				if unit := extractXLIFFUnit(node, "p"+strconv.Itoa(count)); unit != nil {
+					part.units = append(part.units, unit)
+				}
+			}
+			return true
+		})
+		parts = append(parts, part)
+	}
+
+	return parts, nil
+}
+
+// xliffExtractor builds the inline content of a unit
+type xliffExtractor struct {
+	unit    *xliffUnit
+	baseKey string
+	// spanKey and spanOpen describe the format code that is open
+	spanKey  string
+	spanID   string
+	spanOpen bool
+}
+
+// extractXLIFFUnit extracts the inline content of a paragraph. Nested
+// paragraphs, as in text boxes, stay inside their placeholder and become
+// units of their own. Paragraphs without text are not translatable.
+func extractXLIFFUnit(paragraph *parser.XMLNode, id string) *xliffUnit {
+	// 统计各种格式的文字长度，最常用的格式不需要标记
+	lengths := make(map[string]int)
+	props := make(map[string]*parser.XMLNode)
+	var text strings.Builder
+	var measure func(nodes []*parser.XMLNode)
+	measure = func(nodes []*parser.XMLNode) {
+		for _, node := range nodes {
+			switch {
+			case node.Is(parser.WordNamespace, "r"):
+				rPr := node.Child(parser.WordNamespace, "rPr")
+				key := xliffPropsKey(rPr)
+				props[key] = rPr
+				for _, child := range node.Children {
+					if child.Is(parser.WordNamespace, "t") {
+						value := child.Text()
+						lengths[key] += len([]rune(value))
+						text.WriteString(value)
+					}
+				}
+			case isXLIFFContainer(node):
+				measure(node.Children)
+			}
+		}
+	}
+	measure(paragraph.Children)
+
+	if strings.TrimSpace(text.String()) == "" {
+		return nil
+	}
+
+	baseKey := ""
+	keys := make([]string, 0, len(lengths))
+	for key := range lengths {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if lengths[key] > lengths[baseKey] {
+			baseKey = key
+		}
+	}
+
+	extractor := &xliffExtractor{
+		unit: &xliffUnit{
+			id:        id,
+			paragraph: paragraph,
+			base:      props[baseKey],
+			codes:     make(map[string]*xliffCode),
+		},
+		baseKey: baseKey,
+	}
+	extractor.extract(paragraph.Children)
+	extractor.closeSpan()
+	return extractor.unit
+}
+
+// isXLIFFContainer reports whether the element encloses runs
+func isXLIFFContainer(node *parser.XMLNode) bool {
+	return node.Kind == parser.XMLElementNode && node.URI == parser.WordNamespace && xliffContainers[node.Name.Local]
+}
+
+// xliffPropsKey identifies run properties by their serialized form
+func xliffPropsKey(props *parser.XMLNode) string {
+	if props == nil {
+		return ""
+	}
+	return string(props.Bytes())
+}
+
+// addCode registers a code and returns its ID
+func (xe *xliffExtractor) addCode(code *xliffCode) string {
+	id := strconv.Itoa(len(xe.unit.codes) + 1)
+	xe.unit.codes[id] = code
+	return id
+}
+
+// emit appends inline content
+func (xe *xliffExtractor) emit(inline xliffInline) {
+	xe.unit.content = append(xe.unit.content, inline)
+}
+
+// emitFormatted appends content of a run, opening a format code when the
+// run properties differ from the base formatting
+func (xe *xliffExtractor) emitFormatted(props *parser.XMLNode, inline xliffInline) {
+	key := xliffPropsKey(props)
+	switch {
+	case key == xe.baseKey:
+		xe.closeSpan()
+	case xe.spanOpen && xe.spanKey == key:
+	default:
+		xe.closeSpan()
+		xe.spanID = xe.addCode(&xliffCode{
+			kind:     xliffFormatCode,
+			props:    props,
+			codeType: "fmt",
+			disp:     describeRunProps(props),
+		})
+		xe.spanKey = key
+		xe.spanOpen = true
+		xe.emit(xliffInline{kind: xliffOpen, id: xe.spanID})
+	}
+	xe.emit(inline)
+}
+
+// closeSpan closes the open format code
+func (xe *xliffExtractor) closeSpan() {
+	if xe.spanOpen {
+		xe.emit(xliffInline{kind: xliffClose, id: xe.spanID})
+		xe.spanOpen = false
+	}
+}
+
+// extract converts paragraph content into inline content
+func (xe *xliffExtractor) extract(nodes []*parser.XMLNode) {
+	for _, node := range nodes {
+		if node.Kind != parser.XMLElementNode {
+			continue
+		}
+
+		switch {
+		case node.URI == parser.WordNamespace && (node.Name.Local == "pPr" || node.Name.Local == "proofErr"):
+			// 段落属性保持不变，拼写检查标记可以丢弃
+
+		case node.Is(parser.WordNamespace, "r"):
+			xe.extractRun(node)
+
+		case isXLIFFContainer(node):
+			xe.closeSpan()
+			codeType, disp := "other", node.Name.Local
+			switch node.Name.Local {
+			case "hyperlink":
+				codeType, disp = "link", "link"
+				if anchor, ok := node.GetAttr(node.Name.Space + ":anchor"); ok {
+					disp = "link #" + anchor
+				}
+			case "fldSimple":
+				if instr, ok := node.GetAttr(node.Name.Space + ":instr"); ok {
+					disp = strings.TrimSpace(instr)
+				}
+			}
+			id := xe.addCode(&xliffCode{kind: xliffContainerCode, node: node, codeType: codeType, disp: disp})
+			xe.emit(xliffInline{kind: xliffOpen, id: id})
+			xe.extract(xliffContainerContent(node))
+			xe.closeSpan()
+			xe.emit(xliffInline{kind: xliffClose, id: id})
+
+		default:
+			// 书签、批注范围、修订删除等段落级元素
+			id := xe.addCode(&xliffCode{kind: xliffPlaceholderCode, node: node, codeType: "other", disp: "[" + node.Name.Local + "]"})
+			xe.emit(xliffInline{kind: xliffPlaceholder, id: id})
+		}
+	}
+}
+
+// extractRun converts the content of a run
+func (xe *xliffExtractor) extractRun(run *parser.XMLNode) {
+	props := run.Child(parser.WordNamespace, "rPr")
+
+	for _, child := range run.Children {
+		if child.Kind != parser.XMLElementNode {
+			continue
+		}
+		if child.URI == parser.WordNamespace {
+			switch child.Name.Local {
+			case "rPr", "lastRenderedPageBreak":
+				continue
+			case "t":
+				if value := child.Text(); value != "" {
+					xe.emitFormatted(props, xliffInline{kind: xliffText, text: value})
+				}
+				continue
+			}
+		}
+
+		codeType, disp := "other", "["+child.Name.Local+"]"
+		switch child.Name.Local {
+		case "tab", "ptab":
+			codeType, disp = "fmt", "[tab]"
+		case "br", "cr":
+			codeType, disp = "fmt", "[br]"
+			if kind, _ := child.GetAttr(child.Name.Space + ":type"); kind == "page" {
+				disp = "[page break]"
+			}
+		case "drawing", "pict", "object":
+			codeType = "image"
+		}
+		id := xe.addCode(&xliffCode{
+			kind:     xliffPlaceholderCode,
+			props:    props,
+			node:     child,
+			inRun:    true,
+			codeType: codeType,
+			disp:     disp,
+		})
+		xe.emitFormatted(props, xliffInline{kind: xliffPlaceholder, id: id})
+	}
+}
+
+// xliffContainerContent returns the children of a container that hold
+// content. Property elements such as w:sdtPr are kept by the container.
+func xliffContainerContent(container *parser.XMLNode) []*parser.XMLNode {
+	var content []*parser.XMLNode
+	for _, child := range container.Children {
+		if !isXLIFFProperty(child) {
+			content = append(content, child)
+		}
+	}
+	return content
+}
+
+// isXLIFFProperty reports whether the node is a property element of a container
+func isXLIFFProperty(node *parser.XMLNode) bool {
+	return node.Kind == parser.XMLElementNode && strings.HasSuffix(node.Name.Local, "Pr")
+}
+
+// describeRunProps returns a short description of run properties for
+// translators
+func describeRunProps(props *parser.XMLNode) string {
+	if props == nil {
+		return "format"
+	}
+
+	var parts []string
+	for _, child := range props.Children {
+		if child.Kind != parser.XMLElementNode {
+			continue
+		}
+		value, _ := child.GetAttr(child.Name.Space + ":val")
+		if value == "0" || value == "false" || value == "none" {
+			continue
+		}
+		switch child.Name.Local {
+		case "b":
+			parts = append(parts, "bold")
+		case "i":
+			parts = append(parts, "italic")
+		case "u":
+			parts = append(parts, "underline")
+		case "strike", "dstrike":
+			parts = append(parts, "strikethrough")
+		case "vertAlign":
+			parts = append(parts, value)
+		case "rStyle":
+			parts = append(parts, "style "+value)
+		case "color":
+			parts = append(parts, "color #"+value)
+		case "highlight":
+			parts = append(parts, "highlight "+value)
+		}
+	}
+	if len(parts) == 0 {
+		return "format"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderContent returns the inline content as XLIFF markup
+func (unit *xliffUnit) renderContent() string {
+	var buf strings.Builder
+	for _, inline := range unit.content {
+		switch inline.kind {
+		case xliffText:
+			xml.EscapeText(&buf, []byte(inline.text))
+		case xliffPlaceholder:
+			code := unit.codes[inline.id]
+			buf.WriteString(`<ph id="` + inline.id + `" type="` + code.codeType + `" disp="` + html.EscapeString(code.disp) + `" canCopy="no" canDelete="no"/>`)
+		case xliffOpen:
+			code := unit.codes[inline.id]
+			attrs := ` type="` + code.codeType + `" dispStart="` + html.EscapeString(code.disp) + `"`
+			if code.kind == xliffContainerCode {
+				attrs += ` canCopy="no" canDelete="no"`
+			}
+			buf.WriteString(`<pc id="` + inline.id + `"` + attrs + `>`)
+		case xliffClose:
+			buf.WriteString("</pc>")
+		}
+	}
+	return buf.String()
+}
+
+// xliffFrame is an element being rebuilt from translated content
+type xliffFrame struct {
+	id       string
+	format   bool
+	props    *parser.XMLNode
+	node     *parser.XMLNode
+	children []*parser.XMLNode
+}
+
+// apply rebuilds the paragraph content from translated inline content.
+// Paragraph properties are kept; text takes the run properties of its
+// format code, and placeholders are restored from the original elements.
+// Placeholders that the translation dropped are appended at the end.
+func (unit *xliffUnit) apply(target []xliffInline) {
+	prefix := unit.paragraph.Name.Space
+	qualified := func(local string) string {
+		if prefix == "" {
+			return local
+		}
+		return prefix + ":" + local
+	}
+
+	stack := []*xliffFrame{{}}
+	used := make(map[string]bool)
+	var lastText *parser.XMLNode
+	var lastProps *parser.XMLNode
+
+	top := func() *xliffFrame { return stack[len(stack)-1] }
+	currentProps := func() *parser.XMLNode {
+		for i := len(stack) - 1; i > 0; i-- {
+			if stack[i].format {
+				return stack[i].props
+			}
+		}
+		return unit.base
+	}
+	newRun := func(props *parser.XMLNode) *parser.XMLNode {
+		run := parser.NewXMLElement(qualified("r"))
+		if props != nil {
+			run.AppendChild(props.Clone())
+		}
+		return run
+	}
+	appendNode := func(node *parser.XMLNode) {
+		frame := top()
+		// 格式帧不是元素，内容直接写入外层
+		for i := len(stack) - 1; i > 0 && frame.format; i-- {
+			frame = stack[i-1]
+		}
+		frame.children = append(frame.children, node)
+		lastText = nil
+	}
+	closeFrame := func() {
+		frame := top()
+		stack = stack[:len(stack)-1]
+		if frame.node != nil {
+			frame.node.SetChildren(frame.children)
+			appendNode(frame.node)
+		}
+	}
+	placeholder := func(code *xliffCode) {
+		if code.inRun {
+			run := newRun(code.props)
+			run.AppendChild(code.node)
+			appendNode(run)
+			return
+		}
+		appendNode(code.node)
+	}
+
+	for _, inline := range target {
+		switch inline.kind {
+		case xliffText:
+			if inline.text == "" {
+				continue
+			}
+			props := currentProps()
+			if lastText != nil && lastProps == props {
+				lastText.SetText(lastText.Text() + inline.text)
+				continue
+			}
+			run := newRun(props)
+			text := parser.NewXMLElement(qualified("t"), parser.XMLAttr("xml:space", "preserve"))
+			text.SetText(inline.text)
+			run.AppendChild(text)
+			appendNode(run)
+			lastText, lastProps = text, props
+
+		case xliffPlaceholder:
+			code, ok := unit.codes[inline.id]
+			if !ok || code.kind != xliffPlaceholderCode || used[inline.id] {
+				continue
+			}
+			used[inline.id] = true
+			placeholder(code)
+
+		case xliffOpen:
+			code, ok := unit.codes[inline.id]
+			if !ok || code.kind == xliffPlaceholderCode || used[inline.id] {
+				// 未知的代码只保留其中的文字
+				stack = append(stack, &xliffFrame{id: inline.id, format: true, props: currentProps()})
+				continue
+			}
+			used[inline.id] = true
+			if code.kind == xliffFormatCode {
+				stack = append(stack, &xliffFrame{id: inline.id, format: true, props: code.props})
+				lastText = nil
+				continue
+			}
+			container := code.node.ShallowCopy()
+			var children []*parser.XMLNode
+			for _, child := range code.node.Children {
+				if isXLIFFProperty(child) {
+					children = append(children, child)
+				}
+			}
+			stack = append(stack, &xliffFrame{id: inline.id, node: container, children: children})
+			lastText = nil
+
+		case xliffClose:
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].id == inline.id {
+					for len(stack) > i {
+						closeFrame()
+					}
+					break
+				}
+			}
+			lastText = nil
+		}
+	}
+	for len(stack) > 1 {
+		closeFrame()
+	}
+
+	ids := make([]int, 0, len(unit.codes))
+	for id, code := range unit.codes {
+		if code.kind == xliffPlaceholderCode && !used[id] {
+			n, _ := strconv.Atoi(id)
+			ids = append(ids, n)
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		placeholder(unit.codes[strconv.Itoa(id)])
+	}
+
+	var children []*parser.XMLNode
+	if pPr := unit.paragraph.Child(parser.WordNamespace, "pPr"); pPr != nil {
+		children = append(children, pPr)
+	}
+	unit.paragraph.SetChildren(append(children, stack[0].children...))
+}
+
+// parseXLIFFTargets returns the translated inline content of every unit
+// with a target, keyed by the original part name and unit ID. Segments
+// without a target keep their source.
+func parseXLIFFTargets(data []byte) (map[string]map[string][]xliffInline, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	translations := make(map[string]map[string][]xliffInline)
+
+	var file, unit string
+	var content []xliffInline
+	var hasTarget bool
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse xliff: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != xliffNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "file":
+				file = xliffAttr(t, "original")
+				if file == "" {
+					file = xliffAttr(t, "id")
+				}
+			case "unit":
+				unit = xliffAttr(t, "id")
+				content = nil
+				hasTarget = false
+			case "segment", "ignorable":
+				source, target, err := readXLIFFSegment(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				if target != nil {
+					hasTarget = true
+					content = append(content, target...)
+				} else {
+					content = append(content, source...)
+				}
+			}
+
+		case xml.EndElement:
+			if t.Name.Space == xliffNamespace && t.Name.Local == "unit" && hasTarget {
+				if translations[file] == nil {
+					translations[file] = make(map[string][]xliffInline)
+				}
+				translations[file][unit] = content
+			}
+		}
+	}
+
+	return translations, nil
+}
+
+// readXLIFFSegment reads the source and target of a segment or ignorable.
+// The target is nil when the segment has none.
+func readXLIFFSegment(decoder *xml.Decoder, start xml.StartElement) ([]xliffInline, []xliffInline, error) {
+	var source, target []xliffInline
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse xliff segment: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			content, err := readXLIFFInline(decoder)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch t.Name.Local {
+			case "source":
+				source = content
+			case "target":
+				target = append([]xliffInline{}, content...)
+			}
+		case xml.EndElement:
+			if t.Name == start.Name {
+				return source, target, nil
+			}
+		}
+	}
+}
+
+// readXLIFFInline reads inline content up to the end of the current element
+func readXLIFFInline(decoder *xml.Decoder) ([]xliffInline, error) {
+	var content []xliffInline
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse xliff content: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			content = append(content, xliffInline{kind: xliffText, text: string(t)})
+		case xml.StartElement:
+			depth++
+			switch t.Name.Local {
+			case "ph":
+				content = append(content, xliffInline{kind: xliffPlaceholder, id: xliffAttr(t, "id")})
+			case "pc", "sc":
+				content = append(content, xliffInline{kind: xliffOpen, id: xliffAttr(t, "id")})
+			case "ec":
+				id := xliffAttr(t, "startRef")
+				if id == "" {
+					id = xliffAttr(t, "id")
+				}
+				content = append(content, xliffInline{kind: xliffClose, id: id})
+			case "cp":
+				if value, err := strconv.ParseInt(xliffAttr(t, "hex"), 16, 32); err == nil {
+					content = append(content, xliffInline{kind: xliffText, text: string(rune(value))})
+				}
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				return content, nil
+			}
+			depth--
+			if t.Name.Local == "pc" {
+				// pc的结束标记对应最近打开的pc
+				for i := len(content) - 1; i >= 0; i-- {
+					if content[i].kind == xliffOpen && !xliffClosed(content, i) {
+						content = append(content, xliffInline{kind: xliffClose, id: content[i].id})
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// xliffClosed reports whether the code opened at index i is already closed
+func xliffClosed(content []xliffInline, i int) bool {
+	for _, inline := range content[i+1:] {
+		if inline.kind == xliffClose && inline.id == content[i].id {
+			return true
+		}
+	}
+	return false
+}
+
+// xliffAttr returns the value of an attribute without namespace
+func xliffAttr(start xml.StartElement, name string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
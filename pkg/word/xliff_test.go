@@ -0,0 +1,145 @@
+package word
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+const xliffTestDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:v="urn:schemas-microsoft-com:vml" xmlns:x="urn:example">
+  <w:body>
+    <w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:bookmarkStart w:id="0" w:name="intro"/><w:r><w:rPr><w:sz w:val="24"/></w:rPr><w:t xml:space="preserve">Hello </w:t></w:r><w:r><w:rPr><w:b/><w:sz w:val="24"/></w:rPr><w:t>bold</w:t></w:r><w:r><w:rPr><w:sz w:val="24"/></w:rPr><w:tab/><w:t>world</w:t></w:r><w:bookmarkEnd w:id="0"/></w:p>
+    <w:p><w:r><w:t xml:space="preserve">Visit </w:t></w:r><w:hyperlink r:id="rId5"><w:r><w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr><w:t>site</w:t></w:r></w:hyperlink><w:r><w:t>.</w:t></w:r><w:r><w:footnoteReference w:id="1"/></w:r></w:p>
+    <w:p><w:r><w:t>Box:</w:t></w:r><w:r><w:pict><v:shape><v:textbox><w:txbxContent><w:p><w:r><w:t>Inside</w:t></w:r></w:p></w:txbxContent></v:textbox></v:shape></w:pict></w:r></w:p>
+    <w:p><w:r><w:t xml:space="preserve">  </w:t></w:r></w:p>
+    <w:p><w:r><w:t>Untouched</w:t></w:r><x:custom a="1"/></w:p>
+    <w:sectPr><w:headerReference w:type="default" r:id="rId7"/></w:sectPr>
+  </w:body>
+</w:document>`
+
+const xliffTestHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>Header text</w:t></w:r></w:p></w:hdr>`
+
+func openXLIFFTestDocument(t *testing.T) *Document {
+	t.Helper()
+
+	doc, err := Open(writeTestPackage(t, map[string]string{
+		"word/document.xml":  xliffTestDocument,
+		"word/header1.xml":   xliffTestHeader,
+		"word/footnotes.xml": markdownTestFootnotes,
+		"word/media/a.png":   "\x89PNG\r\n\x1a\nfake",
+	}))
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+	return doc
+}
+
+func TestXLIFFExporterToXLIFF(t *testing.T) {
+	doc := openXLIFFTestDocument(t)
+	defer doc.Close()
+
+	content, err := NewXLIFFExporter(doc, &XLIFFConfig{TargetLanguage: "zh-CN"}).ToXLIFF()
+	if err != nil {
+		t.Fatalf("XLIFF导出失败: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="zh-CN">`,
+		`<file id="f1" original="word/document.xml" xml:space="preserve">`,
+		`<source><ph id="1" type="other" disp="[bookmarkStart]" canCopy="no" canDelete="no"/>Hello <pc id="2" type="fmt" dispStart="bold">bold</pc><ph id="3" type="fmt" disp="[tab]" canCopy="no" canDelete="no"/>world<ph id="4" type="other" disp="[bookmarkEnd]" canCopy="no" canDelete="no"/></source>`,
+		`<source>Visit <pc id="1" type="link" dispStart="link" canCopy="no" canDelete="no"><pc id="2" type="fmt" dispStart="style Hyperlink">site</pc></pc>.<ph id="3" type="other" disp="[footnoteReference]" canCopy="no" canDelete="no"/></source>`,
+		`<unit id="p4">`,
+		`<source>Inside</source>`,
+		`original="word/header1.xml"`,
+		`<source>Header text</source>`,
+		`original="word/footnotes.xml"`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("XLIFF应该包含 %q:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(content, `<unit id="p5">`) {
+		t.Error("没有文字的段落不应该导出")
+	}
+	if strings.Index(content, "word/header1.xml") > strings.Index(content, "word/footnotes.xml") {
+		t.Error("页眉应该排在脚注之前")
+	}
+}
+
+func TestXLIFFImporterApply(t *testing.T) {
+	doc := openXLIFFTestDocument(t)
+	defer doc.Close()
+
+	translated := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="de">
+  <file id="f1" original="word/document.xml">
+    <unit id="p1"><segment>
+      <source>ignored</source>
+      <target><pc id="2">fett</pc> Hallo &amp; <ph id="3"/>Welt</target>
+    </segment></unit>
+    <unit id="p2"><segment>
+      <source>ignored</source>
+      <target>Besuchen Sie <pc id="1"><pc id="2">unsere Seite</pc></pc>.<ph id="3"/></target>
+    </segment></unit>
+    <unit id="p4"><segment><source>Inside</source><target>Innen</target></segment></unit>
+    <unit id="p6"><segment><source>Untouched</source></segment></unit>
+  </file>
+  <file id="f2" original="word/header1.xml">
+    <unit id="p1"><segment><source>Header text</source><target>Kopfzeile</target></segment></unit>
+  </file>
+</xliff>`
+
+	var buf bytes.Buffer
+	if err := NewXLIFFImporter(doc).Apply([]byte(translated), &buf); err != nil {
+		t.Fatalf("XLIFF导入失败: %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("读取翻译文档失败: %v", err)
+	}
+	files := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("打开 %s 失败: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[file.Name] = string(data)
+	}
+
+	document := files["word/document.xml"]
+	for _, expected := range []string{
+		`<w:pPr><w:jc w:val="center"/></w:pPr>`,
+		`<w:r><w:rPr><w:b/><w:sz w:val="24"/></w:rPr><w:t xml:space="preserve">fett</w:t></w:r><w:r><w:rPr><w:sz w:val="24"/></w:rPr><w:t xml:space="preserve"> Hallo &amp; </w:t></w:r><w:r><w:rPr><w:sz w:val="24"/></w:rPr><w:tab/></w:r>`,
+		`<w:bookmarkStart w:id="0" w:name="intro"/>`,
+		`<w:bookmarkEnd w:id="0"/></w:p>`,
+		`<w:hyperlink r:id="rId5"><w:r><w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr><w:t xml:space="preserve">unsere Seite</w:t></w:r></w:hyperlink>`,
+		`<w:r><w:footnoteReference w:id="1"/></w:r>`,
+		`<w:txbxContent><w:p><w:r><w:t xml:space="preserve">Innen</w:t></w:r></w:p></w:txbxContent>`,
+		`<w:p><w:r><w:t>Untouched</w:t></w:r><x:custom a="1"/></w:p>`,
+		`<w:sectPr><w:headerReference w:type="default" r:id="rId7"/></w:sectPr>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("document.xml应该包含 %q:\n%s", expected, document)
+		}
+	}
+	if strings.Contains(document, "Hello") || strings.Contains(document, "Inside") {
+		t.Errorf("原文应该被替换:\n%s", document)
+	}
+
+	if !strings.Contains(files["word/header1.xml"], ">Kopfzeile</w:t>") {
+		t.Errorf("页眉应该被翻译:\n%s", files["word/header1.xml"])
+	}
+	if files["word/footnotes.xml"] != markdownTestFootnotes {
+		t.Error("没有译文的部件应该保持不变")
+	}
+	if files["word/media/a.png"] != "\x89PNG\r\n\x1a\nfake" {
+		t.Error("媒体文件应该保持不变")
+	}
+}
@@ -0,0 +1,501 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/word"
+)
+
+// paragraphPropertyOrder is the schema order of the paragraph properties
+// (CT_PPr) that new properties are inserted in
+var paragraphPropertyOrder = []string{
+	"pStyle", "keepNext", "keepLines", "pageBreakBefore", "framePr", "widowControl", "numPr",
+	"suppressLineNumbers", "pBdr", "shd", "tabs", "suppressAutoHyphens", "kinsoku", "wordWrap",
+	"overflowPunct", "topLinePunct", "autoSpaceDE", "autoSpaceDN", "bidi", "adjustRightInd",
+	"snapToGrid", "spacing", "ind", "contextualSpacing", "mirrorIndents", "suppressOverlap", "jc",
+	"textDirection", "textAlignment", "textboxTightWrap", "outlineLvl", "divId", "cnfStyle", "rPr",
+	"sectPr", "pPrChange",
+}
+
+// runPropertyOrder is the schema order of the run properties (CT_RPr)
+var runPropertyOrder = []string{
+	"rStyle", "rFonts", "b", "bCs", "i", "iCs", "caps", "smallCaps", "strike", "dstrike", "outline",
+	"shadow", "emboss", "imprint", "noProof", "snapToGrid", "vanish", "webHidden", "color", "spacing",
+	"w", "kern", "position", "sz", "szCs", "highlight", "u", "effect", "bdr", "shd", "fitText",
+	"vertAlign", "rtl", "cs", "em", "lang", "eastAsianLayout", "specVanish", "oMath",
+}
+
+// sectionPropertyOrder is the schema order of the section properties
+// (CT_SectPr)
+var sectionPropertyOrder = []string{
+	"headerReference", "footerReference", "footnotePr", "endnotePr", "type", "pgSz", "pgMar",
+	"paperSrc", "pgBorders", "lnNumType", "pgNumType", "cols", "formProt", "vAlign", "noEndnote",
+	"titlePg", "textDirection", "bidi", "rtlGutter", "docGrid", "printerSettings", "sectPrChange",
+}
+
+// runContainers are the elements of a paragraph whose runs count as runs of
+// the paragraph, as in the parser
+var runContainers = map[string]bool{
+	"hyperlink": true, "fldSimple": true, "ins": true, "smartTag": true, "sdt": true, "sdtContent": true, "customXml": true,
+}
+
+// documentPatch is the parsed XML of the main document part of a document
+// opened for patching
+type documentPatch struct {
+	tree *parser.XMLNode
+	body *parser.XMLNode
+	// prefix is the prefix that the root element declares for the
+	// WordprocessingML namespace
+	prefix string
+}
+
+// OpenForPatching opens an existing document for patch-based modification.
+// Edits change only the targeted nodes of the original document XML, and
+// Save writes all other nodes and parts back byte for byte, so markup that
+// the content model does not know about survives. Paragraphs with
+// pictures, external hyperlinks, comments or lists cannot be added in this
+// mode.
+func (w *DocumentWriter) OpenForPatching(filename string) error {
+	if err := w.OpenForModification(filename); err != nil {
+		return err
+	}
+
+	part, err := w.Container.GetPart("word/document.xml")
+	if err != nil {
+		return fmt.Errorf("failed to read main document part: %w", err)
+	}
+	tree, err := parser.ParseXMLTree(part.Content)
+	if err != nil {
+		return fmt.Errorf("failed to parse main document part: %w", err)
+	}
+	document := tree.Child(parser.WordNamespace, "document")
+	body := document.Child(parser.WordNamespace, "body")
+	if body == nil {
+		return fmt.Errorf("main document part has no body")
+	}
+
+	w.patch = &documentPatch{tree: tree, body: body, prefix: wordPrefix(document)}
+	return nil
+}
+
+// wordPrefix returns the prefix that root declares for the
+// WordprocessingML namespace. Attributes without a prefix are in no
+// namespace, so when the namespace is only the default namespace a prefix
+// is declared on root for new elements and attributes.
+func wordPrefix(root *parser.XMLNode) string {
+	declared := make(map[string]bool)
+	for _, attr := range root.Attr {
+		if attr.Name.Space != "xmlns" {
+			continue
+		}
+		if attr.Value == parser.WordNamespace {
+			return attr.Name.Local
+		}
+		declared[attr.Name.Local] = true
+	}
+
+	prefix := "w"
+	for i := 1; declared[prefix]; i++ {
+		prefix = fmt.Sprintf("w%d", i)
+	}
+	root.SetAttr("xmlns:"+prefix, parser.WordNamespace)
+	return prefix
+}
+
+// IsPatching reports whether the document was opened with OpenForPatching
+func (w *DocumentWriter) IsPatching() bool {
+	return w.patch != nil
+}
+
+// paragraphs returns the paragraphs of the body in the order of the
+// content model
+func (p *documentPatch) paragraphs() []*parser.XMLNode {
+	var paragraphs []*parser.XMLNode
+	for _, child := range p.body.Children {
+		if child.Is(parser.WordNamespace, "p") {
+			paragraphs = append(paragraphs, child)
+		}
+	}
+	return paragraphs
+}
+
+// paragraph returns the body paragraph at index
+func (p *documentPatch) paragraph(index int) (*parser.XMLNode, error) {
+	paragraphs := p.paragraphs()
+	if index < 0 || index >= len(paragraphs) {
+		return nil, fmt.Errorf("paragraph index out of range")
+	}
+	return paragraphs[index], nil
+}
+
+// insert adds a block before the body paragraph at index. Blocks added at
+// the end go before the section properties of the body.
+func (p *documentPatch) insert(index int, block *parser.XMLNode) {
+	paragraphs := p.paragraphs()
+	var before *parser.XMLNode
+	if index < len(paragraphs) {
+		before = paragraphs[index]
+	} else {
+		before = p.body.Child(parser.WordNamespace, "sectPr")
+	}
+
+	position := len(p.body.Children)
+	for i, child := range p.body.Children {
+		if child == before {
+			position = i
+			break
+		}
+	}
+	p.body.InsertChild(position, block)
+}
+
+// element creates an element of the WordprocessingML namespace
+func (p *documentPatch) element(local string) *parser.XMLNode {
+	element := parser.NewXMLElement(p.prefix + ":" + local)
+	// 默认命名空间的文档中新元素的前缀与父元素不同，直接设置命名空间
+	element.URI = parser.WordNamespace
+	return element
+}
+
+// node converts an element of the writer XML model, such as ParagraphXML,
+// to a node that can be added to the tree
+func (p *documentPatch) node(value interface{}) (*parser.XMLNode, error) {
+	data, err := xml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode XML: %w", err)
+	}
+
+	var fragment bytes.Buffer
+	fragment.WriteString(`<w:body xmlns:w="` + parser.WordNamespace + `" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	fragment.Write(data)
+	fragment.WriteString("</w:body>")
+	tree, err := parser.ParseXMLTree(fragment.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := tree.Children[0]
+	node := wrapper.Children[0]
+	wrapper.RemoveChild(node)
+	if p.prefix != "w" {
+		node.RenamePrefix("w", p.prefix)
+	}
+	return node, nil
+}
+
+// runs returns the runs of a paragraph, including the runs in hyperlinks,
+// simple fields, insertions and content controls
+func (p *documentPatch) runs(paragraph *parser.XMLNode) []*parser.XMLNode {
+	var runs []*parser.XMLNode
+	var collect func(node *parser.XMLNode)
+	collect = func(node *parser.XMLNode) {
+		for _, child := range node.Children {
+			switch {
+			case child.Is(parser.WordNamespace, "r"):
+				runs = append(runs, child)
+			case child.Kind == parser.XMLElementNode && child.URI == parser.WordNamespace && runContainers[child.Name.Local]:
+				collect(child)
+			}
+		}
+	}
+	collect(paragraph)
+	return runs
+}
+
+// properties returns the properties element of a paragraph or run, such
+// as w:pPr, which is created as the first child when it is missing
+func (p *documentPatch) properties(node *parser.XMLNode, local string) *parser.XMLNode {
+	if props := node.Child(parser.WordNamespace, local); props != nil {
+		return props
+	}
+	props := p.element(local)
+	node.InsertChild(0, props)
+	return props
+}
+
+// property returns the property element of props with the local name,
+// creating it at its place in the schema order when it is missing
+func (p *documentPatch) property(props *parser.XMLNode, local string, order []string) *parser.XMLNode {
+	if property := props.Child(parser.WordNamespace, local); property != nil {
+		return property
+	}
+
+	rank := propertyRank(local, order)
+	position := len(props.Children)
+	for i, child := range props.Children {
+		if child.Kind == parser.XMLElementNode && child.URI == parser.WordNamespace && propertyRank(child.Name.Local, order) > rank {
+			position = i
+			break
+		}
+	}
+	property := p.element(local)
+	props.InsertChild(position, property)
+	return property
+}
+
+// propertyRank returns the position of a property in the schema order;
+// unknown properties sort last
+func propertyRank(local string, order []string) int {
+	for i, name := range order {
+		if name == local {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// setValue sets the w:val of a property, removing the property when value
+// is empty
+func (p *documentPatch) setValue(props *parser.XMLNode, local, value string, order []string) {
+	if value == "" {
+		if property := props.Child(parser.WordNamespace, local); property != nil {
+			props.RemoveChild(property)
+		}
+		return
+	}
+	p.property(props, local, order).SetAttr(p.prefix+":val", value)
+}
+
+// setToggle turns a toggle property such as w:b on or off. A property
+// that is already on is left as it is.
+func (p *documentPatch) setToggle(props *parser.XMLNode, local string, on bool, order []string) {
+	property := props.Child(parser.WordNamespace, local)
+	switch {
+	case !on && property != nil:
+		props.RemoveChild(property)
+	case on && property == nil:
+		p.property(props, local, order)
+	case on:
+		if value, ok := property.GetAttr(p.prefix + ":val"); ok && (value == "false" || value == "0" || value == "off") {
+			property.SetAttr(p.prefix+":val", "true")
+		}
+	}
+}
+
+// setRunFormatting applies the formatting the content model knows about
+// to a run. Other run properties, such as East Asian fonts, are kept.
+func (p *documentPatch) setRunFormatting(run *parser.XMLNode, formatting types.Run) {
+	props := p.properties(run, "rPr")
+	p.setToggle(props, "b", formatting.Bold, runPropertyOrder)
+	p.setToggle(props, "i", formatting.Italic, runPropertyOrder)
+	p.setToggle(props, "strike", formatting.Strike, runPropertyOrder)
+	p.setToggle(props, "rtl", formatting.RTL, runPropertyOrder)
+	p.setValue(props, "color", strings.TrimPrefix(formatting.Color, "#"), runPropertyOrder)
+	size := ""
+	if formatting.FontSize > 0 {
+		size = fmt.Sprintf("%d", formatting.FontSize)
+	}
+	p.setValue(props, "sz", size, runPropertyOrder)
+	if underline := props.Child(parser.WordNamespace, "u"); !formatting.Underline && underline != nil {
+		props.RemoveChild(underline)
+	} else if formatting.Underline && underline == nil {
+		p.setValue(props, "u", "single", runPropertyOrder)
+	}
+
+	// 只修改西文字体，保留东亚字体等其他属性
+	fonts := props.Child(parser.WordNamespace, "rFonts")
+	if formatting.FontName != "" {
+		fonts = p.property(props, "rFonts", runPropertyOrder)
+		fonts.SetAttr(p.prefix+":ascii", formatting.FontName)
+		fonts.SetAttr(p.prefix+":hAnsi", formatting.FontName)
+	} else if fonts != nil {
+		var kept []xml.Attr
+		for _, attr := range fonts.Attr {
+			if attr.Name.Local != "ascii" && attr.Name.Local != "hAnsi" {
+				kept = append(kept, attr)
+			}
+		}
+		if len(kept) == 0 {
+			props.RemoveChild(fonts)
+		} else if len(kept) != len(fonts.Attr) {
+			fonts.Attr = nil
+			for _, attr := range kept {
+				fonts.SetAttr(qualifiedName(attr.Name), attr.Value)
+			}
+		}
+	}
+	if len(props.Children) == 0 {
+		run.RemoveChild(props)
+	}
+
+	var texts []*parser.XMLNode
+	for _, child := range run.Children {
+		if child.Is(parser.WordNamespace, "t") {
+			texts = append(texts, child)
+		}
+	}
+	current := ""
+	for _, text := range texts {
+		current += text.Text()
+	}
+	if current == formatting.Text {
+		return
+	}
+	if len(texts) == 0 {
+		texts = append(texts, p.element("t"))
+		run.AppendChild(texts[0])
+	}
+	p.setText(texts[0], formatting.Text)
+	for _, text := range texts[1:] {
+		run.RemoveChild(text)
+	}
+}
+
+// setText sets the content of a w:t element, preserving spaces at its ends
+func (p *documentPatch) setText(text *parser.XMLNode, value string) {
+	text.SetText(value)
+	if _, ok := text.GetAttr("xml:space"); !ok && strings.TrimSpace(value) != value {
+		text.SetAttr("xml:space", "preserve")
+	}
+}
+
+// replaceText replaces the matches of replacer in every paragraph of the
+// body, including the paragraphs of tables and text boxes, and returns the
+// number of replacements. Text is matched across the runs of a paragraph.
+func (p *documentPatch) replaceText(replacer *word.TextReplacer) int {
+	count := 0
+	p.body.Walk(func(node *parser.XMLNode) bool {
+		if node.Is(parser.WordNamespace, "p") {
+			count += p.replaceParagraphText(node, replacer)
+		}
+		return true
+	})
+	return count
+}
+
+// replaceParagraphText replaces the matches of replacer in the text of a
+// paragraph. Tabs and breaks are fixed segments, and ruby guides and
+// nested paragraphs are left out.
+func (p *documentPatch) replaceParagraphText(paragraph *parser.XMLNode, replacer *word.TextReplacer) int {
+	var nodes []*parser.XMLNode
+	var segments []word.TextSegment
+	paragraph.Walk(func(node *parser.XMLNode) bool {
+		switch {
+		case node == paragraph:
+			return true
+		case node.Kind != parser.XMLElementNode || node.URI != parser.WordNamespace:
+			return true
+		}
+		switch node.Name.Local {
+		case "p", "rt":
+			return false
+		case "t":
+			nodes = append(nodes, node)
+			segments = append(segments, word.TextSegment{Text: node.Text()})
+			return false
+		case "tab", "ptab":
+			nodes = append(nodes, nil)
+			segments = append(segments, word.TextSegment{Text: "\t", Fixed: true})
+		case "br", "cr":
+			nodes = append(nodes, nil)
+			segments = append(segments, word.TextSegment{Text: "\n", Fixed: true})
+		}
+		return true
+	})
+
+	count := replacer.Replace(segments)
+	if count > 0 {
+		for i, node := range nodes {
+			if node != nil && node.Text() != segments[i].Text {
+				p.setText(node, segments[i].Text)
+			}
+		}
+	}
+	return count
+}
+
+// checkPatchable returns an error for content that needs new package
+// relationships, which patching does not add
+func checkPatchable(paragraph types.Paragraph) error {
+	if paragraph.HasComment {
+		return fmt.Errorf("comments cannot be added when patching")
+	}
+	for _, run := range paragraph.Runs {
+		if run.Image != nil {
+			return fmt.Errorf("pictures cannot be added when patching")
+		}
+		if run.Hyperlink != "" && !strings.HasPrefix(run.Hyperlink, "#") {
+			return fmt.Errorf("external hyperlinks cannot be added when patching")
+		}
+	}
+	return nil
+}
+
+// qualifiedName returns a name with its prefix
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// reloadPatch parses the patched XML into the content model, so that the
+// model and the indexes of later edits follow the patches
+func (w *DocumentWriter) reloadPatch() error {
+	return w.Document.ReloadContent(w.patch.tree.Bytes())
+}
+
+// insertPatchedParagraph adds a paragraph before the body paragraph at index
+func (w *DocumentWriter) insertPatchedParagraph(index int, paragraph types.Paragraph) error {
+	if err := checkPatchable(paragraph); err != nil {
+		return err
+	}
+	xmlParagraph, err := w.buildParagraphXML(paragraph)
+	if err != nil {
+		return err
+	}
+	node, err := w.patch.node(xmlParagraph)
+	if err != nil {
+		return err
+	}
+	w.patch.insert(index, node)
+	return w.reloadPatch()
+}
+
+// insertPatchedTable adds a table before the body paragraph at index
+func (w *DocumentWriter) insertPatchedTable(index int, table types.Table) error {
+	for _, row := range table.Rows {
+		for _, cell := range row.Cells {
+			for _, paragraph := range cell.Paragraphs {
+				if err := checkPatchable(paragraph); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	xmlTable, err := w.buildTableXML(table)
+	if err != nil {
+		return err
+	}
+	node, err := w.patch.node(xmlTable)
+	if err != nil {
+		return err
+	}
+	w.patch.insert(index, node)
+	return w.reloadPatch()
+}
+
+// savePatched writes the package with the patched main document part;
+// all other parts are copied unchanged
+func (w *DocumentWriter) savePatched(filename string) error {
+	if len(w.CommentManager.Comments) > 0 {
+		return fmt.Errorf("comments cannot be added when patching")
+	}
+
+	var buf bytes.Buffer
+	replaced := map[string][]byte{"word/document.xml": w.patch.tree.Bytes()}
+	if err := w.Container.WriteModified(&buf, replaced); err != nil {
+		return fmt.Errorf("failed to write patched document: %w", err)
+	}
+	// 先写入内存，保存到原文件时不会覆盖仍在读取的内容
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to save patched document: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package writer
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/word"
+)
+
+// patchTestDocument uses markup that the content model does not know
+// about: a custom namespace, a bookmark, run properties of East Asian text
+// and a section with a page border
+const patchTestDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:x="urn:example" x:template="invoice">
+  <w:body>
+    <w:p x:id="1"><w:pPr><w:pStyle w:val="Title"/><w:jc w:val="center"/></w:pPr><w:bookmarkStart w:id="0" w:name="top"/><w:r><w:t>Invoice {number}</w:t></w:r><w:bookmarkEnd w:id="0"/></w:p>
+    <w:p><w:r><w:rPr><w:rFonts w:ascii="Arial" w:eastAsia="SimSun"/><w:b/></w:rPr><w:t>Dear </w:t><w:t>customer</w:t></w:r><w:r><w:t xml:space="preserve">, thanks.</w:t></w:r></w:p>
+    <w:p><w:r><w:t>Obsolete</w:t></w:r></w:p>
+    <w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgBorders><w:top w:val="single"/></w:pgBorders><w:docGrid w:linePitch="312"/></w:sectPr>
+  </w:body>
+</w:document>`
+
+// writePatchTestPackage writes a docx package with the main document part
+func writePatchTestPackage(t *testing.T, document string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "template.docx")
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Failed to create package: %v", err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	for name, content := range map[string]string{
+		"word/document.xml":   document,
+		"word/settings.xml":   `<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><x:unknown xmlns:x="urn:example"/></w:settings>`,
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/></Types>`,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create part %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write part %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close package: %v", err)
+	}
+	return filename
+}
+
+func TestDocumentWriterPatchPreservesUnknownMarkup(t *testing.T) {
+	filename := writePatchTestPackage(t, patchTestDocument)
+
+	writer := NewDocumentWriter()
+	if err := writer.OpenForPatching(filename); err != nil {
+		t.Fatalf("Failed to open document for patching: %v", err)
+	}
+	if err := writer.ReplaceText("{number}", "42"); err != nil {
+		t.Fatalf("Failed to replace text: %v", err)
+	}
+	if err := writer.DeleteParagraph(2); err != nil {
+		t.Fatalf("Failed to delete paragraph: %v", err)
+	}
+	if err := writer.InsertParagraph(1, types.Paragraph{Text: "Inserted", Runs: []types.Run{{Text: "Inserted", Italic: true}}}); err != nil {
+		t.Fatalf("Failed to insert paragraph: %v", err)
+	}
+	if err := writer.AddParagraph("Regards", "Closing"); err != nil {
+		t.Fatalf("Failed to add paragraph: %v", err)
+	}
+	if err := writer.SetParagraphAlignment(3, "right"); err != nil {
+		t.Fatalf("Failed to set alignment: %v", err)
+	}
+	if err := writer.SetRunFormatting(2, 0, types.Run{Text: "Dear valued customer", Bold: true, FontName: "Arial", Color: "FF0000"}); err != nil {
+		t.Fatalf("Failed to set run formatting: %v", err)
+	}
+	if err := writer.SetRunFormatting(2, 5, types.Run{}); err == nil {
+		t.Error("Expected an error for a run index out of range")
+	}
+	if err := writer.AddComment("a", "b", "Dear"); err == nil {
+		t.Error("Expected an error for a comment in patch mode")
+	}
+	if _, err := writer.NewList(DecimalList, 1); err == nil {
+		t.Error("Expected an error for a list in patch mode")
+	}
+	if err := writer.AddListItem(1, 0, []types.Run{{Text: "Item"}}); err == nil {
+		t.Error("Expected an error for a list item in patch mode")
+	}
+	if _, err := writer.NewMultilevelList([]ListLevel{{Kind: DecimalList}}); err == nil {
+		t.Error("Expected an error for a list in patch mode")
+	}
+
+	// 模型随补丁同步，索引与文档一致
+	paragraphs := writer.Document.GetMainPart().Content.Paragraphs
+	if len(paragraphs) != 4 || paragraphs[0].Text != "Invoice 42" || paragraphs[1].Text != "Inserted" || paragraphs[3].Text != "Regards" {
+		t.Fatalf("Unexpected paragraphs after patching: %+v", paragraphs)
+	}
+
+	// 保存到原文件
+	if err := writer.Save(filename); err != nil {
+		t.Fatalf("Failed to save patched document: %v", err)
+	}
+
+	documentXML := readZipPart(t, filename, "word/document.xml")
+	for _, expected := range []string{
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:x="urn:example" x:template="invoice">`,
+		`<w:p x:id="1"><w:pPr><w:pStyle w:val="Title"/><w:jc w:val="center"/></w:pPr><w:bookmarkStart w:id="0" w:name="top"/><w:r><w:t>Invoice 42</w:t></w:r><w:bookmarkEnd w:id="0"/></w:p>`,
+		`<w:rFonts w:ascii="Arial" w:eastAsia="SimSun" w:hAnsi="Arial"/><w:b/><w:color w:val="FF0000"/></w:rPr><w:t>Dear valued customer</w:t></w:r><w:r><w:t xml:space="preserve">, thanks.</w:t></w:r></w:p>`,
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgBorders><w:top w:val="single"/></w:pgBorders><w:docGrid w:linePitch="312"/></w:sectPr>`,
+		`<w:pStyle w:val="Closing">`,
+		`<w:jc w:val="right"/>`,
+	} {
+		if !strings.Contains(documentXML, expected) {
+			t.Errorf("Expected %s in the patched document XML", expected)
+		}
+	}
+	if strings.Contains(documentXML, "Obsolete") {
+		t.Error("Expected the deleted paragraph to be gone")
+	}
+	if strings.Index(documentXML, "Inserted") > strings.Index(documentXML, "Dear") ||
+		strings.Index(documentXML, "Regards") > strings.Index(documentXML, "<w:sectPr>") {
+		t.Error("Expected the new paragraphs at their positions before the section properties")
+	}
+	if settings := readZipPart(t, filename, "word/settings.xml"); !strings.Contains(settings, `<x:unknown xmlns:x="urn:example"/>`) {
+		t.Error("Expected other parts to be copied unchanged")
+	}
+}
+
+func TestDocumentWriterPatchOtherPrefix(t *testing.T) {
+	document := strings.NewReplacer("w:", "wp:", "xmlns:w=", "xmlns:wp=").Replace(patchTestDocument)
+	filename := writePatchTestPackage(t, document)
+
+	writer := NewDocumentWriter()
+	if err := writer.OpenForPatching(filename); err != nil {
+		t.Fatalf("Failed to open document for patching: %v", err)
+	}
+	if err := writer.SetParagraphStyle(2, "Heading1"); err != nil {
+		t.Fatalf("Failed to set paragraph style: %v", err)
+	}
+	if err := writer.AddParagraph("Added", ""); err != nil {
+		t.Fatalf("Failed to add paragraph: %v", err)
+	}
+	if err := writer.AppendParagraph(types.Paragraph{Runs: []types.Run{{Image: &types.Image{}}}}); err == nil {
+		t.Error("Expected an error for a picture in patch mode")
+	}
+
+	output := filepath.Join(t.TempDir(), "patched.docx")
+	if err := writer.Save(output); err != nil {
+		t.Fatalf("Failed to save patched document: %v", err)
+	}
+	documentXML := readZipPart(t, output, "word/document.xml")
+	for _, expected := range []string{
+		`<wp:p><wp:pPr><wp:pStyle wp:val="Heading1"/></wp:pPr><wp:r><wp:t>Obsolete</wp:t></wp:r></wp:p>`,
+		`<wp:t>Added</wp:t>`,
+	} {
+		if !strings.Contains(documentXML, expected) {
+			t.Errorf("Expected %s in the patched document XML", expected)
+		}
+	}
+	if strings.Contains(documentXML, "<w:") {
+		t.Error("Expected new elements to use the prefix of the document")
+	}
+}
+
+func TestDocumentWriterPatchDefaultNamespace(t *testing.T) {
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<document xmlns="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w="urn:example">
+  <body><p><r><t>Existing</t></r></p></body>
+</document>`
+	filename := writePatchTestPackage(t, document)
+
+	writer := NewDocumentWriter()
+	if err := writer.OpenForPatching(filename); err != nil {
+		t.Fatalf("Failed to open document for patching: %v", err)
+	}
+	if err := writer.SetParagraphStyle(0, "Heading1"); err != nil {
+		t.Fatalf("Failed to set paragraph style: %v", err)
+	}
+	if err := writer.AddParagraph("Added", ""); err != nil {
+		t.Fatalf("Failed to add paragraph: %v", err)
+	}
+	// 新段落按命名空间识别，可以继续修改
+	if err := writer.SetParagraphStyle(1, "Heading2"); err != nil {
+		t.Fatalf("Failed to set style of the added paragraph: %v", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "patched.docx")
+	if err := writer.Save(output); err != nil {
+		t.Fatalf("Failed to save patched document: %v", err)
+	}
+	documentXML := readZipPart(t, output, "word/document.xml")
+	// w已用于其他命名空间，属性使用新声明的前缀
+	for _, expected := range []string{
+		`xmlns:w1="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`,
+		`<p><w1:pPr><w1:pStyle w1:val="Heading1"/></w1:pPr><r><t>Existing</t></r></p>`,
+		`<w1:pStyle w1:val="Heading2"/>`,
+		`<w1:t>Added</w1:t>`,
+	} {
+		if !strings.Contains(documentXML, expected) {
+			t.Errorf("Expected %s in the patched document XML", expected)
+		}
+	}
+	if strings.Contains(documentXML, ` val=`) {
+		t.Error("Expected attributes of the WordprocessingML namespace to have a prefix")
+	}
+}
+
+func TestDocumentWriterPatchReplaceAcrossRuns(t *testing.T) {
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <w:body>
+    <w:p><w:r w:rsidR="00A1"><w:t>Dear {{custo</w:t></w:r><w:proofErr w:type="spellStart"/><w:r w:rsidR="00B2"><w:rPr><w:b/></w:rPr><w:t>mer_name}}</w:t></w:r><w:proofErr w:type="spellEnd"/><w:r><w:t>,</w:t></w:r></w:p>
+    <w:p><w:hyperlink w:anchor="top"><w:r><w:t>See {{page</w:t></w:r></w:hyperlink><w:fldSimple w:instr="PAGE"><w:r><w:t>}} now</w:t></w:r></w:fldSimple></w:p>
+    <w:p><w:r><w:t>ABC</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>DEF</w:t></w:r><w:r><w:t>a</w:t><w:tab/><w:t>b</w:t></w:r></w:p>
+    <w:tbl><w:tr><w:tc><w:p><w:r><w:t>Total: 1</w:t></w:r><w:r><w:t>0 EUR</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+  </w:body>
+</w:document>`
+	filename := writePatchTestPackage(t, document)
+
+	writer := NewDocumentWriter()
+	if err := writer.OpenForPatching(filename); err != nil {
+		t.Fatalf("Failed to open document for patching: %v", err)
+	}
+	if err := writer.ReplaceText("{{customer_name}}", "Alice"); err != nil {
+		t.Fatalf("Failed to replace text: %v", err)
+	}
+	replacements := []struct {
+		search, replacement string
+		options             word.ReplaceOptions
+		count               int
+	}{
+		{"{{page}}", "7", word.ReplaceOptions{}, 1},
+		{"cdEf", "wxyz", word.ReplaceOptions{IgnoreCase: true}, 1},
+		{`a\tb`, "x", word.ReplaceOptions{Regexp: true}, 0},
+		{`(\d+) (EUR)`, "$2 $1", word.ReplaceOptions{Regexp: true}, 1},
+	}
+	for _, r := range replacements {
+		count, err := writer.FindReplace(r.search, r.replacement, r.options)
+		if err != nil {
+			t.Fatalf("Failed to replace %s: %v", r.search, err)
+		}
+		if count != r.count {
+			t.Errorf("Expected %d replacements of %s, got %d", r.count, r.search, count)
+		}
+	}
+	if _, err := writer.FindReplace("(", "", word.ReplaceOptions{Regexp: true}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+
+	output := filepath.Join(t.TempDir(), "replaced.docx")
+	if err := writer.Save(output); err != nil {
+		t.Fatalf("Failed to save patched document: %v", err)
+	}
+	documentXML := readZipPart(t, output, "word/document.xml")
+	for _, expected := range []string{
+		`<w:r w:rsidR="00A1"><w:t>Dear Alice</w:t></w:r><w:proofErr w:type="spellStart"/><w:r w:rsidR="00B2"><w:rPr><w:b/></w:rPr><w:t></w:t></w:r>`,
+		`<w:hyperlink w:anchor="top"><w:r><w:t>See 7</w:t></w:r></w:hyperlink><w:fldSimple w:instr="PAGE"><w:r><w:t xml:space="preserve"> now</w:t></w:r></w:fldSimple>`,
+		`<w:t>ABw</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>xyz</w:t></w:r><w:r><w:t>a</w:t><w:tab/><w:t>b</w:t>`,
+		`<w:t>Total: E</w:t></w:r><w:r><w:t>UR 10</w:t>`,
+	} {
+		if !strings.Contains(documentXML, expected) {
+			t.Errorf("Expected %s in the patched document XML", expected)
+		}
+	}
+}
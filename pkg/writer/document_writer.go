@@ -5,9 +5,13 @@ import (
 	"bytes"
 	"encoding/xml"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/tanqiangyes/go-word/pkg/opc"
+	"github.com/tanqiangyes/go-word/pkg/parser"
 	"github.com/tanqiangyes/go-word/pkg/types"
 	"github.com/tanqiangyes/go-word/pkg/word"
 )
@@ -17,6 +21,23 @@ type DocumentWriter struct {
 	Container      *opc.Container
 	Document       *word.Document
 	CommentManager *word.CommentManager // 使用新的批注管理器
+
+	// 列表编号定义及引用定义的列表
+	listDefinitions []listDefinition
+	lists           []listInstance
+
+	// 保存时生成的关系和媒体部件
+	relationships []packageRelationship
+	media         []*mediaPart
+	mediaByPath   map[string]*mediaPart
+	drawingID     int
+
+	// 各节的页眉页脚及保存时生成的部件
+	headerFooters     []headerFooter
+	headerFooterParts []*headerFooterPart
+
+	// 补丁模式下的原始文档XML
+	patch *documentPatch
 }
 
 // NewDocumentWriter creates a new document writer
@@ -75,6 +96,10 @@ func (w *DocumentWriter) AddParagraph(text string, style string) error {
 		},
 	}
 
+	if w.patch != nil {
+		return w.insertPatchedParagraph(len(w.patch.paragraphs()), paragraph)
+	}
+
 	mainPart := w.Document.GetMainPart()
 	mainPart.Content.Paragraphs = append(
 		mainPart.Content.Paragraphs, paragraph)
@@ -97,6 +122,10 @@ func (w *DocumentWriter) AddFormattedParagraph(text string, style string, runs [
 		Runs:  runs,
 	}
 
+	if w.patch != nil {
+		return w.insertPatchedParagraph(len(w.patch.paragraphs()), paragraph)
+	}
+
 	mainPart := w.Document.GetMainPart()
 	mainPart.Content.Paragraphs = append(
 		mainPart.Content.Paragraphs, paragraph)
@@ -135,50 +164,78 @@ func (w *DocumentWriter) AddTable(rows [][]string) error {
 		table.Columns = len(rows[0])
 	}
 
+	return w.AppendTable(table)
+}
+
+// AppendParagraph appends a fully specified paragraph to the document
+func (w *DocumentWriter) AppendParagraph(paragraph types.Paragraph) error {
+	if w.Document == nil || w.Document.GetMainPart() == nil {
+		return fmt.Errorf("document not initialized")
+	}
+
+	if w.patch != nil {
+		return w.insertPatchedParagraph(len(w.patch.paragraphs()), paragraph)
+	}
+
 	mainPart := w.Document.GetMainPart()
-	mainPart.Content.Tables = append(
-		mainPart.Content.Tables, table)
+	mainPart.Content.Paragraphs = append(
+		mainPart.Content.Paragraphs, paragraph)
+
+	// Update document text
+	mainPart.Content.Text += paragraph.Text + "\n"
 
 	return nil
 }
 
-// ReplaceText replaces all occurrences of old text with new text
-func (w *DocumentWriter) ReplaceText(oldText, newText string) error {
+// AppendTable appends a table after the paragraphs added so far
+func (w *DocumentWriter) AppendTable(table types.Table) error {
 	if w.Document == nil || w.Document.GetMainPart() == nil {
 		return fmt.Errorf("document not initialized")
 	}
 
+	if w.patch != nil {
+		return w.insertPatchedTable(len(w.patch.paragraphs()), table)
+	}
+
 	mainPart := w.Document.GetMainPart()
+	table.Position = len(mainPart.Content.Paragraphs)
+	mainPart.Content.Tables = append(
+		mainPart.Content.Tables, table)
 
-	// Replace in document text
-	mainPart.Content.Text = strings.ReplaceAll(
-		mainPart.Content.Text, oldText, newText)
+	return nil
+}
 
-	// Replace in paragraphs
-	for i := range mainPart.Content.Paragraphs {
-		paragraph := &mainPart.Content.Paragraphs[i]
-		paragraph.Text = strings.ReplaceAll(paragraph.Text, oldText, newText)
+// ReplaceText replaces all occurrences of old text with new text, also
+// where the text is split across several runs
+func (w *DocumentWriter) ReplaceText(oldText, newText string) error {
+	_, err := w.FindReplace(oldText, newText, word.ReplaceOptions{})
+	return err
+}
 
-		// Replace in runs
-		for j := range paragraph.Runs {
-			run := &paragraph.Runs[j]
-			run.Text = strings.ReplaceAll(run.Text, oldText, newText)
-		}
+// FindReplace replaces the matches of search with replacement and returns
+// the number of replacements. Matches may span the runs, hyperlinks and
+// simple fields of a paragraph. A replacement of the same length as the
+// match keeps the formatting of every character; other replacements take
+// the formatting of the first matched character. With options.Regexp the
+// replacement may refer to capture groups as $1 or ${name}.
+func (w *DocumentWriter) FindReplace(search, replacement string, options word.ReplaceOptions) (int, error) {
+	if w.Document == nil || w.Document.GetMainPart() == nil {
+		return 0, fmt.Errorf("document not initialized")
 	}
 
-	// Replace in table cells
-	for i := range mainPart.Content.Tables {
-		table := &mainPart.Content.Tables[i]
-		for j := range table.Rows {
-			row := &table.Rows[j]
-			for k := range row.Cells {
-				cell := &row.Cells[k]
-				cell.Text = strings.ReplaceAll(cell.Text, oldText, newText)
-			}
+	if w.patch != nil {
+		replacer, err := word.NewTextReplacer(search, replacement, options)
+		if err != nil {
+			return 0, err
+		}
+		count := w.patch.replaceText(replacer)
+		if count == 0 {
+			return 0, nil
 		}
+		return count, w.reloadPatch()
 	}
 
-	return nil
+	return w.Document.ReplaceText(search, replacement, options)
 }
 
 // SetParagraphStyle sets the style of a specific paragraph
@@ -187,6 +244,16 @@ func (w *DocumentWriter) SetParagraphStyle(index int, style string) error {
 		return fmt.Errorf("document not initialized")
 	}
 
+	if w.patch != nil {
+		paragraph, err := w.patch.paragraph(index)
+		if err != nil {
+			return err
+		}
+		props := w.patch.properties(paragraph, "pPr")
+		w.patch.setValue(props, "pStyle", style, paragraphPropertyOrder)
+		return w.reloadPatch()
+	}
+
 	mainPart := w.Document.GetMainPart()
 	if index < 0 || index >= len(mainPart.Content.Paragraphs) {
 		return fmt.Errorf("paragraph index out of range")
@@ -196,12 +263,146 @@ func (w *DocumentWriter) SetParagraphStyle(index int, style string) error {
 	return nil
 }
 
+// SetParagraphAlignment sets the justification of a specific paragraph:
+// left, center, right or both. An empty alignment removes it.
+func (w *DocumentWriter) SetParagraphAlignment(index int, alignment string) error {
+	if w.Document == nil || w.Document.GetMainPart() == nil {
+		return fmt.Errorf("document not initialized")
+	}
+	switch alignment {
+	case "", "left", "center", "right", "both", "start", "end", "distribute":
+	default:
+		return fmt.Errorf("invalid paragraph alignment: %s", alignment)
+	}
+
+	if w.patch != nil {
+		paragraph, err := w.patch.paragraph(index)
+		if err != nil {
+			return err
+		}
+		props := w.patch.properties(paragraph, "pPr")
+		w.patch.setValue(props, "jc", alignment, paragraphPropertyOrder)
+		return w.reloadPatch()
+	}
+
+	mainPart := w.Document.GetMainPart()
+	if index < 0 || index >= len(mainPart.Content.Paragraphs) {
+		return fmt.Errorf("paragraph index out of range")
+	}
+
+	mainPart.Content.Paragraphs[index].Alignment = alignment
+	return nil
+}
+
+// InsertParagraph inserts a paragraph before the paragraph at index. An
+// index equal to the number of paragraphs appends the paragraph.
+func (w *DocumentWriter) InsertParagraph(index int, paragraph types.Paragraph) error {
+	if w.Document == nil || w.Document.GetMainPart() == nil {
+		return fmt.Errorf("document not initialized")
+	}
+
+	if w.patch != nil {
+		if index < 0 || index > len(w.patch.paragraphs()) {
+			return fmt.Errorf("paragraph index out of range")
+		}
+		return w.insertPatchedParagraph(index, paragraph)
+	}
+
+	content := w.Document.GetMainPart().Content
+	if index < 0 || index > len(content.Paragraphs) {
+		return fmt.Errorf("paragraph index out of range")
+	}
+
+	content.Paragraphs = append(content.Paragraphs[:index],
+		append([]types.Paragraph{paragraph}, content.Paragraphs[index:]...)...)
+	// 插入点之后的表格和分节随之后移
+	for i := range content.Tables {
+		if content.Tables[i].Position > index {
+			content.Tables[i].Position++
+		}
+	}
+	for i := range content.Sections {
+		if content.Sections[i].End >= index {
+			content.Sections[i].End++
+		}
+	}
+	content.Text = paragraphsText(content.Paragraphs)
+	return nil
+}
+
+// DeleteParagraph removes the paragraph at index. A paragraph that ends a
+// section other than the last cannot be removed.
+func (w *DocumentWriter) DeleteParagraph(index int) error {
+	if w.Document == nil || w.Document.GetMainPart() == nil {
+		return fmt.Errorf("document not initialized")
+	}
+
+	if w.patch != nil {
+		paragraph, err := w.patch.paragraph(index)
+		if err != nil {
+			return err
+		}
+		if props := paragraph.Child(parser.WordNamespace, "pPr"); props.Child(parser.WordNamespace, "sectPr") != nil {
+			return fmt.Errorf("paragraph %d ends a section", index)
+		}
+		w.patch.body.RemoveChild(paragraph)
+		return w.reloadPatch()
+	}
+
+	content := w.Document.GetMainPart().Content
+	if index < 0 || index >= len(content.Paragraphs) {
+		return fmt.Errorf("paragraph index out of range")
+	}
+	for i, section := range content.Sections {
+		if section.End == index && i < len(content.Sections)-1 {
+			return fmt.Errorf("paragraph %d ends a section", index)
+		}
+	}
+
+	content.Paragraphs = append(content.Paragraphs[:index], content.Paragraphs[index+1:]...)
+	for i := range content.Tables {
+		if content.Tables[i].Position > index {
+			content.Tables[i].Position--
+		}
+	}
+	for i := range content.Sections {
+		if content.Sections[i].End >= index && content.Sections[i].End > 0 {
+			content.Sections[i].End--
+		}
+	}
+	content.Text = paragraphsText(content.Paragraphs)
+	return nil
+}
+
+// paragraphsText joins the text of paragraphs as the content model does
+func paragraphsText(paragraphs []types.Paragraph) string {
+	var text strings.Builder
+	for _, paragraph := range paragraphs {
+		text.WriteString(paragraph.Text)
+		text.WriteString("\n")
+	}
+	return text.String()
+}
+
 // SetRunFormatting sets formatting for a specific run in a paragraph
 func (w *DocumentWriter) SetRunFormatting(paragraphIndex, runIndex int, formatting types.Run) error {
 	if w.Document == nil || w.Document.GetMainPart() == nil {
 		return fmt.Errorf("document not initialized")
 	}
 
+	if w.patch != nil {
+		paragraph, err := w.patch.paragraph(paragraphIndex)
+		if err != nil {
+			return err
+		}
+		runs := w.patch.runs(paragraph)
+		if runIndex < 0 || runIndex >= len(runs) {
+			return fmt.Errorf("run index out of range")
+		}
+		w.patch.setRunFormatting(runs[runIndex], formatting)
+		return w.reloadPatch()
+	}
+
 	mainPart := w.Document.GetMainPart()
 	if paragraphIndex < 0 || paragraphIndex >= len(mainPart.Content.Paragraphs) {
 		return fmt.Errorf("paragraph index out of range")
@@ -216,12 +417,44 @@ func (w *DocumentWriter) SetRunFormatting(paragraphIndex, runIndex int, formatti
 	return nil
 }
 
+// SetTextDirection sets the text direction of the last section, such as
+// "tbRl" for vertical text with lines from right to left
+func (w *DocumentWriter) SetTextDirection(direction string) error {
+	if w.Document == nil || w.Document.GetMainPart() == nil {
+		return fmt.Errorf("document not initialized")
+	}
+	switch direction {
+	case "", "lrTb", "tbRl", "btLr", "lrTbV", "tbRlV", "tbLrV":
+	default:
+		return fmt.Errorf("invalid text direction: %s", direction)
+	}
+	if w.patch != nil {
+		section := w.patch.body.Child(parser.WordNamespace, "sectPr")
+		if section == nil {
+			section = w.patch.element("sectPr")
+			w.patch.body.AppendChild(section)
+		}
+		w.patch.setValue(section, "textDirection", direction, sectionPropertyOrder)
+		return w.reloadPatch()
+	}
+
+	content := w.Document.GetMainPart().Content
+	if len(content.Sections) == 0 {
+		content.Sections = []types.Section{{End: len(content.Paragraphs) - 1}}
+	}
+	content.Sections[len(content.Sections)-1].TextDirection = direction
+	return nil
+}
+
 // AddComment adds a comment to the document
 // Based on Open-XML-SDK AddComment method
 func (w *DocumentWriter) AddComment(author, text, paragraphText string) error {
 	if w.Document == nil {
 		return fmt.Errorf("document not initialized")
 	}
+	if w.patch != nil {
+		return fmt.Errorf("comments cannot be added when patching")
+	}
 
 	// 使用新的批注管理器添加批注
 	comment, err := w.CommentManager.AddComment(author, text, "para_1", "run_1", 0, len(paragraphText))
@@ -259,6 +492,9 @@ func (w *DocumentWriter) Save(filename string) error {
 	if w.Document == nil {
 		return fmt.Errorf("document not initialized")
 	}
+	if w.patch != nil {
+		return w.savePatched(filename)
+	}
 
 	// Generate XML content for the main document part
 	xmlContent, err := w.generateDocumentXML()
@@ -286,6 +522,37 @@ func (w *DocumentWriter) Save(filename string) error {
 		)
 	}
 
+	// Add numbering part if lists were created
+	if len(w.lists) > 0 {
+		container.AddPart(
+			"word/numbering.xml",
+			w.generateNumberingXML(),
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml",
+		)
+		w.addRelationship(numberingRelationshipType, "numbering.xml", false)
+	}
+
+	// Add header and footer parts with their relationships
+	for _, part := range w.headerFooterParts {
+		contentType := headerContentType
+		if part.footer {
+			contentType = footerContentType
+		}
+		container.AddPart(part.Name, part.Data, contentType)
+		if len(part.Relationships) > 0 {
+			container.AddPart(
+				"word/_rels/"+strings.TrimPrefix(part.Name, "word/")+".rels",
+				generatePartRelationshipsXML(part.Relationships),
+				"application/vnd.openxmlformats-package.relationships+xml",
+			)
+		}
+	}
+
+	// Add media parts referenced by the document
+	for _, part := range w.media {
+		container.AddPart("word/"+part.Name, part.Data, part.ContentType)
+	}
+
 	// Add [Content_Types].xml
 	contentTypesXML := w.generateContentTypesXML()
 	container.AddPart(
@@ -407,6 +674,12 @@ func (w *DocumentWriter) generateDocumentXML() ([]byte, error) {
 
 	mainPart := w.Document.GetMainPart()
 
+	// Relationships and media are rebuilt on every save
+	w.resetPackageState()
+	if err := w.buildHeaderFooterParts(); err != nil {
+		return nil, err
+	}
+
 	// Create the XML structure
 	doc := &DocumentXML{
 		XMLName: xml.Name{Local: "w:document"},
@@ -414,239 +687,576 @@ func (w *DocumentWriter) generateDocumentXML() ([]byte, error) {
 		XMLNSMC: "http://schemas.openxmlformats.org/markup-compatibility/2006",
 		XMLNSR: "http://schemas.openxmlformats.org/officeDocument/2006/relationships",
 		XMLNSW14: "http://schemas.microsoft.com/office/word/2010/wordml",
+		XMLNSWP: "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing",
 		MCIgnorable: "w14",
 		Body: DocumentBody{
 			XMLName: xml.Name{Local: "w:body"},
 		},
 	}
 
-	// Add paragraphs
-	for _, paragraph := range mainPart.Content.Paragraphs {
-		xmlParagraph := ParagraphXML{
-			XMLName: xml.Name{Local: "w:p"},
+	// Sections other than the last end with properties in their last paragraph
+	paragraphs := mainPart.Content.Paragraphs
+	sections := mainPart.Content.Sections
+	if len(sections) == 0 {
+		sections = []types.Section{{End: len(paragraphs) - 1}}
+	}
+	sectionEnds := make(map[int]int)
+	for i, section := range sections[:len(sections)-1] {
+		if section.End >= 0 && section.End < len(paragraphs) {
+			sectionEnds[section.End] = i
 		}
+	}
 
-		// Add paragraph properties if style is set
-		if paragraph.Style != "" {
-			xmlParagraph.Properties = &ParagraphPropertiesXML{
-				XMLName: xml.Name{Local: "w:pPr"},
-				Style: &StyleXML{
-					XMLName: xml.Name{Local: "w:pStyle"},
-					Val:     paragraph.Style,
-				},
-			}
-		} else {
-			// Always add paragraph properties for compatibility
-			xmlParagraph.Properties = &ParagraphPropertiesXML{
-				XMLName: xml.Name{Local: "w:pPr"},
-				Style: &StyleXML{
-					XMLName: xml.Name{Local: "w:pStyle"},
-					Val:     "Normal",
-				},
-			}
+	// Tables are anchored before the paragraph at their position
+	tablesAt := make(map[int][]types.Table)
+	for _, table := range mainPart.Content.Tables {
+		position := table.Position
+		if position < 0 || position > len(paragraphs) {
+			position = len(paragraphs)
 		}
+		tablesAt[position] = append(tablesAt[position], table)
+	}
 
-		// Add comment range start if paragraph has comment
-		if paragraph.HasComment {
-			xmlParagraph.CommentRangeStart = &CommentRangeStartXML{
-				ID: paragraph.CommentID,
+	for i := 0; i <= len(paragraphs); i++ {
+		for _, table := range tablesAt[i] {
+			xmlTable, err := w.buildTableXML(table)
+			if err != nil {
+				return nil, err
 			}
+			doc.Body.Content = append(doc.Body.Content, xmlTable)
 		}
 
-		// Add runs
-		for _, run := range paragraph.Runs {
-			xmlRun := RunXML{
-				XMLName: xml.Name{Local: "w:r"},
-			}
+		if i == len(paragraphs) {
+			break
+		}
 
-			// Add run properties only if there's actual formatting
-			hasFormatting := run.Bold || run.Italic || run.Underline || run.FontSize > 0 || run.FontName != ""
-			if hasFormatting {
-				xmlRun.Properties = &RunPropertiesXML{
-					XMLName: xml.Name{Local: "w:rPr"},
-				}
+		xmlParagraph, err := w.buildParagraphXML(paragraphs[i])
+		if err != nil {
+			return nil, err
+		}
+		if section, ok := sectionEnds[i]; ok {
+			xmlParagraph.Properties.SectionProperties = w.buildSectionPropertiesXML(section, sections[section])
+		}
+		doc.Body.Content = append(doc.Body.Content, xmlParagraph)
+	}
 
-				// Add specific formatting if set
-				if run.Bold {
-					xmlRun.Properties.Bold = &BoldXML{
-						XMLName: xml.Name{Local: "w:b"},
-						Val:     "true",
-					}
-				}
+	// Add section properties for page settings
+	doc.Body.SectionProperties = w.buildSectionPropertiesXML(len(sections)-1, sections[len(sections)-1])
 
-				if run.Italic {
-					xmlRun.Properties.Italic = &ItalicXML{
-						XMLName: xml.Name{Local: "w:i"},
-						Val:     "true",
-					}
-				}
+	// Marshal to XML
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	buf.WriteString("\n")
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode document XML: %w", err)
+	}
 
-				if run.Underline {
-					xmlRun.Properties.Underline = &UnderlineXML{
-						XMLName: xml.Name{Local: "w:u"},
-						Val:     "single",
-					}
-				}
+	return buf.Bytes(), nil
+}
 
-				if run.FontSize > 0 {
-					xmlRun.Properties.Size = &SizeXML{
-						XMLName: xml.Name{Local: "w:sz"},
-						Val:     fmt.Sprintf("%d", run.FontSize),
-					}
-				}
+// buildParagraphXML converts a paragraph of the document model to its XML form
+func (w *DocumentWriter) buildParagraphXML(paragraph types.Paragraph) (ParagraphXML, error) {
+	xmlParagraph := ParagraphXML{
+		XMLName: xml.Name{Local: "w:p"},
+	}
 
-				if run.FontName != "" {
-					xmlRun.Properties.Font = &FontXML{
-						XMLName: xml.Name{Local: "w:rFonts"},
-						Ascii:   run.FontName,
-						HAnsi:   run.FontName,
-					}
+	// Always add paragraph properties for compatibility
+	style := paragraph.Style
+	if style == "" {
+		style = "Normal"
+	}
+	xmlParagraph.Properties = &ParagraphPropertiesXML{
+		XMLName: xml.Name{Local: "w:pPr"},
+		Style: &StyleXML{
+			XMLName: xml.Name{Local: "w:pStyle"},
+			Val:     style,
+		},
+	}
+
+	if paragraph.PageBreakBefore {
+		xmlParagraph.Properties.PageBreakBefore = &OnOffXML{XMLName: xml.Name{Local: "w:pageBreakBefore"}}
+	}
+
+	// Add list numbering
+	if paragraph.NumID > 0 {
+		xmlParagraph.Properties.Numbering = &NumberingPropertiesXML{
+			XMLName: xml.Name{Local: "w:numPr"},
+			Level:   &IntValXML{XMLName: xml.Name{Local: "w:ilvl"}, Val: paragraph.ListLevel},
+			NumID:   &IntValXML{XMLName: xml.Name{Local: "w:numId"}, Val: paragraph.NumID},
+		}
+	}
+
+	// Right-to-left paragraphs
+	if paragraph.Bidi {
+		xmlParagraph.Properties.Bidi = &OnOffXML{XMLName: xml.Name{Local: "w:bidi"}}
+	}
+
+	// Add spacing and indentation; zero values are left to the style
+	if paragraph.SpaceBefore != 0 || paragraph.SpaceAfter != 0 || paragraph.LineSpacing > 0 {
+		spacing := &ParagraphSpacingXML{XMLName: xml.Name{Local: "w:spacing"}}
+		if paragraph.SpaceBefore != 0 {
+			spacing.Before = twips(paragraph.SpaceBefore)
+		}
+		if paragraph.SpaceAfter != 0 {
+			spacing.After = twips(paragraph.SpaceAfter)
+		}
+		if paragraph.LineSpacing > 0 {
+			// 行距以单倍行距的240分之一为单位
+			spacing.Line = strconv.Itoa(int(math.Round(paragraph.LineSpacing * 240)))
+			spacing.LineRule = "auto"
+		}
+		xmlParagraph.Properties.Spacing = spacing
+	}
+	if paragraph.LeftIndent != 0 || paragraph.RightIndent != 0 || paragraph.FirstLineIndent != 0 {
+		indentation := &IndentationXML{XMLName: xml.Name{Local: "w:ind"}}
+		if paragraph.LeftIndent != 0 {
+			indentation.Left = twips(paragraph.LeftIndent)
+		}
+		if paragraph.RightIndent != 0 {
+			indentation.Right = twips(paragraph.RightIndent)
+		}
+		if paragraph.FirstLineIndent > 0 {
+			indentation.FirstLine = twips(paragraph.FirstLineIndent)
+		} else if paragraph.FirstLineIndent < 0 {
+			indentation.Hanging = twips(-paragraph.FirstLineIndent)
+		}
+		xmlParagraph.Properties.Indentation = indentation
+	}
+
+	// Add paragraph alignment
+	if paragraph.Alignment != "" {
+		xmlParagraph.Properties.Justification = &JustificationXML{
+			XMLName: xml.Name{Local: "w:jc"},
+			Val:     paragraph.Alignment,
+		}
+	}
+
+	// Add comment range start if paragraph has comment
+	if paragraph.HasComment {
+		xmlParagraph.CommentRangeStart = &CommentRangeStartXML{
+			ID: paragraph.CommentID,
+		}
+	}
+
+	// Add runs, grouping consecutive runs of the same hyperlink
+	var hyperlink *HyperlinkXML
+	for _, run := range paragraph.Runs {
+		xmlRuns, err := w.buildRunXML(run)
+		if err != nil {
+			return xmlParagraph, err
+		}
+
+		// Fields such as PAGE keep their text as the last computed result
+		if run.Field != "" {
+			hyperlink = nil
+			xmlParagraph.Content = append(xmlParagraph.Content, FieldSimpleXML{
+				XMLName: xml.Name{Local: "w:fldSimple"},
+				Instr:   " " + run.Field + " ",
+				Runs:    xmlRuns,
+			})
+			continue
+		}
+
+		if run.Hyperlink == "" {
+			hyperlink = nil
+			for _, xmlRun := range xmlRuns {
+				xmlParagraph.Content = append(xmlParagraph.Content, xmlRun)
+			}
+			continue
+		}
+
+		if hyperlink == nil || hyperlink.target != run.Hyperlink {
+			hyperlink = w.newHyperlinkXML(run.Hyperlink)
+			xmlParagraph.Content = append(xmlParagraph.Content, hyperlink)
+		}
+		for _, xmlRun := range xmlRuns {
+			if xmlRun.Properties == nil {
+				xmlRun.Properties = &RunPropertiesXML{XMLName: xml.Name{Local: "w:rPr"}}
+			}
+			if xmlRun.Properties.Style == nil {
+				xmlRun.Properties.Style = &CharacterStyleXML{
+					XMLName: xml.Name{Local: "w:rStyle"},
+					Val:     "Hyperlink",
 				}
 			}
+			hyperlink.Runs = append(hyperlink.Runs, xmlRun)
+		}
+	}
+
+	// Add comment range end and reference if paragraph has comment
+	if paragraph.HasComment {
+		xmlParagraph.CommentRangeEnd = &CommentRangeEndXML{
+			ID: paragraph.CommentID,
+		}
+
+		// 创建带样式的批注引用
+		commentRefRun := RunXML{
+			XMLName: xml.Name{Local: "w:r"},
+			Properties: &RunPropertiesXML{
+				XMLName: xml.Name{Local: "w:rPr"},
+			},
+		}
 
-			// Add text
-			xmlRun.Text = &TextXML{
-				XMLName: xml.Name{Local: "w:t"},
-				Content: run.Text,
+		// 添加批注引用样式
+		commentRefRun.Properties.Style = &CharacterStyleXML{
+			XMLName: xml.Name{Local: "w:rStyle"},
+			Val:     "CommentReference",
+		}
+
+		// 将批注引用添加到段落的 Runs 中
+		xmlParagraph.Content = append(xmlParagraph.Content, commentRefRun)
+
+		// 创建实际的批注引用标记
+		commentRefMarkRun := RunXML{
+			XMLName: xml.Name{Local: "w:r"},
+		}
+
+		// 添加批注引用标记
+		commentRefMarkRun.CommentReference = &CommentReferenceXML{
+			ID: paragraph.CommentID,
+		}
+
+		// 将批注引用标记添加到段落的 Runs 中
+		xmlParagraph.Content = append(xmlParagraph.Content, commentRefMarkRun)
+	}
+
+	return xmlParagraph, nil
+}
+
+// buildRunXML converts a run of the document model to its XML form. A run
+// whose text has line breaks or tabs becomes several runs with the same
+// properties, each ending with at most one break or tab, as the parser
+// reads a run.
+func (w *DocumentWriter) buildRunXML(run types.Run) ([]RunXML, error) {
+	if run.Ruby != nil {
+		xmlRun, err := w.buildRubyXML(run)
+		return []RunXML{xmlRun}, err
+	}
+	xmlRun := RunXML{
+		XMLName: xml.Name{Local: "w:r"},
+	}
+
+	// Add run properties only if there's actual formatting
+	hasFormatting := run.Bold || run.Italic || run.Underline || run.Strike ||
+		run.FontSize > 0 || run.FontName != "" || run.Color != "" || run.RTL
+	if hasFormatting {
+		xmlRun.Properties = &RunPropertiesXML{
+			XMLName: xml.Name{Local: "w:rPr"},
+		}
+
+		// Add specific formatting if set
+		if run.FontName != "" {
+			xmlRun.Properties.Font = &FontXML{
+				XMLName: xml.Name{Local: "w:rFonts"},
+				Ascii:   run.FontName,
+				HAnsi:   run.FontName,
 			}
+		}
 
-			xmlParagraph.Runs = append(xmlParagraph.Runs, xmlRun)
+		if run.Bold {
+			xmlRun.Properties.Bold = &BoldXML{
+				XMLName: xml.Name{Local: "w:b"},
+				Val:     "true",
+			}
 		}
 
-		// Add comment range end and reference if paragraph has comment
-		if paragraph.HasComment {
-			xmlParagraph.CommentRangeEnd = &CommentRangeEndXML{
-				ID: paragraph.CommentID,
+		if run.Italic {
+			xmlRun.Properties.Italic = &ItalicXML{
+				XMLName: xml.Name{Local: "w:i"},
+				Val:     "true",
 			}
-			
-			// 创建带样式的批注引用
-			commentRefRun := RunXML{
-				XMLName: xml.Name{Local: "w:r"},
-				Properties: &RunPropertiesXML{
-					XMLName: xml.Name{Local: "w:rPr"},
-				},
+		}
+
+		if run.Strike {
+			xmlRun.Properties.Strike = &StrikeXML{
+				XMLName: xml.Name{Local: "w:strike"},
+				Val:     "true",
 			}
-			
-			// 添加批注引用样式
-			commentRefRun.Properties.Style = &CharacterStyleXML{
-				XMLName: xml.Name{Local: "w:rStyle"},
-				Val:     "CommentReference",
+		}
+
+		if run.Color != "" {
+			xmlRun.Properties.Color = &ColorXML{
+				XMLName: xml.Name{Local: "w:color"},
+				Val:     strings.TrimPrefix(run.Color, "#"),
 			}
-			
-			// 将批注引用添加到段落的 Runs 中
-			xmlParagraph.Runs = append(xmlParagraph.Runs, commentRefRun)
-			
-			// 创建实际的批注引用标记
-			commentRefMarkRun := RunXML{
-				XMLName: xml.Name{Local: "w:r"},
+		}
+
+		if run.FontSize > 0 {
+			xmlRun.Properties.Size = &SizeXML{
+				XMLName: xml.Name{Local: "w:sz"},
+				Val:     fmt.Sprintf("%d", run.FontSize),
 			}
-			
-			// 添加批注引用标记
-			commentRefMarkRun.CommentReference = &CommentReferenceXML{
-				ID: paragraph.CommentID,
+		}
+
+		if run.Underline {
+			xmlRun.Properties.Underline = &UnderlineXML{
+				XMLName: xml.Name{Local: "w:u"},
+				Val:     "single",
 			}
-			
-			// 将批注引用标记添加到段落的 Runs 中
-			xmlParagraph.Runs = append(xmlParagraph.Runs, commentRefMarkRun)
 		}
 
-		doc.Body.Paragraphs = append(doc.Body.Paragraphs, xmlParagraph)
+		if run.RTL {
+			xmlRun.Properties.RTL = &OnOffXML{XMLName: xml.Name{Local: "w:rtl"}}
+		}
 	}
 
-	// Add tables
-	for _, table := range mainPart.Content.Tables {
-		xmlTable := TableXML{
-			XMLName: xml.Name{Local: "w:tbl"},
-		}
-
-		// Add table properties for better compatibility
-		xmlTable.Properties = &TablePropertiesXML{
-			XMLName: xml.Name{Local: "w:tblPr"},
-			TableBorders: &TableBordersXML{
-				XMLName: xml.Name{Local: "w:tblBorders"},
-				Top:     &TopBorderXML{XMLName: xml.Name{Local: "w:top"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
-				Left:    &LeftBorderXML{XMLName: xml.Name{Local: "w:left"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
-				Bottom:  &BottomBorderXML{XMLName: xml.Name{Local: "w:bottom"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
-				Right:   &RightBorderXML{XMLName: xml.Name{Local: "w:right"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
-				InsideH: &InsideHBorderXML{XMLName: xml.Name{Local: "w:insideH"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
-				InsideV: &InsideVBorderXML{XMLName: xml.Name{Local: "w:insideV"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
+	// A tab and a break of the model follow the content of the run
+	var tab *TabXML
+	if run.Tab {
+		tab = &TabXML{XMLName: xml.Name{Local: "w:tab"}}
+	}
+	var runBreak *BreakXML
+	switch run.Break {
+	case "":
+	case "page", "column":
+		runBreak = &BreakXML{XMLName: xml.Name{Local: "w:br"}, Type: run.Break}
+	default:
+		runBreak = &BreakXML{XMLName: xml.Name{Local: "w:br"}}
+	}
+
+	// Pictures replace the text of the run
+	if run.Image != nil {
+		drawing, err := w.buildDrawing(run.Image)
+		if err != nil {
+			return nil, err
+		}
+		xmlRun.Drawing = drawing
+		xmlRun.Tab, xmlRun.Break = tab, runBreak
+		return []RunXML{xmlRun}, nil
+	}
+
+	// 换行和制表符结束一个运行，后面的文字使用新的运行
+	var xmlRuns []RunXML
+	var segment strings.Builder
+	for _, r := range run.Text {
+		if r != '\n' && r != '\t' {
+			segment.WriteRune(r)
+			continue
+		}
+		part := xmlRun
+		if segment.Len() > 0 {
+			part.Text = newTextXML(segment.String())
+			segment.Reset()
+		}
+		if r == '\n' {
+			part.Break = &BreakXML{XMLName: xml.Name{Local: "w:br"}}
+		} else {
+			part.Tab = &TabXML{XMLName: xml.Name{Local: "w:tab"}}
+		}
+		xmlRuns = append(xmlRuns, part)
+	}
+	if segment.Len() > 0 || (len(xmlRuns) == 0 && tab == nil && runBreak == nil) {
+		xmlRun.Text = newTextXML(segment.String())
+	}
+	if xmlRun.Text != nil || tab != nil || runBreak != nil {
+		xmlRun.Tab, xmlRun.Break = tab, runBreak
+		xmlRuns = append(xmlRuns, xmlRun)
+	}
+
+	return xmlRuns, nil
+}
+
+// buildRubyXML converts a run with a phonetic guide. The run text is the
+// base text; sizes that are not set follow the base text size, and the
+// language follows the scripts of the guide.
+func (w *DocumentWriter) buildRubyXML(run types.Run) (RunXML, error) {
+	ruby := *run.Ruby
+	baseSize := ruby.BaseFontSize
+	if baseSize <= 0 {
+		baseSize = run.FontSize
+	}
+	if baseSize <= 0 {
+		// Word的默认字号为10磅
+		baseSize = 20
+	}
+	if ruby.FontSize <= 0 {
+		ruby.FontSize = baseSize / 2
+	}
+	if ruby.Raise <= 0 {
+		ruby.Raise = baseSize - 2
+	}
+	if ruby.Alignment == "" {
+		ruby.Alignment = "center"
+	}
+	if ruby.Language == "" {
+		ruby.Language = rubyLanguage(run.Text + ruby.Text)
+	}
+
+	base := run
+	base.Ruby = nil
+	baseRuns, err := w.buildRunXML(base)
+	if err != nil {
+		return RunXML{}, err
+	}
+	annotationRuns, err := w.buildRunXML(types.Run{Text: ruby.Text, FontName: run.FontName, FontSize: ruby.FontSize})
+	if err != nil {
+		return RunXML{}, err
+	}
+
+	value := func(name, val string) *StringValXML {
+		return &StringValXML{XMLName: xml.Name{Local: name}, Val: val}
+	}
+	return RunXML{
+		XMLName: xml.Name{Local: "w:r"},
+		Ruby: &RubyXML{
+			Properties: RubyPropertiesXML{
+				Align:    value("w:rubyAlign", ruby.Alignment),
+				Size:     value("w:hps", strconv.Itoa(ruby.FontSize)),
+				Raise:    value("w:hpsRaise", strconv.Itoa(ruby.Raise)),
+				BaseSize: value("w:hpsBaseText", strconv.Itoa(baseSize)),
+				Language: value("w:lid", ruby.Language),
 			},
+			Annotation: RubyContentXML{Runs: annotationRuns},
+			Base:       RubyContentXML{Runs: baseRuns},
+		},
+	}, nil
+}
+
+// rubyLanguage returns the language of a phonetic guide from its scripts:
+// kana is Japanese, Hangul Korean and anything else Chinese
+func rubyLanguage(text string) string {
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			return "ja-JP"
+		case unicode.Is(unicode.Hangul, r):
+			return "ko-KR"
+		}
+	}
+	return "zh-CN"
+}
+
+// newTextXML creates a text element that keeps leading and trailing spaces
+func newTextXML(text string) *TextXML {
+	textXML := &TextXML{
+		XMLName: xml.Name{Local: "w:t"},
+		Content: text,
+	}
+	if strings.TrimSpace(text) != text {
+		textXML.Space = "preserve"
+	}
+	return textXML
+}
+
+// newHyperlinkXML creates a hyperlink element for an external or internal target
+func (w *DocumentWriter) newHyperlinkXML(target string) *HyperlinkXML {
+	hyperlink := &HyperlinkXML{
+		XMLName: xml.Name{Local: "w:hyperlink"},
+		History: "1",
+		target:  target,
+	}
+
+	if strings.HasPrefix(target, "#") {
+		hyperlink.Anchor = strings.TrimPrefix(target, "#")
+	} else {
+		hyperlink.ID = w.addRelationship(hyperlinkRelationshipType, target, true)
+	}
+
+	return hyperlink
+}
+
+// buildTableXML converts a table of the document model to its XML form
+func (w *DocumentWriter) buildTableXML(table types.Table) (TableXML, error) {
+	xmlTable := TableXML{
+		XMLName: xml.Name{Local: "w:tbl"},
+	}
+
+	// Add table properties for better compatibility
+	xmlTable.Properties = &TablePropertiesXML{
+		XMLName: xml.Name{Local: "w:tblPr"},
+		TableBorders: &TableBordersXML{
+			XMLName: xml.Name{Local: "w:tblBorders"},
+			Top:     &TopBorderXML{XMLName: xml.Name{Local: "w:top"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
+			Left:    &LeftBorderXML{XMLName: xml.Name{Local: "w:left"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
+			Bottom:  &BottomBorderXML{XMLName: xml.Name{Local: "w:bottom"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
+			Right:   &RightBorderXML{XMLName: xml.Name{Local: "w:right"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
+			InsideH: &InsideHBorderXML{XMLName: xml.Name{Local: "w:insideH"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
+			InsideV: &InsideVBorderXML{XMLName: xml.Name{Local: "w:insideV"}, Val: "single", Sz: "4", Space: "0", Color: "auto"},
+		},
+	}
+
+	// Add the column grid, splitting the text width evenly
+	columns := table.Columns
+	for _, row := range table.Rows {
+		span := 0
+		for _, cell := range row.Cells {
+			span += max(cell.ColSpan, 1)
+		}
+		if span > columns {
+			columns = span
 		}
+	}
+	if columns > 0 {
+		xmlTable.Grid = &TableGridXML{XMLName: xml.Name{Local: "w:tblGrid"}}
+		for i := 0; i < columns; i++ {
+			xmlTable.Grid.Columns = append(xmlTable.Grid.Columns, GridColumnXML{
+				XMLName: xml.Name{Local: "w:gridCol"},
+				Width:   defaultTextWidth / columns,
+			})
+		}
+	}
 
-		for _, row := range table.Rows {
-			xmlRow := TableRowXML{
-				XMLName: xml.Name{Local: "w:tr"},
+	for _, row := range table.Rows {
+		xmlRow := TableRowXML{
+			XMLName: xml.Name{Local: "w:tr"},
+		}
+
+		if row.Header {
+			xmlRow.Properties = &TableRowPropertiesXML{
+				XMLName:     xml.Name{Local: "w:trPr"},
+				TableHeader: &OnOffXML{XMLName: xml.Name{Local: "w:tblHeader"}},
+			}
+		}
+
+		for _, cell := range row.Cells {
+			xmlCell := TableCellXML{
+				XMLName: xml.Name{Local: "w:tc"},
+			}
+
+			if cell.ColSpan > 1 || cell.VMerge != "" {
+				xmlCell.Properties = &TableCellPropertiesXML{XMLName: xml.Name{Local: "w:tcPr"}}
+				if cell.ColSpan > 1 {
+					xmlCell.Properties.GridSpan = &IntValXML{XMLName: xml.Name{Local: "w:gridSpan"}, Val: cell.ColSpan}
+				}
+				switch cell.VMerge {
+				case "restart":
+					xmlCell.Properties.VMerge = &VMergeXML{XMLName: xml.Name{Local: "w:vMerge"}, Val: "restart"}
+				case "continue":
+					xmlCell.Properties.VMerge = &VMergeXML{XMLName: xml.Name{Local: "w:vMerge"}}
+				}
 			}
 
-			for _, cell := range row.Cells {
-				xmlCell := TableCellXML{
-					XMLName: xml.Name{Local: "w:tc"},
-					Content: []interface{}{
-						ParagraphXML{
-							XMLName: xml.Name{Local: "w:p"},
-							Runs: []RunXML{
-								{
-									XMLName: xml.Name{Local: "w:r"},
-									Text: &TextXML{
-										XMLName: xml.Name{Local: "w:t"},
-										Content: cell.Text,
-									},
-								},
+			if len(cell.Paragraphs) == 0 {
+				xmlCell.Content = []interface{}{
+					ParagraphXML{
+						XMLName: xml.Name{Local: "w:p"},
+						Content: []interface{}{
+							RunXML{
+								XMLName: xml.Name{Local: "w:r"},
+								Text:    newTextXML(cell.Text),
 							},
 						},
 					},
 				}
-				xmlRow.Cells = append(xmlRow.Cells, xmlCell)
 			}
 
-			xmlTable.Rows = append(xmlTable.Rows, xmlRow)
-		}
+			for _, paragraph := range cell.Paragraphs {
+				xmlParagraph, err := w.buildParagraphXML(paragraph)
+				if err != nil {
+					return xmlTable, err
+				}
+				xmlCell.Content = append(xmlCell.Content, xmlParagraph)
+			}
 
-		doc.Body.Tables = append(doc.Body.Tables, xmlTable)
-	}
+			xmlRow.Cells = append(xmlRow.Cells, xmlCell)
+		}
 
-	// Add section properties for page settings
-	doc.Body.SectionProperties = &SectionPropertiesXML{
-		XMLName: xml.Name{Local: "w:sectPr"},
-		PageSize: &PageSizeXML{
-			XMLName: xml.Name{Local: "w:pgSz"},
-			Width:   "11906",
-			Height:  "16838",
-		},
-		PageMargins: &PageMarginsXML{
-			XMLName: xml.Name{Local: "w:pgMar"},
-			Top:     "1440",
-			Right:   "1800",
-			Bottom:  "1440",
-			Left:    "1800",
-			Header:  "851",
-			Footer:  "992",
-			Gutter:  "0",
-		},
-		Columns: &ColumnsXML{
-			XMLName: xml.Name{Local: "w:cols"},
-			Space:   "425",
-			Number:  "1",
-		},
-		DocumentGrid: &DocumentGridXML{
-			XMLName: xml.Name{Local: "w:docGrid"},
-			Type:    "lines",
-			LinePitch: "312",
-			CharSpace: "0",
-		},
+		xmlTable.Rows = append(xmlTable.Rows, xmlRow)
 	}
 
-	// Marshal to XML
-	var buf bytes.Buffer
-	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
-	buf.WriteString("\n")
-	encoder := xml.NewEncoder(&buf)
-	encoder.Indent("", "  ")
-	if err := encoder.Encode(doc); err != nil {
-		return nil, fmt.Errorf("failed to encode document XML: %w", err)
-	}
-
-	return buf.Bytes(), nil
+	return xmlTable, nil
 }
 
 // generateContentTypesXML generates the XML content for [Content_Types].xml
@@ -663,6 +1273,7 @@ func (w *DocumentWriter) generateContentTypesXML() []byte {
   <Default Extension="bmp" ContentType="image/bmp"/>
   <Default Extension="wmf" ContentType="image/wmf"/>
   <Default Extension="emf" ContentType="image/emf"/>
+  <Default Extension="svg" ContentType="image/svg+xml"/>
   <Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
   <Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>`
 
@@ -681,6 +1292,22 @@ func (w *DocumentWriter) generateContentTypesXML() []byte {
   <Override PartName="/word/comments.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.comments+xml"/>`
 	}
 
+	// Add numbering content type if there are lists
+	if len(w.lists) > 0 {
+		contentTypesXML += `
+  <Override PartName="/word/numbering.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"/>`
+	}
+
+	// Add header and footer content types
+	for _, part := range w.headerFooterParts {
+		contentType := headerContentType
+		if part.footer {
+			contentType = footerContentType
+		}
+		contentTypesXML += fmt.Sprintf(`
+  <Override PartName="/%s" ContentType="%s"/>`, part.Name, contentType)
+	}
+
 	contentTypesXML += `
 </Types>`
 	return []byte(contentTypesXML)
@@ -714,6 +1341,9 @@ func (w *DocumentWriter) generateDocumentRelsXML() []byte {
   <Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments" Target="comments.xml"/>`, relationshipId)
 	}
 
+	// Add relationships created while generating the document
+	documentRelsXML += w.generateRelationshipsXML()
+
 	documentRelsXML += `
 </Relationships>`
 
@@ -729,7 +1359,12 @@ func (w *DocumentWriter) generateSettingsXML() []byte {
   <w:printComments w:val="true"/>
   <w:printHiddenText w:val="false"/>
   <w:printBackground w:val="false"/>
-  <w:zoom w:percent="100"/>
+  <w:zoom w:percent="100"/>`
+	if w.usesEvenHeaders() {
+		settingsXML += `
+  <w:evenAndOddHeaders/>`
+	}
+	settingsXML += `
 </w:settings>`
 	return []byte(settingsXML)
 }
@@ -805,6 +1440,156 @@ func (w *DocumentWriter) generateStylesXML() []byte {
       <w:color w:val="0000FF"/>
     </w:rPr>
   </w:style>
+  <!-- 标题样式 -->
+  <w:style w:type="paragraph" w:styleId="Heading1">
+    <w:name w:val="heading 1"/>
+    <w:basedOn w:val="Normal"/>
+    <w:next w:val="Normal"/>
+    <w:qFormat/>
+    <w:pPr>
+      <w:keepNext/>
+      <w:spacing w:before="240" w:after="80"/>
+      <w:outlineLvl w:val="0"/>
+    </w:pPr>
+    <w:rPr>
+      <w:b/>
+      <w:sz w:val="32"/>
+    </w:rPr>
+  </w:style>
+  <w:style w:type="paragraph" w:styleId="Heading2">
+    <w:name w:val="heading 2"/>
+    <w:basedOn w:val="Normal"/>
+    <w:next w:val="Normal"/>
+    <w:qFormat/>
+    <w:pPr>
+      <w:keepNext/>
+      <w:spacing w:before="200" w:after="80"/>
+      <w:outlineLvl w:val="1"/>
+    </w:pPr>
+    <w:rPr>
+      <w:b/>
+      <w:sz w:val="28"/>
+    </w:rPr>
+  </w:style>
+  <w:style w:type="paragraph" w:styleId="Heading3">
+    <w:name w:val="heading 3"/>
+    <w:basedOn w:val="Normal"/>
+    <w:next w:val="Normal"/>
+    <w:qFormat/>
+    <w:pPr>
+      <w:keepNext/>
+      <w:spacing w:before="160" w:after="80"/>
+      <w:outlineLvl w:val="2"/>
+    </w:pPr>
+    <w:rPr>
+      <w:b/>
+      <w:sz w:val="26"/>
+    </w:rPr>
+  </w:style>
+  <w:style w:type="paragraph" w:styleId="Heading4">
+    <w:name w:val="heading 4"/>
+    <w:basedOn w:val="Normal"/>
+    <w:next w:val="Normal"/>
+    <w:qFormat/>
+    <w:pPr>
+      <w:keepNext/>
+      <w:spacing w:before="120" w:after="80"/>
+      <w:outlineLvl w:val="3"/>
+    </w:pPr>
+    <w:rPr>
+      <w:b/>
+      <w:sz w:val="24"/>
+    </w:rPr>
+  </w:style>
+  <w:style w:type="paragraph" w:styleId="Heading5">
+    <w:name w:val="heading 5"/>
+    <w:basedOn w:val="Normal"/>
+    <w:next w:val="Normal"/>
+    <w:qFormat/>
+    <w:pPr>
+      <w:keepNext/>
+      <w:spacing w:before="120" w:after="80"/>
+      <w:outlineLvl w:val="4"/>
+    </w:pPr>
+    <w:rPr>
+      <w:b/>
+      <w:sz w:val="22"/>
+    </w:rPr>
+  </w:style>
+  <w:style w:type="paragraph" w:styleId="Heading6">
+    <w:name w:val="heading 6"/>
+    <w:basedOn w:val="Normal"/>
+    <w:next w:val="Normal"/>
+    <w:qFormat/>
+    <w:pPr>
+      <w:keepNext/>
+      <w:spacing w:before="120" w:after="80"/>
+      <w:outlineLvl w:val="5"/>
+    </w:pPr>
+    <w:rPr>
+      <w:b/>
+      <w:sz w:val="22"/>
+    </w:rPr>
+  </w:style>
+  <!-- 列表、引用、代码和超链接样式 -->
+  <w:style w:type="paragraph" w:styleId="ListParagraph">
+    <w:name w:val="List Paragraph"/>
+    <w:basedOn w:val="Normal"/>
+    <w:qFormat/>
+    <w:pPr>
+      <w:spacing w:after="40"/>
+      <w:ind w:left="720"/>
+      <w:contextualSpacing/>
+    </w:pPr>
+  </w:style>
+  <w:style w:type="paragraph" w:styleId="Quote">
+    <w:name w:val="Quote"/>
+    <w:basedOn w:val="Normal"/>
+    <w:next w:val="Normal"/>
+    <w:qFormat/>
+    <w:pPr>
+      <w:pBdr>
+        <w:left w:val="single" w:sz="18" w:space="8" w:color="BFBFBF"/>
+      </w:pBdr>
+      <w:ind w:left="360"/>
+    </w:pPr>
+    <w:rPr>
+      <w:i/>
+      <w:color w:val="595959"/>
+    </w:rPr>
+  </w:style>
+  <w:style w:type="paragraph" w:styleId="SourceCode">
+    <w:name w:val="Source Code"/>
+    <w:basedOn w:val="Normal"/>
+    <w:qFormat/>
+    <w:pPr>
+      <w:shd w:val="clear" w:color="auto" w:fill="F2F2F2"/>
+      <w:spacing w:after="0" w:line="240" w:lineRule="auto"/>
+    </w:pPr>
+    <w:rPr>
+      <w:rFonts w:ascii="Consolas" w:hAnsi="Consolas"/>
+      <w:sz w:val="20"/>
+    </w:rPr>
+  </w:style>
+  <w:style w:type="paragraph" w:styleId="HorizontalLine">
+    <w:name w:val="Horizontal Line"/>
+    <w:basedOn w:val="Normal"/>
+    <w:next w:val="Normal"/>
+    <w:pPr>
+      <w:pBdr>
+        <w:bottom w:val="single" w:sz="6" w:space="1" w:color="auto"/>
+      </w:pBdr>
+    </w:pPr>
+  </w:style>
+  <w:style w:type="character" w:styleId="Hyperlink">
+    <w:name w:val="Hyperlink"/>
+    <w:basedOn w:val="DefaultParagraphFont"/>
+    <w:unhideWhenUsed/>
+    <w:rPr>
+      <w:color w:val="0563C1"/>
+      <w:u w:val="single"/>
+    </w:rPr>
+  </w:style>
 </w:styles>`
 	return []byte(stylesXML)
 }
@@ -816,14 +1601,15 @@ type DocumentXML struct {
 	XMLNSMC string `xml:"xmlns:mc,attr"`
 	XMLNSR string `xml:"xmlns:r,attr"`
 	XMLNSW14 string `xml:"xmlns:w14,attr"`
+	XMLNSWP string `xml:"xmlns:wp,attr"`
 	MCIgnorable string `xml:"mc:Ignorable,attr"`
 	Body    DocumentBody
 }
 
 type DocumentBody struct {
-	XMLName    xml.Name       `xml:"w:body"`
-	Paragraphs []ParagraphXML `xml:"w:p"`
-	Tables     []TableXML     `xml:"w:tbl"`
+	XMLName xml.Name `xml:"w:body"`
+	// Content holds paragraphs and tables in document order
+	Content           []interface{}         `xml:",any"`
 	SectionProperties *SectionPropertiesXML `xml:"w:sectPr,omitempty"`
 }
 
@@ -831,14 +1617,47 @@ type ParagraphXML struct {
 	XMLName           xml.Name                `xml:"w:p"`
 	Properties        *ParagraphPropertiesXML `xml:"w:pPr,omitempty"`
 	CommentRangeStart *CommentRangeStartXML   `xml:"w:commentRangeStart,omitempty"`
-	Runs              []RunXML                `xml:"w:r"`
+	// Content holds runs and hyperlinks in document order
+	Content           []interface{}           `xml:",any"`
 	CommentRangeEnd   *CommentRangeEndXML     `xml:"w:commentRangeEnd,omitempty"`
 	CommentReference  *CommentReferenceXML    `xml:"w:commentReference,omitempty"`
 }
 
 type ParagraphPropertiesXML struct {
-	XMLName xml.Name  `xml:"w:pPr"`
-	Style   *StyleXML `xml:"w:pStyle,omitempty"`
+	XMLName   xml.Name                `xml:"w:pPr"`
+	Style     *StyleXML               `xml:"w:pStyle,omitempty"`
+	PageBreakBefore *OnOffXML         `xml:"w:pageBreakBefore,omitempty"`
+	Numbering *NumberingPropertiesXML `xml:"w:numPr,omitempty"`
+	Bidi      *OnOffXML               `xml:"w:bidi,omitempty"`
+	Spacing   *ParagraphSpacingXML    `xml:"w:spacing,omitempty"`
+	Indentation *IndentationXML       `xml:"w:ind,omitempty"`
+	Justification *JustificationXML   `xml:"w:jc,omitempty"`
+	SectionProperties *SectionPropertiesXML `xml:"w:sectPr,omitempty"`
+}
+
+// ParagraphSpacingXML represents the space around a paragraph in twips
+// and its line spacing in 240ths of a line
+type ParagraphSpacingXML struct {
+	XMLName  xml.Name `xml:"w:spacing"`
+	Before   string   `xml:"w:before,attr,omitempty"`
+	After    string   `xml:"w:after,attr,omitempty"`
+	Line     string   `xml:"w:line,attr,omitempty"`
+	LineRule string   `xml:"w:lineRule,attr,omitempty"`
+}
+
+// IndentationXML represents the indentation of a paragraph in twips
+type IndentationXML struct {
+	XMLName   xml.Name `xml:"w:ind"`
+	Left      string   `xml:"w:left,attr,omitempty"`
+	Right     string   `xml:"w:right,attr,omitempty"`
+	FirstLine string   `xml:"w:firstLine,attr,omitempty"`
+	Hanging   string   `xml:"w:hanging,attr,omitempty"`
+}
+
+// JustificationXML represents the paragraph alignment
+type JustificationXML struct {
+	XMLName xml.Name `xml:"w:jc"`
+	Val     string   `xml:"w:val,attr"`
 }
 
 type StyleXML struct {
@@ -846,33 +1665,123 @@ type StyleXML struct {
 	Val     string   `xml:"w:val,attr"`
 }
 
+// NumberingPropertiesXML references the list a paragraph belongs to
+type NumberingPropertiesXML struct {
+	XMLName xml.Name   `xml:"w:numPr"`
+	Level   *IntValXML `xml:"w:ilvl,omitempty"`
+	NumID   *IntValXML `xml:"w:numId,omitempty"`
+}
+
+// IntValXML represents an element with a single numeric w:val attribute
+type IntValXML struct {
+	XMLName xml.Name
+	Val     int `xml:"w:val,attr"`
+}
+
+// StringValXML represents an element with a single w:val attribute
+type StringValXML struct {
+	XMLName xml.Name
+	Val     string `xml:"w:val,attr"`
+}
+
+// OnOffXML represents an element whose presence switches a property on
+type OnOffXML struct {
+	XMLName xml.Name
+}
+
 type CharacterStyleXML struct {
 	XMLName xml.Name `xml:"w:rStyle"`
 	Val     string   `xml:"w:val,attr"`
 }
 
+// HyperlinkXML represents a hyperlink around one or more runs
+type HyperlinkXML struct {
+	XMLName xml.Name `xml:"w:hyperlink"`
+	ID      string   `xml:"r:id,attr,omitempty"`
+	Anchor  string   `xml:"w:anchor,attr,omitempty"`
+	History string   `xml:"w:history,attr,omitempty"`
+	Runs    []RunXML `xml:"w:r"`
+
+	target string
+}
+
+// FieldSimpleXML represents a field whose result runs follow its instruction
+type FieldSimpleXML struct {
+	XMLName xml.Name `xml:"w:fldSimple"`
+	Instr   string   `xml:"w:instr,attr"`
+	Runs    []RunXML `xml:"w:r"`
+}
+
 type RunXML struct {
 	XMLName    xml.Name          `xml:"w:r"`
 	Properties *RunPropertiesXML `xml:"w:rPr,omitempty"`
 	Text       *TextXML          `xml:"w:t,omitempty"`
+	Tab        *TabXML           `xml:"w:tab,omitempty"`
+	Break      *BreakXML         `xml:"w:br,omitempty"`
+	Drawing    *DrawingXML       `xml:"w:drawing,omitempty"`
 	CommentReference *CommentReferenceXML `xml:"w:commentReference,omitempty"`
+	Ruby       *RubyXML          `xml:"w:ruby,omitempty"`
+}
+
+// RubyXML represents a phonetic guide with its annotation and base text
+type RubyXML struct {
+	XMLName    xml.Name          `xml:"w:ruby"`
+	Properties RubyPropertiesXML `xml:"w:rubyPr"`
+	Annotation RubyContentXML    `xml:"w:rt"`
+	Base       RubyContentXML    `xml:"w:rubyBase"`
+}
+
+// RubyPropertiesXML represents the properties of a phonetic guide, which
+// all must be present
+type RubyPropertiesXML struct {
+	Align    *StringValXML `xml:"w:rubyAlign"`
+	Size     *StringValXML `xml:"w:hps"`
+	Raise    *StringValXML `xml:"w:hpsRaise"`
+	BaseSize *StringValXML `xml:"w:hpsBaseText"`
+	Language *StringValXML `xml:"w:lid"`
+}
+
+// RubyContentXML holds the runs of the annotation or base text of a guide
+type RubyContentXML struct {
+	Runs []RunXML `xml:"w:r"`
 }
 
 type RunPropertiesXML struct {
 	XMLName   xml.Name            `xml:"w:rPr"`
 	Style     *CharacterStyleXML  `xml:"w:rStyle,omitempty"`
+	Font      *FontXML            `xml:"w:rFonts,omitempty"`
 	Bold      *BoldXML            `xml:"w:b,omitempty"`
 	Italic    *ItalicXML          `xml:"w:i,omitempty"`
-	Underline *UnderlineXML       `xml:"w:u,omitempty"`
+	Strike    *StrikeXML          `xml:"w:strike,omitempty"`
+	Color     *ColorXML           `xml:"w:color,omitempty"`
 	Size      *SizeXML            `xml:"w:sz,omitempty"`
-	Font      *FontXML            `xml:"w:rFonts,omitempty"`
+	Underline *UnderlineXML       `xml:"w:u,omitempty"`
+	RTL       *OnOffXML           `xml:"w:rtl,omitempty"`
 }
 
 type TextXML struct {
 	XMLName xml.Name `xml:"w:t"`
+	Space   string   `xml:"xml:space,attr,omitempty"`
 	Content string   `xml:",chardata"`
 }
 
+// BreakXML represents a line, page or column break at the end of a run
+type BreakXML struct {
+	XMLName xml.Name `xml:"w:br"`
+	Type    string   `xml:"w:type,attr,omitempty"`
+}
+
+// TabXML represents a tab character at the end of a run
+type TabXML struct {
+	XMLName xml.Name `xml:"w:tab"`
+}
+
+// DrawingXML wraps the DrawingML markup of a picture
+type DrawingXML struct {
+	XMLName xml.Name `xml:"w:drawing"`
+	Inner   string   `xml:",innerxml"`
+}
+
 type BoldXML struct {
 	XMLName xml.Name `xml:"w:b"`
 	Val     string   `xml:"w:val,attr"`
@@ -883,6 +1792,18 @@ type ItalicXML struct {
 	Val     string   `xml:"w:val,attr"`
 }
 
+// StrikeXML represents strikethrough formatting
+type StrikeXML struct {
+	XMLName xml.Name `xml:"w:strike"`
+	Val     string   `xml:"w:val,attr"`
+}
+
+// ColorXML represents the text color
+type ColorXML struct {
+	XMLName xml.Name `xml:"w:color"`
+	Val     string   `xml:"w:val,attr"`
+}
+
 type UnderlineXML struct {
 	XMLName xml.Name `xml:"w:u"`
 	Val     string   `xml:"w:val,attr"`
@@ -920,17 +1841,51 @@ type CommentReferenceXML struct {
 type TableXML struct {
 	XMLName xml.Name      `xml:"w:tbl"`
 	Properties *TablePropertiesXML `xml:"w:tblPr,omitempty"`
+	Grid    *TableGridXML `xml:"w:tblGrid,omitempty"`
 	Rows    []TableRowXML `xml:"w:tr"`
 }
 
+// TableGridXML represents the column grid of a table
+type TableGridXML struct {
+	XMLName xml.Name        `xml:"w:tblGrid"`
+	Columns []GridColumnXML `xml:"w:gridCol"`
+}
+
+// GridColumnXML represents a single grid column width in twips
+type GridColumnXML struct {
+	XMLName xml.Name `xml:"w:gridCol"`
+	Width   int      `xml:"w:w,attr"`
+}
+
 type TableRowXML struct {
-	XMLName xml.Name       `xml:"w:tr"`
-	Cells   []TableCellXML `xml:"w:tc"`
+	XMLName    xml.Name               `xml:"w:tr"`
+	Properties *TableRowPropertiesXML `xml:"w:trPr,omitempty"`
+	Cells      []TableCellXML         `xml:"w:tc"`
+}
+
+// TableRowPropertiesXML represents the properties of a table row
+type TableRowPropertiesXML struct {
+	XMLName     xml.Name  `xml:"w:trPr"`
+	TableHeader *OnOffXML `xml:"w:tblHeader,omitempty"`
 }
 
 type TableCellXML struct {
-	XMLName xml.Name      `xml:"w:tc"`
-	Content []interface{} `xml:",any"`
+	XMLName    xml.Name                `xml:"w:tc"`
+	Properties *TableCellPropertiesXML `xml:"w:tcPr,omitempty"`
+	Content    []interface{}           `xml:",any"`
+}
+
+// TableCellPropertiesXML represents cell properties (w:tcPr)
+type TableCellPropertiesXML struct {
+	XMLName  xml.Name    `xml:"w:tcPr"`
+	GridSpan *IntValXML  `xml:"w:gridSpan,omitempty"`
+	VMerge   *VMergeXML  `xml:"w:vMerge,omitempty"`
+}
+
+// VMergeXML represents a vertical cell merge; an empty value continues the merge
+type VMergeXML struct {
+	XMLName xml.Name `xml:"w:vMerge"`
+	Val     string   `xml:"w:val,attr,omitempty"`
 }
 
 // generateFontTableXML generates the XML content for word/fontTable.xml
@@ -1347,12 +2302,32 @@ type InsideVBorderXML struct {
 // SectionPropertiesXML represents the properties of a document section
 type SectionPropertiesXML struct {
 	XMLName xml.Name `xml:"w:sectPr"`
+	HeaderReferences []HeaderFooterReferenceXML `xml:"w:headerReference"`
+	FooterReferences []HeaderFooterReferenceXML `xml:"w:footerReference"`
+	Type *StringValXML `xml:"w:type,omitempty"`
 	PageSize *PageSizeXML `xml:"w:pgSz,omitempty"`
 	PageMargins *PageMarginsXML `xml:"w:pgMar,omitempty"`
+	PageNumbering *PageNumberingXML `xml:"w:pgNumType,omitempty"`
 	Columns *ColumnsXML `xml:"w:cols,omitempty"`
+	TitlePage *OnOffXML `xml:"w:titlePg,omitempty"`
+	TextDirection *StringValXML `xml:"w:textDirection,omitempty"`
 	DocumentGrid *DocumentGridXML `xml:"w:docGrid,omitempty"`
 }
 
+// HeaderFooterReferenceXML references a header or footer part of a section
+type HeaderFooterReferenceXML struct {
+	XMLName xml.Name
+	Type string `xml:"w:type,attr"`
+	ID string `xml:"r:id,attr"`
+}
+
+// PageNumberingXML represents the page number format of a document section
+type PageNumberingXML struct {
+	XMLName xml.Name `xml:"w:pgNumType"`
+	Format string `xml:"w:fmt,attr,omitempty"`
+	Start string `xml:"w:start,attr,omitempty"`
+}
+
 // PageSizeXML represents the page size of a document section
 type PageSizeXML struct {
 	XMLName xml.Name `xml:"w:pgSz"`
@@ -1,9 +1,13 @@
 package writer
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/word"
 )
 
 func TestDocumentWriterCreateNewDocument(t *testing.T) {
@@ -280,6 +284,230 @@ func TestDocumentWriterSetRunFormatting(t *testing.T) {
 	}
 }
 
+func TestDocumentWriterRightToLeft(t *testing.T) {
+	writer := NewDocumentWriter()
+	writer.CreateNewDocument()
+
+	runs := []types.Run{{Text: "שלום", RTL: true}, {Text: " world"}}
+	writer.AddFormattedParagraph("שלום world", "Normal", runs)
+	paragraph := &writer.Document.GetMainPart().Content.Paragraphs[0]
+	paragraph.Bidi = true
+	paragraph.Alignment = "left"
+
+	filename := filepath.Join(t.TempDir(), "rtl.docx")
+	if err := writer.Save(filename); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+
+	// w:bidi 必须位于 w:jc 之前
+	documentXML := readZipPart(t, filename, "word/document.xml")
+	bidi, jc := strings.Index(documentXML, "<w:bidi>"), strings.Index(documentXML, "<w:jc ")
+	if bidi < 0 || jc < bidi {
+		t.Error("Expected w:bidi before w:jc in the paragraph properties")
+	}
+	if strings.Count(documentXML, "<w:rtl>") != 1 {
+		t.Error("Expected w:rtl on the right-to-left run only")
+	}
+
+	doc, err := word.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open saved document: %v", err)
+	}
+	defer doc.Close()
+	paragraphs, err := doc.GetParagraphs()
+	if err != nil || len(paragraphs) != 1 {
+		t.Fatalf("Failed to read paragraphs: %v", err)
+	}
+	if !paragraphs[0].Bidi || !paragraphs[0].Runs[0].RTL || paragraphs[0].Runs[1].RTL {
+		t.Error("Expected the right-to-left properties to survive a round trip")
+	}
+}
+
+func TestDocumentWriterLineBreaksAndTabs(t *testing.T) {
+	dir := t.TempDir()
+	markdown := filepath.Join(dir, "input.md")
+	if err := os.WriteFile(markdown, []byte("line one  \nline two\n"), 0644); err != nil {
+		t.Fatalf("Failed to write markdown: %v", err)
+	}
+
+	writer := NewDocumentWriter()
+	if err := writer.ImportMarkdownFile(markdown); err != nil {
+		t.Fatalf("Failed to import markdown: %v", err)
+	}
+	if err := writer.AppendParagraph(types.Paragraph{Runs: []types.Run{{Text: "first line\nsecond line\tafter tab", Bold: true}}}); err != nil {
+		t.Fatalf("Failed to append paragraph: %v", err)
+	}
+
+	filename := filepath.Join(dir, "breaks.docx")
+	if err := writer.Save(filename); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+	doc, err := word.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open saved document: %v", err)
+	}
+	defer doc.Close()
+	paragraphs, err := doc.GetParagraphs()
+	if err != nil || len(paragraphs) != 2 {
+		t.Fatalf("Failed to read paragraphs: %v", err)
+	}
+
+	// 每个运行只有一段文字，后面最多跟一个换行或制表符
+	describe := func(paragraph types.Paragraph) string {
+		var parts []string
+		for _, run := range paragraph.Runs {
+			part := run.Text
+			if run.Tab {
+				part += "<tab>"
+			}
+			if run.Break != "" {
+				part += "<" + run.Break + ">"
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, "|")
+	}
+	if got, want := describe(paragraphs[0]), "line one<line>|line two"; got != want {
+		t.Errorf("Unexpected runs of the hard break: got %s, want %s", got, want)
+	}
+	if got, want := describe(paragraphs[1]), "first line<line>|second line<tab>|after tab"; got != want {
+		t.Errorf("Unexpected runs of the paragraph: got %s, want %s", got, want)
+	}
+	for _, run := range paragraphs[1].Runs {
+		if !run.Bold {
+			t.Errorf("Expected every part of the run to keep its formatting: %+v", run)
+		}
+	}
+}
+
+func TestDocumentWriterParagraphLayout(t *testing.T) {
+	writer := NewDocumentWriter()
+	writer.CreateNewDocument()
+	paragraph := types.Paragraph{
+		SpaceBefore:     12,
+		SpaceAfter:      6,
+		LineSpacing:     1.5,
+		LeftIndent:      36,
+		RightIndent:     18,
+		FirstLineIndent: -18,
+		PageBreakBefore: true,
+		Runs: []types.Run{
+			{Text: "before tab", Tab: true},
+			{Text: "before page", Break: "page"},
+			{Break: "line"},
+			{Text: "last"},
+		},
+	}
+	if err := writer.AppendParagraph(paragraph); err != nil {
+		t.Fatalf("Failed to append paragraph: %v", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "layout.docx")
+	if err := writer.Save(filename); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+	doc, err := word.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open saved document: %v", err)
+	}
+	defer doc.Close()
+	paragraphs, err := doc.GetParagraphs()
+	if err != nil || len(paragraphs) != 1 {
+		t.Fatalf("Failed to read paragraphs: %v", err)
+	}
+
+	got := paragraphs[0]
+	if got.SpaceBefore != 12 || got.SpaceAfter != 6 || got.LineSpacing != 1.5 {
+		t.Errorf("Unexpected spacing: before %v, after %v, line %v", got.SpaceBefore, got.SpaceAfter, got.LineSpacing)
+	}
+	if got.LeftIndent != 36 || got.RightIndent != 18 || got.FirstLineIndent != -18 {
+		t.Errorf("Unexpected indentation: left %v, right %v, first line %v", got.LeftIndent, got.RightIndent, got.FirstLineIndent)
+	}
+	if !got.PageBreakBefore {
+		t.Error("Expected the page break before the paragraph to be kept")
+	}
+	if len(got.Runs) != 4 {
+		t.Fatalf("Expected 4 runs, got %d", len(got.Runs))
+	}
+	if !got.Runs[0].Tab || got.Runs[0].Text != "before tab" {
+		t.Errorf("Expected the tab to follow the first run: %+v", got.Runs[0])
+	}
+	if got.Runs[1].Break != "page" {
+		t.Errorf("Expected a page break after the second run, got %q", got.Runs[1].Break)
+	}
+	if got.Runs[2].Break != "line" || got.Runs[2].Text != "" {
+		t.Errorf("Expected a run with only a line break: %+v", got.Runs[2])
+	}
+}
+
+func TestDocumentWriterRubyAndVerticalText(t *testing.T) {
+	writer := NewDocumentWriter()
+	writer.CreateNewDocument()
+
+	runs := []types.Run{
+		{Text: "漢字", FontSize: 24, Ruby: &types.Ruby{Text: "かんじ"}},
+		{Text: "を読む"},
+	}
+	writer.AddFormattedParagraph("漢字を読む", "Normal", runs)
+	writer.AddFormattedParagraph("汉语", "Normal", []types.Run{
+		{Text: "汉语", Ruby: &types.Ruby{Text: "hànyǔ", Alignment: "distributeLetter", FontSize: 8, Raise: 16}},
+	})
+	if err := writer.SetTextDirection("upward"); err == nil {
+		t.Error("Expected an error for an invalid text direction")
+	}
+	if err := writer.SetTextDirection("tbRl"); err != nil {
+		t.Fatalf("Failed to set text direction: %v", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "ruby.docx")
+	if err := writer.Save(filename); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+
+	documentXML := readZipPart(t, filename, "word/document.xml")
+	for _, expected := range []string{
+		`<w:rubyAlign w:val="center">`, `<w:hps w:val="12">`, `<w:hpsRaise w:val="22">`, `<w:hpsBaseText w:val="24">`, `<w:lid w:val="ja-JP">`,
+		`<w:lid w:val="zh-CN">`, `<w:rubyAlign w:val="distributeLetter">`,
+		`<w:textDirection w:val="tbRl">`,
+	} {
+		if !strings.Contains(documentXML, expected) {
+			t.Errorf("Expected %s in the document XML", expected)
+		}
+	}
+	// w:rt 必须位于 w:rubyBase 之前，w:textDirection 位于 w:docGrid 之前
+	if strings.Index(documentXML, "<w:rt>") > strings.Index(documentXML, "<w:rubyBase>") {
+		t.Error("Expected w:rt before w:rubyBase")
+	}
+	if strings.Index(documentXML, "<w:textDirection") > strings.Index(documentXML, "<w:docGrid") {
+		t.Error("Expected w:textDirection before w:docGrid")
+	}
+
+	doc, err := word.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open saved document: %v", err)
+	}
+	defer doc.Close()
+	paragraphs, err := doc.GetParagraphs()
+	if err != nil || len(paragraphs) != 2 {
+		t.Fatalf("Failed to read paragraphs: %v", err)
+	}
+	if ruby := paragraphs[1].Runs[0].Ruby; ruby == nil || ruby.Text != "hànyǔ" || ruby.FontSize != 8 || ruby.Raise != 16 || ruby.BaseFontSize != 20 {
+		t.Errorf("Expected the ruby to survive a round trip: %+v", ruby)
+	}
+
+	text, err := doc.GetTextWithRuby(word.RubyWithAnnotation)
+	if err != nil || text != "漢字(かんじ)を読む\n汉语(hànyǔ)\n" {
+		t.Errorf("Unexpected annotated text: %q", text)
+	}
+	text, err = doc.GetTextWithRuby(word.RubyBaseOnly)
+	if err != nil || text != "漢字を読む\n汉语\n" {
+		t.Errorf("Unexpected base text: %q", text)
+	}
+	if content := doc.GetMainPart().Content; len(content.Sections) == 0 || !content.Sections[len(content.Sections)-1].IsVertical() {
+		t.Error("Expected a vertical section after a round trip")
+	}
+}
+
 func TestDocumentWriterSetRunFormattingWithoutInitialization(t *testing.T) {
 	writer := NewDocumentWriter()
 	
@@ -419,4 +647,4 @@ func TestDocumentWriterGenerateDocumentRels(t *testing.T) {
 	if len(documentRelsXML) == 0 {
 		t.Error("Expected Document Rels XML to not be empty")
 	}
-}
\ No newline at end of file
+}
\ No newline at end of file
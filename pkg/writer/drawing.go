@@ -0,0 +1,485 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	_ "golang.org/x/image/bmp"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+)
+
+// Unit conversions for DrawingML extents
+const (
+	emuPerPixel = 9525 // at 96 DPI
+	emuPerTwip  = 635
+	emuPerPoint = 12700
+	emuPerInch  = 914400
+)
+
+// svgBlipExtension is the URI of the blip extension that holds SVG pictures
+const svgBlipExtension = "{96DAC541-7B7A-43D3-8B79-37D633B846F1}"
+
+// imageFormat describes a supported picture encoding
+type imageFormat struct {
+	Extension   string
+	ContentType string
+}
+
+// svgFormat is the format of SVG pictures
+var svgFormat = imageFormat{Extension: "svg", ContentType: "image/svg+xml"}
+
+// detectImageFormat identifies the picture encoding from its leading bytes
+func detectImageFormat(data []byte) (imageFormat, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return imageFormat{Extension: "png", ContentType: "image/png"}, nil
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return imageFormat{Extension: "jpeg", ContentType: "image/jpeg"}, nil
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return imageFormat{Extension: "gif", ContentType: "image/gif"}, nil
+	case bytes.HasPrefix(data, []byte("BM")):
+		return imageFormat{Extension: "bmp", ContentType: "image/bmp"}, nil
+	case isSVG(data):
+		return svgFormat, nil
+	default:
+		return imageFormat{}, fmt.Errorf("unsupported image format")
+	}
+}
+
+// isSVG reports whether data is an SVG document
+func isSVG(data []byte) bool {
+	data = bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if !bytes.HasPrefix(data, []byte("<?xml")) && !bytes.HasPrefix(data, []byte("<svg")) && !bytes.HasPrefix(data, []byte("<!")) {
+		return false
+	}
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	return bytes.Contains(head, []byte("<svg"))
+}
+
+// loadImageData returns the encoded bytes of an image
+func loadImageData(img *types.Image) ([]byte, error) {
+	if len(img.Data) > 0 {
+		return img.Data, nil
+	}
+	if img.Path == "" {
+		return nil, fmt.Errorf("image has neither data nor path")
+	}
+
+	data, err := os.ReadFile(img.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image %s: %w", img.Path, err)
+	}
+	return data, nil
+}
+
+// addImagePart stores the picture under word/media and relates it to the
+// main document part. Pictures loaded from the same path share one part.
+// SVG pictures get a bitmap fallback: the Fallback of the image, or a
+// transparent picture of the same size.
+func (w *DocumentWriter) addImagePart(img *types.Image) (*mediaPart, error) {
+	if img.Path != "" && len(img.Data) == 0 {
+		if part, exists := w.mediaByPath[img.Path]; exists {
+			return w.relateMedia(part), nil
+		}
+	}
+
+	data, err := loadImageData(img)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := detectImageFormat(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add image %s: %w", img.Path, err)
+	}
+
+	var part *mediaPart
+	if format == svgFormat {
+		width, height, err := svgSize(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SVG image %s: %w", img.Path, err)
+		}
+		fallback := img.Fallback
+		if len(fallback) == 0 {
+			if fallback, err = transparentPNG(width, height); err != nil {
+				return nil, err
+			}
+		}
+		fallbackPart, err := w.addBitmapPart(fallback)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add fallback of SVG image %s: %w", img.Path, err)
+		}
+		part = w.addMediaPart(data, format, width, height)
+		part.Fallback = fallbackPart
+	} else if part, err = w.addBitmapPart(data); err != nil {
+		return nil, fmt.Errorf("failed to add image %s: %w", img.Path, err)
+	}
+
+	if img.Path != "" && len(img.Data) == 0 {
+		w.mediaByPath[img.Path] = part
+	}
+	return part, nil
+}
+
+// addBitmapPart stores a PNG, JPEG, GIF or BMP picture
+func (w *DocumentWriter) addBitmapPart(data []byte) (*mediaPart, error) {
+	format, err := detectImageFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	if format == svgFormat {
+		return nil, fmt.Errorf("SVG is not a bitmap")
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	part := w.addMediaPart(data, format, config.Width, config.Height)
+	part.DPI = imageDPI(data, format)
+	return part, nil
+}
+
+// addMediaPart stores encoded picture data and relates it to the part
+// being generated
+func (w *DocumentWriter) addMediaPart(data []byte, format imageFormat, width, height int) *mediaPart {
+	name := fmt.Sprintf("media/image%d.%s", len(w.media)+1, format.Extension)
+	part := &mediaPart{
+		Name:        name,
+		RelID:       w.addRelationship(imageRelationshipType, name, false),
+		Data:        data,
+		ContentType: format.ContentType,
+		Width:       width,
+		Height:      height,
+	}
+	w.media = append(w.media, part)
+	return part
+}
+
+// imageDPI reads the resolution stored in a PNG (pHYs), JPEG (JFIF) or BMP
+// picture; it returns zero when the picture has none
+func imageDPI(data []byte, format imageFormat) float64 {
+	switch format.Extension {
+	case "png":
+		// 遍历数据块查找pHYs，单位1表示每米像素数
+		for offset := 8; offset+12 <= len(data); {
+			length := int(binary.BigEndian.Uint32(data[offset:]))
+			kind := string(data[offset+4 : offset+8])
+			if kind == "pHYs" && length >= 9 && offset+8+length <= len(data) {
+				chunk := data[offset+8:]
+				if chunk[8] == 1 {
+					return math.Round(float64(binary.BigEndian.Uint32(chunk)) * 0.0254)
+				}
+				return 0
+			}
+			if kind == "IDAT" || length < 0 {
+				return 0
+			}
+			offset += 12 + length
+		}
+	case "jpeg":
+		if len(data) >= 18 && data[3] == 0xe0 && string(data[6:11]) == "JFIF\x00" {
+			density := float64(binary.BigEndian.Uint16(data[14:]))
+			switch data[13] {
+			case 1:
+				return density
+			case 2:
+				return math.Round(density * 2.54)
+			}
+		}
+	case "bmp":
+		if len(data) >= 42 {
+			return math.Round(float64(int32(binary.LittleEndian.Uint32(data[38:]))) * 0.0254)
+		}
+	}
+	return 0
+}
+
+// svgSize returns the size of an SVG picture in pixels at 96 DPI from the
+// width and height of its root element, or from its view box. Pictures
+// without either get the default size of 300 by 150 pixels.
+func svgSize(data []byte) (int, int, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, 0, fmt.Errorf("no svg element found: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return 0, 0, fmt.Errorf("root element is %s, not svg", start.Name.Local)
+		}
+
+		var width, height float64
+		var viewBox []float64
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "width":
+				width = svgLength(attr.Value)
+			case "height":
+				height = svgLength(attr.Value)
+			case "viewBox":
+				for _, field := range strings.FieldsFunc(attr.Value, func(r rune) bool { return r == ',' || r == ' ' }) {
+					value, _ := strconv.ParseFloat(field, 64)
+					viewBox = append(viewBox, value)
+				}
+			}
+		}
+		if len(viewBox) == 4 && viewBox[2] > 0 && viewBox[3] > 0 {
+			switch {
+			case width <= 0 && height <= 0:
+				width, height = viewBox[2], viewBox[3]
+			case width <= 0:
+				width = height * viewBox[2] / viewBox[3]
+			case height <= 0:
+				height = width * viewBox[3] / viewBox[2]
+			}
+		}
+		if width <= 0 || height <= 0 {
+			width, height = 300, 150
+		}
+		return int(math.Round(width)), int(math.Round(height)), nil
+	}
+}
+
+// svgLength converts an SVG length such as "2in" or "120" to pixels at 96
+// DPI; percentages and invalid lengths give zero
+func svgLength(value string) float64 {
+	value = strings.TrimSpace(value)
+	units := map[string]float64{"px": 1, "pt": 96.0 / 72, "pc": 16, "in": 96, "cm": 96 / 2.54, "mm": 96 / 25.4}
+	scale := 1.0
+	for unit, factor := range units {
+		if strings.HasSuffix(value, unit) {
+			value, scale = strings.TrimSuffix(value, unit), factor
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return number * scale
+}
+
+// transparentPNG encodes a transparent picture, used as the fallback of
+// SVG pictures
+func transparentPNG(width, height int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))); err != nil {
+		return nil, fmt.Errorf("failed to encode fallback image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// imageExtent computes the displayed size in EMUs. Width and Height of the
+// image are pixels at 96 DPI; without them the picture keeps the size its
+// pixels have at its DPI, and a missing dimension keeps the aspect ratio.
+// Pictures wider than the text area are scaled down to fit.
+func imageExtent(img *types.Image, part *mediaPart) (int64, int64) {
+	dpi := img.DPI
+	if dpi <= 0 {
+		dpi = part.DPI
+	}
+	if dpi <= 0 {
+		dpi = 96
+	}
+	naturalWidth := float64(part.Width) * 96 / dpi
+	naturalHeight := float64(part.Height) * 96 / dpi
+
+	width, height := img.Width, img.Height
+	switch {
+	case width <= 0 && height <= 0:
+		width, height = naturalWidth, naturalHeight
+	case width <= 0 && part.Height > 0:
+		width = height * float64(part.Width) / float64(part.Height)
+	case height <= 0 && part.Width > 0:
+		height = width * float64(part.Height) / float64(part.Width)
+	}
+
+	cx := int64(math.Round(width * emuPerPixel))
+	cy := int64(math.Round(height * emuPerPixel))
+
+	maxWidth := int64(defaultTextWidth * emuPerTwip)
+	if cx > maxWidth {
+		cy = cy * maxWidth / cx
+		cx = maxWidth
+	}
+
+	return cx, cy
+}
+
+// relateMedia returns a picture part that was added before with the ID of
+// its relationship from the part being generated, which differs from the
+// main document part for pictures in headers and footers
+func (w *DocumentWriter) relateMedia(part *mediaPart) *mediaPart {
+	related := *part
+	related.RelID = ""
+	for _, rel := range w.relationships {
+		if rel.Type == imageRelationshipType && rel.Target == part.Name {
+			related.RelID = rel.ID
+			break
+		}
+	}
+	if related.RelID == "" {
+		related.RelID = w.addRelationship(imageRelationshipType, part.Name, false)
+	}
+	if part.Fallback != nil {
+		related.Fallback = w.relateMedia(part.Fallback)
+	}
+	return &related
+}
+
+// buildDrawing creates the DrawingML markup of a picture: wp:inline for
+// pictures in the text line and wp:anchor for floating pictures
+func (w *DocumentWriter) buildDrawing(img *types.Image) (*DrawingXML, error) {
+	part, err := w.addImagePart(img)
+	if err != nil {
+		return nil, err
+	}
+
+	cx, cy := imageExtent(img, part)
+	w.drawingID++
+	name := fmt.Sprintf("Picture %d", w.drawingID)
+
+	graphic := fmt.Sprintf(`<wp:extent cx="%d" cy="%d"/>`, cx, cy)
+	if img.IsFloating() {
+		graphic += `<wp:effectExtent l="0" t="0" r="0" b="0"/>` + anchorWrapXML(img.Wrapping)
+	}
+	graphic += fmt.Sprintf(`<wp:docPr id="%d" name="%s" descr="%s" title="%s"/>`+
+		`<wp:cNvGraphicFramePr><a:graphicFrameLocks xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" noChangeAspect="1"/></wp:cNvGraphicFramePr>`+
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">`+
+		`<a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:nvPicPr><pic:cNvPr id="%d" name="%s" descr="%s"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill>%s<a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic>`,
+		w.drawingID, name, html.EscapeString(img.AltText), html.EscapeString(img.Title),
+		w.drawingID, html.EscapeString(part.Name), html.EscapeString(img.AltText),
+		blipXML(part),
+		cx, cy)
+
+	if !img.IsFloating() {
+		return &DrawingXML{Inner: `<wp:inline distT="0" distB="0" distL="0" distR="0">` + graphic + `</wp:inline>`}, nil
+	}
+	return &DrawingXML{Inner: w.anchorXML(img) + graphic + `</wp:anchor>`}, nil
+}
+
+// blipXML references the picture of a part. SVG pictures reference their
+// fallback, with the SVG in an extension that Word 2016 and later read.
+func blipXML(part *mediaPart) string {
+	if part.Fallback == nil {
+		return fmt.Sprintf(`<a:blip r:embed="%s"/>`, part.RelID)
+	}
+	return fmt.Sprintf(`<a:blip r:embed="%s"><a:extLst><a:ext uri="%s">`+
+		`<asvg:svgBlip xmlns:asvg="http://schemas.microsoft.com/office/drawing/2016/SVG/main" r:embed="%s"/>`+
+		`</a:ext></a:extLst></a:blip>`, part.Fallback.RelID, svgBlipExtension, part.RelID)
+}
+
+// anchorXML opens the wp:anchor element of a floating picture with its
+// position
+func (w *DocumentWriter) anchorXML(img *types.Image) string {
+	anchor := types.ImageAnchor{}
+	if img.Anchor != nil {
+		anchor = *img.Anchor
+	}
+
+	// 文字与图片的间距，默认0.125英寸
+	distance := int64(emuPerInch / 8)
+	if anchor.Distance > 0 {
+		distance = int64(math.Round(anchor.Distance * emuPerPoint))
+	}
+	vertical := int64(0)
+	if img.Wrapping == "topAndBottom" {
+		vertical = distance
+	}
+	behind := "0"
+	if img.Wrapping == "behind" {
+		behind = "1"
+	}
+
+	// 水平位置相对段落时使用段落所在的栏
+	horizontalRelative := anchor.HorizontalRelative
+	switch horizontalRelative {
+	case "", "paragraph":
+		horizontalRelative = "column"
+	}
+	verticalRelative := anchor.VerticalRelative
+	if verticalRelative == "" {
+		verticalRelative = "paragraph"
+	}
+
+	horizontal := fmt.Sprintf(`<wp:posOffset>%d</wp:posOffset>`, int64(math.Round(anchor.X*emuPerPoint)))
+	switch img.Alignment {
+	case "left", "center", "right", "inside", "outside":
+		horizontal = `<wp:align>` + img.Alignment + `</wp:align>`
+	}
+
+	return fmt.Sprintf(`<wp:anchor distT="%d" distB="%d" distL="%d" distR="%d" simplePos="0" relativeHeight="%d" behindDoc="%s" locked="0" layoutInCell="1" allowOverlap="1">`+
+		`<wp:simplePos x="0" y="0"/>`+
+		`<wp:positionH relativeFrom="%s">%s</wp:positionH>`+
+		`<wp:positionV relativeFrom="%s"><wp:posOffset>%d</wp:posOffset></wp:positionV>`,
+		vertical, vertical, distance, distance, 251658240+w.drawingID, behind,
+		horizontalRelative, horizontal,
+		verticalRelative, int64(math.Round(anchor.Y*emuPerPoint)))
+}
+
+// anchorWrapXML returns the wrapping element of a floating picture. Text
+// wraps around the square of the picture unless wrapping says otherwise.
+func anchorWrapXML(wrapping string) string {
+	switch wrapping {
+	case "tight":
+		return `<wp:wrapTight wrapText="bothSides"><wp:wrapPolygon edited="0">` +
+			`<wp:start x="0" y="0"/><wp:lineTo x="0" y="21600"/><wp:lineTo x="21600" y="21600"/><wp:lineTo x="21600" y="0"/><wp:lineTo x="0" y="0"/>` +
+			`</wp:wrapPolygon></wp:wrapTight>`
+	case "topAndBottom":
+		return `<wp:wrapTopAndBottom/>`
+	case "behind", "inFront", "none":
+		return `<wp:wrapNone/>`
+	default:
+		return `<wp:wrapSquare wrapText="bothSides"/>`
+	}
+}
+
+// AddImage appends a paragraph that holds a picture. Inline pictures are
+// aligned in the paragraph as alignment says ("left", "center" or
+// "right"); floating pictures use the alignment as their horizontal
+// position unless it is empty.
+func (w *DocumentWriter) AddImage(img types.Image, alignment string) error {
+	data, err := loadImageData(&img)
+	if err != nil {
+		return err
+	}
+	if _, err := detectImageFormat(data); err != nil {
+		return fmt.Errorf("failed to add image %s: %w", img.Path, err)
+	}
+
+	paragraph := types.Paragraph{Runs: []types.Run{{Image: &img}}}
+	if img.IsFloating() {
+		if alignment != "" {
+			img.Alignment = alignment
+		}
+	} else {
+		paragraph.Alignment = alignment
+	}
+	return w.AppendParagraph(paragraph)
+}
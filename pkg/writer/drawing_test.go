@@ -0,0 +1,131 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/word"
+)
+
+// pngWithDPI encodes a PNG picture with a pHYs chunk for the resolution
+func pngWithDPI(t *testing.T, width, height int, dpi float64) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	data := buf.Bytes()
+
+	chunk := make([]byte, 0, 21)
+	chunk = binary.BigEndian.AppendUint32(chunk, 9)
+	chunk = append(chunk, "pHYs"...)
+	chunk = binary.BigEndian.AppendUint32(chunk, uint32(dpi/0.0254+0.5))
+	chunk = binary.BigEndian.AppendUint32(chunk, uint32(dpi/0.0254+0.5))
+	chunk = append(chunk, 1)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	// pHYs必须位于IHDR之后
+	const afterIHDR = 8 + 25
+	return append(append(append([]byte{}, data[:afterIHDR]...), chunk...), data[afterIHDR:]...)
+}
+
+func TestDocumentWriterImages(t *testing.T) {
+	var logo bytes.Buffer
+	if err := jpeg.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 120, 60)), nil); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	svg := []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" width="2in" height="1in" viewBox="0 0 20 10"><rect width="20" height="10"/></svg>`)
+
+	writer := NewDocumentWriter()
+	writer.CreateNewDocument()
+	writer.AddParagraph("Invoice", "Normal")
+	if err := writer.AddImage(types.Image{Data: pngWithDPI(t, 200, 100, 192), AltText: "Chart"}, "center"); err != nil {
+		t.Fatalf("Failed to add image: %v", err)
+	}
+	if err := writer.AddImage(types.Image{
+		Data:     logo.Bytes(),
+		AltText:  "Company logo",
+		Wrapping: "tight",
+		Anchor:   &types.ImageAnchor{HorizontalRelative: "page", VerticalRelative: "margin", X: 36, Y: 18, Distance: 9},
+	}, ""); err != nil {
+		t.Fatalf("Failed to add logo: %v", err)
+	}
+	if err := writer.AddImage(types.Image{Data: logo.Bytes(), Width: 60, Wrapping: "behind", Anchor: &types.ImageAnchor{}}, "right"); err != nil {
+		t.Fatalf("Failed to add signature: %v", err)
+	}
+	if err := writer.AddImage(types.Image{Data: svg, AltText: "Stamp"}, ""); err != nil {
+		t.Fatalf("Failed to add SVG image: %v", err)
+	}
+	if err := writer.AddImage(types.Image{Data: []byte("not a picture")}, ""); err == nil {
+		t.Error("Expected an error for an unsupported image")
+	}
+
+	filename := filepath.Join(t.TempDir(), "images.docx")
+	if err := writer.Save(filename); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+
+	documentXML := readZipPart(t, filename, "word/document.xml")
+	// 192 DPI的200x100像素图片显示为100x50像素
+	for _, expected := range []string{
+		`<w:jc w:val="center">`,
+		`<wp:inline distT="0" distB="0" distL="0" distR="0"><wp:extent cx="952500" cy="476250"/>`,
+		`descr="Chart"`,
+		`distL="114300" distR="114300" simplePos="0" relativeHeight="251658242" behindDoc="0"`,
+		`<wp:positionH relativeFrom="page"><wp:posOffset>457200</wp:posOffset></wp:positionH>`,
+		`<wp:positionV relativeFrom="margin"><wp:posOffset>228600</wp:posOffset></wp:positionV>`,
+		`<wp:wrapTight wrapText="bothSides">`,
+		`behindDoc="1"`,
+		`<wp:positionH relativeFrom="column"><wp:align>right</wp:align></wp:positionH>`,
+		`<wp:extent cx="571500" cy="285750"/><wp:effectExtent l="0" t="0" r="0" b="0"/><wp:wrapNone/>`,
+		`<wp:extent cx="1828800" cy="914400"/>`,
+		`<a:blip r:embed="rId9"><a:extLst><a:ext uri="{96DAC541-7B7A-43D3-8B79-37D633B846F1}"><asvg:svgBlip xmlns:asvg="http://schemas.microsoft.com/office/drawing/2016/SVG/main" r:embed="rId10"/>`,
+	} {
+		if !strings.Contains(documentXML, expected) {
+			t.Errorf("Expected %s in the document", expected)
+		}
+	}
+	if !strings.Contains(readZipPart(t, filename, "[Content_Types].xml"), `<Default Extension="svg" ContentType="image/svg+xml"/>`) {
+		t.Error("Expected a content type for SVG pictures")
+	}
+	if fallback := readZipPart(t, filename, "word/media/image4.png"); !strings.HasPrefix(fallback, "\x89PNG") {
+		t.Error("Expected a PNG fallback of the SVG picture")
+	}
+
+	doc, err := word.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open saved document: %v", err)
+	}
+	defer doc.Close()
+	var images []*types.Image
+	for _, paragraph := range doc.GetMainPart().Content.Paragraphs {
+		for _, run := range paragraph.Runs {
+			if run.Image != nil {
+				images = append(images, run.Image)
+			}
+		}
+	}
+	if len(images) != 4 {
+		t.Fatalf("Expected 4 images, got %d", len(images))
+	}
+	if images[0].Position != types.ImagePositionInline || images[0].Width != 100 || images[0].AltText != "Chart" {
+		t.Errorf("Unexpected inline image: %+v", images[0])
+	}
+	if logo := images[1]; logo.Wrapping != "tight" || logo.Anchor == nil ||
+		*logo.Anchor != (types.ImageAnchor{HorizontalRelative: "page", VerticalRelative: "margin", X: 36, Y: 18, Distance: 9}) {
+		t.Errorf("Unexpected floating image: %+v", logo)
+	}
+	if signature := images[2]; signature.Wrapping != "behind" || signature.Alignment != "right" {
+		t.Errorf("Unexpected image behind text: %+v", signature)
+	}
+	if stamp := images[3]; stamp.Path != "word/media/image5.svg" {
+		t.Errorf("Expected the SVG picture to be read, got %s", stamp.Path)
+	}
+}
@@ -0,0 +1,381 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/word"
+)
+
+// Content types of the header and footer parts
+const (
+	headerContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"
+	footerContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"
+)
+
+// headerFooterKinds are the kinds of headers and footers of a section in
+// the order their parts are numbered
+var headerFooterKinds = []string{"default", "first", "even"}
+
+// headerFooter is a header or footer set for a section
+type headerFooter struct {
+	section int
+	kind    string
+	footer  bool
+	content *types.DocumentContent
+}
+
+// headerFooterPart is a header or footer part generated on save
+type headerFooterPart struct {
+	headerFooter
+	Name  string
+	RelID string
+	Data  []byte
+	// Relationships are the relationships of the part itself, such as
+	// its pictures and hyperlinks
+	Relationships []packageRelationship
+}
+
+// SetHeader sets the header of the section at index. kind is "default"
+// for all pages, "first" for the first page of the section or "even" for
+// even pages. A first page header turns on the title page of the section,
+// and an even page header the different odd and even pages of the
+// document. Runs with a Field, such as "PAGE", "NUMPAGES" or
+// "DATE \@ \"yyyy-MM-dd\"", become fields that Word updates.
+func (w *DocumentWriter) SetHeader(section int, kind string, content *types.DocumentContent) error {
+	return w.setHeaderFooter(section, kind, false, content)
+}
+
+// SetFooter sets the footer of the section at index, like SetHeader
+func (w *DocumentWriter) SetFooter(section int, kind string, content *types.DocumentContent) error {
+	return w.setHeaderFooter(section, kind, true, content)
+}
+
+// AddHeaderFooter sets a header or footer created by the AdvancedFormatter
+// for the section at index
+func (w *DocumentWriter) AddHeaderFooter(section int, headerFooter *word.HeaderFooter) error {
+	content := &types.DocumentContent{Paragraphs: headerFooter.Content}
+	switch headerFooter.Type {
+	case word.HeaderType:
+		return w.SetHeader(section, "default", content)
+	case word.FirstHeaderType:
+		return w.SetHeader(section, "first", content)
+	case word.EvenHeaderType:
+		return w.SetHeader(section, "even", content)
+	case word.FooterType:
+		return w.SetFooter(section, "default", content)
+	case word.FirstFooterType:
+		return w.SetFooter(section, "first", content)
+	case word.EvenFooterType:
+		return w.SetFooter(section, "even", content)
+	default:
+		return fmt.Errorf("unsupported header or footer type: %v", headerFooter.Type)
+	}
+}
+
+// setHeaderFooter replaces the header or footer of a kind of a section
+func (w *DocumentWriter) setHeaderFooter(section int, kind string, footer bool, content *types.DocumentContent) error {
+	if w.Document == nil || w.Document.GetMainPart() == nil {
+		return fmt.Errorf("document not initialized")
+	}
+	if w.patch != nil {
+		return fmt.Errorf("headers and footers cannot be added when patching")
+	}
+	if headerFooterKindRank(kind) < 0 {
+		return fmt.Errorf("invalid header or footer kind: %s", kind)
+	}
+	if content == nil {
+		return fmt.Errorf("header or footer content is nil")
+	}
+
+	sections := w.sections()
+	if section < 0 || section >= len(sections) {
+		return fmt.Errorf("section index out of range")
+	}
+	if kind == "first" {
+		sections[section].TitlePage = true
+	}
+
+	entry := headerFooter{section: section, kind: kind, footer: footer, content: content}
+	for i, existing := range w.headerFooters {
+		if existing.section == section && existing.kind == kind && existing.footer == footer {
+			w.headerFooters[i] = entry
+			return nil
+		}
+	}
+	w.headerFooters = append(w.headerFooters, entry)
+	return nil
+}
+
+// AddSectionBreak ends the current section after the last paragraph. The
+// paragraphs added afterwards belong to a new section that starts as kind:
+// "nextPage", "continuous", "evenPage" or "oddPage".
+func (w *DocumentWriter) AddSectionBreak(kind string) error {
+	if w.Document == nil || w.Document.GetMainPart() == nil {
+		return fmt.Errorf("document not initialized")
+	}
+	switch kind {
+	case "nextPage", "continuous", "evenPage", "oddPage":
+	default:
+		return fmt.Errorf("invalid section break: %s", kind)
+	}
+
+	content := w.Document.GetMainPart().Content
+	if len(content.Paragraphs) == 0 {
+		return fmt.Errorf("a section needs at least one paragraph")
+	}
+	sections := w.sections()
+	last := &sections[len(sections)-1]
+	if len(sections) > 1 && sections[len(sections)-2].End >= len(content.Paragraphs)-1 {
+		return fmt.Errorf("the current section has no paragraphs")
+	}
+	last.End = len(content.Paragraphs) - 1
+	content.Sections = append(sections, types.Section{End: last.End, Break: kind})
+	return nil
+}
+
+// sections returns the sections of the document, creating a single
+// section when the content has none
+func (w *DocumentWriter) sections() []types.Section {
+	content := w.Document.GetMainPart().Content
+	if len(content.Sections) == 0 {
+		content.Sections = []types.Section{{End: len(content.Paragraphs) - 1}}
+	}
+	return content.Sections
+}
+
+// headerFooterKindRank returns the position of a kind in
+// headerFooterKinds, or -1 for an unknown kind
+func headerFooterKindRank(kind string) int {
+	for i, k := range headerFooterKinds {
+		if k == kind {
+			return i
+		}
+	}
+	return -1
+}
+
+// buildHeaderFooterParts generates the header and footer parts and relates
+// them to the main document part. Pictures and hyperlinks of a header or
+// footer are related to its own part.
+func (w *DocumentWriter) buildHeaderFooterParts() error {
+	entries := append([]headerFooter(nil), w.headerFooters...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].section != entries[j].section {
+			return entries[i].section < entries[j].section
+		}
+		return headerFooterKindRank(entries[i].kind) < headerFooterKindRank(entries[j].kind)
+	})
+
+	headers, footers := 0, 0
+	for _, entry := range entries {
+		name, root, relType := "", "w:hdr", headerRelationshipType
+		if entry.footer {
+			footers++
+			name, root, relType = fmt.Sprintf("footer%d.xml", footers), "w:ftr", footerRelationshipType
+		} else {
+			headers++
+			name = fmt.Sprintf("header%d.xml", headers)
+		}
+
+		// 页眉页脚的关系属于各自的部件
+		documentRelationships := w.relationships
+		w.relationships = nil
+		data, err := w.generateHeaderFooterXML(root, entry.content)
+		partRelationships := w.relationships
+		w.relationships = documentRelationships
+		if err != nil {
+			return fmt.Errorf("failed to generate %s: %w", name, err)
+		}
+
+		w.headerFooterParts = append(w.headerFooterParts, &headerFooterPart{
+			headerFooter:  entry,
+			Name:          "word/" + name,
+			RelID:         w.addRelationship(relType, name, false),
+			Data:          data,
+			Relationships: partRelationships,
+		})
+	}
+	return nil
+}
+
+// generateHeaderFooterXML generates the XML of a header (w:hdr) or footer
+// (w:ftr) part
+func (w *DocumentWriter) generateHeaderFooterXML(root string, content *types.DocumentContent) ([]byte, error) {
+	part := &HeaderFooterXML{
+		XMLName: xml.Name{Local: root},
+		XMLNS:   "http://schemas.openxmlformats.org/wordprocessingml/2006/main",
+		XMLNSR:  "http://schemas.openxmlformats.org/officeDocument/2006/relationships",
+		XMLNSWP: "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing",
+	}
+
+	paragraphs := content.Paragraphs
+	tablesAt := make(map[int][]types.Table)
+	for _, table := range content.Tables {
+		position := table.Position
+		if position < 0 || position > len(paragraphs) {
+			position = len(paragraphs)
+		}
+		tablesAt[position] = append(tablesAt[position], table)
+	}
+	for i := 0; i <= len(paragraphs); i++ {
+		for _, table := range tablesAt[i] {
+			xmlTable, err := w.buildTableXML(table)
+			if err != nil {
+				return nil, err
+			}
+			part.Content = append(part.Content, xmlTable)
+		}
+		if i == len(paragraphs) {
+			break
+		}
+		xmlParagraph, err := w.buildParagraphXML(paragraphs[i])
+		if err != nil {
+			return nil, err
+		}
+		part.Content = append(part.Content, xmlParagraph)
+	}
+
+	// 页眉页脚至少需要一个段落
+	if len(paragraphs) == 0 || len(tablesAt[len(paragraphs)]) > 0 {
+		xmlParagraph, err := w.buildParagraphXML(types.Paragraph{})
+		if err != nil {
+			return nil, err
+		}
+		part.Content = append(part.Content, xmlParagraph)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	buf.WriteString("\n")
+	if err := xml.NewEncoder(&buf).Encode(part); err != nil {
+		return nil, fmt.Errorf("failed to encode header or footer XML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// generatePartRelationshipsXML renders the relationships part of a header
+// or footer
+func generatePartRelationshipsXML(relationships []packageRelationship) []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		relationshipsXML(relationships) + `
+</Relationships>`)
+}
+
+// usesEvenHeaders reports whether a section has an even page header or
+// footer, which needs evenAndOddHeaders in the settings
+func (w *DocumentWriter) usesEvenHeaders() bool {
+	for _, entry := range w.headerFooters {
+		if entry.kind == "even" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSectionPropertiesXML converts a section of the document model to
+// its XML form, with the references to the headers and footers of the
+// section
+func (w *DocumentWriter) buildSectionPropertiesXML(index int, section types.Section) *SectionPropertiesXML {
+	sectPr := &SectionPropertiesXML{
+		XMLName: xml.Name{Local: "w:sectPr"},
+		PageSize: &PageSizeXML{
+			XMLName: xml.Name{Local: "w:pgSz"},
+			Width:   "11906",
+			Height:  "16838",
+		},
+		PageMargins: &PageMarginsXML{
+			XMLName: xml.Name{Local: "w:pgMar"},
+			Top:     "1440",
+			Right:   "1800",
+			Bottom:  "1440",
+			Left:    "1800",
+			Header:  "851",
+			Footer:  "992",
+			Gutter:  "0",
+		},
+		Columns: &ColumnsXML{
+			XMLName: xml.Name{Local: "w:cols"},
+			Space:   "425",
+			Number:  "1",
+		},
+		DocumentGrid: &DocumentGridXML{
+			XMLName:   xml.Name{Local: "w:docGrid"},
+			Type:      "lines",
+			LinePitch: "312",
+			CharSpace: "0",
+		},
+	}
+
+	for _, part := range w.headerFooterParts {
+		if part.section != index {
+			continue
+		}
+		if part.footer {
+			sectPr.FooterReferences = append(sectPr.FooterReferences, HeaderFooterReferenceXML{
+				XMLName: xml.Name{Local: "w:footerReference"}, Type: part.kind, ID: part.RelID,
+			})
+		} else {
+			sectPr.HeaderReferences = append(sectPr.HeaderReferences, HeaderFooterReferenceXML{
+				XMLName: xml.Name{Local: "w:headerReference"}, Type: part.kind, ID: part.RelID,
+			})
+		}
+	}
+
+	if section.Break != "" {
+		sectPr.Type = &StringValXML{XMLName: xml.Name{Local: "w:type"}, Val: section.Break}
+	}
+	if section.PageWidth > 0 && section.PageHeight > 0 {
+		sectPr.PageSize.Width, sectPr.PageSize.Height = twips(section.PageWidth), twips(section.PageHeight)
+	}
+	if margins := section.Margins; margins != nil {
+		sectPr.PageMargins.Top, sectPr.PageMargins.Right = twips(margins.Top), twips(margins.Right)
+		sectPr.PageMargins.Bottom, sectPr.PageMargins.Left = twips(margins.Bottom), twips(margins.Left)
+	}
+	if section.HeaderDistance > 0 {
+		sectPr.PageMargins.Header = twips(section.HeaderDistance)
+	}
+	if section.FooterDistance > 0 {
+		sectPr.PageMargins.Footer = twips(section.FooterDistance)
+	}
+	if section.PageNumberFormat != "" || section.PageNumberStart > 0 {
+		sectPr.PageNumbering = &PageNumberingXML{XMLName: xml.Name{Local: "w:pgNumType"}, Format: section.PageNumberFormat}
+		if section.PageNumberStart > 0 {
+			sectPr.PageNumbering.Start = strconv.Itoa(section.PageNumberStart)
+		}
+	}
+	if section.Columns > 1 {
+		sectPr.Columns.Number = strconv.Itoa(section.Columns)
+		if section.ColumnSpace > 0 {
+			sectPr.Columns.Space = twips(section.ColumnSpace)
+		}
+	}
+	if section.TitlePage {
+		sectPr.TitlePage = &OnOffXML{XMLName: xml.Name{Local: "w:titlePg"}}
+	}
+	if section.TextDirection != "" {
+		sectPr.TextDirection = &StringValXML{XMLName: xml.Name{Local: "w:textDirection"}, Val: section.TextDirection}
+	}
+	return sectPr
+}
+
+// twips converts points to twentieths of a point
+func twips(points float64) string {
+	return strconv.Itoa(int(math.Round(points * 20)))
+}
+
+// HeaderFooterXML represents a header (w:hdr) or footer (w:ftr) part
+type HeaderFooterXML struct {
+	XMLName xml.Name
+	XMLNS   string `xml:"xmlns:w,attr"`
+	XMLNSR  string `xml:"xmlns:r,attr"`
+	XMLNSWP string `xml:"xmlns:wp,attr"`
+	// Content holds paragraphs and tables in order
+	Content []interface{} `xml:",any"`
+}
@@ -0,0 +1,124 @@
+package writer
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tanqiangyes/go-word/pkg/parser"
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/word"
+)
+
+func TestDocumentWriterHeadersAndFooters(t *testing.T) {
+	var logo bytes.Buffer
+	if err := png.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+
+	writer := NewDocumentWriter()
+	writer.CreateNewDocument()
+	writer.AddParagraph("Cover", "Normal")
+	if err := writer.AddSectionBreak("sideways"); err == nil {
+		t.Error("Expected an error for an invalid section break")
+	}
+	if err := writer.AddSectionBreak("oddPage"); err != nil {
+		t.Fatalf("Failed to add section break: %v", err)
+	}
+	if err := writer.AddSectionBreak("nextPage"); err == nil {
+		t.Error("Expected an error for an empty section")
+	}
+	writer.AddParagraph("Body", "Normal")
+	writer.AppendParagraph(types.Paragraph{Runs: []types.Run{{Image: &types.Image{Data: logo.Bytes(), AltText: "Logo"}}}})
+
+	header := &types.DocumentContent{
+		Paragraphs: []types.Paragraph{{Runs: []types.Run{{Image: &types.Image{Data: logo.Bytes()}}, {Text: " Annual report"}}}},
+		Tables:     []types.Table{{Position: 1, Rows: []types.TableRow{{Cells: []types.TableCell{{Text: "Left"}, {Text: "Right"}}}}}},
+	}
+	footer := &types.DocumentContent{Paragraphs: []types.Paragraph{{
+		Alignment: "center",
+		Runs: []types.Run{
+			{Text: "Page "}, {Text: "1", Field: "PAGE"}, {Text: " of "}, {Text: "1", Field: "NUMPAGES"},
+			{Text: " printed "}, {Field: `DATE \@ "yyyy-MM-dd"`},
+		},
+	}}}
+	if err := writer.SetHeader(0, "first", header); err != nil {
+		t.Fatalf("Failed to set header: %v", err)
+	}
+	if err := writer.SetFooter(1, "default", footer); err != nil {
+		t.Fatalf("Failed to set footer: %v", err)
+	}
+	if err := writer.SetFooter(1, "even", footer); err != nil {
+		t.Fatalf("Failed to set footer: %v", err)
+	}
+	if err := writer.AddHeaderFooter(1, word.NewAdvancedFormatter(writer.Document).CreateHeader(word.HeaderType)); err != nil {
+		t.Fatalf("Failed to add header: %v", err)
+	}
+	if err := writer.SetHeader(2, "default", header); err == nil {
+		t.Error("Expected an error for a section index out of range")
+	}
+	if err := writer.SetHeader(0, "odd", header); err == nil {
+		t.Error("Expected an error for an invalid kind")
+	}
+
+	filename := filepath.Join(t.TempDir(), "headers.docx")
+	if err := writer.Save(filename); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+
+	// 页眉中的图片关系属于页眉部件
+	if rels := readZipPart(t, filename, "word/_rels/header1.xml.rels"); !strings.Contains(rels, `Target="media/image1.png"`) {
+		t.Error("Expected the header picture to be related to the header part")
+	}
+	documentRels := readZipPart(t, filename, "word/_rels/document.xml.rels")
+	for _, target := range []string{"header1.xml", "header2.xml", "footer1.xml", "footer2.xml", "media/image2.png"} {
+		if !strings.Contains(documentRels, `Target="`+target+`"`) {
+			t.Errorf("Expected a relationship to %s", target)
+		}
+	}
+	contentTypes := readZipPart(t, filename, "[Content_Types].xml")
+	if !strings.Contains(contentTypes, `<Override PartName="/word/header2.xml" ContentType="`+headerContentType+`"/>`) ||
+		!strings.Contains(contentTypes, `<Override PartName="/word/footer2.xml" ContentType="`+footerContentType+`"/>`) {
+		t.Error("Expected content types of the header and footer parts")
+	}
+	if settings := readZipPart(t, filename, "word/settings.xml"); !strings.Contains(settings, "<w:evenAndOddHeaders/>") {
+		t.Error("Expected evenAndOddHeaders in the settings")
+	}
+	footerXML := readZipPart(t, filename, "word/footer1.xml")
+	if !strings.Contains(footerXML, `<w:fldSimple w:instr=" PAGE "><w:r><w:t>1</w:t></w:r></w:fldSimple>`) ||
+		!strings.Contains(footerXML, `<w:fldSimple w:instr=" DATE \@ &#34;yyyy-MM-dd&#34; ">`) {
+		t.Errorf("Expected simple fields in the footer: %s", footerXML)
+	}
+
+	content, err := parser.ParseHeaderFooter([]byte(footerXML), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse footer: %v", err)
+	}
+	if runs := content.Paragraphs[0].Runs; len(runs) != 6 || runs[1].Field != "PAGE" || runs[3].Field != "NUMPAGES" || runs[5].Field != `DATE \@ "yyyy-MM-dd"` {
+		t.Errorf("Unexpected footer runs: %+v", runs)
+	}
+	headerXML := readZipPart(t, filename, "word/header1.xml")
+	if !strings.Contains(headerXML, "<w:tbl>") || !strings.HasSuffix(strings.TrimSpace(headerXML), "<w:p><w:pPr><w:pStyle w:val=\"Normal\"></w:pStyle></w:pPr></w:p></w:hdr>") {
+		t.Error("Expected the header table to be followed by a paragraph")
+	}
+
+	doc, err := word.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open saved document: %v", err)
+	}
+	defer doc.Close()
+	sections := doc.GetMainPart().Content.Sections
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(sections))
+	}
+	if first := sections[0]; first.End != 0 || !first.TitlePage || first.Headers["first"] != "word/header1.xml" {
+		t.Errorf("Unexpected first section: %+v", first)
+	}
+	if second := sections[1]; second.Break != "oddPage" || second.TitlePage || second.Headers["default"] != "word/header2.xml" ||
+		second.Footers["default"] != "word/footer1.xml" || second.Footers["even"] != "word/footer2.xml" {
+		t.Errorf("Unexpected second section: %+v", second)
+	}
+}
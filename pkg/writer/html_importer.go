@@ -0,0 +1,888 @@
+package writer
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+// HTMLImporter converts a sanitized subset of HTML into paragraphs, lists,
+// tables, hyperlinks and images of a document created by a DocumentWriter.
+// Scripts, styles, forms and embedded objects are dropped; unknown elements
+// are treated as transparent containers.
+type HTMLImporter struct {
+	Writer *DocumentWriter
+	// BaseDir is used to resolve relative image paths
+	BaseDir string
+	Logger  *utils.Logger
+}
+
+// htmlBlock is the paragraph being collected while walking the tree
+type htmlBlock struct {
+	runs      []types.Run
+	style     string
+	alignment string
+
+	// 列表项的第一个段落带编号，其余段落只缩进
+	numID     int
+	level     int
+	numbered  bool
+	listLevel int
+	// inCell is set when the paragraphs belong to a table cell
+	inCell bool
+	emit   func(types.Paragraph) error
+}
+
+// htmlTableCell is a cell with its spans while converting a table
+type htmlTableCell struct {
+	cell    types.TableCell
+	colSpan int
+	rowSpan int
+}
+
+// htmlDroppedElements are skipped together with their content
+var htmlDroppedElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Form: true, atom.Input: true, atom.Button: true, atom.Select: true,
+	atom.Textarea: true, atom.Svg: true, atom.Math: true, atom.Canvas: true,
+	atom.Audio: true, atom.Video: true,
+}
+
+// htmlBlockElements start a new paragraph
+var htmlBlockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Header: true, atom.Footer: true, atom.Main: true, atom.Nav: true,
+	atom.Aside: true, atom.Figure: true, atom.Figcaption: true, atom.Address: true,
+	atom.Center: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Caption: true, atom.Details: true, atom.Summary: true,
+}
+
+// htmlNamedColors maps common CSS color names to RGB values
+var htmlNamedColors = map[string]string{
+	"black": "000000", "white": "FFFFFF", "red": "FF0000", "green": "008000",
+	"blue": "0000FF", "yellow": "FFFF00", "gray": "808080", "grey": "808080",
+	"orange": "FFA500", "purple": "800080", "navy": "000080", "maroon": "800000",
+	"teal": "008080", "silver": "C0C0C0", "olive": "808000", "lime": "00FF00",
+	"aqua": "00FFFF", "fuchsia": "FF00FF", "darkgray": "A9A9A9", "darkgrey": "A9A9A9",
+	"darkred": "8B0000", "darkgreen": "006400", "darkblue": "00008B",
+}
+
+// NewHTMLImporter creates an HTML importer that appends to the writer's document
+func NewHTMLImporter(writer *DocumentWriter, baseDir string) *HTMLImporter {
+	return &HTMLImporter{
+		Writer:  writer,
+		BaseDir: baseDir,
+		Logger:  utils.NewLogger(utils.LogLevelInfo, nil),
+	}
+}
+
+// ImportHTMLFile creates a new document from an HTML file. Relative image
+// paths are resolved against the directory of the file.
+func (w *DocumentWriter) ImportHTMLFile(filename string) error {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read html file: %w", err)
+	}
+
+	if w.Document == nil {
+		if err := w.CreateNewDocument(); err != nil {
+			return err
+		}
+	}
+
+	return NewHTMLImporter(w, filepath.Dir(filename)).Import(source)
+}
+
+// Import parses the HTML source and appends its content to the document
+func (hi *HTMLImporter) Import(source []byte) error {
+	if hi.Writer == nil || hi.Writer.Document == nil {
+		return fmt.Errorf("document not initialized")
+	}
+
+	root, err := html.Parse(bytes.NewReader(source))
+	if err != nil {
+		return fmt.Errorf("failed to parse html: %w", err)
+	}
+
+	body := findHTMLElement(root, atom.Body)
+	if body == nil {
+		body = root
+	}
+
+	block := &htmlBlock{emit: hi.Writer.AppendParagraph}
+	if err := hi.convertChildren(body, runFormat{}, block); err != nil {
+		return err
+	}
+	return hi.flush(block)
+}
+
+// findHTMLElement returns the first element of the given type
+func findHTMLElement(node *html.Node, a atom.Atom) *html.Node {
+	if node.Type == html.ElementNode && node.DataAtom == a {
+		return node
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if found := findHTMLElement(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// convertChildren converts the children of a node
+func (hi *HTMLImporter) convertChildren(node *html.Node, format runFormat, block *htmlBlock) error {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if err := hi.convertNode(child, format, block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertNode converts a single node into runs or blocks
+func (hi *HTMLImporter) convertNode(node *html.Node, format runFormat, block *htmlBlock) error {
+	switch node.Type {
+	case html.TextNode:
+		if text := collapseWhitespace(node.Data); text != "" {
+			block.runs = append(block.runs, newRun(text, format))
+		}
+		return nil
+	case html.ElementNode:
+	default:
+		return nil
+	}
+
+	if htmlDroppedElements[node.DataAtom] {
+		return nil
+	}
+
+	css := parseInlineCSS(htmlAttr(node, "style"))
+	format = applyCSSFormat(format, css)
+
+	switch node.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(node.Data[1] - '0')
+		return hi.convertBlock(node, format, block, fmt.Sprintf("Heading%d", level), css)
+
+	case atom.Blockquote:
+		return hi.convertBlock(node, format, block, "Quote", css)
+
+	case atom.Pre:
+		if err := hi.flush(block); err != nil {
+			return err
+		}
+		return hi.convertPre(node, format, block)
+
+	case atom.Hr:
+		if err := hi.flush(block); err != nil {
+			return err
+		}
+		return block.emit(newParagraph("HorizontalLine", "", nil))
+
+	case atom.Br:
+		block.runs = append(block.runs, newRun("\n", format))
+		return nil
+
+	case atom.Ul, atom.Ol:
+		if err := hi.flush(block); err != nil {
+			return err
+		}
+		return hi.convertList(node, format, block)
+
+	case atom.Table:
+		if err := hi.flush(block); err != nil {
+			return err
+		}
+		return hi.convertTable(node, format, block)
+
+	case atom.Img:
+		if run, ok := hi.convertImage(node, format); ok {
+			block.runs = append(block.runs, run)
+		}
+		return nil
+
+	case atom.A:
+		href := strings.TrimSpace(htmlAttr(node, "href"))
+		if isSafeHyperlink(href) {
+			format.Hyperlink = href
+		}
+		return hi.convertChildren(node, format, block)
+
+	case atom.Strong, atom.B:
+		format.Bold = true
+	case atom.Em, atom.I, atom.Cite, atom.Var, atom.Dfn:
+		format.Italic = true
+	case atom.U, atom.Ins:
+		format.Underline = true
+	case atom.S, atom.Strike, atom.Del:
+		format.Strike = true
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		format.Code = true
+	case atom.Font:
+		if color := parseCSSColor(htmlAttr(node, "color")); color != "" {
+			format.Color = color
+		}
+		if face := parseCSSFontFamily(htmlAttr(node, "face")); face != "" {
+			format.FontName = face
+		}
+	}
+
+	if htmlBlockElements[node.DataAtom] {
+		return hi.convertBlock(node, format, block, block.style, css)
+	}
+
+	return hi.convertChildren(node, format, block)
+}
+
+// convertBlock converts a block element into its own paragraph
+func (hi *HTMLImporter) convertBlock(node *html.Node, format runFormat, block *htmlBlock, style string, css map[string]string) error {
+	if err := hi.flush(block); err != nil {
+		return err
+	}
+
+	savedStyle, savedAlignment := block.style, block.alignment
+	block.style = style
+	if alignment := htmlAlignment(node, css); alignment != "" {
+		block.alignment = alignment
+	}
+
+	if err := hi.convertChildren(node, format, block); err != nil {
+		return err
+	}
+	if err := hi.flush(block); err != nil {
+		return err
+	}
+
+	block.style, block.alignment = savedStyle, savedAlignment
+	return nil
+}
+
+// flush emits the collected runs as a paragraph
+func (hi *HTMLImporter) flush(block *htmlBlock) error {
+	runs := trimRuns(mergeRuns(block.runs))
+	block.runs = nil
+	if len(runs) == 0 {
+		return nil
+	}
+
+	paragraph := newParagraph(block.style, block.alignment, runs)
+	if block.numID > 0 {
+		if !block.numbered {
+			paragraph.Style = "ListParagraph"
+			paragraph.NumID = block.numID
+			paragraph.ListLevel = block.level
+			block.numbered = true
+		} else if paragraph.Style == "" {
+			paragraph.Style = "ListParagraph"
+		}
+	}
+	if paragraph.Style == "" {
+		paragraph.Style = "Normal"
+	}
+
+	return block.emit(paragraph)
+}
+
+// convertPre adds one SourceCode paragraph per line of preformatted text
+func (hi *HTMLImporter) convertPre(node *html.Node, format runFormat, block *htmlBlock) error {
+	var text strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			text.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.DataAtom == atom.Br:
+			text.WriteString("\n")
+		case n.Type == html.ElementNode && htmlDroppedElements[n.DataAtom]:
+		default:
+			for child := n.FirstChild; child != nil; child = child.NextSibling {
+				collect(child)
+			}
+		}
+	}
+	collect(node)
+
+	content := strings.TrimSuffix(strings.ReplaceAll(text.String(), "\r\n", "\n"), "\n")
+	for _, line := range strings.Split(content, "\n") {
+		if err := block.emit(newParagraph("SourceCode", "", []types.Run{newRun(line, runFormat{Color: format.Color})})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertList converts ul and ol elements, including nested lists
+func (hi *HTMLImporter) convertList(node *html.Node, format runFormat, parent *htmlBlock) error {
+	kind := BulletList
+	start := 1
+	if node.DataAtom == atom.Ol {
+		kind = DecimalList
+		if value, err := strconv.Atoi(htmlAttr(node, "start")); err == nil {
+			start = value
+		}
+		switch htmlAttr(node, "type") {
+		case "a":
+			kind = LowerLetterList
+		case "A":
+			kind = UpperLetterList
+		case "i":
+			kind = LowerRomanList
+		case "I":
+			kind = UpperRomanList
+		}
+	}
+	numID, err := hi.Writer.NewList(kind, start)
+	if err != nil {
+		return err
+	}
+
+	level := 0
+	if parent.numID > 0 {
+		level = parent.listLevel + 1
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li {
+			// 非li内容按普通内容处理
+			if err := hi.convertNode(child, format, parent); err != nil {
+				return err
+			}
+			continue
+		}
+
+		item := &htmlBlock{
+			emit:      parent.emit,
+			numID:     numID,
+			level:     level,
+			listLevel: level,
+		}
+		itemFormat := applyCSSFormat(format, parseInlineCSS(htmlAttr(child, "style")))
+		if err := hi.convertChildren(child, itemFormat, item); err != nil {
+			return err
+		}
+		if err := hi.flush(item); err != nil {
+			return err
+		}
+		if !item.numbered {
+			// 空列表项也保留编号
+			if err := parent.emit(types.Paragraph{Style: "ListParagraph", NumID: numID, ListLevel: level}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// convertTable converts a table, expanding rowspan into vertically merged cells
+func (hi *HTMLImporter) convertTable(node *html.Node, format runFormat, block *htmlBlock) error {
+	var rows [][]htmlTableCell
+	var headers []bool
+
+	var collectRows func(n *html.Node, header bool)
+	collectRows = func(n *html.Node, header bool) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Thead:
+				collectRows(child, true)
+			case atom.Tbody, atom.Tfoot:
+				collectRows(child, false)
+			case atom.Tr:
+				row, allHeader, err := hi.convertTableRow(child, format)
+				if err != nil {
+					hi.Logger.Warning("无法转换表格行: %v", err)
+					continue
+				}
+				rows = append(rows, row)
+				headers = append(headers, header || (allHeader && len(rows) == 1))
+			}
+		}
+	}
+	collectRows(node, false)
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	table := types.Table{}
+	// pending records columns covered by a rowspan from a previous row
+	type pendingSpan struct{ rows, colSpan int }
+	pending := make(map[int]*pendingSpan)
+
+	for r, cells := range rows {
+		tableRow := types.TableRow{Header: headers[r] && (r == 0 || headers[r-1])}
+		column := 0
+
+		fillPending := func(limit int) {
+			for column < limit {
+				span, ok := pending[column]
+				if !ok || span.rows == 0 {
+					return
+				}
+				tableRow.Cells = append(tableRow.Cells, types.TableCell{ColSpan: span.colSpan, VMerge: "continue"})
+				span.rows--
+				column += max(span.colSpan, 1)
+			}
+		}
+
+		for _, cell := range cells {
+			fillPending(math.MaxInt)
+			if cell.rowSpan > 1 {
+				cell.cell.VMerge = "restart"
+				pending[column] = &pendingSpan{rows: cell.rowSpan - 1, colSpan: cell.colSpan}
+			}
+			if cell.colSpan > 1 {
+				cell.cell.ColSpan = cell.colSpan
+			}
+			tableRow.Cells = append(tableRow.Cells, cell.cell)
+			column += cell.colSpan
+		}
+
+		// 行尾仍被上方单元格覆盖的列
+		for {
+			before := column
+			fillPending(math.MaxInt)
+			next := -1
+			for c, span := range pending {
+				if c > column && span.rows > 0 && (next < 0 || c < next) {
+					next = c
+				}
+			}
+			if next < 0 {
+				break
+			}
+			for column < next {
+				tableRow.Cells = append(tableRow.Cells, types.TableCell{})
+				column++
+			}
+			if column == before {
+				break
+			}
+		}
+
+		if column > table.Columns {
+			table.Columns = column
+		}
+		table.Rows = append(table.Rows, tableRow)
+	}
+
+	if block.numID > 0 || block.inCell {
+		// 嵌套表格无法放入单元格段落，按行输出文本
+		for _, row := range table.Rows {
+			var texts []string
+			for _, cell := range row.Cells {
+				if cell.VMerge != "continue" {
+					texts = append(texts, cell.Text)
+				}
+			}
+			if err := block.emit(newParagraph("Normal", "", []types.Run{{Text: strings.Join(texts, "\t")}})); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return hi.Writer.AppendTable(table)
+}
+
+// convertTableRow converts the cells of a tr element
+func (hi *HTMLImporter) convertTableRow(node *html.Node, format runFormat) ([]htmlTableCell, bool, error) {
+	var cells []htmlTableCell
+	allHeader := true
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || (child.DataAtom != atom.Td && child.DataAtom != atom.Th) {
+			continue
+		}
+		header := child.DataAtom == atom.Th
+		if !header {
+			allHeader = false
+		}
+
+		cellFormat := format
+		if header {
+			cellFormat.Bold = true
+		}
+		css := parseInlineCSS(htmlAttr(child, "style"))
+		cellFormat = applyCSSFormat(cellFormat, css)
+
+		var paragraphs []types.Paragraph
+		block := &htmlBlock{
+			alignment: htmlAlignment(child, css),
+			inCell:    true,
+			emit: func(p types.Paragraph) error {
+				paragraphs = append(paragraphs, p)
+				return nil
+			},
+		}
+		if err := hi.convertChildren(child, cellFormat, block); err != nil {
+			return nil, false, err
+		}
+		if err := hi.flush(block); err != nil {
+			return nil, false, err
+		}
+
+		var texts []string
+		for _, p := range paragraphs {
+			texts = append(texts, p.Text)
+		}
+
+		cells = append(cells, htmlTableCell{
+			cell: types.TableCell{
+				Text:       strings.Join(texts, "\n"),
+				Paragraphs: paragraphs,
+			},
+			colSpan: htmlSpan(child, "colspan"),
+			rowSpan: htmlSpan(child, "rowspan"),
+		})
+	}
+
+	return cells, allHeader && len(cells) > 0, nil
+}
+
+// convertImage embeds local and data URI images. Remote images are kept as
+// a hyperlink with the alternative text because they cannot be embedded offline.
+func (hi *HTMLImporter) convertImage(node *html.Node, format runFormat) (types.Run, bool) {
+	src := strings.TrimSpace(htmlAttr(node, "src"))
+	alt := htmlAttr(node, "alt")
+
+	image := &types.Image{
+		AltText: alt,
+		Title:   htmlAttr(node, "title"),
+	}
+
+	css := parseInlineCSS(htmlAttr(node, "style"))
+	image.Width = parseCSSPixels(htmlAttr(node, "width"), css["width"])
+	image.Height = parseCSSPixels(htmlAttr(node, "height"), css["height"])
+
+	switch {
+	case src == "":
+		return types.Run{}, false
+
+	case strings.HasPrefix(src, "data:"):
+		data, err := decodeDataURI(src)
+		if err != nil {
+			hi.Logger.Warning("无法解码图片数据: %v", err)
+			return newRun(alt, format), alt != ""
+		}
+		image.Data = data
+
+	case isRemoteURL(src):
+		format.Hyperlink = src
+		return newRun(alt, format), alt != ""
+
+	default:
+		path := strings.TrimPrefix(src, "file://")
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(hi.BaseDir, filepath.FromSlash(path))
+		}
+		if _, err := os.Stat(path); err != nil {
+			hi.Logger.Warning("找不到图片文件: %s", path)
+			return newRun(alt, format), alt != ""
+		}
+		image.Path = path
+	}
+
+	run := newRun("", format)
+	run.Image = image
+	return run, true
+}
+
+// htmlAttr returns the value of an attribute, or an empty string
+func htmlAttr(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if attr.Namespace == "" && strings.EqualFold(attr.Key, name) {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// htmlSpan returns a colspan or rowspan value, at least 1
+func htmlSpan(node *html.Node, name string) int {
+	if span, err := strconv.Atoi(strings.TrimSpace(htmlAttr(node, name))); err == nil && span > 1 {
+		// Word支持最多63列
+		return min(span, 63)
+	}
+	return 1
+}
+
+// htmlAlignment returns the paragraph justification of an element
+func htmlAlignment(node *html.Node, css map[string]string) string {
+	alignment := css["text-align"]
+	if alignment == "" {
+		alignment = strings.ToLower(htmlAttr(node, "align"))
+	}
+	if node.DataAtom == atom.Center {
+		alignment = "center"
+	}
+
+	switch alignment {
+	case "left", "start":
+		return "left"
+	case "center":
+		return "center"
+	case "right", "end":
+		return "right"
+	case "justify":
+		return "both"
+	default:
+		return ""
+	}
+}
+
+// isSafeHyperlink rejects script and empty hyperlink targets
+func isSafeHyperlink(href string) bool {
+	if href == "" {
+		return false
+	}
+	lower := strings.ToLower(href)
+	return !strings.HasPrefix(lower, "javascript:") && !strings.HasPrefix(lower, "vbscript:") &&
+		!strings.HasPrefix(lower, "data:")
+}
+
+// collapseWhitespace replaces whitespace sequences with a single space
+func collapseWhitespace(text string) string {
+	var buf strings.Builder
+	space := false
+	for _, r := range text {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f':
+			if !space {
+				buf.WriteByte(' ')
+				space = true
+			}
+		default:
+			buf.WriteRune(r)
+			space = false
+		}
+	}
+	return buf.String()
+}
+
+// trimRuns removes leading and trailing spaces of a paragraph and collapses
+// spaces across run boundaries
+func trimRuns(runs []types.Run) []types.Run {
+	trimmed := make([]types.Run, 0, len(runs))
+	for _, run := range runs {
+		if run.Image == nil && len(trimmed) > 0 && strings.HasPrefix(run.Text, " ") {
+			if last := trimmed[len(trimmed)-1]; last.Image == nil && (strings.HasSuffix(last.Text, " ") || strings.HasSuffix(last.Text, "\n")) {
+				run.Text = strings.TrimLeft(run.Text, " ")
+			}
+		}
+		if run.Image == nil && len(trimmed) == 0 {
+			run.Text = strings.TrimLeft(run.Text, " ")
+		}
+		if run.Image == nil && run.Text == "" {
+			continue
+		}
+		trimmed = append(trimmed, run)
+	}
+
+	for len(trimmed) > 0 {
+		last := &trimmed[len(trimmed)-1]
+		if last.Image != nil {
+			break
+		}
+		last.Text = strings.TrimRight(last.Text, " ")
+		if last.Text != "" {
+			break
+		}
+		trimmed = trimmed[:len(trimmed)-1]
+	}
+
+	return trimmed
+}
+
+// parseInlineCSS parses a style attribute into lower-case property names
+func parseInlineCSS(style string) map[string]string {
+	css := make(map[string]string)
+	for _, declaration := range strings.Split(style, ";") {
+		name, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		if name != "" && value != "" {
+			css[name] = value
+		}
+	}
+	return css
+}
+
+// applyCSSFormat applies inline CSS to the run formatting
+func applyCSSFormat(format runFormat, css map[string]string) runFormat {
+	if color := parseCSSColor(css["color"]); color != "" {
+		format.Color = color
+	}
+	if size := parseCSSFontSize(css["font-size"]); size > 0 {
+		format.FontSize = size
+	}
+	if family := parseCSSFontFamily(css["font-family"]); family != "" {
+		format.FontName = family
+	}
+
+	switch strings.ToLower(css["font-weight"]) {
+	case "bold", "bolder", "600", "700", "800", "900":
+		format.Bold = true
+	case "normal", "lighter", "100", "200", "300", "400", "500":
+		format.Bold = false
+	}
+	switch strings.ToLower(css["font-style"]) {
+	case "italic", "oblique":
+		format.Italic = true
+	case "normal":
+		format.Italic = false
+	}
+
+	decoration := strings.ToLower(css["text-decoration"] + " " + css["text-decoration-line"])
+	if strings.Contains(decoration, "underline") {
+		format.Underline = true
+	}
+	if strings.Contains(decoration, "line-through") {
+		format.Strike = true
+	}
+	if strings.Contains(decoration, "none") {
+		format.Underline, format.Strike = false, false
+	}
+
+	return format
+}
+
+// parseCSSColor converts a CSS color to an RRGGBB value
+func parseCSSColor(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case value == "":
+		return ""
+	case strings.HasPrefix(value, "#"):
+		hex := value[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return ""
+		}
+		if _, err := strconv.ParseUint(hex, 16, 32); err != nil {
+			return ""
+		}
+		return strings.ToUpper(hex)
+	case strings.HasPrefix(value, "rgb"):
+		open, close := strings.IndexByte(value, '('), strings.IndexByte(value, ')')
+		if open < 0 || close < open {
+			return ""
+		}
+		parts := strings.FieldsFunc(value[open+1:close], func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+		if len(parts) < 3 {
+			return ""
+		}
+		var rgb [3]int
+		for i := 0; i < 3; i++ {
+			if strings.HasSuffix(parts[i], "%") {
+				percent, err := strconv.ParseFloat(strings.TrimSuffix(parts[i], "%"), 64)
+				if err != nil {
+					return ""
+				}
+				rgb[i] = int(math.Round(percent * 255 / 100))
+			} else {
+				component, err := strconv.ParseFloat(parts[i], 64)
+				if err != nil {
+					return ""
+				}
+				rgb[i] = int(math.Round(component))
+			}
+			rgb[i] = max(0, min(255, rgb[i]))
+		}
+		return fmt.Sprintf("%02X%02X%02X", rgb[0], rgb[1], rgb[2])
+	default:
+		return htmlNamedColors[value]
+	}
+}
+
+// parseCSSFontSize converts a CSS font size to half-points
+func parseCSSFontSize(value string) int {
+	value = strings.ToLower(strings.TrimSpace(value))
+	keywords := map[string]float64{
+		"xx-small": 7, "x-small": 7.5, "small": 10, "medium": 12,
+		"large": 13.5, "x-large": 18, "xx-large": 24,
+	}
+	if points, ok := keywords[value]; ok {
+		return int(math.Round(points * 2))
+	}
+
+	var points float64
+	number := func(suffix string) (float64, bool) {
+		if !strings.HasSuffix(value, suffix) {
+			return 0, false
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, suffix)), 64)
+		return n, err == nil
+	}
+	if n, ok := number("pt"); ok {
+		points = n
+	} else if n, ok := number("px"); ok {
+		points = n * 0.75
+	} else if n, ok := number("rem"); ok {
+		points = n * 12
+	} else if n, ok := number("em"); ok {
+		points = n * 12
+	} else if n, ok := number("%"); ok {
+		points = n * 12 / 100
+	}
+
+	if points <= 0 {
+		return 0
+	}
+	return int(math.Round(points * 2))
+}
+
+// parseCSSFontFamily returns the first concrete font of a font-family list.
+// The generic monospace family maps to the code font.
+func parseCSSFontFamily(value string) string {
+	for _, family := range strings.Split(value, ",") {
+		family = strings.Trim(strings.TrimSpace(family), `"'`)
+		switch strings.ToLower(family) {
+		case "":
+			continue
+		case "monospace":
+			return markdownCodeFont
+		case "serif", "sans-serif", "cursive", "fantasy", "system-ui", "inherit", "initial":
+			continue
+		}
+		return family
+	}
+	return ""
+}
+
+// parseCSSPixels returns an image dimension in pixels from an attribute or
+// a CSS length
+func parseCSSPixels(attr, css string) float64 {
+	for _, value := range []string{css, attr} {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" || strings.HasSuffix(value, "%") {
+			continue
+		}
+		if n, err := strconv.ParseFloat(strings.TrimSuffix(value, "px"), 64); err == nil && n > 0 {
+			return n
+		}
+		if n, err := strconv.ParseFloat(strings.TrimSuffix(value, "pt"), 64); err == nil && n > 0 {
+			return n * 4 / 3
+		}
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTMLImporterImport(t *testing.T) {
+	dir := t.TempDir()
+
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	dataURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString(encoded.Bytes())
+
+	source := `<!DOCTYPE html>
+<html><head><title>t</title><style>p { color: red }</style></head>
+<body>
+  <h1>Title</h1>
+  <script>alert("x")</script>
+  <p style="text-align: center">Some <strong>bold</strong>, <em>italic</em>, <u>under</u> and
+    <span style="color: #f00; font-size: 16px; font-family: 'Courier New', monospace">styled</span>
+    with a <a href="https://example.com">link</a> and <a href="javascript:alert(1)">bad</a>.</p>
+  <ul>
+    <li>first
+      <ol start="3"><li>nested</li></ol>
+    </li>
+    <li>second</li>
+  </ul>
+  <table>
+    <thead><tr><th colspan="2">Wide</th></tr></thead>
+    <tr><td rowspan="2">Tall</td><td>x</td></tr>
+    <tr><td>y</td></tr>
+  </table>
+  <p><img src="` + dataURI + `" alt="Dot" width="16" height="16"></p>
+</body></html>`
+
+	htmlPath := filepath.Join(dir, "input.html")
+	if err := os.WriteFile(htmlPath, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write html: %v", err)
+	}
+
+	w := NewDocumentWriter()
+	if err := w.ImportHTMLFile(htmlPath); err != nil {
+		t.Fatalf("Failed to import html: %v", err)
+	}
+
+	content := w.Document.GetMainPart().Content
+	if len(content.Tables) != 1 {
+		t.Fatalf("Expected 1 table, got %d", len(content.Tables))
+	}
+	table := content.Tables[0]
+	if !table.Rows[0].Header || table.Rows[0].Cells[0].ColSpan != 2 {
+		t.Error("Expected a spanning header row")
+	}
+	if table.Rows[1].Cells[0].VMerge != "restart" || table.Rows[2].Cells[0].VMerge != "continue" {
+		t.Errorf("Expected vertically merged cells, got %+v", table.Rows[2].Cells)
+	}
+	if strings.Contains(content.Text, "alert") {
+		t.Error("Expected script content to be dropped")
+	}
+
+	output := filepath.Join(dir, "output.docx")
+	if err := w.Save(output); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+
+	documentXML := readZipPart(t, output, "word/document.xml")
+	for _, expected := range []string{
+		`<w:pStyle w:val="Heading1">`,
+		`<w:jc w:val="center">`,
+		`<w:b`,
+		`<w:i`,
+		`<w:u w:val="single"`,
+		`<w:color w:val="FF0000">`,
+		`<w:sz w:val="24">`,
+		`<w:rFonts w:ascii="Courier New"`,
+		`<w:hyperlink r:id="rId`,
+		`<w:numPr>`,
+		`<w:ilvl w:val="1">`,
+		`<w:gridSpan w:val="2">`,
+		`<w:vMerge w:val="restart">`,
+		`<w:vMerge>`,
+		`<wp:inline`,
+		`descr="Dot"`,
+	} {
+		if !strings.Contains(documentXML, expected) {
+			t.Errorf("Expected document.xml to contain %q", expected)
+		}
+	}
+
+	relsXML := readZipPart(t, output, "word/_rels/document.xml.rels")
+	if strings.Contains(relsXML, "javascript:") {
+		t.Error("Expected script hyperlinks to be dropped")
+	}
+	readZipPart(t, output, "word/media/image1.png")
+}
+
+func TestParseCSSValues(t *testing.T) {
+	colors := map[string]string{
+		"#abc":             "AABBCC",
+		"#336699":          "336699",
+		"rgb(255, 0, 128)": "FF0080",
+		"Navy":             "000080",
+		"invalid":          "",
+	}
+	for value, expected := range colors {
+		if got := parseCSSColor(value); got != expected {
+			t.Errorf("parseCSSColor(%q) = %q, expected %q", value, got, expected)
+		}
+	}
+
+	sizes := map[string]int{"12pt": 24, "16px": 24, "1.5em": 36, "large": 27, "bogus": 0}
+	for value, expected := range sizes {
+		if got := parseCSSFontSize(value); got != expected {
+			t.Errorf("parseCSSFontSize(%q) = %d, expected %d", value, got, expected)
+		}
+	}
+}
+
+func TestHTMLImporterWithoutDocument(t *testing.T) {
+	importer := NewHTMLImporter(NewDocumentWriter(), "")
+	if err := importer.Import([]byte("<p>text</p>")); err == nil {
+		t.Error("Expected error when document not initialized")
+	}
+}
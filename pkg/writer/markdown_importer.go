@@ -0,0 +1,459 @@
+package writer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/utils"
+)
+
+// markdownCodeFont is the font used for inline code spans
+const markdownCodeFont = "Consolas"
+
+// MarkdownImporter converts CommonMark/GFM Markdown into paragraphs, lists,
+// tables, hyperlinks and images of a document created by a DocumentWriter
+type MarkdownImporter struct {
+	Writer *DocumentWriter
+	// BaseDir is used to resolve relative image paths
+	BaseDir string
+	Logger  *utils.Logger
+
+	source []byte
+}
+
+// runFormat is the inline formatting in effect while walking the AST
+type runFormat struct {
+	Bold      bool
+	Italic    bool
+	Underline bool
+	Strike    bool
+	Code      bool
+	Hyperlink string
+	Color     string
+	// FontSize is in half-points
+	FontSize int
+	FontName string
+}
+
+// NewMarkdownImporter creates a Markdown importer that appends to the writer's document
+func NewMarkdownImporter(writer *DocumentWriter, baseDir string) *MarkdownImporter {
+	return &MarkdownImporter{
+		Writer:  writer,
+		BaseDir: baseDir,
+		Logger:  utils.NewLogger(utils.LogLevelInfo, nil),
+	}
+}
+
+// ImportMarkdownFile creates a new document from a Markdown file. Relative
+// image paths are resolved against the directory of the file.
+func (w *DocumentWriter) ImportMarkdownFile(filename string) error {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read markdown file: %w", err)
+	}
+
+	if w.Document == nil {
+		if err := w.CreateNewDocument(); err != nil {
+			return err
+		}
+	}
+
+	return NewMarkdownImporter(w, filepath.Dir(filename)).Import(source)
+}
+
+// Import parses the Markdown source and appends its content to the document
+func (mi *MarkdownImporter) Import(source []byte) error {
+	if mi.Writer == nil || mi.Writer.Document == nil {
+		return fmt.Errorf("document not initialized")
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	root := md.Parser().Parse(text.NewReader(source))
+
+	mi.source = source
+	defer func() { mi.source = nil }()
+
+	return mi.convertBlocks(root, "")
+}
+
+// convertBlocks converts the block children of a node. Paragraphs without
+// a more specific style get the given style.
+func (mi *MarkdownImporter) convertBlocks(parent ast.Node, style string) error {
+	for node := parent.FirstChild(); node != nil; node = node.NextSibling() {
+		if err := mi.convertBlock(node, style); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertBlock converts a single block node
+func (mi *MarkdownImporter) convertBlock(node ast.Node, style string) error {
+	switch n := node.(type) {
+	case *ast.Heading:
+		return mi.appendParagraph(fmt.Sprintf("Heading%d", n.Level), "", mi.convertInlines(n, runFormat{}))
+
+	case *ast.Paragraph, *ast.TextBlock:
+		return mi.appendParagraph(style, "", mi.convertInlines(n, runFormat{}))
+
+	case *ast.Blockquote:
+		return mi.convertBlocks(n, "Quote")
+
+	case *ast.List:
+		return mi.convertList(n, 0)
+
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		return mi.convertCodeBlock(n)
+
+	case *ast.ThematicBreak:
+		return mi.appendParagraph("HorizontalLine", "", nil)
+
+	case *ast.HTMLBlock:
+		// Raw HTML is kept as literal text
+		return mi.convertCodeBlock(n)
+
+	case *extast.Table:
+		return mi.convertTable(n)
+
+	default:
+		return mi.convertBlocks(n, style)
+	}
+}
+
+// appendParagraph adds a paragraph built from runs to the document
+func (mi *MarkdownImporter) appendParagraph(style, alignment string, runs []types.Run) error {
+	return mi.Writer.AppendParagraph(newParagraph(style, alignment, runs))
+}
+
+// newParagraph creates a paragraph whose text is the concatenation of its runs
+func newParagraph(style, alignment string, runs []types.Run) types.Paragraph {
+	var text strings.Builder
+	for _, run := range runs {
+		text.WriteString(run.Text)
+	}
+
+	return types.Paragraph{
+		Text:      text.String(),
+		Style:     style,
+		Alignment: alignment,
+		Runs:      runs,
+	}
+}
+
+// convertList converts a bullet or ordered list and its nested lists
+func (mi *MarkdownImporter) convertList(list *ast.List, level int) error {
+	kind := BulletList
+	if list.IsOrdered() {
+		kind = DecimalList
+	}
+	numID, err := mi.Writer.NewList(kind, list.Start)
+	if err != nil {
+		return err
+	}
+
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		first := true
+		for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+			switch c := child.(type) {
+			case *ast.List:
+				if err := mi.convertList(c, level+1); err != nil {
+					return err
+				}
+			case *ast.Paragraph, *ast.TextBlock:
+				if first {
+					if err := mi.Writer.AddListItem(numID, level, mi.convertInlines(c, runFormat{})); err != nil {
+						return err
+					}
+				} else {
+					// Continuation paragraphs stay indented without a number
+					if err := mi.appendParagraph("ListParagraph", "", mi.convertInlines(c, runFormat{})); err != nil {
+						return err
+					}
+				}
+			default:
+				if err := mi.convertBlock(c, "ListParagraph"); err != nil {
+					return err
+				}
+			}
+			first = false
+		}
+	}
+
+	return nil
+}
+
+// convertCodeBlock adds one SourceCode paragraph per line of a code block
+func (mi *MarkdownImporter) convertCodeBlock(node ast.Node) error {
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		line := strings.TrimRight(string(segment.Value(mi.source)), "\r\n")
+		if err := mi.appendParagraph("SourceCode", "", []types.Run{{Text: line}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertTable converts a GFM table, using the first row as header
+func (mi *MarkdownImporter) convertTable(table *extast.Table) error {
+	result := types.Table{
+		Columns: len(table.Alignments),
+	}
+
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		_, header := row.(*extast.TableHeader)
+		tableRow := types.TableRow{Header: header}
+
+		column := 0
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			alignment := ""
+			if column < len(table.Alignments) {
+				alignment = markdownAlignment(table.Alignments[column])
+			}
+
+			runs := mi.convertInlines(cell, runFormat{Bold: header})
+			paragraph := newParagraph("", alignment, runs)
+			tableRow.Cells = append(tableRow.Cells, types.TableCell{
+				Text:       paragraph.Text,
+				Paragraphs: []types.Paragraph{paragraph},
+			})
+			column++
+		}
+
+		result.Rows = append(result.Rows, tableRow)
+	}
+
+	return mi.Writer.AppendTable(result)
+}
+
+// markdownAlignment maps a GFM column alignment to a paragraph justification
+func markdownAlignment(alignment extast.Alignment) string {
+	switch alignment {
+	case extast.AlignCenter:
+		return "center"
+	case extast.AlignRight:
+		return "right"
+	default:
+		return ""
+	}
+}
+
+// convertInlines converts the inline children of a node into runs
+func (mi *MarkdownImporter) convertInlines(parent ast.Node, format runFormat) []types.Run {
+	var runs []types.Run
+	for node := parent.FirstChild(); node != nil; node = node.NextSibling() {
+		runs = append(runs, mi.convertInline(node, format)...)
+	}
+	return mergeRuns(runs)
+}
+
+// convertInline converts a single inline node into runs
+func (mi *MarkdownImporter) convertInline(node ast.Node, format runFormat) []types.Run {
+	switch n := node.(type) {
+	case *ast.Text:
+		value := string(n.Text(mi.source))
+		switch {
+		case n.HardLineBreak():
+			value += "\n"
+		case n.SoftLineBreak():
+			value += " "
+		}
+		return []types.Run{newRun(value, format)}
+
+	case *ast.String:
+		return []types.Run{newRun(string(n.Value), format)}
+
+	case *ast.Emphasis:
+		if n.Level >= 2 {
+			format.Bold = true
+		} else {
+			format.Italic = true
+		}
+		return mi.convertInlines(n, format)
+
+	case *extast.Strikethrough:
+		format.Strike = true
+		return mi.convertInlines(n, format)
+
+	case *ast.CodeSpan:
+		format.Code = true
+		var code strings.Builder
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			if t, ok := child.(*ast.Text); ok {
+				code.Write(t.Segment.Value(mi.source))
+			}
+		}
+		return []types.Run{newRun(code.String(), format)}
+
+	case *ast.Link:
+		format.Hyperlink = string(n.Destination)
+		return mi.convertInlines(n, format)
+
+	case *ast.AutoLink:
+		label := string(n.Label(mi.source))
+		target := string(n.URL(mi.source))
+		if n.AutoLinkType == ast.AutoLinkEmail && !strings.HasPrefix(target, "mailto:") {
+			target = "mailto:" + target
+		}
+		format.Hyperlink = target
+		return []types.Run{newRun(label, format)}
+
+	case *ast.Image:
+		return []types.Run{mi.convertImage(n, format)}
+
+	case *extast.TaskCheckBox:
+		if n.IsChecked {
+			return []types.Run{newRun("☒ ", format)}
+		}
+		return []types.Run{newRun("☐ ", format)}
+
+	case *ast.RawHTML:
+		var raw strings.Builder
+		for i := 0; i < n.Segments.Len(); i++ {
+			segment := n.Segments.At(i)
+			raw.Write(segment.Value(mi.source))
+		}
+		return []types.Run{newRun(raw.String(), format)}
+
+	default:
+		return mi.convertInlines(n, format)
+	}
+}
+
+// convertImage embeds local and data URI images. Remote images are kept as
+// a hyperlink with the alternative text because they cannot be embedded offline.
+func (mi *MarkdownImporter) convertImage(node *ast.Image, format runFormat) types.Run {
+	destination := string(node.Destination)
+	alt := plainText(node, mi.source)
+
+	image := &types.Image{
+		AltText: alt,
+		Title:   string(node.Title),
+	}
+
+	switch {
+	case strings.HasPrefix(destination, "data:"):
+		data, err := decodeDataURI(destination)
+		if err != nil {
+			mi.Logger.Warning("无法解码图片数据: %v", err)
+			return newRun(alt, format)
+		}
+		image.Data = data
+
+	case isRemoteURL(destination):
+		format.Hyperlink = destination
+		return newRun(alt, format)
+
+	default:
+		path, err := url.PathUnescape(destination)
+		if err != nil {
+			path = destination
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(mi.BaseDir, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			mi.Logger.Warning("找不到图片文件: %s", path)
+			return newRun(alt, format)
+		}
+		image.Path = path
+	}
+
+	run := newRun("", format)
+	run.Image = image
+	return run
+}
+
+// newRun creates a run with the given inline formatting
+func newRun(value string, format runFormat) types.Run {
+	run := types.Run{
+		Text:      value,
+		Bold:      format.Bold,
+		Italic:    format.Italic,
+		Underline: format.Underline,
+		Strike:    format.Strike,
+		Hyperlink: format.Hyperlink,
+		Color:     format.Color,
+		FontSize:  format.FontSize,
+		FontName:  format.FontName,
+	}
+	if format.Code {
+		run.FontName = markdownCodeFont
+	}
+	return run
+}
+
+// mergeRuns joins adjacent text runs that share the same formatting
+func mergeRuns(runs []types.Run) []types.Run {
+	merged := make([]types.Run, 0, len(runs))
+	for _, run := range runs {
+		if n := len(merged); n > 0 && sameRunFormat(merged[n-1], run) {
+			merged[n-1].Text += run.Text
+			continue
+		}
+		merged = append(merged, run)
+	}
+	return merged
+}
+
+// sameRunFormat reports whether two text runs can be merged
+func sameRunFormat(a, b types.Run) bool {
+	return a.Image == nil && b.Image == nil &&
+		a.Bold == b.Bold && a.Italic == b.Italic && a.Underline == b.Underline &&
+		a.Strike == b.Strike && a.FontSize == b.FontSize && a.FontName == b.FontName &&
+		a.Color == b.Color && a.Hyperlink == b.Hyperlink
+}
+
+// plainText returns the text content of a node without formatting
+func plainText(node ast.Node, source []byte) string {
+	var buf strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch c := child.(type) {
+		case *ast.Text:
+			buf.Write(c.Text(source))
+		case *ast.String:
+			buf.Write(c.Value)
+		default:
+			buf.WriteString(plainText(c, source))
+		}
+	}
+	return buf.String()
+}
+
+// isRemoteURL reports whether a link destination points to another host
+func isRemoteURL(destination string) bool {
+	lower := strings.ToLower(destination)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+		strings.HasPrefix(lower, "//")
+}
+
+// decodeDataURI returns the payload of a base64 or percent-encoded data URI
+func decodeDataURI(uri string) ([]byte, error) {
+	comma := strings.IndexByte(uri, ',')
+	if comma < 0 {
+		return nil, fmt.Errorf("invalid data URI")
+	}
+
+	meta, payload := uri[len("data:"):comma], uri[comma+1:]
+	if strings.HasSuffix(meta, ";base64") {
+		return base64.StdEncoding.DecodeString(payload)
+	}
+
+	decoded, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(decoded), nil
+}
@@ -0,0 +1,160 @@
+package writer
+
+import (
+	"archive/zip"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readZipPart returns the content of a part in a saved docx package
+func readZipPart(t *testing.T, filename, name string) string {
+	t.Helper()
+
+	reader, err := zip.OpenReader(filename)
+	if err != nil {
+		t.Fatalf("Failed to open package: %v", err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		if file.Name != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open part %s: %v", name, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("Failed to read part %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	t.Fatalf("Part %s not found", name)
+	return ""
+}
+
+func TestMarkdownImporterImport(t *testing.T) {
+	dir := t.TempDir()
+
+	// 准备本地图片
+	imageFile, err := os.Create(filepath.Join(dir, "logo.png"))
+	if err != nil {
+		t.Fatalf("Failed to create image: %v", err)
+	}
+	if err := png.Encode(imageFile, image.NewRGBA(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	imageFile.Close()
+
+	source := strings.Join([]string{
+		"# Title",
+		"",
+		"Some **bold**, *italic*, ~~gone~~ and `code` with a [link](https://example.com).",
+		"",
+		"- first",
+		"  - nested",
+		"- second",
+		"",
+		"3. three",
+		"4. four",
+		"",
+		"- [x] done",
+		"",
+		"| Name | Value |",
+		"|:-----|------:|",
+		"| a    | 1     |",
+		"",
+		"> quoted",
+		"",
+		"```",
+		"line 1",
+		"line 2",
+		"```",
+		"",
+		"---",
+		"",
+		"![Logo](logo.png \"Company logo\")",
+		"",
+		"After table",
+	}, "\n")
+
+	mdPath := filepath.Join(dir, "input.md")
+	if err := os.WriteFile(mdPath, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write markdown: %v", err)
+	}
+
+	w := NewDocumentWriter()
+	if err := w.ImportMarkdownFile(mdPath); err != nil {
+		t.Fatalf("Failed to import markdown: %v", err)
+	}
+
+	content := w.Document.GetMainPart().Content
+	if len(content.Tables) != 1 {
+		t.Fatalf("Expected 1 table, got %d", len(content.Tables))
+	}
+	if !content.Tables[0].Rows[0].Header {
+		t.Error("Expected first table row to be a header")
+	}
+	if content.Tables[0].Rows[1].Cells[1].Paragraphs[0].Alignment != "right" {
+		t.Error("Expected right aligned column")
+	}
+
+	output := filepath.Join(dir, "output.docx")
+	if err := w.Save(output); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+
+	documentXML := readZipPart(t, output, "word/document.xml")
+	for _, expected := range []string{
+		`<w:pStyle w:val="Heading1">`,
+		`<w:b`,
+		`<w:i`,
+		`<w:strike`,
+		`<w:rFonts w:ascii="Consolas"`,
+		`<w:hyperlink r:id="rId`,
+		`<w:numPr>`,
+		`<w:ilvl w:val="1">`,
+		`☒ done`,
+		`<w:tbl>`,
+		`<w:tblHeader`,
+		`<w:jc w:val="right">`,
+		`<w:pStyle w:val="Quote">`,
+		`<w:pStyle w:val="SourceCode">`,
+		`<w:pStyle w:val="HorizontalLine">`,
+		`<wp:inline`,
+		`descr="Logo"`,
+	} {
+		if !strings.Contains(documentXML, expected) {
+			t.Errorf("Expected document.xml to contain %q", expected)
+		}
+	}
+
+	numberingXML := readZipPart(t, output, "word/numbering.xml")
+	if !strings.Contains(numberingXML, `<w:startOverride w:val="3"/>`) {
+		t.Error("Expected ordered list to start at 3")
+	}
+
+	relsXML := readZipPart(t, output, "word/_rels/document.xml.rels")
+	if !strings.Contains(relsXML, `Target="https://example.com" TargetMode="External"`) {
+		t.Error("Expected external hyperlink relationship")
+	}
+	if !strings.Contains(relsXML, `Target="media/image1.png"`) {
+		t.Error("Expected image relationship")
+	}
+	readZipPart(t, output, "word/media/image1.png")
+}
+
+func TestMarkdownImporterWithoutDocument(t *testing.T) {
+	importer := NewMarkdownImporter(NewDocumentWriter(), "")
+	if err := importer.Import([]byte("# Title")); err == nil {
+		t.Error("Expected error when document not initialized")
+	}
+}
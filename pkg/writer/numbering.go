@@ -0,0 +1,366 @@
+package writer
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/word"
+)
+
+// ListKind identifies the numbering format of a list created by the writer
+type ListKind string
+
+const (
+	// BulletList renders items with bullet symbols
+	BulletList ListKind = "bullet"
+	// DecimalList renders items as 1. 2. 3.
+	DecimalList ListKind = "decimal"
+	// LowerLetterList renders items as a. b. c.
+	LowerLetterList ListKind = "lowerLetter"
+	// UpperLetterList renders items as A. B. C.
+	UpperLetterList ListKind = "upperLetter"
+	// LowerRomanList renders items as i. ii. iii.
+	LowerRomanList ListKind = "lowerRoman"
+	// UpperRomanList renders items as I. II. III.
+	UpperRomanList ListKind = "upperRoman"
+	// ChineseCountingList renders items as 一、二、三、
+	ChineseCountingList ListKind = "chineseCounting"
+	// OutlineList numbers nested items with the numbers of their parents,
+	// as 1. 1.1. 1.1.1.
+	OutlineList ListKind = "outline"
+)
+
+// maxListLevels is the number of levels of a list definition
+const maxListLevels = 9
+
+// ListLevel describes the numbering of one level of a multilevel list
+type ListLevel struct {
+	// Kind is the number format of the level. OutlineList is not a level
+	// format; use DecimalList with a Text such as "%1.%2.".
+	Kind ListKind
+	// Text is the label of the items, such as "%1.%2)" or "(%2)", where
+	// %N stands for the current number of level N. Empty uses the default
+	// label of Kind.
+	Text string
+	// Start is the first number of the level; values below one start at one
+	Start int
+}
+
+// listDefinition represents a w:abstractNum entry shared by the lists
+// that number their items alike
+type listDefinition struct {
+	// Kind is empty for definitions created by NewMultilevelList
+	Kind       ListKind
+	Levels     [maxListLevels]ListLevel
+	Multilevel bool
+}
+
+// listInstance represents a w:num entry that paragraphs refer to
+type listInstance struct {
+	NumID      int
+	Definition int
+	// Start restarts the numbering of the first level; zero continues the
+	// numbering of the lists before that share the definition
+	Start int
+}
+
+// bulletSymbols are cycled through the nine list levels
+var bulletSymbols = []string{"•", "◦", "▪"}
+
+// NewList registers a new list and returns the numId that list paragraphs
+// must reference. Numbering of every list starts over at start; values
+// below one start at one. Lists of the same kind share one definition.
+func (w *DocumentWriter) NewList(kind ListKind, start int) (int, error) {
+	if w.patch != nil {
+		return 0, fmt.Errorf("lists cannot be added when patching")
+	}
+	if start < 1 {
+		start = 1
+	}
+
+	definition := -1
+	for i, existing := range w.listDefinitions {
+		if existing.Kind == kind {
+			definition = i
+			break
+		}
+	}
+	if definition < 0 {
+		definition = w.addListDefinition(newListDefinition(kind))
+	}
+
+	return w.addList(definition, start), nil
+}
+
+// NewMultilevelList registers a list whose levels use different formats,
+// such as 1. a. i., and returns its numId. Levels after the given ones
+// repeat the format of the last given level.
+func (w *DocumentWriter) NewMultilevelList(levels []ListLevel) (int, error) {
+	if w.patch != nil {
+		return 0, fmt.Errorf("lists cannot be added when patching")
+	}
+	if len(levels) == 0 || len(levels) > maxListLevels {
+		return 0, fmt.Errorf("a list needs 1 to %d levels, got %d", maxListLevels, len(levels))
+	}
+
+	definition := listDefinition{Multilevel: true}
+	for level := range definition.Levels {
+		given := levels[min(level, len(levels)-1)]
+		if !isLevelKind(given.Kind) {
+			return 0, fmt.Errorf("unsupported list level format %q", given.Kind)
+		}
+		if level >= len(levels) {
+			given.Text = ""
+		}
+		definition.Levels[level] = newListLevel(given, level)
+	}
+
+	return w.addList(w.addListDefinition(definition), definition.Levels[0].Start), nil
+}
+
+// RestartList registers a list that numbers its items like the list numID
+// but starts over at start, and returns its numId
+func (w *DocumentWriter) RestartList(numID, start int) (int, error) {
+	if w.patch != nil {
+		return 0, fmt.Errorf("lists cannot be added when patching")
+	}
+	if numID < 1 || numID > len(w.lists) {
+		return 0, fmt.Errorf("list %d not found", numID)
+	}
+	if start < 1 {
+		start = 1
+	}
+	return w.addList(w.lists[numID-1].Definition, start), nil
+}
+
+// ContinueList registers a list that goes on with the numbering of the
+// list numID, such as after paragraphs that interrupt it, and returns its
+// numId
+func (w *DocumentWriter) ContinueList(numID int) (int, error) {
+	if w.patch != nil {
+		return 0, fmt.Errorf("lists cannot be added when patching")
+	}
+	if numID < 1 || numID > len(w.lists) {
+		return 0, fmt.Errorf("list %d not found", numID)
+	}
+	return w.addList(w.lists[numID-1].Definition, 0), nil
+}
+
+// addListDefinition stores a definition and returns its index
+func (w *DocumentWriter) addListDefinition(definition listDefinition) int {
+	w.listDefinitions = append(w.listDefinitions, definition)
+	return len(w.listDefinitions) - 1
+}
+
+// addList stores a list instance and returns its numId
+func (w *DocumentWriter) addList(definition, start int) int {
+	numID := len(w.lists) + 1
+	w.lists = append(w.lists, listInstance{
+		NumID:      numID,
+		Definition: definition,
+		Start:      start,
+	})
+	return numID
+}
+
+// isLevelKind reports whether kind is the number format of a level
+func isLevelKind(kind ListKind) bool {
+	switch kind {
+	case BulletList, DecimalList, LowerLetterList, UpperLetterList, LowerRomanList, UpperRomanList, ChineseCountingList:
+		return true
+	}
+	return false
+}
+
+// newListDefinition creates the definition of a list kind. Unknown kinds
+// are numbered as decimal lists.
+func newListDefinition(kind ListKind) listDefinition {
+	definition := listDefinition{Kind: kind, Multilevel: kind == OutlineList}
+	for level := range definition.Levels {
+		levelKind := kind
+		text := ""
+		switch {
+		case kind == OutlineList:
+			// 大纲编号包含所有上级编号
+			levelKind = DecimalList
+			for parent := 0; parent <= level; parent++ {
+				text += fmt.Sprintf("%%%d.", parent+1)
+			}
+		case !isLevelKind(kind):
+			levelKind = DecimalList
+		}
+		definition.Levels[level] = newListLevel(ListLevel{Kind: levelKind, Text: text}, level)
+	}
+	return definition
+}
+
+// newListLevel fills the defaults of a level
+func newListLevel(level ListLevel, index int) ListLevel {
+	if level.Start < 1 {
+		level.Start = 1
+	}
+	if level.Text == "" {
+		switch level.Kind {
+		case BulletList:
+			level.Text = bulletSymbols[index%len(bulletSymbols)]
+		case ChineseCountingList:
+			level.Text = fmt.Sprintf("%%%d、", index+1)
+		default:
+			level.Text = fmt.Sprintf("%%%d.", index+1)
+		}
+	}
+	return level
+}
+
+// AddListItem adds a paragraph to a list registered with NewList
+func (w *DocumentWriter) AddListItem(numID, level int, runs []types.Run) error {
+	if w.patch != nil {
+		return fmt.Errorf("lists cannot be added when patching")
+	}
+	if numID < 1 || numID > len(w.lists) {
+		return fmt.Errorf("list %d not found", numID)
+	}
+	if level < 0 || level >= maxListLevels {
+		return fmt.Errorf("list level %d out of range", level)
+	}
+
+	var text strings.Builder
+	for _, run := range runs {
+		text.WriteString(run.Text)
+	}
+
+	return w.AppendParagraph(types.Paragraph{
+		Text:      text.String(),
+		Style:     "ListParagraph",
+		Runs:      runs,
+		NumID:     numID,
+		ListLevel: level,
+	})
+}
+
+// AddRichTextList adds the items of a list built with FormatSupport. A
+// list that does not restart goes on with the numbering of the previous
+// list of the same type.
+func (w *DocumentWriter) AddRichTextList(list *word.RichTextList) error {
+	if list == nil {
+		return fmt.Errorf("list is nil")
+	}
+
+	kind := DecimalList
+	if list.Type == word.BulletList || list.Type == word.CustomList {
+		kind = BulletList
+	}
+
+	numID := 0
+	if !list.Properties.Restart {
+		for i := len(w.lists) - 1; i >= 0; i-- {
+			if w.listDefinitions[w.lists[i].Definition].Kind == kind {
+				numID, _ = w.ContinueList(w.lists[i].NumID)
+				break
+			}
+		}
+	}
+	if numID == 0 {
+		var err error
+		if numID, err = w.NewList(kind, list.Properties.Start); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range list.Items {
+		font := item.Content.Formatting.Font
+		run := newRun(item.Content.Text, runFormat{
+			Bold:      font.Bold,
+			Italic:    font.Italic,
+			Underline: font.Underline,
+			Strike:    font.Strike,
+			Color:     strings.TrimPrefix(font.Color, "#"),
+			FontSize:  int(font.Size * 2),
+			FontName:  font.Name,
+		})
+		if err := w.AddListItem(numID, item.Level, []types.Run{run}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateNumberingXML generates the XML content for word/numbering.xml
+func (w *DocumentWriter) generateNumberingXML() []byte {
+	var numbering strings.Builder
+	numbering.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml" mc:Ignorable="w14">`)
+
+	// 每个定义对应一个abstractNum，必须位于所有num之前
+	for abstractID, definition := range w.listDefinitions {
+		numbering.WriteString(generateAbstractNumXML(abstractID, definition))
+	}
+
+	for _, list := range w.lists {
+		numbering.WriteString(fmt.Sprintf(`
+  <w:num w:numId="%d">
+    <w:abstractNumId w:val="%d"/>`, list.NumID, list.Definition))
+		// Word按定义累计编号，重新开始的列表需要覆盖起始编号
+		if list.Start > 0 {
+			numbering.WriteString(fmt.Sprintf(`
+    <w:lvlOverride w:ilvl="0">
+      <w:startOverride w:val="%d"/>
+    </w:lvlOverride>`, list.Start))
+		}
+		numbering.WriteString(`
+  </w:num>`)
+	}
+
+	numbering.WriteString(`
+</w:numbering>`)
+	return []byte(numbering.String())
+}
+
+// generateAbstractNumXML renders the nine levels of an abstract numbering definition
+func generateAbstractNumXML(abstractID int, definition listDefinition) string {
+	multiLevelType := "hybridMultilevel"
+	if definition.Multilevel {
+		multiLevelType = "multilevel"
+	}
+
+	var abstract strings.Builder
+	abstract.WriteString(fmt.Sprintf(`
+  <w:abstractNum w:abstractNumId="%d">
+    <w:multiLevelType w:val="%s"/>`, abstractID, multiLevelType))
+
+	for index, level := range definition.Levels {
+		// 多级编号的标签随级别变长，悬挂缩进随之增加
+		left, hanging := 720*(index+1), 360
+		if definition.Multilevel {
+			left = 432 + 144*index
+			hanging = left
+		} else if level.Kind == ChineseCountingList {
+			hanging = 720
+		}
+
+		font := ""
+		if level.Kind == BulletList {
+			font = `
+      <w:rPr>
+        <w:rFonts w:ascii="Arial" w:hAnsi="Arial" w:hint="default"/>
+      </w:rPr>`
+		}
+
+		abstract.WriteString(fmt.Sprintf(`
+    <w:lvl w:ilvl="%d">
+      <w:start w:val="%d"/>
+      <w:numFmt w:val="%s"/>
+      <w:lvlText w:val="%s"/>
+      <w:lvlJc w:val="left"/>
+      <w:pPr>
+        <w:ind w:left="%d" w:hanging="%d"/>
+      </w:pPr>%s
+    </w:lvl>`, index, level.Start, level.Kind, html.EscapeString(level.Text), left, hanging, font))
+	}
+
+	abstract.WriteString(`
+  </w:abstractNum>`)
+	return abstract.String()
+}
@@ -0,0 +1,187 @@
+package writer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tanqiangyes/go-word/pkg/types"
+	"github.com/tanqiangyes/go-word/pkg/word"
+)
+
+func TestDocumentWriterLists(t *testing.T) {
+	writer := NewDocumentWriter()
+	writer.CreateNewDocument()
+
+	item := func(numID, level int, text string) {
+		t.Helper()
+		if err := writer.AddListItem(numID, level, []types.Run{{Text: text}}); err != nil {
+			t.Fatalf("Failed to add list item: %v", err)
+		}
+	}
+	newList := func(kind ListKind, start int) int {
+		t.Helper()
+		numID, err := writer.NewList(kind, start)
+		if err != nil {
+			t.Fatalf("Failed to create list: %v", err)
+		}
+		return numID
+	}
+
+	steps := newList(DecimalList, 1)
+	item(steps, 0, "Collect data")
+	item(steps, 1, "Sales")
+	writer.AddParagraph("An interruption", "Normal")
+	continued, err := writer.ContinueList(steps)
+	if err != nil {
+		t.Fatalf("Failed to continue list: %v", err)
+	}
+	item(continued, 0, "Analyse data")
+	restarted, err := writer.RestartList(steps, 5)
+	if err != nil {
+		t.Fatalf("Failed to restart list: %v", err)
+	}
+	item(restarted, 0, "Fifth")
+
+	bullets := newList(BulletList, 0)
+	item(bullets, 2, "Deep bullet")
+	chinese := newList(ChineseCountingList, 1)
+	item(chinese, 0, "总则")
+	outline := newList(OutlineList, 1)
+	item(outline, 2, "1.1.1")
+	mixed, err := writer.NewMultilevelList([]ListLevel{{Kind: UpperRomanList}, {Kind: LowerLetterList, Text: "(%2)"}})
+	if err != nil {
+		t.Fatalf("Failed to create multilevel list: %v", err)
+	}
+	item(mixed, 3, "Fourth level")
+
+	list := word.NewFormatSupport(writer.Document).CreateRichTextList(word.NumberedList)
+	list.Items = append(list.Items, word.RichTextListItem{Content: word.RichTextContent{Text: "Rich", Formatting: word.RichTextFormatting{Font: word.Font{Bold: true}}}})
+	if err := writer.AddRichTextList(list); err != nil {
+		t.Fatalf("Failed to add rich text list: %v", err)
+	}
+
+	if _, err := writer.NewMultilevelList([]ListLevel{{Kind: OutlineList}}); err == nil {
+		t.Error("Expected an error for an outline level format")
+	}
+	if _, err := writer.ContinueList(42); err == nil {
+		t.Error("Expected an error for an unknown list")
+	}
+	if err := writer.AddListItem(steps, 9, nil); err == nil {
+		t.Error("Expected an error for a level out of range")
+	}
+
+	filename := filepath.Join(t.TempDir(), "lists.docx")
+	if err := writer.Save(filename); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+
+	numbering := readZipPart(t, filename, "word/numbering.xml")
+	for _, expected := range []string{
+		// 继续编号的列表不覆盖起始编号
+		"<w:num w:numId=\"2\">\n    <w:abstractNumId w:val=\"0\"/>\n  </w:num>",
+		"<w:num w:numId=\"3\">\n    <w:abstractNumId w:val=\"0\"/>\n    <w:lvlOverride w:ilvl=\"0\">\n      <w:startOverride w:val=\"5\"/>",
+		"<w:num w:numId=\"4\">\n    <w:abstractNumId w:val=\"1\"/>\n    <w:lvlOverride w:ilvl=\"0\">\n      <w:startOverride w:val=\"1\"/>",
+		`<w:lvlText w:val="▪"/>`,
+		`<w:numFmt w:val="chineseCounting"/>` + "\n      " + `<w:lvlText w:val="%1、"/>`,
+		`<w:multiLevelType w:val="multilevel"/>`,
+		`<w:lvlText w:val="%1.%2.%3."/>`,
+		`<w:numFmt w:val="upperRoman"/>`,
+		`<w:numFmt w:val="lowerLetter"/>` + "\n      " + `<w:lvlText w:val="(%2)"/>`,
+		`<w:numFmt w:val="lowerLetter"/>` + "\n      " + `<w:lvlText w:val="%4."/>`,
+	} {
+		if !strings.Contains(numbering, expected) {
+			t.Errorf("Expected %q in the numbering part", expected)
+		}
+	}
+	// 富文本列表不重新开始时继续之前的十进制列表
+	if !strings.Contains(numbering, "<w:num w:numId=\"8\">\n    <w:abstractNumId w:val=\"0\"/>\n  </w:num>") {
+		t.Error("Expected the rich text list to continue the decimal list")
+	}
+
+	doc, err := word.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open saved document: %v", err)
+	}
+	defer doc.Close()
+	var items []string
+	for _, paragraph := range doc.GetMainPart().Content.Paragraphs {
+		if paragraph.NumID > 0 {
+			items = append(items, fmt.Sprintf("%s@%d/%d", paragraph.Text, paragraph.NumID, paragraph.ListLevel))
+		}
+	}
+	expected := "Collect data@1/0 Sales@1/1 Analyse data@2/0 Fifth@3/0 Deep bullet@4/2 总则@5/0 1.1.1@6/2 Fourth level@7/3 Rich@8/0"
+	if got := strings.Join(items, " "); got != expected {
+		t.Errorf("Unexpected list paragraphs:\n got %s\nwant %s", got, expected)
+	}
+}
+
+func TestDocumentWriterListNumberingExport(t *testing.T) {
+	writer := NewDocumentWriter()
+	writer.CreateNewDocument()
+
+	item := func(numID int, text string) {
+		t.Helper()
+		if err := writer.AddListItem(numID, 0, []types.Run{{Text: text}}); err != nil {
+			t.Fatalf("Failed to add list item: %v", err)
+		}
+	}
+
+	steps, err := writer.NewList(DecimalList, 1)
+	if err != nil {
+		t.Fatalf("Failed to create list: %v", err)
+	}
+	item(steps, "one")
+	item(steps, "two")
+	writer.AddParagraph("An interruption", "Normal")
+	continued, err := writer.ContinueList(steps)
+	if err != nil {
+		t.Fatalf("Failed to continue list: %v", err)
+	}
+	item(continued, "three")
+	writer.AddParagraph("Another interruption", "Normal")
+	restarted, err := writer.RestartList(steps, 5)
+	if err != nil {
+		t.Fatalf("Failed to restart list: %v", err)
+	}
+	item(restarted, "five")
+	item(restarted, "six")
+	writer.AddParagraph("Last interruption", "Normal")
+	again, err := writer.ContinueList(steps)
+	if err != nil {
+		t.Fatalf("Failed to continue list: %v", err)
+	}
+	item(again, "seven")
+
+	filename := filepath.Join(t.TempDir(), "numbers.docx")
+	if err := writer.Save(filename); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+	doc, err := word.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open saved document: %v", err)
+	}
+	defer doc.Close()
+
+	// 共用抽象定义的列表继续编号，覆盖起始编号的列表重新编号
+	markdown, err := word.NewMarkdownExporter(doc, nil).ToMarkdown()
+	if err != nil {
+		t.Fatalf("Failed to export markdown: %v", err)
+	}
+	for _, expected := range []string{"1. one\n2. two", "3. three", "5. five\n6. six", "7. seven"} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected %q in the markdown:\n%s", expected, markdown)
+		}
+	}
+
+	html, err := word.NewHTMLExporter(doc, nil).ToHTML()
+	if err != nil {
+		t.Fatalf("Failed to export HTML: %v", err)
+	}
+	for _, expected := range []string{`<ol start="3">`, `<ol start="5">`, `<ol start="7">`} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in the HTML", expected)
+		}
+	}
+}
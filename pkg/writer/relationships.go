@@ -0,0 +1,90 @@
+package writer
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Relationship types used by parts the writer generates on demand
+const (
+	hyperlinkRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	imageRelationshipType     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+	numberingRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+	headerRelationshipType    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/header"
+	footerRelationshipType    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer"
+)
+
+// firstDynamicRelationshipID is the first rId handed out for relationships
+// created while generating the document. rId1-rId5 are reserved for the
+// fixed parts written by generateDocumentRelsXML.
+const firstDynamicRelationshipID = 6
+
+// defaultTextWidth is the width of the text area in twips for the default
+// A4 page with 1800 twip side margins
+const defaultTextWidth = 11906 - 2*1800
+
+// packageRelationship represents a relationship of the main document part
+type packageRelationship struct {
+	ID       string
+	Type     string
+	Target   string
+	External bool
+}
+
+// mediaPart represents a binary part stored under word/media
+type mediaPart struct {
+	Name        string
+	RelID       string
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+	// DPI is the resolution stored in the picture; zero when unknown
+	DPI float64
+	// Fallback is the bitmap shown instead of an SVG picture by
+	// applications that cannot show SVG
+	Fallback *mediaPart
+}
+
+// resetPackageState clears the relationships and media collected while
+// generating the previous document XML
+func (w *DocumentWriter) resetPackageState() {
+	w.relationships = nil
+	w.media = nil
+	w.mediaByPath = make(map[string]*mediaPart)
+	w.drawingID = 0
+	w.headerFooterParts = nil
+}
+
+// addRelationship registers a relationship of the main document part and
+// returns its ID
+func (w *DocumentWriter) addRelationship(relType, target string, external bool) string {
+	id := fmt.Sprintf("rId%d", firstDynamicRelationshipID+len(w.relationships))
+	w.relationships = append(w.relationships, packageRelationship{
+		ID:       id,
+		Type:     relType,
+		Target:   target,
+		External: external,
+	})
+	return id
+}
+
+// generateRelationshipsXML renders the dynamic relationships
+func (w *DocumentWriter) generateRelationshipsXML() string {
+	return relationshipsXML(w.relationships)
+}
+
+// relationshipsXML renders relationships as Relationship elements
+func relationshipsXML(relationships []packageRelationship) string {
+	var rels strings.Builder
+	for _, rel := range relationships {
+		targetMode := ""
+		if rel.External {
+			targetMode = ` TargetMode="External"`
+		}
+		rels.WriteString(fmt.Sprintf(`
+  <Relationship Id="%s" Type="%s" Target="%s"%s/>`, rel.ID, rel.Type, html.EscapeString(rel.Target), targetMode))
+	}
+	return rels.String()
+}
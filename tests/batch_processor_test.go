@@ -2,9 +2,13 @@ package tests
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/tanqiangyes/go-word/pkg/types"
 	"github.com/tanqiangyes/go-word/pkg/word"
 )
 
@@ -297,3 +301,38 @@ func TestOperationTypes(t *testing.T) {
 }
 
 // 辅助函数
+
+func TestProcessBatchConvertToHTML(t *testing.T) {
+	processor := word.NewBatchProcessor(1)
+
+	document, err := word.New()
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer document.Close()
+	content := document.GetMainPart().Content
+	content.Paragraphs = append(content.Paragraphs, types.Paragraph{Text: "Quarterly report", Runs: []types.Run{{Text: "Quarterly report"}}})
+	processor.AddDocument(document)
+
+	outputDir := filepath.Join(t.TempDir(), "html")
+	processor.AddOperation(word.BatchOperation{
+		Type:       word.ConvertFormat,
+		Parameters: map[string]interface{}{"target_format": "html", "output_dir": outputDir},
+	})
+	if err := processor.ProcessBatch(); err != nil {
+		t.Fatalf("Failed to process batch: %v", err)
+	}
+	select {
+	case batchErr := <-processor.GetErrorChannel():
+		t.Fatalf("Unexpected batch error: %v", batchErr.Error)
+	default:
+	}
+
+	html, err := os.ReadFile(filepath.Join(outputDir, "doc_0.html"))
+	if err != nil {
+		t.Fatalf("Expected the HTML file to be written: %v", err)
+	}
+	if !strings.Contains(string(html), "Quarterly report") {
+		t.Errorf("Expected the paragraph in the HTML output, got:\n%s", html)
+	}
+}
@@ -1,12 +1,21 @@
 package tests
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"image"
+	"image/png"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/tanqiangyes/go-word/pkg/types"
 	"github.com/tanqiangyes/go-word/pkg/utils"
 	"github.com/tanqiangyes/go-word/pkg/word"
+	"github.com/tanqiangyes/go-word/pkg/writer"
 )
 
 // TestImageProcessor 测试图片处理器
@@ -184,3 +193,103 @@ func TestImageProcessorErrorHandling(t *testing.T) {
 		t.Error("应该返回错误，但未返回")
 	}
 }
+
+// TestImageProcessorInsertImageIntoDocument 测试将图片插入文档
+func TestImageProcessorInsertImageIntoDocument(t *testing.T) {
+	logger := *utils.NewLogger(utils.LogLevelInfo, nil)
+	ip := word.NewImageProcessor(logger, &word.ImageProcessorConfig{MaxImages: 10, MaxImageSize: 1024 * 1024})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 80, 40))); err != nil {
+		t.Fatalf("编码测试图片失败: %v", err)
+	}
+	testImagePath := filepath.Join(t.TempDir(), "logo.png")
+	if err := os.WriteFile(testImagePath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("创建测试图片文件失败: %v", err)
+	}
+	loaded, err := ip.LoadImage(context.Background(), testImagePath)
+	if err != nil {
+		t.Fatalf("加载图片失败: %v", err)
+	}
+	if loaded.Size.Width != 80 || loaded.Size.Height != 40 {
+		t.Errorf("图片尺寸错误: %+v", loaded.Size)
+	}
+
+	docWriter := writer.NewDocumentWriter()
+	if err := docWriter.CreateNewDocument(); err != nil {
+		t.Fatalf("创建文档失败: %v", err)
+	}
+	doc := docWriter.Document
+
+	position := &word.ImageProcessorPosition{
+		X:         36,
+		Y:         18,
+		Alignment: word.ImageProcessorAlignmentLeft,
+		Wrapping:  word.ImageProcessorWrappingInFront,
+	}
+	if err := ip.InsertImageIntoDocument(context.Background(), doc, loaded.ID, position); err != nil {
+		t.Fatalf("插入图片失败: %v", err)
+	}
+
+	paragraphs := doc.GetMainPart().Content.Paragraphs
+	if len(paragraphs) != 1 || len(paragraphs[0].Runs) != 1 || paragraphs[0].Runs[0].Image == nil {
+		t.Fatalf("文档中应该有一个图片段落: %+v", paragraphs)
+	}
+	inserted := paragraphs[0].Runs[0].Image
+	if inserted.Position != types.ImagePositionFloating || inserted.Wrapping != "inFront" || inserted.Width != 80 {
+		t.Errorf("图片属性错误: %+v", inserted)
+	}
+	if inserted.Anchor == nil || inserted.Anchor.X != 36 || inserted.Anchor.Y != 18 || inserted.Alignment != "" {
+		t.Errorf("图片位置错误: %+v", inserted.Anchor)
+	}
+
+	// 嵌入型环绕的图片在文字行中，段落按图片对齐
+	inline := &word.ImageProcessorPosition{Alignment: word.ImageProcessorAlignmentCenter, Wrapping: word.ImageProcessorWrappingInline}
+	if err := ip.InsertImageIntoDocument(context.Background(), doc, loaded.ID, inline); err != nil {
+		t.Fatalf("插入嵌入型图片失败: %v", err)
+	}
+	if paragraph := doc.GetMainPart().Content.Paragraphs[1]; paragraph.Runs[0].Image.IsFloating() || paragraph.Alignment != "center" {
+		t.Errorf("嵌入型图片属性错误: %+v", paragraph)
+	}
+
+	output := filepath.Join(t.TempDir(), "images.docx")
+	if err := docWriter.Save(output); err != nil {
+		t.Fatalf("保存文档失败: %v", err)
+	}
+	reader, err := zip.OpenReader(output)
+	if err != nil {
+		t.Fatalf("打开保存的文档失败: %v", err)
+	}
+	defer reader.Close()
+	parts := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("读取部件 %s 失败: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[file.Name] = string(data)
+	}
+
+	documentXML := parts["word/document.xml"]
+	for _, expected := range []string{
+		`<wp:anchor `,
+		`<wp:positionH relativeFrom="column"><wp:posOffset>457200</wp:posOffset></wp:positionH>`,
+		`<wp:wrapNone/>`,
+		`<w:jc w:val="center">`,
+		`<wp:inline `,
+		`<wp:extent cx="762000" cy="381000"/>`,
+	} {
+		if !strings.Contains(documentXML, expected) {
+			t.Errorf("文档XML缺少 %s", expected)
+		}
+	}
+	if !strings.HasPrefix(parts["word/media/image1.png"], "\x89PNG") {
+		t.Error("应该写入图片部件 word/media/image1.png")
+	}
+	relationships := parts["word/_rels/document.xml.rels"]
+	if !strings.Contains(relationships, `Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"`) {
+		t.Errorf("缺少图片关系: %s", relationships)
+	}
+}